@@ -82,6 +82,10 @@ func main() {
 	// Setup router
 	router := gin.Default()
 	router.Use(gin.Recovery())
+	// Only listed proxies may set the client IP forwarded to services
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES", err)
+	}
 
 	// Initialize proxy handler with message broker
 	proxyHandler := handler.NewProxyHandler(cfg, log, messageBrokerService)
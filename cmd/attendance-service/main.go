@@ -13,6 +13,14 @@ import (
 	"unsri-backend/internal/attendance/handler"
 	"unsri-backend/internal/attendance/repository"
 	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/attendance/worker"
+	calendarRepo "unsri-backend/internal/calendar/repository"
+	courseRepo "unsri-backend/internal/course/repository"
+	fileRepo "unsri-backend/internal/file-storage/repository"
+	leaveRepo "unsri-backend/internal/leave/repository"
+	locationRepo "unsri-backend/internal/location/repository"
+	masterDataRepo "unsri-backend/internal/master-data/repository"
+	notificationRepo "unsri-backend/internal/notification/repository"
 	"unsri-backend/internal/shared/database"
 	"unsri-backend/internal/shared/logger"
 	"unsri-backend/internal/shared/models"
@@ -48,16 +56,52 @@ func main() {
 		&models.Attendance{},
 		&models.AttendanceSession{},
 		&models.Schedule{},
+		&models.AttendanceScanRejection{},
+		&models.LatenessPolicy{},
+		&models.AttendanceCorrectionRequest{},
+		&models.AttendanceChangeLog{},
+		&models.EligibilityPolicy{},
+		&models.EligibilityWarning{},
+		&models.DeviceBinding{},
+		&models.DeviceRebindRequest{},
+		&models.SharedDeviceFlag{},
+		&models.LocationAnomaly{},
+		&models.LectureJournal{},
 		// Work Attendance (HRIS) models
 		&models.ShiftPattern{},
 		&models.UserShift{},
 		&models.WorkSchedule{},
 		&models.WorkAttendanceSession{},
 		&models.WorkAttendanceRecord{},
+		&models.CheckInRequirement{},
+		&models.KioskDevice{},
+		&models.WorkAttendancePolicy{},
+		&models.WorkTimesheet{},
+		&models.WorkTimesheetDay{},
+		&models.ShiftSwapRequest{},
+		&models.ShiftSwapEvent{},
+		&models.WorkAttendanceSweep{},
+		&models.UnitSupervisor{},
+		&models.TukinRuleSet{},
+		&models.TukinRule{},
+		&models.TukinDeduction{},
+		&models.TukinDeductionLine{},
 	); err != nil {
 		log.Fatal("Failed to migrate database", err)
 	}
 
+	// Initialize Redis, used to fan out live roster events across replicas
+	redisClient, err := database.NewRedis(database.RedisConfig{
+		Host:     cfg.Redis.Host,
+		Port:     cfg.Redis.Port,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to redis", err)
+	}
+	defer redisClient.Close()
+
 	// Initialize JWT
 	jwtToken := jwt.NewJWT(
 		cfg.JWT.SecretKey,
@@ -67,9 +111,49 @@ func main() {
 
 	// Initialize repository
 	attendanceRepo := repository.NewAttendanceRepository(db)
+	courseRepository := courseRepo.NewCourseRepository(db)
+	masterDataRepository := masterDataRepo.NewMasterDataRepository(db)
+	locationRepository := locationRepo.NewLocationRepository(db)
+	leaveRepository := leaveRepo.NewLeaveRepository(db)
+	calendarRepository := calendarRepo.NewCalendarRepository(db)
+	fileRepository := fileRepo.NewFileRepository(db)
+	notificationRepository := notificationRepo.NewNotificationRepository(db)
+
+	campusNetwork, err := service.CampusNetwork(cfg.CampusNetwork.CIDRs, cfg.CampusNetwork.BSSIDs, cfg.CampusNetwork.AttestationKey, cfg.CampusNetwork.AttestationMaxAge)
+	if err != nil {
+		log.Fatal("Invalid campus network configuration", err)
+	}
 
 	// Initialize service
-	attendanceService := service.NewAttendanceService(attendanceRepo, jwtToken)
+	attendanceService := service.NewAttendanceService(attendanceRepo, courseRepository, masterDataRepository, locationRepository, leaveRepository, calendarRepository, fileRepository, notificationRepository, redisClient, service.ScanConfig{
+		QRSigningKey:       cfg.QRSigningKey,
+		ClockSkewTolerance: cfg.OfflineClockSkewTolerance,
+		MaxOfflineAge:      cfg.OfflineMaxAge,
+		DeviceBinding:      cfg.DeviceBindingMode,
+		WorkLocation:       service.WorkLocation(cfg.WorkTimezone),
+		CampusNetwork:      campusNetwork,
+		Kiosk: service.KioskPolicy{
+			CloseTime:  cfg.Kiosk.CloseTime,
+			QRRotation: cfg.Kiosk.QRRotation,
+		},
+		Sweep: service.SweepPolicy{
+			Time:             cfg.Sweep.Time,
+			CorrectionWindow: cfg.Sweep.CorrectionWindow,
+		},
+		Anomaly: service.AnomalyPolicy{
+			Mode:              cfg.Anomaly.Policy,
+			MaxSpeedKmh:       cfg.Anomaly.MaxSpeedKmh,
+			MinTravelMeters:   cfg.Anomaly.MinTravelMeters,
+			MaxAccuracyMeters: cfg.Anomaly.MaxAccuracyMeters,
+			RepeatThreshold:   cfg.Anomaly.RepeatThreshold,
+			Lookback:          cfg.Anomaly.Lookback,
+		},
+	}, jwtToken, log)
+
+	// Start background jobs
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	go worker.NewWorker(attendanceService, log, cfg.WorkerInterval).Start(workerCtx)
 
 	// Initialize handler
 	attendanceHandler := handler.NewAttendanceHandler(attendanceService, log)
@@ -77,6 +161,11 @@ func main() {
 	// Setup router
 	router := gin.Default()
 	router.Use(gin.Recovery())
+	// The client IP is only taken from X-Real-IP when the gateway set it
+	router.RemoteIPHeaders = []string{"X-Real-IP"}
+	if err := router.SetTrustedProxies(cfg.GatewayProxies); err != nil {
+		log.Fatal("Invalid GATEWAY_PROXIES", err)
+	}
 	handler.SetupRoutes(router, attendanceHandler, jwtToken)
 
 	// Start server
@@ -100,6 +189,7 @@ func main() {
 	<-quit
 
 	log.Info("Shutting down server...")
+	stopWorker()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
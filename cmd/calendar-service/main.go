@@ -42,6 +42,7 @@ func main() {
 
 	if err := db.AutoMigrate(
 		&models.AcademicEvent{},
+		&models.CalendarFeed{},
 	); err != nil {
 		log.Fatal("Failed to migrate database", err)
 	}
@@ -53,7 +54,7 @@ func main() {
 	)
 
 	calendarRepo := repository.NewCalendarRepository(db)
-	calendarService := service.NewCalendarService(calendarRepo)
+	calendarService := service.NewCalendarService(calendarRepo, cfg.FeedBaseURL)
 	calendarHandler := handler.NewCalendarHandler(calendarService, log)
 
 	router := gin.Default()
@@ -44,6 +44,7 @@ func main() {
 		&models.StudyProgram{},
 		&models.AcademicPeriod{},
 		&models.Room{},
+		&models.RoomBooking{},
 	); err != nil {
 		log.Fatal("Failed to migrate database", err)
 	}
@@ -60,7 +60,7 @@ func main() {
 
 	qrRepo := repository.NewQRRepository(db)
 	userRepository := userRepo.NewUserRepository(db)
-	qrService := service.NewQRService(qrRepo, userRepository)
+	qrService := service.NewQRService(qrRepo, userRepository, cfg.QRSigningKey)
 	qrHandler := handler.NewQRHandler(qrService, log)
 
 	router := gin.Default()
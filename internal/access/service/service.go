@@ -121,12 +121,14 @@ func (s *AccessService) GetAccessHistory(ctx context.Context, req GetAccessHisto
 
 // LogAccessRequest represents log access request
 type LogAccessRequest struct {
-	UserID     string `json:"user_id" binding:"required"`
-	GateID     string `json:"gate_id" binding:"required"`
-	AccessType string `json:"access_type" binding:"required,oneof=entry exit"`
-	IsAllowed  bool   `json:"is_allowed"`
-	Reason     string `json:"reason,omitempty"`
-	QRCodeID   string `json:"qr_code_id,omitempty"`
+	UserID     string   `json:"user_id" binding:"required"`
+	GateID     string   `json:"gate_id" binding:"required"`
+	AccessType string   `json:"access_type" binding:"required,oneof=entry exit"`
+	IsAllowed  bool     `json:"is_allowed"`
+	Reason     string   `json:"reason,omitempty"`
+	QRCodeID   string   `json:"qr_code_id,omitempty"`
+	Latitude   *float64 `json:"latitude,omitempty"` // Gate location
+	Longitude  *float64 `json:"longitude,omitempty"`
 }
 
 // LogAccess logs an access attempt
@@ -137,6 +139,8 @@ func (s *AccessService) LogAccess(ctx context.Context, req LogAccessRequest) (*m
 		AccessType: req.AccessType,
 		IsAllowed:  req.IsAllowed,
 		Reason:     req.Reason,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
 	}
 
 	if req.QRCodeID != "" {
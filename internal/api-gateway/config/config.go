@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -28,6 +29,7 @@ type Config struct {
 	LeaveServiceURL        string
 	LogLevel               string
 	JWTSecret              string
+	TrustedProxies         []string // Load balancers allowed to set X-Forwarded-For, none by default
 
 	// RabbitMQ Configuration
 	RabbitMQHost     string
@@ -47,6 +49,7 @@ func Load() *Config {
 	envs := []string{
 		"PORT",
 		"LOG_LEVEL",
+		"TRUSTED_PROXIES",
 
 		// Service URLs (WAJIB di Docker)
 		"AUTH_SERVICE_URL",
@@ -107,7 +110,8 @@ func Load() *Config {
 		MasterDataServiceURL:   mustGetEnv("MASTER_DATA_SERVICE_URL"),
 		LeaveServiceURL:        mustGetEnv("LEAVE_SERVICE_URL"),
 
-		JWTSecret: mustGetEnv("JWT_SECRET"),
+		JWTSecret:      mustGetEnv("JWT_SECRET"),
+		TrustedProxies: splitList(getEnv("TRUSTED_PROXIES", "")),
 
 		// 🔥 RabbitMQ (NO localhost)
 		RabbitMQHost:     mustGetEnv("RABBITMQ_HOST"),
@@ -134,3 +138,14 @@ func mustGetEnv(key string) string {
 	}
 	return value
 }
+
+// splitList splits a comma separated env value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"unsri-backend/internal/api-gateway/config"
@@ -34,6 +35,7 @@ type ProxyHandler struct {
 	cfg           *config.Config
 	logger        logger.Logger
 	client        *http.Client
+	streamClient  *http.Client // No timeout, for Server-Sent Events streams
 	messageBroker MessageBrokerService
 }
 
@@ -75,6 +77,7 @@ func NewProxyHandler(cfg *config.Config, logger logger.Logger, messageBroker Mes
 		cfg:           cfg,
 		logger:        logger,
 		client:        &http.Client{Timeout: 30 * time.Second},
+		streamClient:  &http.Client{},
 		messageBroker: messageBroker,
 	}
 }
@@ -886,7 +889,7 @@ func (h *ProxyHandler) proxyRequest(c *gin.Context, targetURL string) {
 		requestSize = 0
 	}
 
-	req, err := http.NewRequest(c.Request.Method, url, c.Request.Body)
+	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, url, c.Request.Body)
 	if err != nil {
 		h.logger.Errorf("Failed to create request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
@@ -899,9 +902,15 @@ func (h *ProxyHandler) proxyRequest(c *gin.Context, targetURL string) {
 			req.Header.Add(key, value)
 		}
 	}
+	// Services trust this header for network checks, never the client's own copy
+	req.Header.Set("X-Real-IP", c.ClientIP())
 
-	// Send request
-	resp, err := h.client.Do(req)
+	// Send request, event streams stay open for as long as the client listens
+	client := h.client
+	if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		client = h.streamClient
+	}
+	resp, err := client.Do(req)
 	duration := time.Since(startTime).Milliseconds()
 	
 	if err != nil {
@@ -983,10 +992,36 @@ func (h *ProxyHandler) proxyRequest(c *gin.Context, targetURL string) {
 		}
 	}
 
+	// Stream event responses as they arrive instead of buffering them
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		h.streamResponse(c, resp)
+		return
+	}
+
 	// Copy response body
 	c.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
 }
 
+// streamResponse copies a Server-Sent Events response, flushing each chunk to the client
+func (h *ProxyHandler) streamResponse(c *gin.Context, resp *http.Response) {
+	c.Status(resp.StatusCode)
+	c.Writer.WriteHeaderNow()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := c.Writer.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
 // extractServiceName extracts service name from URL
 func (h *ProxyHandler) extractServiceName(url string) string {
 	serviceMap := map[string]string{
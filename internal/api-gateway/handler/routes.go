@@ -52,6 +52,13 @@ func SetupRoutes(router *gin.Engine, proxyHandler *ProxyHandler) {
 		courses.Any("/*path", proxyHandler.ProxyCourse)
 	}
 
+	// Class routes (part of course service)
+	classes := router.Group("/api/v1/classes")
+	classes.Use() // Add auth middleware here if needed
+	{
+		classes.Any("/*path", proxyHandler.ProxyCourse)
+	}
+
 	// Enrollment service routes (part of course service)
 	enrollments := router.Group("/api/v1/enrollments")
 	enrollments.Use() // Add auth middleware here if needed
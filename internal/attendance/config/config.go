@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,11 +9,52 @@ import (
 
 // Config holds the configuration for attendance service
 type Config struct {
-	Port            string
-	Database        DatabaseConfig
-	Redis           RedisConfig
-	JWT             JWTConfig
-	LogLevel        string
+	Port                      string
+	GatewayProxies            []string // Gateway addresses allowed to forward the client IP in X-Real-IP, none by default
+	Database                  DatabaseConfig
+	Redis                     RedisConfig
+	JWT                       JWTConfig
+	LogLevel                  string
+	WorkerInterval            time.Duration // How often background jobs such as closing expired sessions run
+	QRSigningKey              string        // Shared with the QR service to sign attendance QR payloads
+	OfflineClockSkewTolerance time.Duration // Max difference between a syncing device's clock and the server
+	OfflineMaxAge             time.Duration // Oldest offline capture accepted on sync
+	DeviceBindingMode         string        // off, monitor (bind and flag) or enforce (refuse other devices)
+	Anomaly                   AnomalyConfig
+	WorkTimezone              string // IANA zone work days and shift windows are evaluated in
+	CampusNetwork             CampusNetworkConfig
+	Kiosk                     KioskConfig
+	Sweep                     SweepConfig
+}
+
+// KioskConfig holds the settings of kiosk QR sessions for work check-ins
+type KioskConfig struct {
+	CloseTime  string        // Clock time (HH:MM, work time zone) open kiosk sessions close at
+	QRRotation time.Duration // How long each displayed kiosk QR code is valid
+}
+
+// SweepConfig holds the settings of the daily work attendance sweep
+type SweepConfig struct {
+	Time             string        // Clock time (HH:MM, work time zone) after which the previous work day is swept
+	CorrectionWindow time.Duration // How long supervisors can amend what the sweep recorded
+}
+
+// CampusNetworkConfig holds how work check-ins are verified to come from the campus network
+type CampusNetworkConfig struct {
+	CIDRs             []string      // Campus address ranges, matched against the client IP forwarded by a trusted gateway
+	BSSIDs            []string      // Campus Wi-Fi access points accepted in attestations
+	AttestationKey    string        // Shared with the Wi-Fi controller that signs BSSID attestations, empty disables them
+	AttestationMaxAge time.Duration // Oldest attestation accepted
+}
+
+// AnomalyConfig holds the location anomaly engine configuration
+type AnomalyConfig struct {
+	Policy            string        // off, flag or strict (refuse the action)
+	MaxSpeedKmh       float64       // Faster travel between two located events is implausible
+	MinTravelMeters   float64       // Shorter moves are treated as GPS noise
+	MaxAccuracyMeters float64       // Worse reported GPS accuracy is not trusted
+	RepeatThreshold   int           // Other events at the exact same coordinates before flagging
+	Lookback          time.Duration // Window of the user's events compared with a new one
 }
 
 // DatabaseConfig holds database configuration
@@ -54,12 +96,36 @@ func Load() *Config {
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
+	viper.SetDefault("WORKER_INTERVAL", "1m")
+	viper.SetDefault("QR_SIGNING_KEY", "your-qr-signing-key-change-in-production")
+	viper.SetDefault("OFFLINE_CLOCK_SKEW_TOLERANCE", "2m")
+	viper.SetDefault("OFFLINE_MAX_AGE", "24h")
+	viper.SetDefault("DEVICE_BINDING_MODE", "monitor")
+	viper.SetDefault("ANOMALY_POLICY", "flag")
+	viper.SetDefault("ANOMALY_MAX_SPEED_KMH", 120)
+	viper.SetDefault("ANOMALY_MIN_TRAVEL_METERS", 1000)
+	viper.SetDefault("ANOMALY_MAX_ACCURACY_METERS", 100)
+	viper.SetDefault("ANOMALY_REPEAT_THRESHOLD", 3)
+	viper.SetDefault("ANOMALY_LOOKBACK", "12h")
+	viper.SetDefault("WORK_TIMEZONE", "Asia/Jakarta")
+	viper.SetDefault("WIFI_ATTESTATION_MAX_AGE", "5m")
+	viper.SetDefault("KIOSK_CLOSE_TIME", "22:00")
+	viper.SetDefault("KIOSK_QR_ROTATION", "30s")
+	viper.SetDefault("DAILY_SWEEP_TIME", "09:00")
+	viper.SetDefault("CORRECTION_WINDOW", "72h")
 
 	viper.AutomaticEnv()
 
 	return &Config{
-		Port:     viper.GetString("PORT"),
-		LogLevel: viper.GetString("LOG_LEVEL"),
+		Port:                      viper.GetString("PORT"),
+		GatewayProxies:            splitList(viper.GetString("GATEWAY_PROXIES")),
+		LogLevel:                  viper.GetString("LOG_LEVEL"),
+		WorkerInterval:            viper.GetDuration("WORKER_INTERVAL"),
+		QRSigningKey:              viper.GetString("QR_SIGNING_KEY"),
+		OfflineClockSkewTolerance: viper.GetDuration("OFFLINE_CLOCK_SKEW_TOLERANCE"),
+		OfflineMaxAge:             viper.GetDuration("OFFLINE_MAX_AGE"),
+		DeviceBindingMode:         viper.GetString("DEVICE_BINDING_MODE"),
+		WorkTimezone:              viper.GetString("WORK_TIMEZONE"),
 		Database: DatabaseConfig{
 			Host:            viper.GetString("DATABASE_HOST"),
 			Port:            viper.GetString("DATABASE_PORT"),
@@ -80,6 +146,38 @@ func Load() *Config {
 		JWT: JWTConfig{
 			SecretKey: viper.GetString("JWT_SECRET"),
 		},
+		Anomaly: AnomalyConfig{
+			Policy:            viper.GetString("ANOMALY_POLICY"),
+			MaxSpeedKmh:       viper.GetFloat64("ANOMALY_MAX_SPEED_KMH"),
+			MinTravelMeters:   viper.GetFloat64("ANOMALY_MIN_TRAVEL_METERS"),
+			MaxAccuracyMeters: viper.GetFloat64("ANOMALY_MAX_ACCURACY_METERS"),
+			RepeatThreshold:   viper.GetInt("ANOMALY_REPEAT_THRESHOLD"),
+			Lookback:          viper.GetDuration("ANOMALY_LOOKBACK"),
+		},
+		CampusNetwork: CampusNetworkConfig{
+			CIDRs:             splitList(viper.GetString("CAMPUS_CIDRS")),
+			BSSIDs:            splitList(viper.GetString("CAMPUS_WIFI_BSSIDS")),
+			AttestationKey:    viper.GetString("WIFI_ATTESTATION_KEY"),
+			AttestationMaxAge: viper.GetDuration("WIFI_ATTESTATION_MAX_AGE"),
+		},
+		Kiosk: KioskConfig{
+			CloseTime:  viper.GetString("KIOSK_CLOSE_TIME"),
+			QRRotation: viper.GetDuration("KIOSK_QR_ROTATION"),
+		},
+		Sweep: SweepConfig{
+			Time:             viper.GetString("DAILY_SWEEP_TIME"),
+			CorrectionWindow: viper.GetDuration("CORRECTION_WINDOW"),
+		},
 	}
 }
 
+// splitList splits a comma separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetLocationAnomalies handles get location anomalies request
+func (h *AttendanceHandler) GetLocationAnomalies(c *gin.Context) {
+	var req service.GetLocationAnomaliesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	anomalies, total, err := h.service.GetLocationAnomalies(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, anomalies, page, perPage, total)
+}
+
+// ReviewLocationAnomaly handles review location anomaly request
+func (h *AttendanceHandler) ReviewLocationAnomaly(c *gin.Context) {
+	userID := c.GetString("user_id")
+	anomalyID := c.Param("id")
+
+	var req service.ReviewLocationAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.ReviewLocationAnomaly(c.Request.Context(), userID, anomalyID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
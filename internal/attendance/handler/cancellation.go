@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CancelSchedule handles cancel schedule request
+func (h *AttendanceHandler) CancelSchedule(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	scheduleID := c.Param("scheduleId")
+
+	var req service.CancelScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CancelSchedule(c.Request.Context(), userID, userRole, scheduleID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ScheduleMakeUp handles create make-up meeting request
+func (h *AttendanceHandler) ScheduleMakeUp(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	scheduleID := c.Param("scheduleId")
+
+	var req service.MakeUpMeetingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.ScheduleMakeUp(c.Request.Context(), userID, userRole, scheduleID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetClassMeetingCount handles get class meeting count request
+func (h *AttendanceHandler) GetClassMeetingCount(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	classID := c.Param("classId")
+
+	result, err := h.service.GetClassMeetingCount(c.Request.Context(), userID, userRole, classID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
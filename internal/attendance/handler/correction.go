@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// SubmitCorrection handles submit attendance correction request
+func (h *AttendanceHandler) SubmitCorrection(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.SubmitCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.SubmitCorrection(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetCorrections handles get attendance correction requests request
+func (h *AttendanceHandler) GetCorrections(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GetCorrectionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	corrections, total, err := h.service.GetCorrections(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, corrections, page, perPage, total)
+}
+
+// GetCorrection handles get attendance correction request by ID request
+func (h *AttendanceHandler) GetCorrection(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	correctionID := c.Param("id")
+
+	result, err := h.service.GetCorrectionByID(c.Request.Context(), userID, userRole, correctionID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ApproveCorrection handles approve attendance correction request
+func (h *AttendanceHandler) ApproveCorrection(c *gin.Context) {
+	userID := c.GetString("user_id")
+	correctionID := c.Param("id")
+
+	var req service.ReviewCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.ApproveCorrection(c.Request.Context(), userID, correctionID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RejectCorrection handles reject attendance correction request
+func (h *AttendanceHandler) RejectCorrection(c *gin.Context) {
+	userID := c.GetString("user_id")
+	correctionID := c.Param("id")
+
+	var req service.ReviewCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.RejectCorrection(c.Request.Context(), userID, correctionID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetAttendance handles get attendance record with change history request
+func (h *AttendanceHandler) GetAttendance(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	attendanceID := c.Param("id")
+
+	result, err := h.service.GetAttendanceByID(c.Request.Context(), userID, userRole, attendanceID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetDeviceStatus handles get bound device of the current account request
+func (h *AttendanceHandler) GetDeviceStatus(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	result, err := h.service.GetDeviceStatus(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RegisterDeviceKey handles register the offline signing key of the bound device request
+func (h *AttendanceHandler) RegisterDeviceKey(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.RegisterDeviceKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.RegisterDeviceKey(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// SubmitDeviceRebind handles submit device rebind request
+func (h *AttendanceHandler) SubmitDeviceRebind(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.SubmitDeviceRebindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.SubmitDeviceRebind(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetDeviceRebindRequests handles get device rebind requests request
+func (h *AttendanceHandler) GetDeviceRebindRequests(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GetDeviceRebindRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	requests, total, err := h.service.GetDeviceRebindRequests(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, requests, page, perPage, total)
+}
+
+// ApproveDeviceRebind handles approve device rebind request
+func (h *AttendanceHandler) ApproveDeviceRebind(c *gin.Context) {
+	userID := c.GetString("user_id")
+	requestID := c.Param("id")
+
+	var req service.ReviewDeviceRebindRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.ApproveDeviceRebind(c.Request.Context(), userID, requestID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RejectDeviceRebind handles reject device rebind request
+func (h *AttendanceHandler) RejectDeviceRebind(c *gin.Context) {
+	userID := c.GetString("user_id")
+	requestID := c.Param("id")
+
+	var req service.ReviewDeviceRebindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.RejectDeviceRebind(c.Request.Context(), userID, requestID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetSharedDevices handles get report of devices used for several accounts request
+func (h *AttendanceHandler) GetSharedDevices(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GetSharedDevicesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	items, total, err := h.service.GetSharedDevices(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, items, page, perPage, total)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetClassEligibility handles get class attendance eligibility request
+func (h *AttendanceHandler) GetClassEligibility(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	classID := c.Param("classId")
+
+	var req service.GetClassEligibilityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetClassEligibility(c.Request.Context(), userID, userRole, classID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CreateEligibilityPolicy handles create eligibility policy request
+func (h *AttendanceHandler) CreateEligibilityPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.CreateEligibilityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateEligibilityPolicy(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetEligibilityPolicies handles get eligibility policies request
+func (h *AttendanceHandler) GetEligibilityPolicies(c *gin.Context) {
+	var req service.GetEligibilityPoliciesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetEligibilityPolicies(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// UpdateEligibilityPolicy handles update eligibility policy request
+func (h *AttendanceHandler) UpdateEligibilityPolicy(c *gin.Context) {
+	policyID := c.Param("id")
+
+	var req service.UpdateEligibilityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.UpdateEligibilityPolicy(c.Request.Context(), policyID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// DeleteEligibilityPolicy handles delete eligibility policy request
+func (h *AttendanceHandler) DeleteEligibilityPolicy(c *gin.Context) {
+	policyID := c.Param("id")
+
+	if err := h.service.DeleteEligibilityPolicy(c.Request.Context(), policyID); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Eligibility policy deleted successfully"})
+}
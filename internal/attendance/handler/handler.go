@@ -46,6 +46,7 @@ func (h *AttendanceHandler) GenerateQR(c *gin.Context) {
 // ScanQR handles QR code scan request
 func (h *AttendanceHandler) ScanQR(c *gin.Context) {
 	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
 
 	var req service.ScanQRRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -53,7 +54,37 @@ func (h *AttendanceHandler) ScanQR(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.ScanQRCode(c.Request.Context(), userID, req)
+	result, err := h.service.ScanQRCode(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetScanRejections handles get refused scans for a schedule request
+func (h *AttendanceHandler) GetScanRejections(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	scheduleID := c.Param("scheduleId")
+
+	result, err := h.service.GetScanRejections(c.Request.Context(), userID, userRole, scheduleID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CloseScheduleAttendance handles close attendance for a schedule request
+func (h *AttendanceHandler) CloseScheduleAttendance(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	scheduleID := c.Param("scheduleId")
+
+	result, err := h.service.CloseScheduleAttendance(c.Request.Context(), userID, userRole, scheduleID)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -117,6 +148,7 @@ func (h *AttendanceHandler) CreateManualAttendance(c *gin.Context) {
 
 // UpdateAttendance handles update attendance request
 func (h *AttendanceHandler) UpdateAttendance(c *gin.Context) {
+	userID := c.GetString("user_id")
 	attendanceID := c.Param("id")
 
 	var req service.UpdateAttendanceRequest
@@ -125,7 +157,7 @@ func (h *AttendanceHandler) UpdateAttendance(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.UpdateAttendance(c.Request.Context(), attendanceID, req)
+	result, err := h.service.UpdateAttendance(c.Request.Context(), userID, attendanceID, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -265,13 +297,15 @@ func (h *AttendanceHandler) TapOut(c *gin.Context) {
 
 // CreateSchedule handles create schedule request
 func (h *AttendanceHandler) CreateSchedule(c *gin.Context) {
+	userRole := c.GetString("user_role")
+
 	var req service.CreateScheduleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
 
-	result, err := h.service.CreateSchedule(c.Request.Context(), req)
+	result, err := h.service.CreateSchedule(c.Request.Context(), userRole, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -321,6 +355,7 @@ func (h *AttendanceHandler) GetSchedule(c *gin.Context) {
 
 // UpdateSchedule handles update schedule request
 func (h *AttendanceHandler) UpdateSchedule(c *gin.Context) {
+	userRole := c.GetString("user_role")
 	scheduleID := c.Param("id")
 
 	var req service.UpdateScheduleRequest
@@ -329,7 +364,7 @@ func (h *AttendanceHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.UpdateSchedule(c.Request.Context(), scheduleID, req)
+	result, err := h.service.UpdateSchedule(c.Request.Context(), userRole, scheduleID, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -552,6 +587,7 @@ func (h *AttendanceHandler) CheckIn(c *gin.Context) {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
+	req.ClientIP = c.ClientIP()
 
 	result, err := h.service.CheckIn(c.Request.Context(), userID, req)
 	if err != nil {
@@ -571,6 +607,7 @@ func (h *AttendanceHandler) CheckOut(c *gin.Context) {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
+	req.ClientIP = c.ClientIP()
 
 	result, err := h.service.CheckOut(c.Request.Context(), userID, req)
 	if err != nil {
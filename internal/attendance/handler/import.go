@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/utils"
+	"unsri-backend/pkg/spreadsheet"
+)
+
+// ImportAttendance handles bulk attendance import request
+func (h *AttendanceHandler) ImportAttendance(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.ImportAttendanceRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestResponse(c, "File is required")
+		return
+	}
+	req.File = file
+
+	result, err := h.service.ImportAttendance(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	// A commit with invalid rows stores nothing, the rows explain why
+	if req.Commit && !result.Committed {
+		c.JSON(http.StatusUnprocessableEntity, utils.Response{
+			Success: false,
+			Data:    result,
+			Error: &utils.ErrorInfo{
+				Code:    apperrors.ErrCodeValidationFailed,
+				Message: fmt.Sprintf("%d rows are invalid, nothing was imported", result.Invalid),
+			},
+		})
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ExportAttendanceMatrix handles export class attendance matrix request
+func (h *AttendanceHandler) ExportAttendanceMatrix(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	classID := c.Param("classId")
+
+	var req service.ExportAttendanceMatrixRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	content, filename, format, err := h.service.ExportAttendanceMatrix(c.Request.Context(), userID, userRole, classID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, spreadsheet.ContentType(format), content)
+}
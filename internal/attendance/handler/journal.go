@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetLectureJournal handles get lecture journal request
+func (h *AttendanceHandler) GetLectureJournal(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	scheduleID := c.Param("scheduleId")
+
+	result, err := h.service.GetLectureJournal(c.Request.Context(), userID, userRole, scheduleID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// UpdateLectureJournal handles update lecture journal request
+func (h *AttendanceHandler) UpdateLectureJournal(c *gin.Context) {
+	userID := c.GetString("user_id")
+	scheduleID := c.Param("scheduleId")
+
+	var req service.UpdateLectureJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.UpdateLectureJournal(c.Request.Context(), userID, scheduleID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// SubmitLectureJournal handles submit lecture journal request
+func (h *AttendanceHandler) SubmitLectureJournal(c *gin.Context) {
+	userID := c.GetString("user_id")
+	scheduleID := c.Param("scheduleId")
+
+	result, err := h.service.SubmitLectureJournal(c.Request.Context(), userID, scheduleID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CountersignLectureJournal handles countersign lecture journal request
+func (h *AttendanceHandler) CountersignLectureJournal(c *gin.Context) {
+	userID := c.GetString("user_id")
+	scheduleID := c.Param("scheduleId")
+
+	var req service.CountersignLectureJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CountersignLectureJournal(c.Request.Context(), userID, scheduleID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ExportLectureJournals handles export lecture journals request
+func (h *AttendanceHandler) ExportLectureJournals(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.ExportLectureJournalsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	content, filename, err := h.service.ExportLectureJournals(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", content)
+}
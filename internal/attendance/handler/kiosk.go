@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// kioskTokenHeader carries the token a registered kiosk device authenticates with
+const kioskTokenHeader = "X-Kiosk-Token"
+
+// RegisterKioskDevice handles register kiosk device request
+func (h *AttendanceHandler) RegisterKioskDevice(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.RegisterKioskDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.RegisterKioskDevice(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetKioskDevices handles get kiosk devices request
+func (h *AttendanceHandler) GetKioskDevices(c *gin.Context) {
+	var unit *string
+	if value := c.Query("unit"); value != "" {
+		unit = &value
+	}
+
+	result, err := h.service.GetKioskDevices(c.Request.Context(), unit)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RevokeKioskDevice handles revoke kiosk device request
+func (h *AttendanceHandler) RevokeKioskDevice(c *gin.Context) {
+	result, err := h.service.RevokeKioskDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// OpenKioskSession handles open kiosk session request
+func (h *AttendanceHandler) OpenKioskSession(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.OpenKioskSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.OpenKioskSession(c.Request.Context(), userID, c.GetHeader(kioskTokenHeader), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetKioskSessions handles get kiosk sessions request
+func (h *AttendanceHandler) GetKioskSessions(c *gin.Context) {
+	var req service.GetKioskSessionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	sessions, total, err := h.service.GetKioskSessions(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, sessions, page, perPage, total)
+}
+
+// GetKioskQR handles get current kiosk QR code request
+func (h *AttendanceHandler) GetKioskQR(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	result, err := h.service.GetKioskQR(c.Request.Context(), c.GetHeader(kioskTokenHeader), sessionID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CloseKioskSession handles close kiosk session request
+func (h *AttendanceHandler) CloseKioskSession(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	sessionID := c.Param("id")
+
+	result, err := h.service.CloseKioskSession(c.Request.Context(), userID, userRole, c.GetHeader(kioskTokenHeader), sessionID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CreateLatenessPolicy handles create lateness policy request
+func (h *AttendanceHandler) CreateLatenessPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.CreateLatenessPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateLatenessPolicy(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetLatenessPolicies handles get lateness policies request
+func (h *AttendanceHandler) GetLatenessPolicies(c *gin.Context) {
+	var req service.GetLatenessPoliciesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetLatenessPolicies(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetEffectiveLatenessPolicy handles get the lateness policy applied to a schedule request
+func (h *AttendanceHandler) GetEffectiveLatenessPolicy(c *gin.Context) {
+	scheduleID := c.Query("schedule_id")
+
+	result, err := h.service.GetEffectiveLatenessPolicy(c.Request.Context(), scheduleID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// UpdateLatenessPolicy handles update lateness policy request
+func (h *AttendanceHandler) UpdateLatenessPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	policyID := c.Param("id")
+
+	var req service.UpdateLatenessPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.UpdateLatenessPolicy(c.Request.Context(), userID, userRole, policyID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// DeleteLatenessPolicy handles delete lateness policy request
+func (h *AttendanceHandler) DeleteLatenessPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	policyID := c.Param("id")
+
+	if err := h.service.DeleteLatenessPolicy(c.Request.Context(), userID, userRole, policyID); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Lateness policy deleted successfully"})
+}
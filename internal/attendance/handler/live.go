@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/shared/utils"
+)
+
+// liveRosterHeartbeat keeps idle live roster streams open through proxies
+const liveRosterHeartbeat = 15 * time.Second
+
+// WatchLiveRoster handles the live roster stream of a class session as Server-Sent Events.
+// A "snapshot" event is sent first, then an "accepted" or "rejected" event per scan.
+func (h *AttendanceHandler) WatchLiveRoster(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	sessionID := c.Param("sessionId")
+
+	snapshot, events, err := h.service.WatchLiveRoster(c.Request.Context(), userID, userRole, sessionID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(liveRosterHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// SetCheckInRequirement handles set unit check-in requirement request
+func (h *AttendanceHandler) SetCheckInRequirement(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.SetCheckInRequirementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.SetCheckInRequirement(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetCheckInRequirements handles get unit check-in requirements request
+func (h *AttendanceHandler) GetCheckInRequirements(c *gin.Context) {
+	result, err := h.service.GetCheckInRequirements(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// DeleteCheckInRequirement handles delete unit check-in requirement request
+func (h *AttendanceHandler) DeleteCheckInRequirement(c *gin.Context) {
+	if err := h.service.DeleteCheckInRequirement(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Check-in requirement deleted successfully"})
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetOfflinePolicy handles get how the device signs offline scans request
+func (h *AttendanceHandler) GetOfflinePolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	result, err := h.service.GetOfflinePolicy(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// SyncOfflineScans handles sync of scans captured while offline request
+func (h *AttendanceHandler) SyncOfflineScans(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.SyncOfflineScansRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.SyncOfflineScans(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
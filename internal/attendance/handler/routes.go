@@ -20,6 +20,56 @@ func SetupRoutes(router *gin.Engine, handler *AttendanceHandler, jwtToken *jwt.J
 		// QR code operations
 		v1.POST("/qr/generate", middleware.RoleMiddleware("dosen", "staff"), handler.GenerateQR)
 		v1.POST("/qr/scan", handler.ScanQR)
+		v1.GET("/offline/policy", handler.GetOfflinePolicy)
+		v1.POST("/offline/sync", handler.SyncOfflineScans)
+		v1.GET("/sessions/:sessionId/live", middleware.RoleMiddleware("dosen", "staff"), handler.WatchLiveRoster)
+		v1.GET("/schedules/:scheduleId/rejections", middleware.RoleMiddleware("dosen", "staff"), handler.GetScanRejections)
+		v1.POST("/schedules/:scheduleId/close", middleware.RoleMiddleware("dosen", "staff"), handler.CloseScheduleAttendance)
+		v1.POST("/schedules/:scheduleId/cancel", middleware.RoleMiddleware("dosen", "staff"), handler.CancelSchedule)
+		v1.POST("/schedules/:scheduleId/make-up", middleware.RoleMiddleware("dosen", "staff"), handler.ScheduleMakeUp)
+
+		// Lecture journals (BAP)
+		v1.GET("/schedules/:scheduleId/journal", handler.GetLectureJournal)
+		v1.PUT("/schedules/:scheduleId/journal", middleware.RoleMiddleware("dosen"), handler.UpdateLectureJournal)
+		v1.POST("/schedules/:scheduleId/journal/submit", middleware.RoleMiddleware("dosen"), handler.SubmitLectureJournal)
+		v1.POST("/schedules/:scheduleId/journal/countersign", middleware.RoleMiddleware("mahasiswa"), handler.CountersignLectureJournal)
+		v1.GET("/journals/export", middleware.RoleMiddleware("dosen", "staff"), handler.ExportLectureJournals)
+
+		// Lateness policies
+		v1.GET("/lateness-policies", middleware.RoleMiddleware("dosen", "staff"), handler.GetLatenessPolicies)
+		v1.GET("/lateness-policies/effective", handler.GetEffectiveLatenessPolicy)
+		v1.POST("/lateness-policies", middleware.RoleMiddleware("dosen", "staff"), handler.CreateLatenessPolicy)
+		v1.PUT("/lateness-policies/:id", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateLatenessPolicy)
+		v1.DELETE("/lateness-policies/:id", middleware.RoleMiddleware("dosen", "staff"), handler.DeleteLatenessPolicy)
+
+		// Exam eligibility
+		v1.GET("/classes/:classId/eligibility", handler.GetClassEligibility)
+		v1.GET("/classes/:classId/meetings", middleware.RoleMiddleware("dosen", "staff"), handler.GetClassMeetingCount)
+		v1.GET("/classes/:classId/matrix", middleware.RoleMiddleware("dosen", "staff"), handler.ExportAttendanceMatrix)
+		v1.GET("/eligibility-policies", middleware.RoleMiddleware("staff"), handler.GetEligibilityPolicies)
+		v1.POST("/eligibility-policies", middleware.RoleMiddleware("staff"), handler.CreateEligibilityPolicy)
+		v1.PUT("/eligibility-policies/:id", middleware.RoleMiddleware("staff"), handler.UpdateEligibilityPolicy)
+		v1.DELETE("/eligibility-policies/:id", middleware.RoleMiddleware("staff"), handler.DeleteEligibilityPolicy)
+
+		// Correction requests
+		v1.POST("/corrections", middleware.RoleMiddleware("mahasiswa"), handler.SubmitCorrection)
+		v1.GET("/corrections", handler.GetCorrections)
+		v1.GET("/corrections/:id", handler.GetCorrection)
+		v1.POST("/corrections/:id/approve", middleware.RoleMiddleware("dosen"), handler.ApproveCorrection)
+		v1.POST("/corrections/:id/reject", middleware.RoleMiddleware("dosen"), handler.RejectCorrection)
+
+		// Device binding
+		v1.GET("/devices/me", handler.GetDeviceStatus)
+		v1.PUT("/devices/me/key", handler.RegisterDeviceKey)
+		v1.POST("/devices/rebind-requests", handler.SubmitDeviceRebind)
+		v1.GET("/devices/rebind-requests", handler.GetDeviceRebindRequests)
+		v1.POST("/devices/rebind-requests/:id/approve", middleware.RoleMiddleware("staff"), handler.ApproveDeviceRebind)
+		v1.POST("/devices/rebind-requests/:id/reject", middleware.RoleMiddleware("staff"), handler.RejectDeviceRebind)
+		v1.GET("/shared-devices", middleware.RoleMiddleware("dosen", "staff"), handler.GetSharedDevices)
+
+		// Location anomalies
+		v1.GET("/anomalies", middleware.RoleMiddleware("staff"), handler.GetLocationAnomalies)
+		v1.POST("/anomalies/:id/review", middleware.RoleMiddleware("staff"), handler.ReviewLocationAnomaly)
 
 		// Attendance operations
 		v1.GET("", handler.GetAttendances)
@@ -29,6 +79,8 @@ func SetupRoutes(router *gin.Engine, handler *AttendanceHandler, jwtToken *jwt.J
 		v1.GET("/by-course/:courseId", handler.GetByCourse)
 		v1.GET("/by-student/:studentId", handler.GetByStudent)
 		v1.POST("/manual", middleware.RoleMiddleware("dosen", "staff"), handler.CreateManualAttendance)
+		v1.POST("/imports", middleware.RoleMiddleware("dosen", "staff"), handler.ImportAttendance)
+		v1.GET("/:id", handler.GetAttendance)
 		v1.PUT("/:id", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateAttendance)
 
 		// Campus attendance (tap in/out)
@@ -56,6 +108,62 @@ func SetupRoutes(router *gin.Engine, handler *AttendanceHandler, jwtToken *jwt.J
 		workAttendance.POST("/check-in", handler.CheckIn)
 		workAttendance.POST("/check-out", handler.CheckOut)
 		workAttendance.GET("/records", handler.GetWorkAttendanceRecords)
+		workAttendance.POST("/records/:id/amend", middleware.RoleMiddleware("dosen", "staff"), handler.AmendSweptRecord)
+
+		// Daily sweeps and the unit supervisors they notify (staff only)
+		workAttendance.GET("/sweeps", middleware.RoleMiddleware("staff"), handler.GetWorkSweeps)
+		supervisors := workAttendance.Group("/unit-supervisors")
+		supervisors.Use(middleware.RoleMiddleware("staff"))
+		{
+			supervisors.GET("", handler.GetUnitSupervisors)
+			supervisors.POST("", handler.AssignUnitSupervisor)
+			supervisors.DELETE("/:id", handler.RemoveUnitSupervisor)
+		}
+
+		// Unit check-in requirements (staff only)
+		requirements := workAttendance.Group("/check-in-requirements")
+		requirements.Use(middleware.RoleMiddleware("staff"))
+		{
+			requirements.GET("", handler.GetCheckInRequirements)
+			requirements.PUT("", handler.SetCheckInRequirement)
+			requirements.DELETE("/:id", handler.DeleteCheckInRequirement)
+		}
+
+		// Monthly timesheets, computed and reviewed by supervisors
+		workAttendance.GET("/timesheets/me", handler.GetMyTimesheets)
+		workAttendance.GET("/timesheets/:id", handler.GetTimesheet)
+		timesheets := workAttendance.Group("/timesheets")
+		timesheets.Use(middleware.RoleMiddleware("dosen", "staff"))
+		{
+			timesheets.GET("", handler.GetTimesheets)
+			timesheets.POST("/generate", handler.GenerateTimesheet)
+			timesheets.POST("/:id/approve", handler.ApproveTimesheet)
+			timesheets.POST("/:id/lock", handler.LockTimesheet)
+		}
+
+		// Tukin deductions of locked timesheets, computed by HR (staff only)
+		tukin := workAttendance.Group("/tukin")
+		tukin.Use(middleware.RoleMiddleware("staff"))
+		{
+			tukin.GET("/rule-sets", handler.GetTukinRuleSets)
+			tukin.POST("/rule-sets", handler.CreateTukinRuleSet)
+			tukin.GET("/rule-sets/:id", handler.GetTukinRuleSet)
+			tukin.GET("/deductions", handler.GetTukinDeductions)
+			tukin.POST("/deductions/compute", handler.ComputeTukinDeductions)
+			tukin.GET("/deductions/export", handler.ExportTukinDeductions)
+		}
+
+		// Work attendance policies (staff only)
+		policies := workAttendance.Group("/policies")
+		policies.Use(middleware.RoleMiddleware("staff"))
+		{
+			policies.GET("", handler.GetWorkPolicies)
+			policies.GET("/effective", handler.GetEffectiveWorkPolicy)
+			policies.GET("/:id", handler.GetWorkPolicy)
+			policies.POST("", handler.CreateWorkPolicy)
+			policies.PUT("/:id", handler.UpdateWorkPolicy)
+			policies.DELETE("/:id", handler.DeactivateWorkPolicy)
+		}
 
 		// Shift patterns (admin only)
 		shifts := workAttendance.Group("/shifts")
@@ -83,6 +191,43 @@ func SetupRoutes(router *gin.Engine, handler *AttendanceHandler, jwtToken *jwt.J
 			workSchedules.GET("", handler.GetWorkSchedules)
 			workSchedules.POST("", handler.CreateWorkSchedule)
 		}
+
+		// Roster generated from user shifts, previewed unless committed
+		roster := workAttendance.Group("/roster")
+		roster.Use(middleware.RoleMiddleware("dosen", "staff"))
+		{
+			roster.POST("/generate", handler.GenerateRoster)
+		}
+
+		// Front desk tablets registered by staff, the only devices that can open kiosk sessions
+		kioskDevices := workAttendance.Group("/kiosk/devices")
+		kioskDevices.Use(middleware.RoleMiddleware("staff"))
+		{
+			kioskDevices.GET("", handler.GetKioskDevices)
+			kioskDevices.POST("", handler.RegisterKioskDevice)
+			kioskDevices.POST("/:id/revoke", handler.RevokeKioskDevice)
+		}
+
+		// Kiosk sessions displayed on unit front desk tablets, scanned with kiosk_qr on check-in and check-out.
+		// Opening a session and fetching its QR also require the tablet's X-Kiosk-Token.
+		kiosk := workAttendance.Group("/kiosk/sessions")
+		kiosk.Use(middleware.RoleMiddleware("dosen", "staff"))
+		{
+			kiosk.GET("", handler.GetKioskSessions)
+			kiosk.POST("", handler.OpenKioskSession)
+			kiosk.GET("/:id/qr", handler.GetKioskQR)
+			kiosk.POST("/:id/close", handler.CloseKioskSession)
+		}
+
+		// Shift swaps: the target user accepts, then a supervisor approves
+		workAttendance.POST("/shift-swaps", handler.CreateShiftSwap)
+		workAttendance.GET("/shift-swaps", handler.GetShiftSwaps)
+		workAttendance.GET("/shift-swaps/:id", handler.GetShiftSwap)
+		workAttendance.POST("/shift-swaps/:id/accept", handler.AcceptShiftSwap)
+		workAttendance.POST("/shift-swaps/:id/decline", handler.DeclineShiftSwap)
+		workAttendance.POST("/shift-swaps/:id/cancel", handler.CancelShiftSwap)
+		workAttendance.POST("/shift-swaps/:id/approve", middleware.RoleMiddleware("dosen", "staff"), handler.ApproveShiftSwap)
+		workAttendance.POST("/shift-swaps/:id/reject", middleware.RoleMiddleware("dosen", "staff"), handler.RejectShiftSwap)
 	}
 }
 
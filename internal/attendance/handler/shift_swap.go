@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CreateShiftSwap handles create shift swap request
+func (h *AttendanceHandler) CreateShiftSwap(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.CreateShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateShiftSwap(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetShiftSwaps handles get shift swap requests request
+func (h *AttendanceHandler) GetShiftSwaps(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GetShiftSwapsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	swaps, total, err := h.service.GetShiftSwaps(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, swaps, page, perPage, total)
+}
+
+// GetShiftSwap handles get shift swap request by ID request
+func (h *AttendanceHandler) GetShiftSwap(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	swapID := c.Param("id")
+
+	result, err := h.service.GetShiftSwapByID(c.Request.Context(), userID, userRole, swapID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// AcceptShiftSwap handles accept shift swap request
+func (h *AttendanceHandler) AcceptShiftSwap(c *gin.Context) {
+	h.respondShiftSwap(c, h.service.AcceptShiftSwap)
+}
+
+// DeclineShiftSwap handles decline shift swap request
+func (h *AttendanceHandler) DeclineShiftSwap(c *gin.Context) {
+	h.respondShiftSwap(c, h.service.DeclineShiftSwap)
+}
+
+// CancelShiftSwap handles cancel shift swap request
+func (h *AttendanceHandler) CancelShiftSwap(c *gin.Context) {
+	h.respondShiftSwap(c, h.service.CancelShiftSwap)
+}
+
+// ApproveShiftSwap handles approve shift swap request
+func (h *AttendanceHandler) ApproveShiftSwap(c *gin.Context) {
+	userRole := c.GetString("user_role")
+	h.respondShiftSwap(c, func(ctx context.Context, userID string, id string, req service.RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+		return h.service.ApproveShiftSwap(ctx, userID, userRole, id, req)
+	})
+}
+
+// RejectShiftSwap handles reject shift swap request
+func (h *AttendanceHandler) RejectShiftSwap(c *gin.Context) {
+	userRole := c.GetString("user_role")
+	h.respondShiftSwap(c, func(ctx context.Context, userID string, id string, req service.RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+		return h.service.RejectShiftSwap(ctx, userID, userRole, id, req)
+	})
+}
+
+// respondShiftSwap binds the optional notes of a shift swap step and runs it
+func (h *AttendanceHandler) respondShiftSwap(c *gin.Context, step func(ctx context.Context, userID string, id string, req service.RespondShiftSwapRequest) (*models.ShiftSwapRequest, error)) {
+	userID := c.GetString("user_id")
+	swapID := c.Param("id")
+
+	var req service.RespondShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := step(c.Request.Context(), userID, swapID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GetWorkSweeps handles get daily work attendance sweeps request
+func (h *AttendanceHandler) GetWorkSweeps(c *gin.Context) {
+	var req service.GetWorkSweepsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	sweeps, total, err := h.service.GetWorkSweeps(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, sweeps, page, perPage, total)
+}
+
+// AmendSweptRecord handles amend swept work attendance record request
+func (h *AttendanceHandler) AmendSweptRecord(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.AmendSweptRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.AmendSweptRecord(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// AssignUnitSupervisor handles assign unit supervisor request
+func (h *AttendanceHandler) AssignUnitSupervisor(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.AssignUnitSupervisorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.AssignUnitSupervisor(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetUnitSupervisors handles get unit supervisors request
+func (h *AttendanceHandler) GetUnitSupervisors(c *gin.Context) {
+	var unit *string
+	if value := c.Query("unit"); value != "" {
+		unit = &value
+	}
+
+	result, err := h.service.GetUnitSupervisors(c.Request.Context(), unit)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RemoveUnitSupervisor handles remove unit supervisor request
+func (h *AttendanceHandler) RemoveUnitSupervisor(c *gin.Context) {
+	if err := h.service.RemoveUnitSupervisor(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Unit supervisor removed successfully"})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GenerateTimesheet handles compute monthly timesheet request
+func (h *AttendanceHandler) GenerateTimesheet(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GenerateTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GenerateTimesheet(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetTimesheets handles get timesheets request
+func (h *AttendanceHandler) GetTimesheets(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GetTimesheetsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	timesheets, total, err := h.service.GetTimesheets(c.Request.Context(), userID, userRole, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, timesheets, page, perPage, total)
+}
+
+// GetMyTimesheets handles get own timesheets request
+func (h *AttendanceHandler) GetMyTimesheets(c *gin.Context) {
+	var req service.GetTimesheetsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	userID := c.GetString("user_id")
+	req.UserID = &userID
+
+	timesheets, total, err := h.service.GetTimesheets(c.Request.Context(), userID, c.GetString("user_role"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	utils.PaginatedResponse(c, timesheets, page, perPage, total)
+}
+
+// GetTimesheet handles get timesheet with its days request
+func (h *AttendanceHandler) GetTimesheet(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	result, err := h.service.GetTimesheet(c.Request.Context(), userID, userRole, c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ApproveTimesheet handles approve timesheet request
+func (h *AttendanceHandler) ApproveTimesheet(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	result, err := h.service.ApproveTimesheet(c.Request.Context(), userID, userRole, c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// LockTimesheet handles lock timesheet request
+func (h *AttendanceHandler) LockTimesheet(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	result, err := h.service.LockTimesheet(c.Request.Context(), userID, userRole, c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
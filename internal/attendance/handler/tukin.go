@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+	"unsri-backend/pkg/spreadsheet"
+)
+
+// CreateTukinRuleSet handles create tukin rule set version request
+func (h *AttendanceHandler) CreateTukinRuleSet(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.CreateTukinRuleSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateTukinRuleSet(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetTukinRuleSets handles get tukin rule sets request
+func (h *AttendanceHandler) GetTukinRuleSets(c *gin.Context) {
+	result, err := h.service.GetTukinRuleSets(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetTukinRuleSet handles get tukin rule set request
+func (h *AttendanceHandler) GetTukinRuleSet(c *gin.Context) {
+	result, err := h.service.GetTukinRuleSet(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ComputeTukinDeductions handles compute monthly tukin deductions request
+func (h *AttendanceHandler) ComputeTukinDeductions(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.TukinPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.ComputeTukinDeductions(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetTukinDeductions handles get monthly tukin deductions request
+func (h *AttendanceHandler) GetTukinDeductions(c *gin.Context) {
+	var req service.TukinPeriodRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetTukinDeductions(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// ExportTukinDeductions handles export monthly tukin deductions request
+func (h *AttendanceHandler) ExportTukinDeductions(c *gin.Context) {
+	var req service.ExportTukinDeductionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	content, filename, format, err := h.service.ExportTukinDeductions(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, spreadsheet.ContentType(format), content)
+}
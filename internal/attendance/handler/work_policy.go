@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CreateWorkPolicy handles create work attendance policy request
+func (h *AttendanceHandler) CreateWorkPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req service.CreateWorkPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateWorkPolicy(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetWorkPolicies handles get work attendance policies request
+func (h *AttendanceHandler) GetWorkPolicies(c *gin.Context) {
+	var req service.GetWorkPoliciesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetWorkPolicies(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetEffectiveWorkPolicy handles get the work attendance policy applied to a user request
+func (h *AttendanceHandler) GetEffectiveWorkPolicy(c *gin.Context) {
+	result, err := h.service.GetEffectiveWorkPolicy(c.Request.Context(), c.Query("user_id"), c.Query("schedule_id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// GetWorkPolicy handles get work attendance policy request
+func (h *AttendanceHandler) GetWorkPolicy(c *gin.Context) {
+	result, err := h.service.GetWorkPolicy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// UpdateWorkPolicy handles update work attendance policy request
+func (h *AttendanceHandler) UpdateWorkPolicy(c *gin.Context) {
+	userID := c.GetString("user_id")
+	policyID := c.Param("id")
+
+	var req service.UpdateWorkPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.UpdateWorkPolicy(c.Request.Context(), userID, policyID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// DeactivateWorkPolicy handles deactivate work attendance policy request
+func (h *AttendanceHandler) DeactivateWorkPolicy(c *gin.Context) {
+	if err := h.service.DeactivateWorkPolicy(c.Request.Context(), c.Param("id")); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Work attendance policy deactivated successfully"})
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// LocationEvent represents a located event of a user from any attendance source
+type LocationEvent struct {
+	Source         models.LocationEventSource `gorm:"column:source"`
+	SourceID       string                     `gorm:"column:source_id"`
+	Latitude       float64                    `gorm:"column:latitude"`
+	Longitude      float64                    `gorm:"column:longitude"`
+	AccuracyMeters *float64                   `gorm:"column:accuracy_meters"`
+	OccurredAt     time.Time                  `gorm:"column:occurred_at"`
+}
+
+// GetLocationEvents gets a user's located events between two times across class and campus attendance,
+// work attendance, location history and gate access logs, latest first
+func (r *AttendanceRepository) GetLocationEvents(ctx context.Context, userID string, from, to time.Time) ([]LocationEvent, error) {
+	var events []LocationEvent
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT 'attendance' AS source, id AS source_id, latitude, longitude, accuracy_meters,
+				COALESCE(check_in_time, created_at) AS occurred_at
+			FROM attendances
+			WHERE user_id = @user AND latitude IS NOT NULL AND longitude IS NOT NULL AND deleted_at IS NULL
+			UNION ALL
+			SELECT 'work_attendance', id, latitude, longitude, accuracy_meters, recorded_at
+			FROM work_attendance_records
+			WHERE user_id = @user AND latitude IS NOT NULL AND longitude IS NOT NULL
+			UNION ALL
+			SELECT 'location_history', id, latitude, longitude, NULL, created_at
+			FROM location_history
+			WHERE user_id = @user
+			UNION ALL
+			SELECT 'access_log', id, latitude, longitude, NULL, created_at
+			FROM access_logs
+			WHERE user_id = @user AND latitude IS NOT NULL AND longitude IS NOT NULL
+		) events
+		WHERE occurred_at BETWEEN @from AND @to
+		ORDER BY occurred_at DESC
+		LIMIT 100`,
+		map[string]interface{}{"user": userID, "from": from, "to": to}).
+		Scan(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// CreateLocationAnomalies stores location anomalies
+func (r *AttendanceRepository) CreateLocationAnomalies(ctx context.Context, anomalies []models.LocationAnomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(&anomalies).Error
+}
+
+// GetLocationAnomalyByID gets a location anomaly by ID
+func (r *AttendanceRepository) GetLocationAnomalyByID(ctx context.Context, id string) (*models.LocationAnomaly, error) {
+	var anomaly models.LocationAnomaly
+	if err := r.db.WithContext(ctx).Preload("User").Where("id = ?", id).First(&anomaly).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("location anomaly not found")
+		}
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+// GetLocationAnomalies gets location anomalies with filters
+func (r *AttendanceRepository) GetLocationAnomalies(ctx context.Context, userID, anomalyType, source *string, unreviewed bool, startDate, endDate *time.Time, limit, offset int) ([]models.LocationAnomaly, int64, error) {
+	var anomalies []models.LocationAnomaly
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.LocationAnomaly{})
+
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if anomalyType != nil {
+		query = query.Where("type = ?", *anomalyType)
+	}
+	if source != nil {
+		query = query.Where("source = ?", *source)
+	}
+	if unreviewed {
+		query = query.Where("reviewed_at IS NULL")
+	}
+	if startDate != nil {
+		query = query.Where("occurred_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("occurred_at < ?", endDate.AddDate(0, 0, 1))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("User").
+		Limit(limit).Offset(offset).
+		Order("occurred_at DESC").
+		Find(&anomalies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return anomalies, total, nil
+}
+
+// UpdateLocationAnomaly updates a location anomaly
+func (r *AttendanceRepository) UpdateLocationAnomaly(ctx context.Context, anomaly *models.LocationAnomaly) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(anomaly).Error
+}
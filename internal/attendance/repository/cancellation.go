@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// CancelSchedule cancels a meeting in one transaction: it deactivates the meeting's sessions,
+// creates the make-up meeting when given and stores the cancelled meeting linked to it
+func (r *AttendanceRepository) CancelSchedule(ctx context.Context, schedule *models.Schedule, replacement *models.Schedule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AttendanceSession{}).
+			Where("schedule_id = ? AND is_active = ?", schedule.ID, true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		if replacement != nil {
+			if err := tx.Omit(clause.Associations).Create(replacement).Error; err != nil {
+				return err
+			}
+			schedule.ReplacementScheduleID = &replacement.ID
+		}
+
+		return tx.Omit(clause.Associations).Save(schedule).Error
+	})
+}
+
+// GetClassMeetingCounts counts the meetings of a class by outcome
+func (r *AttendanceRepository) GetClassMeetingCounts(ctx context.Context, classID string) (*MeetingCounts, error) {
+	var counts MeetingCounts
+	if err := r.db.WithContext(ctx).Model(&models.Schedule{}).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE attendance_closed_at IS NOT NULL AND cancelled_at IS NULL) AS held,
+			COUNT(*) FILTER (WHERE cancelled_at IS NOT NULL) AS cancelled,
+			COUNT(*) FILTER (WHERE cancelled_at IS NOT NULL AND replacement_schedule_id IS NULL) AS not_replaced,
+			COUNT(*) FILTER (WHERE replaces_schedule_id IS NOT NULL) AS make_up`).
+		Where("class_id = ?", classID).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// MeetingCounts represents the meetings of a class by outcome
+type MeetingCounts struct {
+	Total       int64 `gorm:"column:total"`
+	Held        int64 `gorm:"column:held"`
+	Cancelled   int64 `gorm:"column:cancelled"`
+	NotReplaced int64 `gorm:"column:not_replaced"`
+	MakeUp      int64 `gorm:"column:make_up"`
+}
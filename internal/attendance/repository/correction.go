@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateCorrectionRequest creates a new attendance correction request
+func (r *AttendanceRepository) CreateCorrectionRequest(ctx context.Context, request *models.AttendanceCorrectionRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// GetCorrectionRequestByID gets a correction request by ID
+func (r *AttendanceRepository) GetCorrectionRequestByID(ctx context.Context, id string) (*models.AttendanceCorrectionRequest, error) {
+	var request models.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Preload("User").Preload("Schedule").Preload("Attachment").
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("correction request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetPendingCorrectionRequest gets a student's pending correction request for a schedule.
+// Returns nil when there is none.
+func (r *AttendanceRepository) GetPendingCorrectionRequest(ctx context.Context, userID, scheduleID string) (*models.AttendanceCorrectionRequest, error) {
+	var request models.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND schedule_id = ? AND status = ?", userID, scheduleID, models.CorrectionStatusPending).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetCorrectionRequests gets correction requests with filters.
+// reviewerID limits the results to schedules taught by the reviewer, as schedule dosen or class (assistant) dosen.
+func (r *AttendanceRepository) GetCorrectionRequests(ctx context.Context, userID *string, reviewerID *string, scheduleID *string, status *string, limit, offset int) ([]models.AttendanceCorrectionRequest, int64, error) {
+	var requests []models.AttendanceCorrectionRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.AttendanceCorrectionRequest{})
+
+	if userID != nil {
+		query = query.Where("attendance_correction_requests.user_id = ?", *userID)
+	}
+	if reviewerID != nil {
+		query = query.
+			Joins("JOIN schedules ON schedules.id = attendance_correction_requests.schedule_id").
+			Joins("LEFT JOIN classes ON classes.id = schedules.class_id").
+			Where("schedules.dosen_id = ? OR classes.dosen_id = ? OR classes.assistant_dosen_id = ?", *reviewerID, *reviewerID, *reviewerID)
+	}
+	if scheduleID != nil {
+		query = query.Where("attendance_correction_requests.schedule_id = ?", *scheduleID)
+	}
+	if status != nil {
+		query = query.Where("attendance_correction_requests.status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("User").Preload("Schedule").Preload("Attachment").
+		Limit(limit).Offset(offset).
+		Order("attendance_correction_requests.created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// UpdateCorrectionRequest updates a correction request
+func (r *AttendanceRepository) UpdateCorrectionRequest(ctx context.Context, request *models.AttendanceCorrectionRequest) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(request).Error
+}
+
+// ApplyCorrection approves a correction request in one transaction:
+// it creates or updates the attendance record, logs the change and stores the reviewed request.
+func (r *AttendanceRepository) ApplyCorrection(ctx context.Context, request *models.AttendanceCorrectionRequest, attendance *models.Attendance, changeLog *models.AttendanceChangeLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveAttendanceWithLog(tx, attendance, changeLog); err != nil {
+			return err
+		}
+
+		request.AttendanceID = &attendance.ID
+		return tx.Omit(clause.Associations).Save(request).Error
+	})
+}
+
+// SaveAttendanceWithLog creates or updates an attendance record and logs the change
+func (r *AttendanceRepository) SaveAttendanceWithLog(ctx context.Context, attendance *models.Attendance, changeLog *models.AttendanceChangeLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveAttendanceWithLog(tx, attendance, changeLog)
+	})
+}
+
+// saveAttendanceWithLog creates the attendance when it has no ID yet, otherwise updates it, then stores the log
+func saveAttendanceWithLog(tx *gorm.DB, attendance *models.Attendance, changeLog *models.AttendanceChangeLog) error {
+	if attendance.ID == "" {
+		if err := tx.Omit(clause.Associations).Create(attendance).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Omit(clause.Associations).Save(attendance).Error; err != nil {
+		return err
+	}
+
+	changeLog.AttendanceID = attendance.ID
+	return tx.Omit(clause.Associations).Create(changeLog).Error
+}
+
+// GetAttendanceWithHistory gets an attendance record with its change history
+func (r *AttendanceRepository) GetAttendanceWithHistory(ctx context.Context, id string) (*models.Attendance, error) {
+	var attendance models.Attendance
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("History", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("History.ChangedByUser").
+		Where("id = ?", id).
+		First(&attendance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attendance not found")
+		}
+		return nil, err
+	}
+	return &attendance, nil
+}
+
+// GetAttendanceByUserAndSchedule gets a user's attendance record for a schedule.
+// Returns nil when there is none.
+func (r *AttendanceRepository) GetAttendanceByUserAndSchedule(ctx context.Context, userID, scheduleID string) (*models.Attendance, error) {
+	var attendance models.Attendance
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND schedule_id = ?", userID, scheduleID).
+		Order("created_at DESC").
+		First(&attendance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attendance, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetActiveDeviceBinding gets the device an account is bound to.
+// Returns nil when the account has no bound device.
+func (r *AttendanceRepository) GetActiveDeviceBinding(ctx context.Context, userID string) (*models.DeviceBinding, error) {
+	var binding models.DeviceBinding
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// GetActiveDeviceBindingByDeviceID gets the account a device is bound to.
+// Returns nil when the device is not bound.
+func (r *AttendanceRepository) GetActiveDeviceBindingByDeviceID(ctx context.Context, deviceID string) (*models.DeviceBinding, error) {
+	var binding models.DeviceBinding
+	if err := r.db.WithContext(ctx).
+		Where("device_id = ? AND is_active = ?", deviceID, true).
+		First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// CreateDeviceBinding creates a device binding
+func (r *AttendanceRepository) CreateDeviceBinding(ctx context.Context, binding *models.DeviceBinding) error {
+	return r.db.WithContext(ctx).Create(binding).Error
+}
+
+// UpdateDeviceBinding updates a device binding
+func (r *AttendanceRepository) UpdateDeviceBinding(ctx context.Context, binding *models.DeviceBinding) error {
+	return r.db.WithContext(ctx).Save(binding).Error
+}
+
+// CreateDeviceRebindRequest creates a device re-binding request
+func (r *AttendanceRepository) CreateDeviceRebindRequest(ctx context.Context, request *models.DeviceRebindRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// GetDeviceRebindRequestByID gets a device re-binding request by ID
+func (r *AttendanceRepository) GetDeviceRebindRequestByID(ctx context.Context, id string) (*models.DeviceRebindRequest, error) {
+	var request models.DeviceRebindRequest
+	if err := r.db.WithContext(ctx).Preload("User").Where("id = ?", id).First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device rebind request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetPendingDeviceRebindRequest gets an account's pending re-binding request.
+// Returns nil when there is none.
+func (r *AttendanceRepository) GetPendingDeviceRebindRequest(ctx context.Context, userID string) (*models.DeviceRebindRequest, error) {
+	var request models.DeviceRebindRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, models.CorrectionStatusPending).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetDeviceRebindRequests gets device re-binding requests with filters
+func (r *AttendanceRepository) GetDeviceRebindRequests(ctx context.Context, userID *string, status *string, limit, offset int) ([]models.DeviceRebindRequest, int64, error) {
+	var requests []models.DeviceRebindRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.DeviceRebindRequest{})
+
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("User").
+		Limit(limit).Offset(offset).
+		Order("created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// UpdateDeviceRebindRequest updates a device re-binding request
+func (r *AttendanceRepository) UpdateDeviceRebindRequest(ctx context.Context, request *models.DeviceRebindRequest) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(request).Error
+}
+
+// RebindDevice approves a re-binding request in one transaction:
+// it revokes the account's current binding, creates the new one and stores the reviewed request.
+func (r *AttendanceRepository) RebindDevice(ctx context.Context, request *models.DeviceRebindRequest, binding *models.DeviceBinding) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.DeviceBinding{}).
+			Where("user_id = ? AND is_active = ?", binding.UserID, true).
+			Updates(map[string]interface{}{"is_active": false, "revoked_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(binding).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Save(request).Error
+	})
+}
+
+// GetScheduleDeviceUserIDs gets the accounts that scanned for a class meeting from a device,
+// both recorded and refused scans count
+func (r *AttendanceRepository) GetScheduleDeviceUserIDs(ctx context.Context, scheduleID, deviceID string) ([]string, error) {
+	var userIDs []string
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT user_id FROM attendances WHERE schedule_id = ? AND device_id = ? AND deleted_at IS NULL
+		UNION
+		SELECT user_id FROM attendance_scan_rejections WHERE schedule_id = ? AND device_id = ?
+		ORDER BY user_id`, scheduleID, deviceID, scheduleID, deviceID).
+		Scan(&userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// GetWorkDeviceUserIDs gets the accounts that recorded work attendance from a device on a date
+func (r *AttendanceRepository) GetWorkDeviceUserIDs(ctx context.Context, deviceID string, date time.Time) ([]string, error) {
+	var userIDs []string
+	if err := r.db.WithContext(ctx).Model(&models.WorkAttendanceRecord{}).
+		Distinct("user_id").
+		Where("device_id = ? AND DATE(recorded_at) = DATE(?)", deviceID, date).
+		Order("user_id").
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// UpsertSharedDeviceFlag creates the flag of a device in a scope, or updates its accounts
+func (r *AttendanceRepository) UpsertSharedDeviceFlag(ctx context.Context, flag *models.SharedDeviceFlag) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope_key"}, {Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_ids", "account_count", "updated_at"}),
+	}).Create(flag).Error
+}
+
+// GetSharedDeviceFlags gets shared device flags with filters.
+// reviewerID limits the results to class meetings taught by the reviewer.
+func (r *AttendanceRepository) GetSharedDeviceFlags(ctx context.Context, reviewerID, scheduleID, classID *string, startDate, endDate *time.Time, limit, offset int) ([]models.SharedDeviceFlag, int64, error) {
+	var flags []models.SharedDeviceFlag
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.SharedDeviceFlag{})
+
+	if reviewerID != nil {
+		query = query.
+			Joins("JOIN schedules ON schedules.id = shared_device_flags.schedule_id").
+			Joins("LEFT JOIN classes ON classes.id = schedules.class_id").
+			Where("schedules.dosen_id = ? OR classes.dosen_id = ? OR classes.assistant_dosen_id = ?", *reviewerID, *reviewerID, *reviewerID)
+	}
+	if scheduleID != nil {
+		query = query.Where("shared_device_flags.schedule_id = ?", *scheduleID)
+	}
+	if classID != nil {
+		query = query.Where("shared_device_flags.class_id = ?", *classID)
+	}
+	if startDate != nil {
+		query = query.Where("shared_device_flags.date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("shared_device_flags.date <= ?", *endDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Limit(limit).Offset(offset).
+		Order("shared_device_flags.date DESC, shared_device_flags.account_count DESC").
+		Find(&flags).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return flags, total, nil
+}
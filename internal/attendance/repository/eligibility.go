@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateEligibilityPolicy creates a new eligibility policy
+func (r *AttendanceRepository) CreateEligibilityPolicy(ctx context.Context, policy *models.EligibilityPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// GetEligibilityPolicyByID gets an eligibility policy by ID
+func (r *AttendanceRepository) GetEligibilityPolicyByID(ctx context.Context, id string) (*models.EligibilityPolicy, error) {
+	var policy models.EligibilityPolicy
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("eligibility policy not found")
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetEligibilityPolicies gets eligibility policies with filters
+func (r *AttendanceRepository) GetEligibilityPolicies(ctx context.Context, scope *string, courseID *string, prodi *string) ([]models.EligibilityPolicy, error) {
+	var policies []models.EligibilityPolicy
+	query := r.db.WithContext(ctx).Model(&models.EligibilityPolicy{})
+
+	if scope != nil {
+		query = query.Where("scope = ?", *scope)
+	}
+	if courseID != nil {
+		query = query.Where("course_id = ?", *courseID)
+	}
+	if prodi != nil {
+		query = query.Where("prodi = ?", *prodi)
+	}
+
+	if err := query.Order("scope ASC, created_at DESC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetActiveEligibilityPolicy gets the active policy for a scope and target.
+// targetID is the course ID or prodi, ignored for the global scope. Returns nil when none is set.
+func (r *AttendanceRepository) GetActiveEligibilityPolicy(ctx context.Context, scope models.PolicyScope, targetID string) (*models.EligibilityPolicy, error) {
+	var policy models.EligibilityPolicy
+	query := r.db.WithContext(ctx).Where("scope = ? AND is_active = ?", scope, true)
+
+	switch scope {
+	case models.PolicyScopeCourse:
+		query = query.Where("course_id = ?", targetID)
+	case models.PolicyScopeStudyProgram:
+		query = query.Where("prodi = ?", targetID)
+	}
+
+	if err := query.Order("updated_at DESC").First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdateEligibilityPolicy updates an eligibility policy
+func (r *AttendanceRepository) UpdateEligibilityPolicy(ctx context.Context, policy *models.EligibilityPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// DeleteEligibilityPolicy soft deletes an eligibility policy
+func (r *AttendanceRepository) DeleteEligibilityPolicy(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.EligibilityPolicy{}, "id = ?", id).Error
+}
+
+// CountHeldSchedulesByClassID counts the meetings of a class whose attendance has been closed
+func (r *AttendanceRepository) CountHeldSchedulesByClassID(ctx context.Context, classID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Schedule{}).
+		Where("class_id = ? AND attendance_closed_at IS NOT NULL", classID).
+		Count(&count).Error
+	return count, err
+}
+
+// CountPresenceByClassID counts, per student, the closed meetings of a class attended as hadir or terlambat
+func (r *AttendanceRepository) CountPresenceByClassID(ctx context.Context, classID string) (map[string]int, error) {
+	var rows []struct {
+		UserID string
+		Count  int
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.Attendance{}).
+		Select("attendances.user_id, COUNT(DISTINCT attendances.schedule_id) AS count").
+		Joins("JOIN schedules ON schedules.id = attendances.schedule_id AND schedules.deleted_at IS NULL").
+		Where("schedules.class_id = ? AND schedules.attendance_closed_at IS NOT NULL", classID).
+		Where("attendances.status IN ?", []models.AttendanceStatus{models.StatusHadir, models.StatusTerlambat}).
+		Group("attendances.user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetMahasiswaByUserIDs gets the student profiles of the given users
+func (r *AttendanceRepository) GetMahasiswaByUserIDs(ctx context.Context, userIDs []string) ([]models.Mahasiswa, error) {
+	var mahasiswa []models.Mahasiswa
+	if len(userIDs) == 0 {
+		return mahasiswa, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&mahasiswa).Error; err != nil {
+		return nil, err
+	}
+	return mahasiswa, nil
+}
+
+// GetEligibilityWarningsByClassID gets the warnings already sent for a class
+func (r *AttendanceRepository) GetEligibilityWarningsByClassID(ctx context.Context, classID string) ([]models.EligibilityWarning, error) {
+	var warnings []models.EligibilityWarning
+	if err := r.db.WithContext(ctx).Where("class_id = ?", classID).Find(&warnings).Error; err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// CreateEligibilityWarning records a sent eligibility warning
+func (r *AttendanceRepository) CreateEligibilityWarning(ctx context.Context, warning *models.EligibilityWarning) error {
+	return r.db.WithContext(ctx).Create(warning).Error
+}
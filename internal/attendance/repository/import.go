@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetMahasiswaByNIMs gets the student profiles with the given NIMs
+func (r *AttendanceRepository) GetMahasiswaByNIMs(ctx context.Context, nims []string) ([]models.Mahasiswa, error) {
+	var mahasiswa []models.Mahasiswa
+	if len(nims) == 0 {
+		return mahasiswa, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("nim IN ?", nims).Find(&mahasiswa).Error; err != nil {
+		return nil, err
+	}
+	return mahasiswa, nil
+}
+
+// ImportAttendances stores imported attendance records with their change logs in one transaction,
+// nothing is stored when any record fails
+func (r *AttendanceRepository) ImportAttendances(ctx context.Context, attendances []*models.Attendance, changeLogs []*models.AttendanceChangeLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range attendances {
+			if err := saveAttendanceWithLog(tx, attendances[i], changeLogs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetClassMeetings gets the meetings of a class that were not cancelled, in date order
+func (r *AttendanceRepository) GetClassMeetings(ctx context.Context, classID string) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if err := r.db.WithContext(ctx).
+		Where("class_id = ? AND cancelled_at IS NULL", classID).
+		Order("date, start_time").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetAttendancesByScheduleIDs gets the attendance records of several meetings
+func (r *AttendanceRepository) GetAttendancesByScheduleIDs(ctx context.Context, scheduleIDs []string) ([]models.Attendance, error) {
+	var attendances []models.Attendance
+	if len(scheduleIDs) == 0 {
+		return attendances, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("schedule_id IN ?", scheduleIDs).Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetLectureJournalByScheduleID gets the lecture journal of a class meeting.
+// Returns nil when the meeting has no journal yet.
+func (r *AttendanceRepository) GetLectureJournalByScheduleID(ctx context.Context, scheduleID string) (*models.LectureJournal, error) {
+	var journal models.LectureJournal
+	if err := r.db.WithContext(ctx).Where("schedule_id = ?", scheduleID).First(&journal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &journal, nil
+}
+
+// CreateLectureJournal creates a lecture journal
+func (r *AttendanceRepository) CreateLectureJournal(ctx context.Context, journal *models.LectureJournal) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(journal).Error
+}
+
+// UpdateLectureJournal updates a lecture journal
+func (r *AttendanceRepository) UpdateLectureJournal(ctx context.Context, journal *models.LectureJournal) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(journal).Error
+}
+
+// CountClassSchedulesBefore counts the meetings of a class, not cancelled, before the given date and start time
+func (r *AttendanceRepository) CountClassSchedulesBefore(ctx context.Context, classID string, date, startTime time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Schedule{}).
+		Where("class_id = ? AND cancelled_at IS NULL AND (date < ? OR (date = ? AND start_time < ?))", classID, date, date, startTime).
+		Count(&count).Error
+	return count, err
+}
+
+// GetFirstSessionByScheduleID gets the earliest attendance session opened for a schedule.
+// Returns nil when no session was opened.
+func (r *AttendanceRepository) GetFirstSessionByScheduleID(ctx context.Context, scheduleID string) (*models.AttendanceSession, error) {
+	var session models.AttendanceSession
+	if err := r.db.WithContext(ctx).
+		Where("schedule_id = ?", scheduleID).
+		Order("created_at ASC").
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetLectureJournals gets lecture journals with filters, ordered by class and meeting
+func (r *AttendanceRepository) GetLectureJournals(ctx context.Context, dosenID, classID, semester, academicYear *string) ([]models.LectureJournal, error) {
+	var journals []models.LectureJournal
+
+	query := r.db.WithContext(ctx).Model(&models.LectureJournal{})
+
+	if dosenID != nil {
+		query = query.Where("dosen_id = ?", *dosenID)
+	}
+	if classID != nil {
+		query = query.Where("class_id = ?", *classID)
+	}
+	if semester != nil {
+		query = query.Where("semester = ?", *semester)
+	}
+	if academicYear != nil {
+		query = query.Where("academic_year = ?", *academicYear)
+	}
+
+	if err := query.Preload("Schedule").
+		Order("class_id, meeting_number").
+		Find(&journals).Error; err != nil {
+		return nil, err
+	}
+
+	return journals, nil
+}
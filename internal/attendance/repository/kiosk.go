@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateKioskDevice registers a kiosk device
+func (r *AttendanceRepository) CreateKioskDevice(ctx context.Context, device *models.KioskDevice) error {
+	return r.db.WithContext(ctx).Create(device).Error
+}
+
+// GetKioskDeviceByID gets a kiosk device by ID
+func (r *AttendanceRepository) GetKioskDeviceByID(ctx context.Context, id string) (*models.KioskDevice, error) {
+	var device models.KioskDevice
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("kiosk device not found")
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetActiveKioskDeviceByTokenHash gets the active kiosk device a token was issued to.
+// Returns nil when no active device has the token.
+func (r *AttendanceRepository) GetActiveKioskDeviceByTokenHash(ctx context.Context, tokenHash string) (*models.KioskDevice, error) {
+	var device models.KioskDevice
+	if err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND is_active = ?", tokenHash, true).
+		First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetKioskDevices gets kiosk devices, optionally of one unit
+func (r *AttendanceRepository) GetKioskDevices(ctx context.Context, unit *string) ([]models.KioskDevice, error) {
+	var devices []models.KioskDevice
+	query := r.db.WithContext(ctx)
+	if unit != nil {
+		query = query.Where("unit = ?", *unit)
+	}
+	if err := query.Order("unit ASC, name ASC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RevokeKioskDevice deactivates a kiosk device and closes the sessions it opened in one transaction
+func (r *AttendanceRepository) RevokeKioskDevice(ctx context.Context, device *models.KioskDevice) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(device).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WorkAttendanceSession{}).
+			Where("kiosk_device_id = ? AND status = ?", device.ID, models.WorkSessionStatusOpen).
+			Updates(map[string]interface{}{"status": models.WorkSessionStatusClosed, "is_active": false, "closed_at": device.RevokedAt}).Error
+	})
+}
+
+// GetKioskSessions gets kiosk sessions with filters
+func (r *AttendanceRepository) GetKioskSessions(ctx context.Context, unit *string, status *string, limit, offset int) ([]models.WorkAttendanceSession, int64, error) {
+	var sessions []models.WorkAttendanceSession
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WorkAttendanceSession{}).Where("unit <> ''")
+
+	if unit != nil {
+		query = query.Where("unit = ?", *unit)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+// ExpireKioskSessions marks the open sessions past their close time as expired
+func (r *AttendanceRepository) ExpireKioskSessions(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.WorkAttendanceSession{}).
+		Where("status = ? AND expires_at <= ?", models.WorkSessionStatusOpen, now).
+		Updates(map[string]interface{}{"status": models.WorkSessionStatusExpired, "is_active": false})
+	return result.RowsAffected, result.Error
+}
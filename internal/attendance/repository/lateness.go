@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateLatenessPolicy creates a new lateness policy
+func (r *AttendanceRepository) CreateLatenessPolicy(ctx context.Context, policy *models.LatenessPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// GetLatenessPolicyByID gets a lateness policy by ID
+func (r *AttendanceRepository) GetLatenessPolicyByID(ctx context.Context, id string) (*models.LatenessPolicy, error) {
+	var policy models.LatenessPolicy
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("lateness policy not found")
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetLatenessPolicies gets lateness policies with filters
+func (r *AttendanceRepository) GetLatenessPolicies(ctx context.Context, scope *string, courseID *string, classID *string) ([]models.LatenessPolicy, error) {
+	var policies []models.LatenessPolicy
+	query := r.db.WithContext(ctx).Model(&models.LatenessPolicy{})
+
+	if scope != nil {
+		query = query.Where("scope = ?", *scope)
+	}
+	if courseID != nil {
+		query = query.Where("course_id = ?", *courseID)
+	}
+	if classID != nil {
+		query = query.Where("class_id = ?", *classID)
+	}
+
+	if err := query.Order("scope ASC, created_at DESC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetActiveLatenessPolicy gets the active policy for a scope and target.
+// targetID is the course or class ID, ignored for the global scope. Returns nil when none is set.
+func (r *AttendanceRepository) GetActiveLatenessPolicy(ctx context.Context, scope models.PolicyScope, targetID string) (*models.LatenessPolicy, error) {
+	var policy models.LatenessPolicy
+	query := r.db.WithContext(ctx).Where("scope = ? AND is_active = ?", scope, true)
+
+	switch scope {
+	case models.PolicyScopeCourse:
+		query = query.Where("course_id = ?", targetID)
+	case models.PolicyScopeClass:
+		query = query.Where("class_id = ?", targetID)
+	}
+
+	if err := query.Order("updated_at DESC").First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdateLatenessPolicy updates a lateness policy
+func (r *AttendanceRepository) UpdateLatenessPolicy(ctx context.Context, policy *models.LatenessPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// DeleteLatenessPolicy soft deletes a lateness policy
+func (r *AttendanceRepository) DeleteLatenessPolicy(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.LatenessPolicy{}, "id = ?", id).Error
+}
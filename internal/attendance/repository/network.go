@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetUserUnit gets the unit of a user: the staff unit, or the prodi of a dosen.
+// Returns an empty unit for other users.
+func (r *AttendanceRepository) GetUserUnit(ctx context.Context, userID string) (string, error) {
+	var staff models.Staff
+	err := r.db.WithContext(ctx).Select("unit").Where("user_id = ?", userID).First(&staff).Error
+	if err == nil {
+		return staff.Unit, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	var dosen models.Dosen
+	err = r.db.WithContext(ctx).Select("prodi").Where("user_id = ?", userID).First(&dosen).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return dosen.Prodi, err
+}
+
+// GetCheckInRequirementByID gets a check-in requirement by ID
+func (r *AttendanceRepository) GetCheckInRequirementByID(ctx context.Context, id string) (*models.CheckInRequirement, error) {
+	var requirement models.CheckInRequirement
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&requirement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("check-in requirement not found")
+		}
+		return nil, err
+	}
+	return &requirement, nil
+}
+
+// GetCheckInRequirementByUnit gets the check-in requirement of a unit, nil when none is set
+func (r *AttendanceRepository) GetCheckInRequirementByUnit(ctx context.Context, unit string) (*models.CheckInRequirement, error) {
+	var requirement models.CheckInRequirement
+	if err := r.db.WithContext(ctx).Where("unit = ?", unit).First(&requirement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &requirement, nil
+}
+
+// GetCheckInRequirements gets all check-in requirements
+func (r *AttendanceRepository) GetCheckInRequirements(ctx context.Context) ([]models.CheckInRequirement, error) {
+	var requirements []models.CheckInRequirement
+	if err := r.db.WithContext(ctx).Order("unit ASC").Find(&requirements).Error; err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// SaveCheckInRequirement creates or updates a check-in requirement
+func (r *AttendanceRepository) SaveCheckInRequirement(ctx context.Context, requirement *models.CheckInRequirement) error {
+	return r.db.WithContext(ctx).Save(requirement).Error
+}
+
+// DeleteCheckInRequirement deletes a check-in requirement
+func (r *AttendanceRepository) DeleteCheckInRequirement(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.CheckInRequirement{}, "id = ?", id).Error
+}
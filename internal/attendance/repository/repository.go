@@ -112,6 +112,23 @@ func (r *AttendanceRepository) UpdateSession(ctx context.Context, session *model
 	return r.db.WithContext(ctx).Save(session).Error
 }
 
+// CreateScanRejection records a refused attendance scan
+func (r *AttendanceRepository) CreateScanRejection(ctx context.Context, rejection *models.AttendanceScanRejection) error {
+	return r.db.WithContext(ctx).Create(rejection).Error
+}
+
+// GetScanRejectionsByScheduleID gets refused scans for a schedule
+func (r *AttendanceRepository) GetScanRejectionsByScheduleID(ctx context.Context, scheduleID string) ([]models.AttendanceScanRejection, error) {
+	var rejections []models.AttendanceScanRejection
+	if err := r.db.WithContext(ctx).Preload("User").
+		Where("schedule_id = ?", scheduleID).
+		Order("created_at DESC").
+		Find(&rejections).Error; err != nil {
+		return nil, err
+	}
+	return rejections, nil
+}
+
 // CreateSchedule creates a new schedule
 func (r *AttendanceRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
 	return r.db.WithContext(ctx).Create(schedule).Error
@@ -211,6 +228,8 @@ func (r *AttendanceRepository) GetAttendanceStatistics(ctx context.Context, user
 	if endDate != nil {
 		query = query.Where("date <= ?", endDate)
 	}
+	// Reuse the filters across the counts below without stacking their clauses
+	query = query.Session(&gorm.Session{})
 
 	// Total attendances
 	var total int64
@@ -253,10 +272,12 @@ func (r *AttendanceRepository) GetAttendanceStatistics(ctx context.Context, user
 	}
 	stats["by_type"] = typeMap
 
-	// Attendance rate
+	stats["late"] = statusMap[string(models.StatusTerlambat)]
+
+	// Attendance rate, late arrivals still count as attended
 	if total > 0 {
-		hadir := statusMap["hadir"]
-		stats["attendance_rate"] = float64(hadir) / float64(total) * 100
+		attended := statusMap[string(models.StatusHadir)] + statusMap[string(models.StatusTerlambat)]
+		stats["attendance_rate"] = float64(attended) / float64(total) * 100
 	} else {
 		stats["attendance_rate"] = 0.0
 	}
@@ -628,13 +649,13 @@ func (r *AttendanceRepository) GetWorkAttendanceRecordsByScheduleID(ctx context.
 	return records, nil
 }
 
-// GetTodayWorkAttendanceRecord gets today's work attendance record for check-in/out
-func (r *AttendanceRepository) GetTodayWorkAttendanceRecord(ctx context.Context, userID string, attendanceType string) (*models.WorkAttendanceRecord, error) {
-	today := time.Now().Format("2006-01-02")
+// GetWorkAttendanceRecordByWorkDate gets a user's check-in or check-out attributed to a work day.
+// Records from before work days were stored fall back to their calendar date.
+func (r *AttendanceRepository) GetWorkAttendanceRecordByWorkDate(ctx context.Context, userID string, workDate time.Time, attendanceType string) (*models.WorkAttendanceRecord, error) {
 	var record models.WorkAttendanceRecord
 
 	if err := r.db.WithContext(ctx).
-		Where("user_id = ? AND DATE(recorded_at) = ? AND attendance_type = ?", userID, today, attendanceType).
+		Where("user_id = ? AND COALESCE(work_date, DATE(recorded_at)) = ? AND attendance_type = ?", userID, workDate.Format("2006-01-02"), attendanceType).
 		Order("recorded_at DESC").
 		First(&record).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -646,6 +667,24 @@ func (r *AttendanceRepository) GetTodayWorkAttendanceRecord(ctx context.Context,
 	return &record, nil
 }
 
+// GetLatestWorkCheckIn gets a user's latest check-in recorded since the given time.
+// Returns nil when there is none.
+func (r *AttendanceRepository) GetLatestWorkCheckIn(ctx context.Context, userID string, since time.Time) (*models.WorkAttendanceRecord, error) {
+	var record models.WorkAttendanceRecord
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND attendance_type = ? AND recorded_at >= ?", userID, "CHECK_IN", since).
+		Order("recorded_at DESC").
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
 // UpdateWorkAttendanceRecord updates a work attendance record
 func (r *AttendanceRepository) UpdateWorkAttendanceRecord(ctx context.Context, record *models.WorkAttendanceRecord) error {
 	return r.db.WithContext(ctx).Save(record).Error
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// ErrAttendanceAlreadyClosed is returned when closing a schedule whose roster is already final
+var ErrAttendanceAlreadyClosed = errors.New("attendance already closed")
+
+// GetAttendanceUserIDsByScheduleID gets the IDs of users with an attendance record for a schedule
+func (r *AttendanceRepository) GetAttendanceUserIDsByScheduleID(ctx context.Context, scheduleID string) ([]string, error) {
+	var userIDs []string
+	if err := r.db.WithContext(ctx).Model(&models.Attendance{}).
+		Where("schedule_id = ?", scheduleID).
+		Distinct().
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// GetSchedulesWithExpiredSessions gets open schedules whose latest class session expired before now
+func (r *AttendanceRepository) GetSchedulesWithExpiredSessions(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	expired := r.db.WithContext(ctx).Model(&models.AttendanceSession{}).
+		Select("schedule_id").
+		Where("type = ? AND schedule_id IS NOT NULL", models.AttendanceTypeKelas).
+		Group("schedule_id").
+		Having("MAX(expires_at) < ?", now)
+
+	if err := r.db.WithContext(ctx).
+		Where("id IN (?) AND attendance_closed_at IS NULL AND cancelled_at IS NULL", expired).
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CloseScheduleAttendance finalizes a schedule roster in one transaction:
+// it stores the generated records, deactivates the schedule's sessions and marks the schedule closed.
+func (r *AttendanceRepository) CloseScheduleAttendance(ctx context.Context, schedule *models.Schedule, records []models.Attendance) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Guard against a concurrent close by the worker and a lecturer
+		result := tx.Model(&models.Schedule{}).
+			Where("id = ? AND attendance_closed_at IS NULL", schedule.ID).
+			Updates(map[string]interface{}{
+				"attendance_closed_at": schedule.AttendanceClosedAt,
+				"attendance_closed_by": schedule.AttendanceClosedBy,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAttendanceAlreadyClosed
+		}
+
+		if err := tx.Model(&models.AttendanceSession{}).
+			Where("schedule_id = ? AND is_active = ?", schedule.ID, true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		if len(records) > 0 {
+			if err := tx.Create(&records).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetUserIDsByUnit gets the users of a unit: staff of the unit and dosen of the prodi
+func (r *AttendanceRepository) GetUserIDsByUnit(ctx context.Context, unit string) ([]string, error) {
+	var staffIDs, dosenIDs []string
+	if err := r.db.WithContext(ctx).Model(&models.Staff{}).Where("unit = ?", unit).Pluck("user_id", &staffIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Dosen{}).Where("prodi = ?", unit).Pluck("user_id", &dosenIDs).Error; err != nil {
+		return nil, err
+	}
+	return append(staffIDs, dosenIDs...), nil
+}
+
+// GetUserShiftsInRange gets the active shift assignments overlapping a date range, with their shift pattern.
+// A nil userIDs gets the assignments of all users.
+func (r *AttendanceRepository) GetUserShiftsInRange(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]models.UserShift, error) {
+	var userShifts []models.UserShift
+	query := r.db.WithContext(ctx).Preload("Shift").
+		Where("is_active = ? AND effective_from <= ? AND (effective_until IS NULL OR effective_until >= ?)",
+			true, endDate.Format("2006-01-02"), startDate.Format("2006-01-02"))
+
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+
+	if err := query.Order("user_id ASC, effective_from DESC").Find(&userShifts).Error; err != nil {
+		return nil, err
+	}
+	return userShifts, nil
+}
+
+// GetWorkSchedulesInRange gets the active work schedules of users in a date range
+func (r *AttendanceRepository) GetWorkSchedulesInRange(ctx context.Context, userIDs []string, startDate, endDate time.Time) ([]models.WorkSchedule, error) {
+	var schedules []models.WorkSchedule
+	if len(userIDs) == 0 {
+		return schedules, nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND is_active = ? AND schedule_date BETWEEN ? AND ?",
+			userIDs, true, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("schedule_date ASC, start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ApplyRoster creates, updates and deletes work schedules of a generated roster in one transaction
+func (r *AttendanceRepository) ApplyRoster(ctx context.Context, creates, updates []models.WorkSchedule, deleteIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&creates).Error; err != nil {
+				return err
+			}
+		}
+		for i := range updates {
+			if err := tx.Omit(clause.Associations).Save(&updates[i]).Error; err != nil {
+				return err
+			}
+		}
+		if len(deleteIDs) > 0 {
+			if err := tx.Where("id IN ?", deleteIDs).Delete(&models.WorkSchedule{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
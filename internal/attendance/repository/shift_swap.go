@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// ErrScheduleReassigned is returned when a swapped work schedule no longer belongs to the user it is taken from
+var ErrScheduleReassigned = errors.New("work schedule reassigned")
+
+// CreateShiftSwap creates a shift swap request with its first event in one transaction
+func (r *AttendanceRepository) CreateShiftSwap(ctx context.Context, request *models.ShiftSwapRequest, event *models.ShiftSwapEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(request).Error; err != nil {
+			return err
+		}
+		event.SwapRequestID = request.ID
+		return tx.Create(event).Error
+	})
+}
+
+// GetShiftSwapByID gets a shift swap request with its schedules and history
+func (r *AttendanceRepository) GetShiftSwapByID(ctx context.Context, id string) (*models.ShiftSwapRequest, error) {
+	var request models.ShiftSwapRequest
+	if err := r.db.WithContext(ctx).
+		Preload("RequesterSchedule").Preload("TargetSchedule").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shift swap request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetOpenShiftSwapForSchedules gets a pending or accepted shift swap request involving any of the schedules.
+// Returns nil when there is none.
+func (r *AttendanceRepository) GetOpenShiftSwapForSchedules(ctx context.Context, scheduleIDs []string) (*models.ShiftSwapRequest, error) {
+	var request models.ShiftSwapRequest
+	if err := r.db.WithContext(ctx).
+		Where("status IN ? AND (requester_schedule_id IN ? OR target_schedule_id IN ?)",
+			[]models.ShiftSwapStatus{models.ShiftSwapStatusPending, models.ShiftSwapStatusAccepted}, scheduleIDs, scheduleIDs).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetShiftSwaps gets shift swap requests with filters.
+// partyID limits the results to requests made by or to the user.
+func (r *AttendanceRepository) GetShiftSwaps(ctx context.Context, partyID *string, units []string, status *string, limit, offset int) ([]models.ShiftSwapRequest, int64, error) {
+	var requests []models.ShiftSwapRequest
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.ShiftSwapRequest{})
+
+	switch {
+	case partyID != nil && len(units) > 0:
+		requester, requesterArgs := r.inUnits("requester_id", units)
+		target, targetArgs := r.inUnits("target_user_id", units)
+		query = query.Where("(requester_id = ? OR target_user_id = ? OR ("+requester+" AND "+target+"))",
+			append(append([]interface{}{*partyID, *partyID}, requesterArgs...), targetArgs...)...)
+	case partyID != nil:
+		query = query.Where("requester_id = ? OR target_user_id = ?", *partyID, *partyID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("RequesterSchedule").Preload("TargetSchedule").
+		Limit(limit).Offset(offset).
+		Order("created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// UpdateShiftSwap stores a shift swap request and logs the step in one transaction
+func (r *AttendanceRepository) UpdateShiftSwap(ctx context.Context, request *models.ShiftSwapRequest, event *models.ShiftSwapEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(request).Error; err != nil {
+			return err
+		}
+		event.SwapRequestID = request.ID
+		return tx.Create(event).Error
+	})
+}
+
+// ApplyShiftSwap approves a shift swap request in one transaction:
+// it reassigns the work schedules, stores the request and logs every step.
+// A schedule is only reassigned while it still belongs to the user it is taken from.
+func (r *AttendanceRepository) ApplyShiftSwap(ctx context.Context, request *models.ShiftSwapRequest, events []models.ShiftSwapEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			if events[i].Action != models.ShiftSwapActionReassigned {
+				continue
+			}
+			result := tx.Model(&models.WorkSchedule{}).
+				Where("id = ? AND user_id = ? AND is_active = ?", *events[i].ScheduleID, *events[i].FromUserID, true).
+				Updates(map[string]interface{}{"user_id": *events[i].ToUserID, "user_shift_id": nil})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrScheduleReassigned
+			}
+		}
+
+		if err := tx.Omit(clause.Associations).Save(request).Error; err != nil {
+			return err
+		}
+		for i := range events {
+			events[i].SwapRequestID = request.ID
+		}
+		return tx.Create(&events).Error
+	})
+}
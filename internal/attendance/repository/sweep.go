@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// ClaimWorkSweep stores the sweep of a work day unless it already ran.
+// Returns false when the day was already claimed, e.g. by another instance.
+func (r *AttendanceRepository) ClaimWorkSweep(ctx context.Context, sweep *models.WorkAttendanceSweep) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "work_date"}}, DoNothing: true}).
+		Create(sweep)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteWorkSweep stores the records a sweep created and its totals in one transaction
+func (r *AttendanceRepository) CompleteWorkSweep(ctx context.Context, sweep *models.WorkAttendanceSweep, records []models.WorkAttendanceRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(records) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&records).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(sweep).Error
+	})
+}
+
+// GetLatestCompletedWorkSweep gets the completed sweep of the latest work day, nil when none was completed
+func (r *AttendanceRepository) GetLatestCompletedWorkSweep(ctx context.Context) (*models.WorkAttendanceSweep, error) {
+	var sweep models.WorkAttendanceSweep
+	if err := r.db.WithContext(ctx).
+		Where("completed_at IS NOT NULL").
+		Order("work_date DESC").
+		First(&sweep).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sweep, nil
+}
+
+// ReleaseWorkSweep removes the claim of a sweep that failed, so the day can be swept again
+func (r *AttendanceRepository) ReleaseWorkSweep(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.WorkAttendanceSweep{}, "id = ?", id).Error
+}
+
+// GetWorkSweeps gets the latest work day sweeps
+func (r *AttendanceRepository) GetWorkSweeps(ctx context.Context, limit, offset int) ([]models.WorkAttendanceSweep, int64, error) {
+	var sweeps []models.WorkAttendanceSweep
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WorkAttendanceSweep{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("work_date DESC").Limit(limit).Offset(offset).Find(&sweeps).Error; err != nil {
+		return nil, 0, err
+	}
+	return sweeps, total, nil
+}
+
+// GetWorkSchedulesOnDate gets the active work schedules of all users on a date
+func (r *AttendanceRepository) GetWorkSchedulesOnDate(ctx context.Context, date time.Time) ([]models.WorkSchedule, error) {
+	var schedules []models.WorkSchedule
+	if err := r.db.WithContext(ctx).Preload("Shift").
+		Where("schedule_date = ? AND is_active = ?", date.Format("2006-01-02"), true).
+		Order("user_id ASC, start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetWorkAttendanceRecordsOnWorkDate gets the records of all users attributed to a work day.
+// Records from before work days were stored fall back to their calendar date.
+func (r *AttendanceRepository) GetWorkAttendanceRecordsOnWorkDate(ctx context.Context, date time.Time) ([]models.WorkAttendanceRecord, error) {
+	var records []models.WorkAttendanceRecord
+	if err := r.db.WithContext(ctx).
+		Where("COALESCE(work_date, DATE(recorded_at)) = ?", date.Format("2006-01-02")).
+		Order("recorded_at ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// AmendWorkAttendanceRecord updates a work attendance record without touching its relations
+func (r *AttendanceRepository) AmendWorkAttendanceRecord(ctx context.Context, record *models.WorkAttendanceRecord) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(record).Error
+}
+
+// GetUnitSupervisorIDs gets the supervisors of a unit
+func (r *AttendanceRepository) GetUnitSupervisorIDs(ctx context.Context, unit string) ([]string, error) {
+	var supervisorIDs []string
+	if err := r.db.WithContext(ctx).Model(&models.UnitSupervisor{}).
+		Where("unit = ?", unit).
+		Pluck("supervisor_id", &supervisorIDs).Error; err != nil {
+		return nil, err
+	}
+	return supervisorIDs, nil
+}
+
+// GetUnitSupervisors gets unit supervisors, optionally of one unit
+func (r *AttendanceRepository) GetUnitSupervisors(ctx context.Context, unit *string) ([]models.UnitSupervisor, error) {
+	var supervisors []models.UnitSupervisor
+	query := r.db.WithContext(ctx)
+	if unit != nil {
+		query = query.Where("unit = ?", *unit)
+	}
+	if err := query.Order("unit ASC, created_at ASC").Find(&supervisors).Error; err != nil {
+		return nil, err
+	}
+	return supervisors, nil
+}
+
+// GetUnitSupervisor gets the assignment of a supervisor to a unit, nil when there is none
+func (r *AttendanceRepository) GetUnitSupervisor(ctx context.Context, unit, supervisorID string) (*models.UnitSupervisor, error) {
+	var supervisor models.UnitSupervisor
+	if err := r.db.WithContext(ctx).
+		Where("unit = ? AND supervisor_id = ?", unit, supervisorID).
+		First(&supervisor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &supervisor, nil
+}
+
+// GetSupervisedUnits gets the units a user supervises
+func (r *AttendanceRepository) GetSupervisedUnits(ctx context.Context, supervisorID string) ([]string, error) {
+	var units []string
+	if err := r.db.WithContext(ctx).Model(&models.UnitSupervisor{}).
+		Where("supervisor_id = ?", supervisorID).
+		Pluck("unit", &units).Error; err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// inUnits returns the condition matching a user ID column to the users of units: staff by their unit,
+// dosen by their prodi
+func (r *AttendanceRepository) inUnits(column string, units []string) (string, []interface{}) {
+	return "(" + column + " IN (?) OR " + column + " IN (?))", []interface{}{
+		r.db.Model(&models.Staff{}).Select("user_id").Where("unit IN ?", units),
+		r.db.Model(&models.Dosen{}).Select("user_id").Where("prodi IN ?", units),
+	}
+}
+
+// CreateUnitSupervisor assigns a supervisor to a unit
+func (r *AttendanceRepository) CreateUnitSupervisor(ctx context.Context, supervisor *models.UnitSupervisor) error {
+	return r.db.WithContext(ctx).Create(supervisor).Error
+}
+
+// DeleteUnitSupervisor removes a supervisor from a unit, returning false when the assignment does not exist
+func (r *AttendanceRepository) DeleteUnitSupervisor(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.UnitSupervisor{}, "id = ?", id)
+	return result.RowsAffected > 0, result.Error
+}
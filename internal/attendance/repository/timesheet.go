@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetWorkAttendanceRecordsByWorkDates gets a user's check-ins and check-outs attributed to work days in a range.
+// Records from before work days were stored fall back to their calendar date.
+func (r *AttendanceRepository) GetWorkAttendanceRecordsByWorkDates(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.WorkAttendanceRecord, error) {
+	var records []models.WorkAttendanceRecord
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND COALESCE(work_date, DATE(recorded_at)) BETWEEN ? AND ?",
+			userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("recorded_at ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetTimesheet gets a user's timesheet for a month, nil when it was not computed yet
+func (r *AttendanceRepository) GetTimesheet(ctx context.Context, userID string, year, month int) (*models.WorkTimesheet, error) {
+	var timesheet models.WorkTimesheet
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+		First(&timesheet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// GetTimesheetByID gets a timesheet with its days
+func (r *AttendanceRepository) GetTimesheetByID(ctx context.Context, id string) (*models.WorkTimesheet, error) {
+	var timesheet models.WorkTimesheet
+	if err := r.db.WithContext(ctx).
+		Preload("Days", func(db *gorm.DB) *gorm.DB { return db.Order("work_date ASC") }).
+		Where("id = ?", id).
+		First(&timesheet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("timesheet not found")
+		}
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// GetTimesheets gets timesheets with filters, without their days. units, when not nil, keeps the timesheets
+// of users in those units.
+func (r *AttendanceRepository) GetTimesheets(ctx context.Context, userID *string, units []string, year, month *int, status *string, limit, offset int) ([]models.WorkTimesheet, int64, error) {
+	var timesheets []models.WorkTimesheet
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.WorkTimesheet{})
+
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if units != nil {
+		condition, args := r.inUnits("user_id", units)
+		query = query.Where(condition, args...)
+	}
+	if year != nil {
+		query = query.Where("year = ?", *year)
+	}
+	if month != nil {
+		query = query.Where("month = ?", *month)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("year DESC, month DESC, user_id ASC").Limit(limit).Offset(offset).Find(&timesheets).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return timesheets, total, nil
+}
+
+// SaveTimesheet stores a computed timesheet, replacing its previous days in one transaction
+func (r *AttendanceRepository) SaveTimesheet(ctx context.Context, timesheet *models.WorkTimesheet) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(timesheet).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("timesheet_id = ?", timesheet.ID).Delete(&models.WorkTimesheetDay{}).Error; err != nil {
+			return err
+		}
+		if len(timesheet.Days) == 0 {
+			return nil
+		}
+		for i := range timesheet.Days {
+			timesheet.Days[i].TimesheetID = timesheet.ID
+		}
+		return tx.Create(&timesheet.Days).Error
+	})
+}
+
+// UpdateTimesheet updates a timesheet without touching its days
+func (r *AttendanceRepository) UpdateTimesheet(ctx context.Context, timesheet *models.WorkTimesheet) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(timesheet).Error
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// rulesInOrder preloads the rules of a rule set in table order
+func rulesInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("category ASC, min_minutes ASC")
+}
+
+// CreateTukinRuleSet stores a rule set version with its rules
+func (r *AttendanceRepository) CreateTukinRuleSet(ctx context.Context, ruleSet *models.TukinRuleSet) error {
+	return r.db.WithContext(ctx).Create(ruleSet).Error
+}
+
+// GetTukinRuleSetByID gets a rule set version with its rules
+func (r *AttendanceRepository) GetTukinRuleSetByID(ctx context.Context, id string) (*models.TukinRuleSet, error) {
+	var ruleSet models.TukinRuleSet
+	if err := r.db.WithContext(ctx).Preload("Rules", rulesInOrder).Where("id = ?", id).First(&ruleSet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tukin rule set not found")
+		}
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// GetLatestTukinRuleSet gets the latest rule set version, nil when there is none
+func (r *AttendanceRepository) GetLatestTukinRuleSet(ctx context.Context) (*models.TukinRuleSet, error) {
+	var ruleSet models.TukinRuleSet
+	if err := r.db.WithContext(ctx).Order("version DESC").First(&ruleSet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// GetEffectiveTukinRuleSet gets the rule set version in effect on a date with its rules, nil when there is none.
+// Of the versions effective from the same date, the latest applies.
+func (r *AttendanceRepository) GetEffectiveTukinRuleSet(ctx context.Context, date time.Time) (*models.TukinRuleSet, error) {
+	var ruleSet models.TukinRuleSet
+	if err := r.db.WithContext(ctx).Preload("Rules", rulesInOrder).
+		Where("effective_from <= ?", date.Format("2006-01-02")).
+		Order("effective_from DESC, version DESC").
+		First(&ruleSet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+// GetTukinRuleSets gets all rule set versions with their rules, latest first
+func (r *AttendanceRepository) GetTukinRuleSets(ctx context.Context) ([]models.TukinRuleSet, error) {
+	var ruleSets []models.TukinRuleSet
+	if err := r.db.WithContext(ctx).Preload("Rules", rulesInOrder).Order("version DESC").Find(&ruleSets).Error; err != nil {
+		return nil, err
+	}
+	return ruleSets, nil
+}
+
+// GetLockedTimesheets gets the locked timesheets of a month with their days.
+// A nil userIDs gets the timesheets of all users.
+func (r *AttendanceRepository) GetLockedTimesheets(ctx context.Context, userIDs []string, year, month int) ([]models.WorkTimesheet, error) {
+	var timesheets []models.WorkTimesheet
+	query := r.db.WithContext(ctx).
+		Preload("Days", func(db *gorm.DB) *gorm.DB { return db.Order("work_date ASC") }).
+		Where("year = ? AND month = ? AND status = ?", year, month, models.TimesheetStatusLocked)
+
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+
+	if err := query.Order("user_id ASC").Find(&timesheets).Error; err != nil {
+		return nil, err
+	}
+	return timesheets, nil
+}
+
+// GetTukinDeductionByTimesheet gets the deduction computed for a timesheet, nil when there is none
+func (r *AttendanceRepository) GetTukinDeductionByTimesheet(ctx context.Context, timesheetID string) (*models.TukinDeduction, error) {
+	var deduction models.TukinDeduction
+	if err := r.db.WithContext(ctx).Where("timesheet_id = ?", timesheetID).First(&deduction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deduction, nil
+}
+
+// GetTukinDeductions gets the deductions of a month with their lines.
+// A nil userIDs gets the deductions of all users.
+func (r *AttendanceRepository) GetTukinDeductions(ctx context.Context, userIDs []string, year, month int) ([]models.TukinDeduction, error) {
+	var deductions []models.TukinDeduction
+	query := r.db.WithContext(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("work_date ASC, category ASC") }).
+		Where("year = ? AND month = ?", year, month)
+
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+
+	if err := query.Order("user_id ASC").Find(&deductions).Error; err != nil {
+		return nil, err
+	}
+	return deductions, nil
+}
+
+// SaveTukinDeduction stores a computed deduction, replacing its previous lines in one transaction
+func (r *AttendanceRepository) SaveTukinDeduction(ctx context.Context, deduction *models.TukinDeduction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(deduction).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("deduction_id = ?", deduction.ID).Delete(&models.TukinDeductionLine{}).Error; err != nil {
+			return err
+		}
+		if len(deduction.Lines) == 0 {
+			return nil
+		}
+		for i := range deduction.Lines {
+			deduction.Lines[i].DeductionID = deduction.ID
+		}
+		return tx.Create(&deduction.Lines).Error
+	})
+}
+
+// GetEmployeesByUserIDs gets the staff and dosen profiles of the given users
+func (r *AttendanceRepository) GetEmployeesByUserIDs(ctx context.Context, userIDs []string) ([]models.Staff, []models.Dosen, error) {
+	var staff []models.Staff
+	var dosen []models.Dosen
+	if len(userIDs) == 0 {
+		return staff, dosen, nil
+	}
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&staff).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&dosen).Error; err != nil {
+		return nil, nil, err
+	}
+	return staff, dosen, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateWorkPolicy creates a new work attendance policy
+func (r *AttendanceRepository) CreateWorkPolicy(ctx context.Context, policy *models.WorkAttendancePolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// GetWorkPolicyByID gets a work attendance policy version by ID
+func (r *AttendanceRepository) GetWorkPolicyByID(ctx context.Context, id string) (*models.WorkAttendancePolicy, error) {
+	var policy models.WorkAttendancePolicy
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("work attendance policy not found")
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetWorkPolicies gets work attendance policies with filters, only active versions unless includeHistory is set
+func (r *AttendanceRepository) GetWorkPolicies(ctx context.Context, scope, userID, shiftID, unit *string, includeHistory bool) ([]models.WorkAttendancePolicy, error) {
+	var policies []models.WorkAttendancePolicy
+	query := r.db.WithContext(ctx).Model(&models.WorkAttendancePolicy{})
+
+	if scope != nil {
+		query = query.Where("scope = ?", *scope)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if shiftID != nil {
+		query = query.Where("shift_id = ?", *shiftID)
+	}
+	if unit != nil {
+		query = query.Where("unit = ?", *unit)
+	}
+	if !includeHistory {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := query.Order("scope ASC, version DESC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetActiveWorkPolicy gets the active policy for a scope and target.
+// targetID is the user ID, shift pattern ID or unit, ignored for the global scope. Returns nil when none is set.
+func (r *AttendanceRepository) GetActiveWorkPolicy(ctx context.Context, scope models.PolicyScope, targetID string) (*models.WorkAttendancePolicy, error) {
+	var policy models.WorkAttendancePolicy
+	query := r.db.WithContext(ctx).Where("scope = ? AND is_active = ?", scope, true)
+
+	switch scope {
+	case models.PolicyScopeUser:
+		query = query.Where("user_id = ?", targetID)
+	case models.PolicyScopeShift:
+		query = query.Where("shift_id = ?", targetID)
+	case models.PolicyScopeUnit:
+		query = query.Where("unit = ?", targetID)
+	}
+
+	if err := query.Order("version DESC").First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// CreateWorkPolicyVersion stores a new version of a policy and deactivates the previous one in one transaction
+func (r *AttendanceRepository) CreateWorkPolicyVersion(ctx context.Context, previous, next *models.WorkAttendancePolicy) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(previous).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// DeactivateWorkPolicy deactivates a policy, keeping it for the records that reference it
+func (r *AttendanceRepository) DeactivateWorkPolicy(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&models.WorkAttendancePolicy{}).Where("id = ?", id).Update("is_active", false).Error
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"unsri-backend/internal/attendance/repository"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/geo"
+)
+
+// Anomaly policy modes
+const (
+	AnomalyPolicyOff    = "off"    // Located events are not evaluated
+	AnomalyPolicyFlag   = "flag"   // Anomalies are stored for staff review
+	AnomalyPolicyStrict = "strict" // Anomalies are stored and the action is refused
+)
+
+// AnomalyPolicy holds the thresholds of the location anomaly engine
+type AnomalyPolicy struct {
+	Mode              string
+	MaxSpeedKmh       float64       // Faster travel between two located events is implausible
+	MinTravelMeters   float64       // Shorter moves are treated as GPS noise
+	MaxAccuracyMeters float64       // Worse reported accuracy is not trusted
+	RepeatThreshold   int           // Other events at the exact same coordinates before flagging
+	Lookback          time.Duration // How far around an event the user's other events are compared
+}
+
+// locationEvent builds the event being evaluated, nil when it is not located
+func locationEvent(source models.LocationEventSource, lat, lng, accuracy *float64, at time.Time) *repository.LocationEvent {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	return &repository.LocationEvent{
+		Source:         source,
+		Latitude:       *lat,
+		Longitude:      *lng,
+		AccuracyMeters: accuracy,
+		OccurredAt:     at,
+	}
+}
+
+// detectLocationAnomalies evaluates a located event against the user's other events
+func detectLocationAnomalies(policy AnomalyPolicy, userID string, event repository.LocationEvent, others []repository.LocationEvent) []models.LocationAnomaly {
+	newAnomaly := func(anomalyType models.LocationAnomalyType, message string) models.LocationAnomaly {
+		return models.LocationAnomaly{
+			UserID:         userID,
+			Type:           anomalyType,
+			Source:         event.Source,
+			Latitude:       event.Latitude,
+			Longitude:      event.Longitude,
+			AccuracyMeters: event.AccuracyMeters,
+			OccurredAt:     event.OccurredAt,
+			Message:        message,
+		}
+	}
+
+	var anomalies []models.LocationAnomaly
+	if event.AccuracyMeters != nil && policy.MaxAccuracyMeters > 0 && *event.AccuracyMeters > policy.MaxAccuracyMeters {
+		anomalies = append(anomalies, newAnomaly(models.AnomalyPoorAccuracy,
+			fmt.Sprintf("GPS accuracy of %.0f meters is above the %.0f meters trusted", *event.AccuracyMeters, policy.MaxAccuracyMeters)))
+	}
+
+	// Compare with the closest events before and after, offline scans may arrive after later events
+	var previous, next *repository.LocationEvent
+	repeats := 0
+	for i := range others {
+		other := &others[i]
+		if other.Latitude == event.Latitude && other.Longitude == event.Longitude {
+			repeats++
+		}
+		if !other.OccurredAt.After(event.OccurredAt) {
+			if previous == nil || other.OccurredAt.After(previous.OccurredAt) {
+				previous = other
+			}
+		} else if next == nil || other.OccurredAt.Before(next.OccurredAt) {
+			next = other
+		}
+	}
+
+	if policy.RepeatThreshold > 0 && repeats >= policy.RepeatThreshold {
+		anomalies = append(anomalies, newAnomaly(models.AnomalyRepeatedCoordinates,
+			fmt.Sprintf("exact same coordinates as %d other events", repeats)))
+	}
+
+	for _, other := range []*repository.LocationEvent{previous, next} {
+		if other == nil {
+			continue
+		}
+		distance, speed, implausible := travelSpeed(policy, event, *other)
+		if !implausible {
+			continue
+		}
+		anomaly := newAnomaly(models.AnomalyImpossibleTravel,
+			fmt.Sprintf("%.1f km from the %s event at %s", distance/1000, other.Source, other.OccurredAt.Format(time.RFC3339)))
+		anomaly.PreviousSource = &other.Source
+		anomaly.PreviousSourceID = &other.SourceID
+		anomaly.DistanceMeters = &distance
+		if !math.IsInf(speed, 1) {
+			anomaly.SpeedKmh = &speed
+		}
+		anomalies = append(anomalies, anomaly)
+		break
+	}
+
+	return anomalies
+}
+
+// travelSpeed returns the distance and speed between two located events,
+// and whether the speed is above the policy maximum
+func travelSpeed(policy AnomalyPolicy, a, b repository.LocationEvent) (distance, speedKmh float64, implausible bool) {
+	distance = geo.Distance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	if distance < policy.MinTravelMeters {
+		return distance, 0, false
+	}
+
+	hours := math.Abs(a.OccurredAt.Sub(b.OccurredAt).Hours())
+	if hours == 0 {
+		return distance, math.Inf(1), true
+	}
+	speedKmh = distance / 1000 / hours
+	return distance, speedKmh, speedKmh > policy.MaxSpeedKmh
+}
+
+// detectAnomalies evaluates a located event against the user's events around it.
+// Only a strict policy fails the action when the events cannot be loaded.
+func (s *AttendanceService) detectAnomalies(ctx context.Context, userID string, event *repository.LocationEvent) ([]models.LocationAnomaly, error) {
+	policy := s.scan.Anomaly
+	if event == nil || policy.Mode == AnomalyPolicyOff || policy.Mode == "" {
+		return nil, nil
+	}
+
+	others, err := s.repo.GetLocationEvents(ctx, userID, event.OccurredAt.Add(-policy.Lookback), event.OccurredAt.Add(policy.Lookback))
+	if err != nil {
+		if policy.Mode == AnomalyPolicyStrict {
+			return nil, apperrors.NewInternalError("failed to get recent locations", err)
+		}
+		return nil, nil
+	}
+
+	return detectLocationAnomalies(policy, userID, *event, others), nil
+}
+
+// anomaliesBlock reports whether the anomalies refuse the action under the policy
+func (s *AttendanceService) anomaliesBlock(anomalies []models.LocationAnomaly) bool {
+	return s.scan.Anomaly.Mode == AnomalyPolicyStrict && len(anomalies) > 0
+}
+
+// blockAnomalies stores anomalies of a refused action and returns the error for the user
+func (s *AttendanceService) blockAnomalies(ctx context.Context, anomalies []models.LocationAnomaly) error {
+	s.saveAnomalies(ctx, anomalies, nil)
+	return apperrors.NewForbiddenError(anomalyMessage(anomalies))
+}
+
+// anomalyMessage explains a refused located action to the user
+func anomalyMessage(anomalies []models.LocationAnomaly) string {
+	return "your location could not be trusted: " + anomalies[0].Message
+}
+
+// saveAnomalies stores the anomalies of an event, linked to its record when it was recorded.
+// Best effort, the action is not failed over its flags.
+func (s *AttendanceService) saveAnomalies(ctx context.Context, anomalies []models.LocationAnomaly, sourceID *string) {
+	for i := range anomalies {
+		anomalies[i].SourceID = sourceID
+		anomalies[i].Blocked = sourceID == nil
+	}
+	_ = s.repo.CreateLocationAnomalies(ctx, anomalies)
+}
+
+// GetLocationAnomaliesRequest represents get location anomalies request
+type GetLocationAnomaliesRequest struct {
+	UserID     *string `form:"user_id"`
+	Type       *string `form:"type"`
+	Source     *string `form:"source"`
+	Unreviewed bool    `form:"unreviewed"`
+	StartDate  string  `form:"start_date"` // YYYY-MM-DD
+	EndDate    string  `form:"end_date"`   // YYYY-MM-DD
+	Page       int     `form:"page,default=1"`
+	PerPage    int     `form:"per_page,default=20"`
+}
+
+// GetLocationAnomalies gets flagged location anomalies for staff review
+func (s *AttendanceService) GetLocationAnomalies(ctx context.Context, req GetLocationAnomaliesRequest) ([]models.LocationAnomaly, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		date, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, 0, apperrors.NewBadRequestError("invalid start_date format, use YYYY-MM-DD")
+		}
+		startDate = &date
+	}
+	if req.EndDate != "" {
+		date, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, 0, apperrors.NewBadRequestError("invalid end_date format, use YYYY-MM-DD")
+		}
+		endDate = &date
+	}
+
+	anomalies, total, err := s.repo.GetLocationAnomalies(ctx, req.UserID, req.Type, req.Source, req.Unreviewed, startDate, endDate, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get location anomalies", err)
+	}
+
+	return anomalies, total, nil
+}
+
+// ReviewLocationAnomalyRequest represents review location anomaly request
+type ReviewLocationAnomalyRequest struct {
+	Notes string `json:"notes" binding:"required"`
+}
+
+// ReviewLocationAnomaly marks a location anomaly as reviewed
+func (s *AttendanceService) ReviewLocationAnomaly(ctx context.Context, reviewerID string, id string, req ReviewLocationAnomalyRequest) (*models.LocationAnomaly, error) {
+	anomaly, err := s.repo.GetLocationAnomalyByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("location anomaly", id)
+	}
+
+	if anomaly.ReviewedAt != nil {
+		return nil, apperrors.NewBadRequestError("location anomaly is already reviewed")
+	}
+
+	now := time.Now()
+	anomaly.ReviewedBy = &reviewerID
+	anomaly.ReviewedAt = &now
+	anomaly.ReviewNotes = req.Notes
+
+	if err := s.repo.UpdateLocationAnomaly(ctx, anomaly); err != nil {
+		return nil, apperrors.NewInternalError("failed to review location anomaly", err)
+	}
+
+	return anomaly, nil
+}
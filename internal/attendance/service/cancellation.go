@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"unsri-backend/internal/shared/conflict"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// MakeUpMeetingRequest represents the make-up meeting (kelas pengganti) of a cancelled meeting
+type MakeUpMeetingRequest struct {
+	Date              string `json:"date" binding:"required"`       // YYYY-MM-DD
+	StartTime         string `json:"start_time" binding:"required"` // HH:MM
+	EndTime           string `json:"end_time" binding:"required"`   // HH:MM
+	Room              string `json:"room"`                          // Defaults to the cancelled meeting's room
+	MeetingMode       string `json:"meeting_mode,omitempty" binding:"omitempty,oneof=offline online hybrid"`
+	OverrideConflicts bool   `json:"override_conflicts,omitempty"` // Staff only: schedule despite room or lecturer conflicts
+}
+
+// CancelScheduleRequest represents cancel schedule request
+type CancelScheduleRequest struct {
+	Reason      string                `json:"reason" binding:"required"`
+	Replacement *MakeUpMeetingRequest `json:"replacement,omitempty"`
+}
+
+// CancelScheduleResponse represents the outcome of cancelling a meeting
+type CancelScheduleResponse struct {
+	Cancelled   *models.Schedule `json:"cancelled"`
+	Replacement *models.Schedule `json:"replacement,omitempty"`
+	Notified    int              `json:"notified"` // Enrolled students notified
+}
+
+// makeUpSchedule builds the make-up meeting of a cancelled meeting, in the same class and with the same dosen
+func makeUpSchedule(original *models.Schedule, req MakeUpMeetingRequest) (*models.Schedule, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid date format, use YYYY-MM-DD")
+	}
+	startTime, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid start_time format, use HH:MM")
+	}
+	endTime, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid end_time format, use HH:MM")
+	}
+	if !endTime.After(startTime) {
+		return nil, apperrors.NewValidationError("end_time must be after start_time")
+	}
+
+	replacement := &models.Schedule{
+		CourseID:           original.CourseID,
+		ClassID:            original.ClassID,
+		CourseCode:         original.CourseCode,
+		CourseName:         original.CourseName,
+		DosenID:            original.DosenID,
+		Room:               original.Room,
+		DayOfWeek:          int(date.Weekday()),
+		StartTime:          time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location()),
+		EndTime:            time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location()),
+		Date:               date,
+		MeetingMode:        original.MeetingMode,
+		ReplacesScheduleID: &original.ID,
+		IsActive:           true,
+	}
+	if req.Room != "" {
+		replacement.Room = req.Room
+	}
+	if req.MeetingMode != "" {
+		replacement.MeetingMode = models.MeetingMode(req.MeetingMode)
+	}
+
+	return replacement, nil
+}
+
+// getManagedSchedule gets a meeting the user may cancel or reschedule: its dosen or staff
+func (s *AttendanceService) getManagedSchedule(ctx context.Context, userID string, role string, scheduleID string) (*models.Schedule, *models.Class, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, nil, apperrors.NewInternalError("failed to get class", err)
+	}
+
+	if role != string(models.RoleStaff) && schedule.DosenID != userID && !isScheduleReviewer(schedule, class, userID) {
+		return nil, nil, apperrors.NewForbiddenError("not authorized to change this schedule")
+	}
+
+	return schedule, class, nil
+}
+
+// CancelSchedule cancels a meeting with a reason and, when given, creates its make-up meeting.
+// Enrolled students of the class are notified.
+func (s *AttendanceService) CancelSchedule(ctx context.Context, userID string, role string, scheduleID string, req CancelScheduleRequest) (*CancelScheduleResponse, error) {
+	schedule, class, err := s.getManagedSchedule(ctx, userID, role, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.CancelledAt != nil {
+		return nil, apperrors.NewConflictError("this meeting is already cancelled")
+	}
+	if schedule.AttendanceClosedAt != nil {
+		return nil, apperrors.NewBadRequestError("attendance for this meeting is already closed, it cannot be cancelled")
+	}
+
+	var replacement *models.Schedule
+	if req.Replacement != nil {
+		replacement, err = makeUpSchedule(schedule, *req.Replacement)
+		if err != nil {
+			return nil, err
+		}
+		// The cancelled meeting frees its slot for its own make-up meeting
+		slot := conflict.MeetingSlot(replacement, class)
+		slot.Excluded = append(slot.Excluded, schedule.ID)
+		if err := conflict.Check(ctx, s.repo, slot, req.Replacement.OverrideConflicts, role); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	schedule.CancelledAt = &now
+	schedule.CancelledBy = &userID
+	schedule.CancellationReason = req.Reason
+	schedule.IsActive = false
+
+	if err := s.repo.CancelSchedule(ctx, schedule, replacement); err != nil {
+		return nil, apperrors.NewInternalError("failed to cancel schedule", err)
+	}
+
+	return &CancelScheduleResponse{
+		Cancelled:   schedule,
+		Replacement: replacement,
+		Notified:    s.notifyScheduleChange(ctx, schedule, class, replacement),
+	}, nil
+}
+
+// ScheduleMakeUp creates the make-up meeting of a meeting cancelled without one
+func (s *AttendanceService) ScheduleMakeUp(ctx context.Context, userID string, role string, scheduleID string, req MakeUpMeetingRequest) (*CancelScheduleResponse, error) {
+	schedule, class, err := s.getManagedSchedule(ctx, userID, role, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.CancelledAt == nil {
+		return nil, apperrors.NewBadRequestError("only a cancelled meeting can have a make-up meeting")
+	}
+	if schedule.ReplacementScheduleID != nil {
+		return nil, apperrors.NewConflictError("this meeting already has a make-up meeting")
+	}
+
+	replacement, err := makeUpSchedule(schedule, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := conflict.Check(ctx, s.repo, conflict.MeetingSlot(replacement, class), req.OverrideConflicts, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CancelSchedule(ctx, schedule, replacement); err != nil {
+		return nil, apperrors.NewInternalError("failed to create make-up meeting", err)
+	}
+
+	return &CancelScheduleResponse{
+		Cancelled:   schedule,
+		Replacement: replacement,
+		Notified:    s.notifyScheduleChange(ctx, schedule, class, replacement),
+	}, nil
+}
+
+// notifyScheduleChange notifies the approved enrollees of a cancellation and its make-up meeting.
+// Best effort, returns the number of students notified.
+func (s *AttendanceService) notifyScheduleChange(ctx context.Context, schedule *models.Schedule, class *models.Class, replacement *models.Schedule) int {
+	if class == nil {
+		// Legacy schedule without a class, there is no roster to notify
+		return 0
+	}
+
+	enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, class.ID)
+	if err != nil {
+		return 0
+	}
+
+	title, message := scheduleChangeMessage(schedule, replacement)
+	payload := map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"class_id":    class.ID,
+	}
+	if replacement != nil {
+		payload["replacement_schedule_id"] = replacement.ID
+	}
+	data, _ := json.Marshal(payload)
+
+	// With no records, every approved enrollee is returned once
+	notified := 0
+	for _, studentID := range missingEnrollees(enrollments, nil) {
+		if err := s.notificationRepo.CreateNotification(ctx, &models.Notification{
+			UserID:  studentID,
+			Title:   title,
+			Message: message,
+			Type:    models.NotificationTypeInfo,
+			Data:    string(data),
+		}); err != nil {
+			continue
+		}
+		notified++
+	}
+
+	return notified
+}
+
+// scheduleChangeMessage describes a cancelled meeting and its make-up meeting to students
+func scheduleChangeMessage(schedule *models.Schedule, replacement *models.Schedule) (string, string) {
+	course := schedule.CourseName
+	if course == "" {
+		course = schedule.CourseCode
+	}
+	meeting := fmt.Sprintf("%s on %s at %s", course, schedule.Date.Format("02 Jan 2006"), schedule.StartTime.Format("15:04"))
+
+	if replacement == nil {
+		return "Class cancelled", fmt.Sprintf("%s is cancelled: %s", meeting, schedule.CancellationReason)
+	}
+
+	room := replacement.Room
+	if replacement.MeetingMode == models.MeetingModeOnline || room == "" {
+		room = string(replacement.MeetingMode)
+	}
+	return "Class rescheduled", fmt.Sprintf("%s is cancelled: %s. Make-up class on %s, %s-%s in %s.",
+		meeting, schedule.CancellationReason, replacement.Date.Format("02 Jan 2006"),
+		replacement.StartTime.Format("15:04"), replacement.EndTime.Format("15:04"), room)
+}
+
+// ClassMeetingCountResponse represents the meeting-count compliance of a class
+type ClassMeetingCountResponse struct {
+	ClassID     string `json:"class_id"`
+	Total       int64  `json:"total"`        // All meetings, including cancelled and make-up ones
+	Held        int64  `json:"held"`         // Meetings whose attendance was closed
+	Cancelled   int64  `json:"cancelled"`    // Cancelled meetings
+	MakeUp      int64  `json:"make_up"`      // Make-up meetings scheduled
+	NotReplaced int64  `json:"not_replaced"` // Cancelled meetings still without a make-up meeting
+}
+
+// GetClassMeetingCount gets the held, cancelled and make-up meetings of a class, for its dosen and staff
+func (s *AttendanceService) GetClassMeetingCount(ctx context.Context, userID string, role string, classID string) (*ClassMeetingCountResponse, error) {
+	class, err := s.courseRepo.GetClassByID(ctx, classID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("class", classID)
+	}
+
+	if role != string(models.RoleStaff) && !isScheduleReviewer(&models.Schedule{}, class, userID) {
+		return nil, apperrors.NewForbiddenError("you can only view meeting counts of classes you teach")
+	}
+
+	counts, err := s.repo.GetClassMeetingCounts(ctx, classID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to count class meetings", err)
+	}
+
+	return &ClassMeetingCountResponse{
+		ClassID:     classID,
+		Total:       counts.Total,
+		Held:        counts.Held,
+		Cancelled:   counts.Cancelled,
+		MakeUp:      counts.MakeUp,
+		NotReplaced: counts.NotReplaced,
+	}, nil
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// SubmitCorrectionRequest represents a student's attendance correction request
+type SubmitCorrectionRequest struct {
+	ScheduleID       *string `json:"schedule_id,omitempty"`
+	SessionID        *string `json:"session_id,omitempty"`
+	RequestedStatus  string  `json:"requested_status" binding:"required,oneof=hadir terlambat izin sakit"`
+	Reason           string  `json:"reason" binding:"required"`
+	AttachmentFileID *string `json:"attachment_file_id,omitempty"` // ID of a file uploaded to the file-storage service
+}
+
+// SubmitCorrection submits an attendance correction request for a schedule or session
+func (s *AttendanceService) SubmitCorrection(ctx context.Context, userID string, req SubmitCorrectionRequest) (*models.AttendanceCorrectionRequest, error) {
+	scheduleID, err := s.resolveCorrectionSchedule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+	}
+	if class != nil {
+		enrollment, err := s.courseRepo.GetEnrollmentByStudentAndClass(ctx, userID, class.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to check enrollment", err)
+		}
+		if enrollment == nil || enrollment.Status != "APPROVED" {
+			return nil, apperrors.NewForbiddenError("you are not enrolled in this class")
+		}
+	}
+
+	if req.AttachmentFileID != nil {
+		file, err := s.fileRepo.GetFileByID(ctx, *req.AttachmentFileID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("file", *req.AttachmentFileID)
+		}
+		if file.UserID != userID {
+			return nil, apperrors.NewForbiddenError("attachment must be a file you uploaded")
+		}
+	}
+
+	pending, err := s.repo.GetPendingCorrectionRequest(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check correction requests", err)
+	}
+	if pending != nil {
+		return nil, apperrors.NewConflictError("a correction request for this schedule is already pending")
+	}
+
+	attendance, err := s.repo.GetAttendanceByUserAndSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get attendance", err)
+	}
+	if attendance != nil && attendance.Status == models.AttendanceStatus(req.RequestedStatus) {
+		return nil, apperrors.NewConflictError("attendance is already recorded with this status")
+	}
+
+	request := &models.AttendanceCorrectionRequest{
+		UserID:           userID,
+		ScheduleID:       scheduleID,
+		SessionID:        req.SessionID,
+		RequestedStatus:  models.AttendanceStatus(req.RequestedStatus),
+		Reason:           req.Reason,
+		AttachmentFileID: req.AttachmentFileID,
+		Status:           models.CorrectionStatusPending,
+	}
+	if attendance != nil {
+		request.AttendanceID = &attendance.ID
+	}
+
+	if err := s.repo.CreateCorrectionRequest(ctx, request); err != nil {
+		return nil, apperrors.NewInternalError("failed to create correction request", err)
+	}
+
+	return request, nil
+}
+
+// resolveCorrectionSchedule returns the schedule a correction request refers to
+func (s *AttendanceService) resolveCorrectionSchedule(ctx context.Context, req SubmitCorrectionRequest) (string, error) {
+	if req.SessionID == nil {
+		if req.ScheduleID == nil {
+			return "", apperrors.NewValidationError("schedule_id or session_id is required")
+		}
+		return *req.ScheduleID, nil
+	}
+
+	session, err := s.repo.GetSessionByID(ctx, *req.SessionID)
+	if err != nil {
+		return "", apperrors.NewNotFoundError("session", *req.SessionID)
+	}
+	if session.ScheduleID == nil {
+		return "", apperrors.NewValidationError("session is not linked to a class schedule")
+	}
+	if req.ScheduleID != nil && *req.ScheduleID != *session.ScheduleID {
+		return "", apperrors.NewValidationError("session does not belong to the schedule")
+	}
+
+	return *session.ScheduleID, nil
+}
+
+// GetCorrectionsRequest represents get correction requests request
+type GetCorrectionsRequest struct {
+	ScheduleID *string `form:"schedule_id"`
+	Status     *string `form:"status"`
+	Page       int     `form:"page,default=1"`
+	PerPage    int     `form:"per_page,default=20"`
+}
+
+// GetCorrections gets correction requests: students see their own, dosen those of schedules they teach
+func (s *AttendanceService) GetCorrections(ctx context.Context, userID string, role string, req GetCorrectionsRequest) ([]models.AttendanceCorrectionRequest, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	var studentID, reviewerID *string
+	switch role {
+	case string(models.RoleMahasiswa):
+		studentID = &userID
+	case string(models.RoleDosen):
+		reviewerID = &userID
+	}
+
+	requests, total, err := s.repo.GetCorrectionRequests(ctx, studentID, reviewerID, req.ScheduleID, req.Status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get correction requests", err)
+	}
+
+	return requests, total, nil
+}
+
+// GetCorrectionByID gets a correction request, visible to its student, the reviewing dosen and staff
+func (s *AttendanceService) GetCorrectionByID(ctx context.Context, userID string, role string, id string) (*models.AttendanceCorrectionRequest, error) {
+	request, err := s.repo.GetCorrectionRequestByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("correction request", id)
+	}
+
+	if role != string(models.RoleStaff) && request.UserID != userID {
+		canReview, err := s.canReviewSchedule(ctx, userID, &request.Schedule)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+		}
+		if !canReview {
+			return nil, apperrors.NewForbiddenError("not authorized to view this correction request")
+		}
+	}
+
+	return request, nil
+}
+
+// ReviewCorrectionRequest represents approve or reject correction request
+type ReviewCorrectionRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// ApproveCorrection approves a correction request and applies it to the attendance record
+func (s *AttendanceService) ApproveCorrection(ctx context.Context, reviewerID string, id string, req ReviewCorrectionRequest) (*models.AttendanceCorrectionRequest, error) {
+	request, err := s.getReviewableCorrection(ctx, reviewerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look the record up again, it may have been created since the request was submitted
+	attendance, err := s.repo.GetAttendanceByUserAndSchedule(ctx, request.UserID, request.ScheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get attendance", err)
+	}
+
+	changeLog := &models.AttendanceChangeLog{
+		ChangedBy:           reviewerID,
+		Source:              models.ChangeSourceCorrection,
+		NewStatus:           request.RequestedStatus,
+		Notes:               request.Reason,
+		CorrectionRequestID: &request.ID,
+	}
+
+	if attendance == nil {
+		attendance = &models.Attendance{
+			UserID:     request.UserID,
+			SessionID:  request.SessionID,
+			ScheduleID: &request.ScheduleID,
+			Type:       models.AttendanceTypeKelas,
+			Status:     request.RequestedStatus,
+			Date:       request.Schedule.Date,
+			Notes:      "Recorded by correction request: " + request.Reason,
+			CreatedBy:  &reviewerID,
+		}
+	} else {
+		oldStatus := attendance.Status
+		changeLog.OldStatus = &oldStatus
+		attendance.Status = request.RequestedStatus
+	}
+
+	now := time.Now()
+	request.Status = models.CorrectionStatusApproved
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	if err := s.repo.ApplyCorrection(ctx, request, attendance, changeLog); err != nil {
+		return nil, apperrors.NewInternalError("failed to apply correction", err)
+	}
+
+	return request, nil
+}
+
+// RejectCorrection rejects a correction request, a reason for the student is required
+func (s *AttendanceService) RejectCorrection(ctx context.Context, reviewerID string, id string, req ReviewCorrectionRequest) (*models.AttendanceCorrectionRequest, error) {
+	if req.Notes == "" {
+		return nil, apperrors.NewValidationError("notes are required when rejecting a correction request")
+	}
+
+	request, err := s.getReviewableCorrection(ctx, reviewerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = models.CorrectionStatusRejected
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	if err := s.repo.UpdateCorrectionRequest(ctx, request); err != nil {
+		return nil, apperrors.NewInternalError("failed to reject correction request", err)
+	}
+
+	return request, nil
+}
+
+// getReviewableCorrection gets a pending correction request the reviewer may decide on
+func (s *AttendanceService) getReviewableCorrection(ctx context.Context, reviewerID string, id string) (*models.AttendanceCorrectionRequest, error) {
+	request, err := s.repo.GetCorrectionRequestByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("correction request", id)
+	}
+
+	if request.Status != models.CorrectionStatusPending {
+		return nil, apperrors.NewConflictError("correction request has already been reviewed")
+	}
+
+	canReview, err := s.canReviewSchedule(ctx, reviewerID, &request.Schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+	}
+	if !canReview {
+		return nil, apperrors.NewForbiddenError("only the class dosen or assistant dosen can review this request")
+	}
+
+	return request, nil
+}
+
+// canReviewSchedule checks whether the user is the dosen or assistant dosen of the schedule's class
+func (s *AttendanceService) canReviewSchedule(ctx context.Context, userID string, schedule *models.Schedule) (bool, error) {
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return false, err
+	}
+
+	return isScheduleReviewer(schedule, class, userID), nil
+}
+
+// isScheduleReviewer reports whether the user teaches the class, falling back
+// to the schedule's dosen for meetings not linked to a class
+func isScheduleReviewer(schedule *models.Schedule, class *models.Class, userID string) bool {
+	if class == nil {
+		return schedule.DosenID == userID
+	}
+
+	return class.DosenID == userID || (class.AssistantDosenID != nil && *class.AssistantDosenID == userID)
+}
+
+// GetAttendanceByID gets an attendance record with its change history,
+// visible to its owner, the schedule's dosen and staff
+func (s *AttendanceService) GetAttendanceByID(ctx context.Context, userID string, role string, id string) (*models.Attendance, error) {
+	attendance, err := s.repo.GetAttendanceWithHistory(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("attendance", id)
+	}
+
+	if role == string(models.RoleStaff) || attendance.UserID == userID {
+		return attendance, nil
+	}
+
+	if attendance.ScheduleID != nil {
+		schedule, err := s.repo.GetScheduleByID(ctx, *attendance.ScheduleID)
+		if err == nil {
+			canReview, err := s.canReviewSchedule(ctx, userID, schedule)
+			if err != nil {
+				return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+			}
+			if canReview {
+				return attendance, nil
+			}
+		}
+	}
+
+	return nil, apperrors.NewForbiddenError("not authorized to view this attendance record")
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Device binding modes
+const (
+	DeviceBindingOff     = "off"     // Devices are recorded but not bound
+	DeviceBindingMonitor = "monitor" // Devices are bound on first use, other devices are only flagged
+	DeviceBindingEnforce = "enforce" // Attendance is refused from any device but the bound one
+)
+
+// deviceCheck decides whether an account may record attendance from a device.
+// binding is the account's bound device and owner the binding of the device, nil when missing.
+// bind reports whether the device should be bound to the account on its first use.
+func deviceCheck(mode, userID, deviceID string, binding, owner *models.DeviceBinding) (bind bool, reason models.ScanRejectionReason) {
+	if mode == DeviceBindingOff {
+		return false, ""
+	}
+
+	enforce := mode == DeviceBindingEnforce
+	if deviceID == "" {
+		if enforce {
+			return false, models.RejectionDeviceRequired
+		}
+		return false, ""
+	}
+
+	if binding == nil {
+		if owner != nil && owner.UserID != userID {
+			if enforce {
+				return false, models.RejectionDeviceNotBound
+			}
+			return false, ""
+		}
+		return true, ""
+	}
+
+	if binding.DeviceID != deviceID && enforce {
+		return false, models.RejectionDeviceNotBound
+	}
+	return false, ""
+}
+
+// deviceRejectionMessage explains a refused device to the user
+func deviceRejectionMessage(reason models.ScanRejectionReason) string {
+	if reason == models.RejectionDeviceRequired {
+		return "a device identifier is required to record attendance"
+	}
+	return "attendance can only be recorded from your bound device, request a device re-binding to change it"
+}
+
+// verifyDevice checks the device an attendance is recorded from against the binding policy,
+// binding the device to the account on its first use
+func (s *AttendanceService) verifyDevice(ctx context.Context, userID, deviceID string) (models.ScanRejectionReason, error) {
+	if s.scan.DeviceBinding == DeviceBindingOff {
+		return "", nil
+	}
+
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return "", apperrors.NewInternalError("failed to get device binding", err)
+	}
+
+	var owner *models.DeviceBinding
+	if binding == nil && deviceID != "" {
+		if owner, err = s.repo.GetActiveDeviceBindingByDeviceID(ctx, deviceID); err != nil {
+			return "", apperrors.NewInternalError("failed to get device binding", err)
+		}
+	}
+
+	bind, reason := deviceCheck(s.scan.DeviceBinding, userID, deviceID, binding, owner)
+	if bind {
+		if err := s.repo.CreateDeviceBinding(ctx, s.newDeviceBinding(ctx, userID, deviceID, "")); err != nil {
+			return "", apperrors.NewInternalError("failed to bind device", err)
+		}
+	}
+	return reason, nil
+}
+
+// newDeviceBinding builds a binding linked to the push token registered from the same device
+func (s *AttendanceService) newDeviceBinding(ctx context.Context, userID, deviceID, platform string) *models.DeviceBinding {
+	binding := &models.DeviceBinding{
+		UserID:   userID,
+		DeviceID: deviceID,
+		Platform: platform,
+		IsActive: true,
+		BoundAt:  time.Now(),
+	}
+
+	// Ignore error, the push token link is informational
+	tokens, _ := s.notificationRepo.GetDeviceTokensByUserID(ctx, userID)
+	for _, token := range tokens {
+		if token.DeviceID == deviceID {
+			binding.DeviceTokenID = &token.ID
+			if binding.Platform == "" {
+				binding.Platform = token.Platform
+			}
+			break
+		}
+	}
+	return binding
+}
+
+// flagClassDevice flags a class meeting when the device scanned for more than one account.
+// Best effort, flagging never fails the scan.
+func (s *AttendanceService) flagClassDevice(ctx context.Context, scheduleID string, classID *string, date time.Time, deviceID *string) {
+	if deviceID == nil || *deviceID == "" {
+		return
+	}
+
+	userIDs, err := s.repo.GetScheduleDeviceUserIDs(ctx, scheduleID, *deviceID)
+	if err != nil || len(userIDs) < 2 {
+		return
+	}
+
+	_ = s.repo.UpsertSharedDeviceFlag(ctx, &models.SharedDeviceFlag{
+		Type:         models.AttendanceTypeKelas,
+		ScopeKey:     "schedule:" + scheduleID,
+		DeviceID:     *deviceID,
+		ScheduleID:   &scheduleID,
+		ClassID:      classID,
+		Date:         date,
+		UserIDs:      strings.Join(userIDs, ","),
+		AccountCount: len(userIDs),
+	})
+}
+
+// flagWorkDevice flags a day of work attendance when the device recorded it for more than one account.
+// Best effort, flagging never fails the check-in.
+func (s *AttendanceService) flagWorkDevice(ctx context.Context, date time.Time, deviceID *string) {
+	if deviceID == nil || *deviceID == "" {
+		return
+	}
+
+	userIDs, err := s.repo.GetWorkDeviceUserIDs(ctx, *deviceID, date)
+	if err != nil || len(userIDs) < 2 {
+		return
+	}
+
+	_ = s.repo.UpsertSharedDeviceFlag(ctx, &models.SharedDeviceFlag{
+		Type:         models.AttendanceTypeKampus,
+		ScopeKey:     "work:" + date.Format("2006-01-02"),
+		DeviceID:     *deviceID,
+		Date:         date,
+		UserIDs:      strings.Join(userIDs, ","),
+		AccountCount: len(userIDs),
+	})
+}
+
+// optionalDeviceID returns nil for a missing device identifier
+func optionalDeviceID(deviceID string) *string {
+	if deviceID == "" {
+		return nil
+	}
+	return &deviceID
+}
+
+// DeviceStatusResponse represents the device binding of the current account
+type DeviceStatusResponse struct {
+	Mode           string                      `json:"mode"`
+	Binding        *models.DeviceBinding       `json:"binding"`
+	PendingRequest *models.DeviceRebindRequest `json:"pending_request,omitempty"`
+}
+
+// GetDeviceStatus gets the bound device and pending re-binding request of an account
+func (s *AttendanceService) GetDeviceStatus(ctx context.Context, userID string) (*DeviceStatusResponse, error) {
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+
+	pending, err := s.repo.GetPendingDeviceRebindRequest(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device rebind request", err)
+	}
+
+	return &DeviceStatusResponse{
+		Mode:           s.scan.DeviceBinding,
+		Binding:        binding,
+		PendingRequest: pending,
+	}, nil
+}
+
+// RegisterDeviceKeyRequest represents the public key a device generated for signing offline captures
+type RegisterDeviceKeyRequest struct {
+	DeviceID  string `json:"device_id" binding:"required"`
+	PublicKey string `json:"public_key" binding:"required"` // Ed25519 public key, base64url
+	Platform  string `json:"platform,omitempty" binding:"omitempty,oneof=ios android web"`
+}
+
+// RegisterDeviceKey registers the public key of the account's bound device, binding the device when none is bound.
+// The private key is generated and kept on the device, a registered key is only replaced by a device re-binding.
+func (s *AttendanceService) RegisterDeviceKey(ctx context.Context, userID string, req RegisterDeviceKeyRequest) (*models.DeviceBinding, error) {
+	if _, err := parseDevicePublicKey(req.PublicKey); err != nil {
+		return nil, apperrors.NewValidationError("public_key must be a base64url Ed25519 public key")
+	}
+
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+
+	if binding == nil {
+		owner, err := s.repo.GetActiveDeviceBindingByDeviceID(ctx, req.DeviceID)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to get device binding", err)
+		}
+		if owner != nil {
+			return nil, apperrors.NewConflictError("this device is bound to another account")
+		}
+
+		binding = s.newDeviceBinding(ctx, userID, req.DeviceID, req.Platform)
+		binding.PublicKey = req.PublicKey
+		if err := s.repo.CreateDeviceBinding(ctx, binding); err != nil {
+			return nil, apperrors.NewInternalError("failed to bind device", err)
+		}
+		return binding, nil
+	}
+
+	if binding.DeviceID != req.DeviceID {
+		return nil, apperrors.NewForbiddenError("only your bound device can register a key, request a device re-binding to change it")
+	}
+	if binding.PublicKey == req.PublicKey {
+		return binding, nil
+	}
+	if binding.PublicKey != "" {
+		return nil, apperrors.NewConflictError("a key is already registered for this device, request a device re-binding to replace it")
+	}
+
+	binding.PublicKey = req.PublicKey
+	if err := s.repo.UpdateDeviceBinding(ctx, binding); err != nil {
+		return nil, apperrors.NewInternalError("failed to register device key", err)
+	}
+
+	return binding, nil
+}
+
+// SubmitDeviceRebindRequest represents a request to move the binding to a new device
+type SubmitDeviceRebindRequest struct {
+	DeviceID  string `json:"device_id" binding:"required"`
+	Platform  string `json:"platform,omitempty" binding:"omitempty,oneof=ios android web"`
+	PublicKey string `json:"public_key,omitempty"` // Ed25519 public key of the new device, base64url
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// SubmitDeviceRebind submits a request to move an account's binding to a new device, reviewed by staff
+func (s *AttendanceService) SubmitDeviceRebind(ctx context.Context, userID string, req SubmitDeviceRebindRequest) (*models.DeviceRebindRequest, error) {
+	if req.PublicKey != "" {
+		if _, err := parseDevicePublicKey(req.PublicKey); err != nil {
+			return nil, apperrors.NewValidationError("public_key must be a base64url Ed25519 public key")
+		}
+	}
+
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+	if binding == nil {
+		return nil, apperrors.NewBadRequestError("no device is bound yet, the first attendance recorded binds the device")
+	}
+	if binding.DeviceID == req.DeviceID {
+		return nil, apperrors.NewBadRequestError("this device is already bound to your account")
+	}
+
+	owner, err := s.repo.GetActiveDeviceBindingByDeviceID(ctx, req.DeviceID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+	if owner != nil {
+		return nil, apperrors.NewConflictError("this device is bound to another account")
+	}
+
+	pending, err := s.repo.GetPendingDeviceRebindRequest(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check device rebind requests", err)
+	}
+	if pending != nil {
+		return nil, apperrors.NewConflictError("a device rebind request is already pending")
+	}
+
+	request := &models.DeviceRebindRequest{
+		UserID:    userID,
+		DeviceID:  req.DeviceID,
+		Platform:  req.Platform,
+		PublicKey: req.PublicKey,
+		Reason:    req.Reason,
+		Status:    models.CorrectionStatusPending,
+	}
+
+	if err := s.repo.CreateDeviceRebindRequest(ctx, request); err != nil {
+		return nil, apperrors.NewInternalError("failed to create device rebind request", err)
+	}
+
+	return request, nil
+}
+
+// GetDeviceRebindRequestsRequest represents get device rebind requests request
+type GetDeviceRebindRequestsRequest struct {
+	Status  *string `form:"status"`
+	Page    int     `form:"page,default=1"`
+	PerPage int     `form:"per_page,default=20"`
+}
+
+// GetDeviceRebindRequests gets device rebind requests: staff see all, other users their own
+func (s *AttendanceService) GetDeviceRebindRequests(ctx context.Context, userID string, role string, req GetDeviceRebindRequestsRequest) ([]models.DeviceRebindRequest, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	var ownerID *string
+	if role != string(models.RoleStaff) {
+		ownerID = &userID
+	}
+
+	requests, total, err := s.repo.GetDeviceRebindRequests(ctx, ownerID, req.Status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get device rebind requests", err)
+	}
+
+	return requests, total, nil
+}
+
+// ReviewDeviceRebindRequest represents approve or reject device rebind request
+type ReviewDeviceRebindRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// ApproveDeviceRebind approves a rebind request, replacing the account's bound device
+func (s *AttendanceService) ApproveDeviceRebind(ctx context.Context, reviewerID string, id string, req ReviewDeviceRebindRequest) (*models.DeviceRebindRequest, error) {
+	request, err := s.getPendingDeviceRebind(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.repo.GetActiveDeviceBindingByDeviceID(ctx, request.DeviceID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+	if owner != nil && owner.UserID != request.UserID {
+		return nil, apperrors.NewConflictError("this device is bound to another account")
+	}
+
+	now := time.Now()
+	request.Status = models.CorrectionStatusApproved
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	binding := s.newDeviceBinding(ctx, request.UserID, request.DeviceID, request.Platform)
+	binding.PublicKey = request.PublicKey
+	if err := s.repo.RebindDevice(ctx, request, binding); err != nil {
+		return nil, apperrors.NewInternalError("failed to rebind device", err)
+	}
+
+	return request, nil
+}
+
+// RejectDeviceRebind rejects a rebind request, the account keeps its bound device
+func (s *AttendanceService) RejectDeviceRebind(ctx context.Context, reviewerID string, id string, req ReviewDeviceRebindRequest) (*models.DeviceRebindRequest, error) {
+	if req.Notes == "" {
+		return nil, apperrors.NewValidationError("notes are required when rejecting a device rebind request")
+	}
+
+	request, err := s.getPendingDeviceRebind(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = models.CorrectionStatusRejected
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	if err := s.repo.UpdateDeviceRebindRequest(ctx, request); err != nil {
+		return nil, apperrors.NewInternalError("failed to reject device rebind request", err)
+	}
+
+	return request, nil
+}
+
+// getPendingDeviceRebind gets a device rebind request still waiting for review
+func (s *AttendanceService) getPendingDeviceRebind(ctx context.Context, id string) (*models.DeviceRebindRequest, error) {
+	request, err := s.repo.GetDeviceRebindRequestByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("device rebind request", id)
+	}
+
+	if request.Status != models.CorrectionStatusPending {
+		return nil, apperrors.NewBadRequestError(fmt.Sprintf("device rebind request is already %s", strings.ToLower(string(request.Status))))
+	}
+
+	return request, nil
+}
+
+// GetSharedDevicesRequest represents get shared device report request
+type GetSharedDevicesRequest struct {
+	ScheduleID *string `form:"schedule_id"`
+	ClassID    *string `form:"class_id"`
+	StartDate  string  `form:"start_date"` // YYYY-MM-DD
+	EndDate    string  `form:"end_date"`   // YYYY-MM-DD
+	Page       int     `form:"page,default=1"`
+	PerPage    int     `form:"per_page,default=20"`
+}
+
+// SharedDeviceAccount represents an account seen on a shared device
+type SharedDeviceAccount struct {
+	UserID string `json:"user_id"`
+	Nama   string `json:"nama,omitempty"`
+	NIM    string `json:"nim,omitempty"`
+	Bound  bool   `json:"bound"` // The device is this account's bound device
+}
+
+// SharedDeviceReportItem represents a flagged device with the accounts it recorded attendance for
+type SharedDeviceReportItem struct {
+	models.SharedDeviceFlag
+	Accounts []SharedDeviceAccount `json:"accounts"`
+}
+
+// GetSharedDevices gets the report of devices that recorded attendance for several accounts.
+// Dosen see the class meetings they teach, staff see everything including work check-ins.
+func (s *AttendanceService) GetSharedDevices(ctx context.Context, userID string, role string, req GetSharedDevicesRequest) ([]SharedDeviceReportItem, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		date, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, 0, apperrors.NewBadRequestError("invalid start_date format, use YYYY-MM-DD")
+		}
+		startDate = &date
+	}
+	if req.EndDate != "" {
+		date, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, 0, apperrors.NewBadRequestError("invalid end_date format, use YYYY-MM-DD")
+		}
+		endDate = &date
+	}
+
+	var reviewerID *string
+	if role == string(models.RoleDosen) {
+		reviewerID = &userID
+	}
+
+	flags, total, err := s.repo.GetSharedDeviceFlags(ctx, reviewerID, req.ScheduleID, req.ClassID, startDate, endDate, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get shared devices", err)
+	}
+
+	var userIDs []string
+	for _, flag := range flags {
+		userIDs = append(userIDs, strings.Split(flag.UserIDs, ",")...)
+	}
+	profiles, err := s.repo.GetMahasiswaByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get students", err)
+	}
+	profileByUser := make(map[string]models.Mahasiswa, len(profiles))
+	for _, profile := range profiles {
+		profileByUser[profile.UserID] = profile
+	}
+
+	items := make([]SharedDeviceReportItem, 0, len(flags))
+	for _, flag := range flags {
+		owner, err := s.repo.GetActiveDeviceBindingByDeviceID(ctx, flag.DeviceID)
+		if err != nil {
+			return nil, 0, apperrors.NewInternalError("failed to get device binding", err)
+		}
+
+		item := SharedDeviceReportItem{SharedDeviceFlag: flag}
+		for _, id := range strings.Split(flag.UserIDs, ",") {
+			item.Accounts = append(item.Accounts, SharedDeviceAccount{
+				UserID: id,
+				Nama:   profileByUser[id].Nama,
+				NIM:    profileByUser[id].NIM,
+				Bound:  owner != nil && owner.UserID == id,
+			})
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Defaults applied when no eligibility policy is configured
+const (
+	defaultMinAttendancePercent = 75
+	defaultWarningLevels        = "85,80"
+)
+
+// defaultEligibilityPolicy returns the built-in policy used when none is configured
+func defaultEligibilityPolicy() *models.EligibilityPolicy {
+	return &models.EligibilityPolicy{
+		Scope:                models.PolicyScopeGlobal,
+		MinAttendancePercent: defaultMinAttendancePercent,
+		WarningLevels:        defaultWarningLevels,
+		IsActive:             true,
+	}
+}
+
+// parseWarningLevels parses comma separated warning percentages, highest first
+func parseWarningLevels(value string) ([]float64, error) {
+	levels := []float64{}
+	seen := map[float64]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		level, err := strconv.ParseFloat(part, 64)
+		if err != nil || level <= 0 || level > 100 {
+			return nil, fmt.Errorf("invalid warning level %q", part)
+		}
+		if !seen[level] {
+			seen[level] = true
+			levels = append(levels, level)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(levels)))
+	return levels, nil
+}
+
+// formatWarningLevels formats warning percentages for storage, highest first
+func formatWarningLevels(levels []float64) string {
+	sorted := append([]float64(nil), levels...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	parts := make([]string, len(sorted))
+	for i, level := range sorted {
+		parts[i] = strconv.FormatFloat(level, 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// attendancePercent returns the share of held meetings attended, 100 before any meeting is held
+func attendancePercent(held, attended int) float64 {
+	if held == 0 {
+		return 100
+	}
+	return float64(attended) / float64(held) * 100
+}
+
+// crossedWarningLevel returns the lowest level, among the warning levels and the minimum itself,
+// that the attendance percentage has dropped below
+func crossedWarningLevel(levels []float64, minPercent float64, percent float64) (float64, bool) {
+	crossed, found := minPercent, percent < minPercent
+	for _, level := range levels {
+		if percent < level && (!found || level < crossed) {
+			crossed, found = level, true
+		}
+	}
+	return crossed, found
+}
+
+// resolveEligibilityPolicy finds the policy for a course: course, then study program, then global, then the built-in default
+func (s *AttendanceService) resolveEligibilityPolicy(ctx context.Context, course *models.Course) (*models.EligibilityPolicy, error) {
+	if course != nil && course.ID != "" {
+		policy, err := s.repo.GetActiveEligibilityPolicy(ctx, models.PolicyScopeCourse, course.ID)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	if course != nil && course.Prodi != "" {
+		policy, err := s.repo.GetActiveEligibilityPolicy(ctx, models.PolicyScopeStudyProgram, course.Prodi)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	policy, err := s.repo.GetActiveEligibilityPolicy(ctx, models.PolicyScopeGlobal, "")
+	if err != nil || policy != nil {
+		return policy, err
+	}
+
+	return defaultEligibilityPolicy(), nil
+}
+
+// StudentEligibility represents a student's attendance standing in a class
+type StudentEligibility struct {
+	StudentID         string  `json:"student_id"`
+	NIM               string  `json:"nim"`
+	Nama              string  `json:"nama"`
+	Attended          int     `json:"attended"`
+	AttendancePercent float64 `json:"attendance_percent"`
+	Eligible          bool    `json:"eligible"`
+
+	advisorID *string // Academic advisor, notified along with the student
+}
+
+// ClassEligibilityResponse represents the attendance standing of a class against its eligibility policy
+type ClassEligibilityResponse struct {
+	ClassID      string                    `json:"class_id"`
+	Policy       *models.EligibilityPolicy `json:"policy"`
+	MeetingsHeld int                       `json:"meetings_held"`
+	Students     []StudentEligibility      `json:"students"`
+}
+
+// GetClassEligibilityRequest represents get class eligibility request
+type GetClassEligibilityRequest struct {
+	IneligibleOnly bool `form:"ineligible_only"`
+}
+
+// GetClassEligibility gets the attendance percentage of each approved enrollee against the class policy.
+// Students only see their own standing.
+func (s *AttendanceService) GetClassEligibility(ctx context.Context, userID string, role string, classID string, req GetClassEligibilityRequest) (*ClassEligibilityResponse, error) {
+	class, err := s.courseRepo.GetClassByID(ctx, classID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("class", classID)
+	}
+
+	isTeacher := class.DosenID == userID || (class.AssistantDosenID != nil && *class.AssistantDosenID == userID)
+	if role != string(models.RoleStaff) && role != string(models.RoleMahasiswa) && !isTeacher {
+		return nil, apperrors.NewForbiddenError("not authorized to view eligibility for this class")
+	}
+
+	result, err := s.evaluateClassEligibility(ctx, class)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to evaluate class eligibility", err)
+	}
+
+	students := []StudentEligibility{}
+	for _, student := range result.Students {
+		if role == string(models.RoleMahasiswa) && student.StudentID != userID {
+			continue
+		}
+		if req.IneligibleOnly && student.Eligible {
+			continue
+		}
+		students = append(students, student)
+	}
+	result.Students = students
+
+	return result, nil
+}
+
+// evaluateClassEligibility computes the standing of every approved enrollee of a class
+func (s *AttendanceService) evaluateClassEligibility(ctx context.Context, class *models.Class) (*ClassEligibilityResponse, error) {
+	policy, err := s.resolveEligibilityPolicy(ctx, &class.Course)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := s.repo.CountHeldSchedulesByClassID(ctx, class.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	presence, err := s.repo.CountPresenceByClassID(ctx, class.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, class.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollees := missingEnrollees(enrollments, nil)
+	profiles, err := s.repo.GetMahasiswaByUserIDs(ctx, enrollees)
+	if err != nil {
+		return nil, err
+	}
+	profileByUser := make(map[string]models.Mahasiswa, len(profiles))
+	for _, profile := range profiles {
+		profileByUser[profile.UserID] = profile
+	}
+
+	students := make([]StudentEligibility, 0, len(enrollees))
+	for _, studentID := range enrollees {
+		percent := attendancePercent(int(held), presence[studentID])
+		students = append(students, StudentEligibility{
+			StudentID:         studentID,
+			NIM:               profileByUser[studentID].NIM,
+			Nama:              profileByUser[studentID].Nama,
+			Attended:          presence[studentID],
+			AttendancePercent: percent,
+			Eligible:          percent >= policy.MinAttendancePercent,
+			advisorID:         profileByUser[studentID].AcademicAdvisorID,
+		})
+	}
+
+	return &ClassEligibilityResponse{
+		ClassID:      class.ID,
+		Policy:       policy,
+		MeetingsHeld: int(held),
+		Students:     students,
+	}, nil
+}
+
+// sendEligibilityWarnings notifies students of the class, and their academic advisors, whose attendance
+// dropped below a warning level they were not warned about yet. Returns the number of students warned.
+func (s *AttendanceService) sendEligibilityWarnings(ctx context.Context, class *models.Class) (int, error) {
+	result, err := s.evaluateClassEligibility(ctx, class)
+	if err != nil {
+		return 0, err
+	}
+
+	levels, err := parseWarningLevels(result.Policy.WarningLevels)
+	if err != nil {
+		return 0, err
+	}
+
+	sent, err := s.repo.GetEligibilityWarningsByClassID(ctx, class.ID)
+	if err != nil {
+		return 0, err
+	}
+	lowestSent := make(map[string]float64, len(sent))
+	for _, warning := range sent {
+		if current, ok := lowestSent[warning.UserID]; !ok || warning.Level < current {
+			lowestSent[warning.UserID] = warning.Level
+		}
+	}
+
+	warned := 0
+	for _, student := range result.Students {
+		level, crossed := crossedWarningLevel(levels, result.Policy.MinAttendancePercent, student.AttendancePercent)
+		if !crossed {
+			continue
+		}
+		if previous, ok := lowestSent[student.StudentID]; ok && previous <= level {
+			continue
+		}
+
+		if err := s.notifyEligibilityWarning(ctx, class, result.Policy, student, level); err != nil {
+			return warned, err
+		}
+
+		if err := s.repo.CreateEligibilityWarning(ctx, &models.EligibilityWarning{
+			UserID:            student.StudentID,
+			ClassID:           class.ID,
+			Level:             level,
+			AttendancePercent: student.AttendancePercent,
+		}); err != nil {
+			return warned, err
+		}
+		warned++
+	}
+
+	return warned, nil
+}
+
+// notifyEligibilityWarning sends the warning to the student and, when assigned, their academic advisor
+func (s *AttendanceService) notifyEligibilityWarning(ctx context.Context, class *models.Class, policy *models.EligibilityPolicy, student StudentEligibility, level float64) error {
+	courseName := class.Course.Name
+	if courseName == "" {
+		courseName = class.ClassCode
+	}
+
+	title := "Attendance warning"
+	message := fmt.Sprintf("Your attendance in %s (%s) is %.1f%%, below %.0f%%. At least %.0f%% is required to sit the final exam.",
+		courseName, class.ClassCode, student.AttendancePercent, level, policy.MinAttendancePercent)
+	advisorMessage := fmt.Sprintf("Attendance of %s (%s) in %s (%s) is %.1f%%, below %.0f%%. At least %.0f%% is required to sit the final exam.",
+		student.Nama, student.NIM, courseName, class.ClassCode, student.AttendancePercent, level, policy.MinAttendancePercent)
+	if student.AttendancePercent < policy.MinAttendancePercent {
+		title = "Not eligible for the final exam"
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"class_id":           class.ID,
+		"student_id":         student.StudentID,
+		"attendance_percent": student.AttendancePercent,
+		"level":              level,
+		"min_percent":        policy.MinAttendancePercent,
+	})
+
+	if err := s.notificationRepo.CreateNotification(ctx, &models.Notification{
+		UserID:  student.StudentID,
+		Title:   title,
+		Message: message,
+		Type:    models.NotificationTypeWarning,
+		Data:    string(data),
+	}); err != nil {
+		return err
+	}
+
+	if student.advisorID == nil {
+		return nil
+	}
+
+	return s.notificationRepo.CreateNotification(ctx, &models.Notification{
+		UserID:  *student.advisorID,
+		Title:   title,
+		Message: advisorMessage,
+		Type:    models.NotificationTypeWarning,
+		Data:    string(data),
+	})
+}
+
+// CreateEligibilityPolicyRequest represents create eligibility policy request
+type CreateEligibilityPolicyRequest struct {
+	Scope                string    `json:"scope" binding:"required,oneof=GLOBAL COURSE STUDY_PROGRAM"`
+	CourseID             *string   `json:"course_id,omitempty"`
+	Prodi                *string   `json:"prodi,omitempty"`
+	MinAttendancePercent float64   `json:"min_attendance_percent" binding:"required,gt=0,max=100"`
+	WarningLevels        []float64 `json:"warning_levels,omitempty" binding:"omitempty,dive,gt=0,max=100"`
+}
+
+// CreateEligibilityPolicy creates an eligibility policy, staff only
+func (s *AttendanceService) CreateEligibilityPolicy(ctx context.Context, userID string, req CreateEligibilityPolicyRequest) (*models.EligibilityPolicy, error) {
+	policy := &models.EligibilityPolicy{
+		Scope:                models.PolicyScope(req.Scope),
+		MinAttendancePercent: req.MinAttendancePercent,
+		WarningLevels:        formatWarningLevels(req.WarningLevels),
+		IsActive:             true,
+		CreatedBy:            userID,
+	}
+
+	targetID := ""
+	switch policy.Scope {
+	case models.PolicyScopeCourse:
+		if req.CourseID == nil {
+			return nil, apperrors.NewValidationError("course_id is required for COURSE scope")
+		}
+		if _, err := s.courseRepo.GetCourseByID(ctx, *req.CourseID); err != nil {
+			return nil, apperrors.NewNotFoundError("course", *req.CourseID)
+		}
+		policy.CourseID = req.CourseID
+		targetID = *req.CourseID
+	case models.PolicyScopeStudyProgram:
+		if req.Prodi == nil || *req.Prodi == "" {
+			return nil, apperrors.NewValidationError("prodi is required for STUDY_PROGRAM scope")
+		}
+		policy.Prodi = req.Prodi
+		targetID = *req.Prodi
+	}
+
+	if err := validateEligibilityPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	// A target has at most one active policy, the new one replaces it
+	existing, err := s.repo.GetActiveEligibilityPolicy(ctx, policy.Scope, targetID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check existing eligibility policy", err)
+	}
+	if existing != nil {
+		return nil, apperrors.NewConflictError("an active eligibility policy already exists for this scope, update it instead")
+	}
+
+	if err := s.repo.CreateEligibilityPolicy(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalError("failed to create eligibility policy", err)
+	}
+
+	return policy, nil
+}
+
+// GetEligibilityPoliciesRequest represents get eligibility policies request
+type GetEligibilityPoliciesRequest struct {
+	Scope    *string `form:"scope"`
+	CourseID *string `form:"course_id"`
+	Prodi    *string `form:"prodi"`
+}
+
+// GetEligibilityPolicies gets eligibility policies
+func (s *AttendanceService) GetEligibilityPolicies(ctx context.Context, req GetEligibilityPoliciesRequest) ([]models.EligibilityPolicy, error) {
+	policies, err := s.repo.GetEligibilityPolicies(ctx, req.Scope, req.CourseID, req.Prodi)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get eligibility policies", err)
+	}
+	return policies, nil
+}
+
+// UpdateEligibilityPolicyRequest represents update eligibility policy request
+type UpdateEligibilityPolicyRequest struct {
+	MinAttendancePercent *float64  `json:"min_attendance_percent,omitempty" binding:"omitempty,gt=0,max=100"`
+	WarningLevels        []float64 `json:"warning_levels,omitempty" binding:"omitempty,dive,gt=0,max=100"`
+	IsActive             *bool     `json:"is_active,omitempty"`
+}
+
+// UpdateEligibilityPolicy updates an eligibility policy
+func (s *AttendanceService) UpdateEligibilityPolicy(ctx context.Context, id string, req UpdateEligibilityPolicyRequest) (*models.EligibilityPolicy, error) {
+	policy, err := s.repo.GetEligibilityPolicyByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("eligibility policy", id)
+	}
+
+	if req.MinAttendancePercent != nil {
+		policy.MinAttendancePercent = *req.MinAttendancePercent
+	}
+	if req.WarningLevels != nil {
+		policy.WarningLevels = formatWarningLevels(req.WarningLevels)
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+
+	if err := validateEligibilityPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateEligibilityPolicy(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalError("failed to update eligibility policy", err)
+	}
+
+	return policy, nil
+}
+
+// DeleteEligibilityPolicy deletes an eligibility policy
+func (s *AttendanceService) DeleteEligibilityPolicy(ctx context.Context, id string) error {
+	if _, err := s.repo.GetEligibilityPolicyByID(ctx, id); err != nil {
+		return apperrors.NewNotFoundError("eligibility policy", id)
+	}
+
+	return s.repo.DeleteEligibilityPolicy(ctx, id)
+}
+
+// validateEligibilityPolicy checks that warning levels sit above the minimum attendance
+func validateEligibilityPolicy(policy *models.EligibilityPolicy) error {
+	levels, err := parseWarningLevels(policy.WarningLevels)
+	if err != nil {
+		return apperrors.NewValidationError(err.Error())
+	}
+	for _, level := range levels {
+		if level <= policy.MinAttendancePercent {
+			return apperrors.NewValidationError("warning_levels must be above min_attendance_percent")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,473 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/spreadsheet"
+)
+
+// maxImportFileSize bounds uploaded sign-in sheets
+const maxImportFileSize = 5 << 20
+
+// Import row actions
+const (
+	ImportActionCreate    = "create"    // A new record is created
+	ImportActionUpdate    = "update"    // The student's record changes status
+	ImportActionUnchanged = "unchanged" // The student's record already has the status
+	ImportActionInvalid   = "invalid"   // The row is refused, see its error
+)
+
+// matrixStatusCodes are the single letter codes of the academic office's attendance sheet
+var matrixStatusCodes = map[models.AttendanceStatus]string{
+	models.StatusHadir:     "H",
+	models.StatusTerlambat: "T",
+	models.StatusIzin:      "I",
+	models.StatusSakit:     "S",
+	models.StatusAlpa:      "A",
+}
+
+// ImportAttendanceRequest represents bulk attendance import request
+type ImportAttendanceRequest struct {
+	ScheduleID *string               `form:"schedule_id"`
+	SessionID  *string               `form:"session_id"`
+	Commit     bool                  `form:"commit"` // Dry run unless set
+	File       *multipart.FileHeader `form:"-"`
+}
+
+// ImportRowResult represents the validation result of one imported row
+type ImportRowResult struct {
+	Row       int    `json:"row"` // Line in the file, the header is line 1
+	NIM       string `json:"nim"`
+	StudentID string `json:"student_id,omitempty"`
+	Nama      string `json:"nama,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Action    string `json:"action"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImportAttendanceResponse represents the outcome of a bulk attendance import
+type ImportAttendanceResponse struct {
+	ScheduleID string            `json:"schedule_id"`
+	Committed  bool              `json:"committed"`
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Unchanged  int               `json:"unchanged"`
+	Invalid    int               `json:"invalid"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// importRow represents one row of a sign-in sheet
+type importRow struct {
+	Row    int
+	NIM    string
+	Status string
+	Notes  string
+}
+
+// parseImportRows reads the NIM, status and optional notes columns of a sign-in sheet by their header
+func parseImportRows(rows [][]string) ([]importRow, error) {
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationError("the file is empty")
+	}
+
+	nimCol, statusCol, notesCol := -1, -1, -1
+	for i, header := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case "nim":
+			nimCol = i
+		case "status", "keterangan":
+			statusCol = i
+		case "notes", "catatan":
+			notesCol = i
+		}
+	}
+	if nimCol < 0 || statusCol < 0 {
+		return nil, apperrors.NewValidationError("the header must have a nim and a status column")
+	}
+
+	cell := func(row []string, col int) string {
+		if col < 0 || col >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[col])
+	}
+
+	parsed := make([]importRow, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		parsed = append(parsed, importRow{
+			Row:    i + 2,
+			NIM:    cell(row, nimCol),
+			Status: cell(row, statusCol),
+			Notes:  cell(row, notesCol),
+		})
+	}
+	return parsed, nil
+}
+
+// parseImportStatus accepts an attendance status or its code on the academic office's sheet
+func parseImportStatus(value string) (models.AttendanceStatus, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for status, code := range matrixStatusCodes {
+		if value == string(status) || value == strings.ToLower(code) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// validateImportRows decides the action of each row. enrolled is nil for meetings without a class,
+// existing holds the meeting's current records by student.
+func validateImportRows(rows []importRow, students map[string]models.Mahasiswa, enrolled map[string]bool, existing map[string]*models.Attendance) []ImportRowResult {
+	results := make([]ImportRowResult, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		result := ImportRowResult{Row: row.Row, NIM: row.NIM, Status: row.Status, Action: ImportActionInvalid}
+		status, validStatus := parseImportStatus(row.Status)
+		student, known := students[row.NIM]
+
+		switch {
+		case row.NIM == "":
+			result.Error = "nim is required"
+		case seen[row.NIM] > 0:
+			result.Error = fmt.Sprintf("nim is duplicated, first on row %d", seen[row.NIM])
+		case !known:
+			result.Error = "no student with this nim"
+		case !validStatus:
+			result.Error = "status must be one of hadir, terlambat, izin, sakit, alpa (or H, T, I, S, A)"
+		case enrolled != nil && !enrolled[student.UserID]:
+			result.Error = "student is not an approved enrollee of the class"
+		}
+		if row.NIM != "" && seen[row.NIM] == 0 {
+			seen[row.NIM] = row.Row
+		}
+
+		if known {
+			result.StudentID = student.UserID
+			result.Nama = student.Nama
+		}
+		if result.Error == "" {
+			result.Status = string(status)
+			current := existing[student.UserID]
+			switch {
+			case current == nil:
+				result.Action = ImportActionCreate
+			case current.Status == status:
+				result.Action = ImportActionUnchanged
+			default:
+				result.Action = ImportActionUpdate
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// ImportAttendance validates a sign-in sheet keyed by NIM for a meeting and, when committed
+// and every row is valid, stores all of its records at once
+func (s *AttendanceService) ImportAttendance(ctx context.Context, userID string, role string, req ImportAttendanceRequest) (*ImportAttendanceResponse, error) {
+	if (req.ScheduleID == nil) == (req.SessionID == nil) {
+		return nil, apperrors.NewValidationError("either schedule_id or session_id is required")
+	}
+
+	scheduleID := req.ScheduleID
+	if req.SessionID != nil {
+		session, err := s.repo.GetSessionByID(ctx, *req.SessionID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("session", *req.SessionID)
+		}
+		if session.ScheduleID == nil {
+			return nil, apperrors.NewBadRequestError("the session is not linked to a class meeting")
+		}
+		scheduleID = session.ScheduleID
+	}
+
+	schedule, class, err := s.getManagedSchedule(ctx, userID, role, *scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.CancelledAt != nil {
+		return nil, apperrors.NewBadRequestError("this meeting has been cancelled")
+	}
+
+	rows, err := readImportFile(req.File)
+	if err != nil {
+		return nil, err
+	}
+
+	nims := make([]string, 0, len(rows))
+	for _, row := range rows {
+		nims = append(nims, row.NIM)
+	}
+	mahasiswa, err := s.repo.GetMahasiswaByNIMs(ctx, nims)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get students", err)
+	}
+	students := make(map[string]models.Mahasiswa, len(mahasiswa))
+	for _, m := range mahasiswa {
+		students[m.NIM] = m
+	}
+
+	var enrolled map[string]bool
+	if class != nil {
+		enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, class.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to get enrollments", err)
+		}
+		enrolled = make(map[string]bool, len(enrollments))
+		for _, studentID := range missingEnrollees(enrollments, nil) {
+			enrolled[studentID] = true
+		}
+	}
+
+	attendances, err := s.repo.GetAttendancesByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get attendances", err)
+	}
+	existing := make(map[string]*models.Attendance, len(attendances))
+	for i := range attendances {
+		existing[attendances[i].UserID] = &attendances[i]
+	}
+
+	result := &ImportAttendanceResponse{
+		ScheduleID: schedule.ID,
+		Total:      len(rows),
+		Rows:       validateImportRows(rows, students, enrolled, existing),
+	}
+	for _, row := range result.Rows {
+		switch row.Action {
+		case ImportActionCreate:
+			result.Created++
+		case ImportActionUpdate:
+			result.Updated++
+		case ImportActionUnchanged:
+			result.Unchanged++
+		default:
+			result.Invalid++
+		}
+	}
+
+	if !req.Commit || result.Invalid > 0 {
+		return result, nil
+	}
+
+	var records []*models.Attendance
+	var changeLogs []*models.AttendanceChangeLog
+	for i, row := range result.Rows {
+		if row.Action == ImportActionUnchanged {
+			continue
+		}
+
+		status := models.AttendanceStatus(row.Status)
+		changeLog := &models.AttendanceChangeLog{
+			ChangedBy: userID,
+			Source:    models.ChangeSourceImport,
+			NewStatus: status,
+			Notes:     rows[i].Notes,
+		}
+
+		record := existing[row.StudentID]
+		if record == nil {
+			record = &models.Attendance{
+				UserID:     row.StudentID,
+				SessionID:  req.SessionID,
+				ScheduleID: &schedule.ID,
+				Type:       models.AttendanceTypeKelas,
+				Date:       schedule.Date,
+				CreatedBy:  &userID,
+			}
+		} else {
+			oldStatus := record.Status
+			changeLog.OldStatus = &oldStatus
+		}
+		record.Status = status
+		if rows[i].Notes != "" {
+			record.Notes = rows[i].Notes
+		}
+
+		records = append(records, record)
+		changeLogs = append(changeLogs, changeLog)
+	}
+
+	if err := s.repo.ImportAttendances(ctx, records, changeLogs); err != nil {
+		return nil, apperrors.NewInternalError("failed to import attendance", err)
+	}
+	result.Committed = true
+
+	return result, nil
+}
+
+// readImportFile reads the rows of an uploaded CSV or XLSX sign-in sheet
+func readImportFile(file *multipart.FileHeader) ([]importRow, error) {
+	if file == nil {
+		return nil, apperrors.NewValidationError("file is required")
+	}
+	if file.Size > maxImportFileSize {
+		return nil, apperrors.NewValidationError("file is larger than 5 MB")
+	}
+
+	format, err := spreadsheet.FormatOf(file.Filename)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	opened, err := file.Open()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to open file", err)
+	}
+	defer opened.Close()
+
+	content, err := io.ReadAll(opened)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to read file", err)
+	}
+
+	cells, err := spreadsheet.Read(content, format)
+	if err != nil {
+		return nil, apperrors.NewValidationError("failed to read file: " + err.Error())
+	}
+
+	return parseImportRows(cells)
+}
+
+// ExportAttendanceMatrixRequest represents export attendance matrix request
+type ExportAttendanceMatrixRequest struct {
+	Format string `form:"format,default=xlsx" binding:"omitempty,oneof=csv xlsx"`
+}
+
+// matrixStudent represents a student row of the attendance matrix
+type matrixStudent struct {
+	UserID string
+	NIM    string
+	Nama   string
+}
+
+// ExportAttendanceMatrix exports the student by meeting attendance matrix of a class, for its dosen and staff
+func (s *AttendanceService) ExportAttendanceMatrix(ctx context.Context, userID string, role string, classID string, req ExportAttendanceMatrixRequest) ([]byte, string, spreadsheet.Format, error) {
+	class, err := s.courseRepo.GetClassByID(ctx, classID)
+	if err != nil {
+		return nil, "", "", apperrors.NewNotFoundError("class", classID)
+	}
+	if role != string(models.RoleStaff) && !isScheduleReviewer(&models.Schedule{}, class, userID) {
+		return nil, "", "", apperrors.NewForbiddenError("you can only export attendance of classes you teach")
+	}
+
+	meetings, err := s.repo.GetClassMeetings(ctx, classID)
+	if err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to get class meetings", err)
+	}
+
+	enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, classID)
+	if err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to get enrollments", err)
+	}
+	studentIDs := missingEnrollees(enrollments, nil)
+	profiles, err := s.repo.GetMahasiswaByUserIDs(ctx, studentIDs)
+	if err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to get students", err)
+	}
+	profileByUser := make(map[string]models.Mahasiswa, len(profiles))
+	for _, profile := range profiles {
+		profileByUser[profile.UserID] = profile
+	}
+	students := make([]matrixStudent, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		profile := profileByUser[studentID]
+		students = append(students, matrixStudent{UserID: studentID, NIM: profile.NIM, Nama: profile.Nama})
+	}
+
+	scheduleIDs := make([]string, 0, len(meetings))
+	for _, meeting := range meetings {
+		scheduleIDs = append(scheduleIDs, meeting.ID)
+	}
+	attendances, err := s.repo.GetAttendancesByScheduleIDs(ctx, scheduleIDs)
+	if err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to get attendances", err)
+	}
+
+	format := spreadsheet.Format(req.Format)
+	if format == "" {
+		format = spreadsheet.FormatXLSX
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, class.ClassCode, attendanceMatrixRows(meetings, students, attendances)); err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to export attendance", err)
+	}
+
+	filename := fmt.Sprintf("presensi-%s-%s.%s", class.ClassCode, strings.ReplaceAll(class.Semester, " ", "_"), format)
+	return buf.Bytes(), filename, format, nil
+}
+
+// attendanceMatrixRows builds the academic office's sheet: one row per student sorted by NIM,
+// the status code per meeting, then the H/I/S/A totals and the percentage present over held meetings
+func attendanceMatrixRows(meetings []models.Schedule, students []matrixStudent, attendances []models.Attendance) [][]string {
+	header := []string{"No", "NIM", "Nama"}
+	held := 0
+	for i, meeting := range meetings {
+		header = append(header, fmt.Sprintf("%d (%s)", i+1, meeting.Date.Format("02/01")))
+		if meeting.AttendanceClosedAt != nil {
+			held++
+		}
+	}
+	header = append(header, "H", "I", "S", "A", "% Hadir")
+
+	statusOf := make(map[string]models.AttendanceStatus, len(attendances))
+	for _, attendance := range attendances {
+		if attendance.ScheduleID != nil {
+			statusOf[*attendance.ScheduleID+"/"+attendance.UserID] = attendance.Status
+		}
+	}
+
+	sorted := append([]matrixStudent(nil), students...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].NIM < sorted[j].NIM })
+
+	rows := [][]string{header}
+	for n, student := range sorted {
+		row := []string{strconv.Itoa(n + 1), student.NIM, student.Nama}
+		var present, izin, sakit, alpa int
+		for _, meeting := range meetings {
+			status, ok := statusOf[meeting.ID+"/"+student.UserID]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, matrixStatusCodes[status])
+			switch status {
+			case models.StatusHadir, models.StatusTerlambat:
+				present++
+			case models.StatusIzin:
+				izin++
+			case models.StatusSakit:
+				sakit++
+			case models.StatusAlpa:
+				alpa++
+			}
+		}
+
+		percent := 0.0
+		if held > 0 {
+			percent = float64(present) / float64(held) * 100
+		}
+		row = append(row, strconv.Itoa(present), strconv.Itoa(izin), strconv.Itoa(sakit), strconv.Itoa(alpa),
+			strconv.FormatFloat(percent, 'f', 1, 64))
+		rows = append(rows, row)
+	}
+
+	return rows
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// ensureJournal gets the journal of a class meeting, creating a prefilled draft when missing
+func (s *AttendanceService) ensureJournal(ctx context.Context, schedule *models.Schedule, class *models.Class) (*models.LectureJournal, error) {
+	journal, err := s.repo.GetLectureJournalByScheduleID(ctx, schedule.ID)
+	if err != nil || journal != nil {
+		return journal, err
+	}
+
+	journal = &models.LectureJournal{
+		ScheduleID:    schedule.ID,
+		DosenID:       schedule.DosenID,
+		MeetingNumber: 1,
+		Status:        models.JournalStatusDraft,
+	}
+	if class != nil {
+		journal.ClassID = &class.ID
+		journal.DosenID = class.DosenID
+		journal.Semester = class.Semester
+		journal.AcademicYear = class.AcademicYear
+
+		earlier, err := s.repo.CountClassSchedulesBefore(ctx, class.ID, schedule.Date, schedule.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		journal.MeetingNumber = int(earlier) + 1
+	}
+
+	session, err := s.repo.GetFirstSessionByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		journal.LecturerCheckInAt = &session.CreatedAt
+		journal.IsHeld = true
+	}
+
+	if err := s.repo.CreateLectureJournal(ctx, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// prefillJournalOnOpen records the lecturer's check-in on the meeting's journal.
+// Best effort, opening the session is not failed over the journal.
+func (s *AttendanceService) prefillJournalOnOpen(ctx context.Context, schedule *models.Schedule, session *models.AttendanceSession) {
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return
+	}
+
+	journal, err := s.ensureJournal(ctx, schedule, class)
+	if err != nil || journal.LecturerCheckInAt != nil || journal.Status != models.JournalStatusDraft {
+		return
+	}
+
+	journal.LecturerCheckInAt = &session.CreatedAt
+	journal.IsHeld = true
+	_ = s.repo.UpdateLectureJournal(ctx, journal)
+}
+
+// prefillJournalOnClose completes the draft journal with the final attendance counts.
+// Best effort, closing attendance is not failed over the journal.
+func (s *AttendanceService) prefillJournalOnClose(ctx context.Context, schedule *models.Schedule, class *models.Class) {
+	journal, err := s.ensureJournal(ctx, schedule, class)
+	if err != nil || journal.Status != models.JournalStatusDraft {
+		return
+	}
+
+	attendances, err := s.repo.GetAttendancesByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return
+	}
+	journal.PresentCount, journal.AbsentCount = countJournalAttendance(attendances)
+	journal.IsHeld = true
+
+	_ = s.repo.UpdateLectureJournal(ctx, journal)
+}
+
+// countJournalAttendance counts present (hadir, terlambat) and absent students of a meeting
+func countJournalAttendance(attendances []models.Attendance) (present, absent int) {
+	for _, attendance := range attendances {
+		if attendance.IsGuest {
+			continue
+		}
+		switch attendance.Status {
+		case models.StatusHadir, models.StatusTerlambat:
+			present++
+		default:
+			absent++
+		}
+	}
+	return present, absent
+}
+
+// getJournalSchedule loads a meeting with its class and checks the user may see its journal.
+// Teaching dosen and staff may view, the class rep may view to counter-sign.
+func (s *AttendanceService) getJournalSchedule(ctx context.Context, userID string, role string, scheduleID string) (*models.Schedule, *models.Class, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, nil, apperrors.NewInternalError("failed to get class", err)
+	}
+
+	if role == string(models.RoleStaff) || isScheduleReviewer(schedule, class, userID) || isClassRep(class, userID) {
+		return schedule, class, nil
+	}
+	return nil, nil, apperrors.NewForbiddenError("you can only access journals of classes you teach or represent")
+}
+
+// isClassRep reports whether the user is the class rep of the class
+func isClassRep(class *models.Class, userID string) bool {
+	return class != nil && class.ClassRepID != nil && *class.ClassRepID == userID
+}
+
+// GetLectureJournal gets the journal of a class meeting, prefilling a draft when missing
+func (s *AttendanceService) GetLectureJournal(ctx context.Context, userID string, role string, scheduleID string) (*models.LectureJournal, error) {
+	schedule, class, err := s.getJournalSchedule(ctx, userID, role, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := s.ensureJournal(ctx, schedule, class)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get lecture journal", err)
+	}
+	journal.Schedule = *schedule
+
+	return journal, nil
+}
+
+// UpdateLectureJournalRequest represents update lecture journal request
+type UpdateLectureJournalRequest struct {
+	MeetingNumber  *int    `json:"meeting_number" binding:"omitempty,min=1"`
+	Topic          *string `json:"topic"`
+	TeachingMethod *string `json:"teaching_method"`
+	Notes          *string `json:"notes"`
+	IsHeld         *bool   `json:"is_held"`
+}
+
+// UpdateLectureJournal fills in a draft journal, only by the dosen teaching the meeting
+func (s *AttendanceService) UpdateLectureJournal(ctx context.Context, userID string, scheduleID string, req UpdateLectureJournalRequest) (*models.LectureJournal, error) {
+	journal, err := s.getOwnJournal(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if journal.Status != models.JournalStatusDraft {
+		return nil, apperrors.NewBadRequestError("lecture journal is already submitted")
+	}
+
+	if req.MeetingNumber != nil {
+		journal.MeetingNumber = *req.MeetingNumber
+	}
+	if req.Topic != nil {
+		journal.Topic = *req.Topic
+	}
+	if req.TeachingMethod != nil {
+		journal.TeachingMethod = *req.TeachingMethod
+	}
+	if req.Notes != nil {
+		journal.Notes = *req.Notes
+	}
+	if req.IsHeld != nil {
+		journal.IsHeld = *req.IsHeld
+	}
+
+	if err := s.repo.UpdateLectureJournal(ctx, journal); err != nil {
+		return nil, apperrors.NewInternalError("failed to update lecture journal", err)
+	}
+
+	return journal, nil
+}
+
+// SubmitLectureJournal signs a draft journal by the dosen teaching the meeting
+func (s *AttendanceService) SubmitLectureJournal(ctx context.Context, userID string, scheduleID string) (*models.LectureJournal, error) {
+	journal, err := s.getOwnJournal(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if journal.Status != models.JournalStatusDraft {
+		return nil, apperrors.NewBadRequestError("lecture journal is already submitted")
+	}
+	if strings.TrimSpace(journal.Topic) == "" {
+		return nil, apperrors.NewValidationError("topic is required before submitting the journal")
+	}
+
+	now := time.Now()
+	journal.Status = models.JournalStatusSubmitted
+	journal.SubmittedAt = &now
+
+	if err := s.repo.UpdateLectureJournal(ctx, journal); err != nil {
+		return nil, apperrors.NewInternalError("failed to submit lecture journal", err)
+	}
+
+	return journal, nil
+}
+
+// getOwnJournal gets the journal of a meeting taught by the user
+func (s *AttendanceService) getOwnJournal(ctx context.Context, userID string, scheduleID string) (*models.LectureJournal, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get class", err)
+	}
+	if !isScheduleReviewer(schedule, class, userID) {
+		return nil, apperrors.NewForbiddenError("only the dosen teaching this class can fill its journal")
+	}
+
+	journal, err := s.ensureJournal(ctx, schedule, class)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get lecture journal", err)
+	}
+
+	return journal, nil
+}
+
+// CountersignLectureJournalRequest represents countersign lecture journal request
+type CountersignLectureJournalRequest struct {
+	Notes string `json:"notes"`
+}
+
+// CountersignLectureJournal counter-signs a submitted journal by the class rep
+func (s *AttendanceService) CountersignLectureJournal(ctx context.Context, userID string, scheduleID string, req CountersignLectureJournalRequest) (*models.LectureJournal, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get class", err)
+	}
+	if !isClassRep(class, userID) {
+		return nil, apperrors.NewForbiddenError("only the class rep can counter-sign the journal")
+	}
+
+	journal, err := s.repo.GetLectureJournalByScheduleID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get lecture journal", err)
+	}
+	if journal == nil || journal.Status != models.JournalStatusSubmitted {
+		return nil, apperrors.NewBadRequestError("lecture journal must be submitted by the dosen before it is counter-signed")
+	}
+
+	now := time.Now()
+	journal.Status = models.JournalStatusCountersigned
+	journal.CountersignedBy = &userID
+	journal.CountersignedAt = &now
+	journal.CountersignNotes = req.Notes
+
+	if err := s.repo.UpdateLectureJournal(ctx, journal); err != nil {
+		return nil, apperrors.NewInternalError("failed to counter-sign lecture journal", err)
+	}
+
+	return journal, nil
+}
+
+// ExportLectureJournalsRequest represents export lecture journals request
+type ExportLectureJournalsRequest struct {
+	Semester     string  `form:"semester" binding:"required"`
+	AcademicYear *string `form:"academic_year"`
+	ClassID      *string `form:"class_id"`
+	DosenID      *string `form:"dosen_id"` // Staff only, dosen always export their own journals
+}
+
+// ExportLectureJournals exports the lecture journals of a semester as CSV
+func (s *AttendanceService) ExportLectureJournals(ctx context.Context, userID string, role string, req ExportLectureJournalsRequest) ([]byte, string, error) {
+	dosenID := req.DosenID
+	if role != string(models.RoleStaff) {
+		dosenID = &userID
+	}
+
+	journals, err := s.repo.GetLectureJournals(ctx, dosenID, req.ClassID, &req.Semester, req.AcademicYear)
+	if err != nil {
+		return nil, "", apperrors.NewInternalError("failed to get lecture journals", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(journalCSVRows(journals)); err != nil {
+		return nil, "", apperrors.NewInternalError("failed to export lecture journals", err)
+	}
+
+	filename := fmt.Sprintf("bap-%s.csv", strings.ReplaceAll(req.Semester, " ", "_"))
+	return buf.Bytes(), filename, nil
+}
+
+// journalCSVRows builds the export rows of lecture journals, header first
+func journalCSVRows(journals []models.LectureJournal) [][]string {
+	rows := [][]string{{
+		"class_id", "course_code", "course_name", "meeting_number", "date", "room",
+		"topic", "teaching_method", "notes", "held", "lecturer_check_in",
+		"present", "absent", "status", "submitted_at", "countersigned_at",
+	}}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, journal := range journals {
+		classID := ""
+		if journal.ClassID != nil {
+			classID = *journal.ClassID
+		}
+		rows = append(rows, []string{
+			classID,
+			journal.Schedule.CourseCode,
+			journal.Schedule.CourseName,
+			strconv.Itoa(journal.MeetingNumber),
+			journal.Schedule.Date.Format("2006-01-02"),
+			journal.Schedule.Room,
+			journal.Topic,
+			journal.TeachingMethod,
+			journal.Notes,
+			strconv.FormatBool(journal.IsHeld),
+			formatTime(journal.LecturerCheckInAt),
+			strconv.Itoa(journal.PresentCount),
+			strconv.Itoa(journal.AbsentCount),
+			string(journal.Status),
+			formatTime(journal.SubmittedAt),
+			formatTime(journal.CountersignedAt),
+		})
+	}
+
+	return rows
+}
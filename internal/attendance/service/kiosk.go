@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/qrcode"
+)
+
+// kioskQRType is the QR type of kiosk sessions, keeping their codes apart from class attendance QR codes
+const kioskQRType = "kiosk"
+
+// KioskPolicy holds the settings of kiosk QR sessions
+type KioskPolicy struct {
+	CloseTime  string        // Clock time (HH:MM) open sessions close at, in the work time zone
+	QRRotation time.Duration // How long each displayed QR code is valid
+}
+
+// rotation returns the QR rotation period, 30 seconds when not configured
+func (p KioskPolicy) rotation() time.Duration {
+	if p.QRRotation <= 0 {
+		return 30 * time.Second
+	}
+	return p.QRRotation
+}
+
+// kioskQR returns the signed QR payload a kiosk session displays at now.
+// Codes rotate every period and never outlive the session.
+func kioskQR(session *models.WorkAttendanceSession, now time.Time, rotation time.Duration, key []byte) qrcode.QRData {
+	expiresAt := now.Truncate(rotation).Add(rotation)
+	if session.ExpiresAt != nil && expiresAt.After(*session.ExpiresAt) {
+		expiresAt = *session.ExpiresAt
+	}
+
+	data := qrcode.QRData{
+		SessionID: session.ID,
+		ExpiresAt: expiresAt.UTC(),
+		Type:      kioskQRType,
+	}
+	qrcode.Sign(&data, key)
+	return data
+}
+
+// verifyKioskQR checks a scanned kiosk QR code. The previous code stays valid for one more period,
+// so a scan made just before the display rotates is not refused.
+func verifyKioskQR(data *qrcode.QRData, now time.Time, rotation time.Duration, key []byte) error {
+	if data.Type != kioskQRType || data.SessionID == "" {
+		return errors.New("not a kiosk QR code")
+	}
+	if !qrcode.Verify(data, key) {
+		return errors.New("kiosk QR code signature is invalid")
+	}
+	if now.After(data.ExpiresAt.Add(rotation)) {
+		return errors.New("kiosk QR code has expired, scan the code currently displayed")
+	}
+	return nil
+}
+
+// kioskSessionOpen reports whether a kiosk session still accepts scans at now
+func kioskSessionOpen(session *models.WorkAttendanceSession, now time.Time) bool {
+	return session.IsActive && session.Status == models.WorkSessionStatusOpen &&
+		(session.ExpiresAt == nil || now.Before(*session.ExpiresAt))
+}
+
+// verifyKioskScan checks the kiosk QR code of a check-in or check-out and returns its session,
+// nil when the check-in was not made at a kiosk. Only users of the kiosk's unit may scan it.
+func (s *AttendanceService) verifyKioskScan(ctx context.Context, userID string, raw string, now time.Time) (*models.WorkAttendanceSession, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	data, err := qrcode.ParseQRData(raw)
+	if err != nil {
+		return nil, apperrors.NewBadRequestError("invalid kiosk QR code data")
+	}
+	if err := verifyKioskQR(data, now, s.scan.Kiosk.rotation(), []byte(s.scan.QRSigningKey)); err != nil {
+		return nil, apperrors.NewBadRequestError(err.Error())
+	}
+
+	session, err := s.repo.GetWorkAttendanceSessionByID(ctx, data.SessionID)
+	if err != nil {
+		return nil, apperrors.NewBadRequestError("invalid kiosk QR code")
+	}
+	if !kioskSessionOpen(session, now) {
+		return nil, apperrors.NewBadRequestError("kiosk session is closed")
+	}
+
+	unit, err := s.repo.GetUserUnit(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get user unit", err)
+	}
+	if unit != session.Unit {
+		return nil, apperrors.NewForbiddenError("this kiosk belongs to another unit")
+	}
+
+	return session, nil
+}
+
+// kioskSessionID returns the ID of a kiosk session, nil without one
+func kioskSessionID(session *models.WorkAttendanceSession) *string {
+	if session == nil {
+		return nil
+	}
+	return &session.ID
+}
+
+// hashKioskToken returns the stored hash of a kiosk device token
+func hashKioskToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterKioskDeviceRequest represents register kiosk device request
+type RegisterKioskDeviceRequest struct {
+	Unit string `json:"unit" binding:"required"`
+	Name string `json:"name" binding:"required"` // e.g. "Front desk tablet, Biro Umum"
+}
+
+// RegisterKioskDeviceResponse represents a registered kiosk device with its token
+type RegisterKioskDeviceResponse struct {
+	Device *models.KioskDevice `json:"device"`
+	Token  string              `json:"token"` // Only returned here, configured on the tablet and sent as X-Kiosk-Token
+}
+
+// RegisterKioskDevice registers a front desk tablet of a unit as a kiosk device.
+// The token is only returned here; a lost token is replaced by registering the tablet again.
+func (s *AttendanceService) RegisterKioskDevice(ctx context.Context, userID string, req RegisterKioskDeviceRequest) (*RegisterKioskDeviceResponse, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, apperrors.NewInternalError("failed to generate kiosk token", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	device := &models.KioskDevice{
+		Unit:         req.Unit,
+		Name:         req.Name,
+		TokenHash:    hashKioskToken(token),
+		IsActive:     true,
+		RegisteredBy: userID,
+	}
+	if err := s.repo.CreateKioskDevice(ctx, device); err != nil {
+		return nil, apperrors.NewInternalError("failed to register kiosk device", err)
+	}
+
+	return &RegisterKioskDeviceResponse{Device: device, Token: token}, nil
+}
+
+// GetKioskDevices gets the registered kiosk devices, optionally of one unit
+func (s *AttendanceService) GetKioskDevices(ctx context.Context, unit *string) ([]models.KioskDevice, error) {
+	devices, err := s.repo.GetKioskDevices(ctx, unit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get kiosk devices", err)
+	}
+	return devices, nil
+}
+
+// RevokeKioskDevice revokes a kiosk device, its token stops working and its open sessions close
+func (s *AttendanceService) RevokeKioskDevice(ctx context.Context, id string) (*models.KioskDevice, error) {
+	device, err := s.repo.GetKioskDeviceByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("kiosk device", id)
+	}
+	if !device.IsActive {
+		return nil, apperrors.NewConflictError("kiosk device is already revoked")
+	}
+
+	now := time.Now()
+	device.IsActive = false
+	device.RevokedAt = &now
+	if err := s.repo.RevokeKioskDevice(ctx, device); err != nil {
+		return nil, apperrors.NewInternalError("failed to revoke kiosk device", err)
+	}
+
+	return device, nil
+}
+
+// authenticateKioskDevice gets the active kiosk device a token was issued to.
+// Only registered kiosk devices can open kiosk sessions and display their QR codes.
+func (s *AttendanceService) authenticateKioskDevice(ctx context.Context, token string) (*models.KioskDevice, error) {
+	if token == "" {
+		return nil, apperrors.NewForbiddenError("only a registered kiosk device can do this")
+	}
+
+	device, err := s.repo.GetActiveKioskDeviceByTokenHash(ctx, hashKioskToken(token))
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get kiosk device", err)
+	}
+	if device == nil {
+		return nil, apperrors.NewForbiddenError("kiosk device token is invalid or revoked")
+	}
+
+	return device, nil
+}
+
+// OpenKioskSessionRequest represents open kiosk session request
+type OpenKioskSessionRequest struct {
+	ClosesAt *string `json:"closes_at,omitempty"` // HH:MM today, defaults to the configured close time
+}
+
+// OpenKioskSession opens a kiosk session on a registered kiosk device for its unit, closing on its own later today.
+// The session is bound to the device, no other device can display its QR code.
+func (s *AttendanceService) OpenKioskSession(ctx context.Context, userID string, kioskToken string, req OpenKioskSessionRequest) (*models.WorkAttendanceSession, error) {
+	device, err := s.authenticateKioskDevice(ctx, kioskToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loc := s.workLocation()
+
+	closeTime := s.scan.Kiosk.CloseTime
+	if req.ClosesAt != nil {
+		closeTime = *req.ClosesAt
+	}
+	clock, err := time.Parse("15:04", closeTime)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid closes_at format, use HH:MM")
+	}
+
+	local := now.In(loc)
+	closesAt := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if !closesAt.After(now) {
+		return nil, apperrors.NewValidationError("kiosk sessions close at " + closesAt.Format("15:04") + ", which has already passed today")
+	}
+
+	session := &models.WorkAttendanceSession{
+		Unit:          device.Unit,
+		KioskDeviceID: &device.ID,
+		SessionDate:   workDay(now, loc),
+		ExpiresAt:     &closesAt,
+		Status:        models.WorkSessionStatusOpen,
+		CreatedBy:     &userID,
+		IsActive:      true,
+	}
+
+	if err := s.repo.CreateWorkAttendanceSession(ctx, session); err != nil {
+		return nil, apperrors.NewInternalError("failed to open kiosk session", err)
+	}
+
+	return session, nil
+}
+
+// KioskQRResponse represents the QR code a kiosk displays
+type KioskQRResponse struct {
+	SessionID string `json:"session_id"`
+	QRData    string `json:"qr_data"`
+	QRImage   string `json:"qr_image"`   // Base64 PNG
+	ExpiresAt string `json:"expires_at"` // Refresh the display by then
+}
+
+// GetKioskQR gets the QR code a kiosk session displays now, only for the kiosk device that opened it
+func (s *AttendanceService) GetKioskQR(ctx context.Context, kioskToken string, id string) (*KioskQRResponse, error) {
+	device, err := s.authenticateKioskDevice(ctx, kioskToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetWorkAttendanceSessionByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("kiosk session", id)
+	}
+	if session.KioskDeviceID == nil || *session.KioskDeviceID != device.ID {
+		return nil, apperrors.NewForbiddenError("this kiosk session was opened on another device")
+	}
+
+	now := time.Now()
+	if !kioskSessionOpen(session, now) {
+		return nil, apperrors.NewBadRequestError("kiosk session is closed")
+	}
+
+	qrData := kioskQR(session, now, s.scan.Kiosk.rotation(), []byte(s.scan.QRSigningKey))
+	qrImage, err := qrcode.GenerateQRCode(qrData)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to generate QR code", err)
+	}
+	qrDataJSON, _ := json.Marshal(qrData)
+
+	return &KioskQRResponse{
+		SessionID: session.ID,
+		QRData:    string(qrDataJSON),
+		QRImage:   base64.StdEncoding.EncodeToString(qrImage),
+		ExpiresAt: qrData.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// canCloseKioskSession reports whether a user may close a kiosk session: on the kiosk device that opened it,
+// as a supervisor of its unit, or as staff
+func (s *AttendanceService) canCloseKioskSession(ctx context.Context, userID string, role string, kioskToken string, session *models.WorkAttendanceSession) (bool, error) {
+	if role == string(models.RoleStaff) {
+		return true, nil
+	}
+
+	if kioskToken != "" && session.KioskDeviceID != nil {
+		device, err := s.repo.GetActiveKioskDeviceByTokenHash(ctx, hashKioskToken(kioskToken))
+		if err != nil {
+			return false, err
+		}
+		if device != nil && device.ID == *session.KioskDeviceID {
+			return true, nil
+		}
+	}
+
+	if session.Unit == "" {
+		return false, nil
+	}
+	assignment, err := s.repo.GetUnitSupervisor(ctx, session.Unit, userID)
+	if err != nil {
+		return false, err
+	}
+	return assignment != nil, nil
+}
+
+// CloseKioskSession closes an open kiosk session before its close time, from the kiosk device that opened it,
+// as a supervisor of its unit, or as staff
+func (s *AttendanceService) CloseKioskSession(ctx context.Context, userID string, role string, kioskToken string, id string) (*models.WorkAttendanceSession, error) {
+	session, err := s.repo.GetWorkAttendanceSessionByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("kiosk session", id)
+	}
+	allowed, err := s.canCloseKioskSession(ctx, userID, role, kioskToken, session)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check kiosk session access", err)
+	}
+	if !allowed {
+		return nil, apperrors.NewForbiddenError("only the session's kiosk device, a supervisor of its unit or staff can close it")
+	}
+	if session.Status != models.WorkSessionStatusOpen {
+		return nil, apperrors.NewConflictError("kiosk session is already closed")
+	}
+
+	now := time.Now()
+	session.Status = models.WorkSessionStatusClosed
+	session.IsActive = false
+	session.ClosedBy = &userID
+	session.ClosedAt = &now
+
+	if err := s.repo.UpdateWorkAttendanceSession(ctx, session); err != nil {
+		return nil, apperrors.NewInternalError("failed to close kiosk session", err)
+	}
+
+	return session, nil
+}
+
+// GetKioskSessionsRequest represents get kiosk sessions request
+type GetKioskSessionsRequest struct {
+	Unit    *string `form:"unit"`
+	Status  *string `form:"status"`
+	Page    int     `form:"page,default=1"`
+	PerPage int     `form:"per_page,default=20"`
+}
+
+// GetKioskSessions gets kiosk sessions, latest first
+func (s *AttendanceService) GetKioskSessions(ctx context.Context, req GetKioskSessionsRequest) ([]models.WorkAttendanceSession, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	sessions, total, err := s.repo.GetKioskSessions(ctx, req.Unit, req.Status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get kiosk sessions", err)
+	}
+
+	return sessions, total, nil
+}
+
+// ExpireKioskSessions closes the open kiosk sessions past their close time, returning how many were closed
+func (s *AttendanceService) ExpireKioskSessions(ctx context.Context) (int64, error) {
+	return s.repo.ExpireKioskSessions(ctx, time.Now())
+}
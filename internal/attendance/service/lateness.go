@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Defaults applied when no lateness policy is configured
+const (
+	defaultGraceMinutes      = 15
+	defaultLateWindowMinutes = 15
+)
+
+// defaultLatenessPolicy returns the built-in policy used when none is configured
+func defaultLatenessPolicy() *models.LatenessPolicy {
+	return &models.LatenessPolicy{
+		Scope:             models.PolicyScopeGlobal,
+		GraceMinutes:      defaultGraceMinutes,
+		LateWindowMinutes: defaultLateWindowMinutes,
+		IsActive:          true,
+	}
+}
+
+// scheduleStartAt returns the start of a scheduled meeting in loc
+func scheduleStartAt(schedule *models.Schedule, loc *time.Location) time.Time {
+	return time.Date(schedule.Date.Year(), schedule.Date.Month(), schedule.Date.Day(),
+		schedule.StartTime.Hour(), schedule.StartTime.Minute(), 0, 0, loc)
+}
+
+// meetingStartAt returns the start of a scheduled meeting in the work time zone (WIB by default).
+// Meeting clock times are campus times, the server's local zone plays no part.
+func (s *AttendanceService) meetingStartAt(schedule *models.Schedule) time.Time {
+	return scheduleStartAt(schedule, s.workLocation())
+}
+
+// classifyScan determines the attendance status of a scan made at scannedAt for a meeting starting at start.
+// Returns refused when the scan falls after the policy cutoff.
+func classifyScan(policy *models.LatenessPolicy, start, scannedAt time.Time) (models.AttendanceStatus, bool) {
+	elapsed := scannedAt.Sub(start)
+	grace := time.Duration(policy.GraceMinutes) * time.Minute
+
+	if elapsed <= grace {
+		return models.StatusHadir, false
+	}
+
+	if policy.CutoffMinutes != nil && elapsed > time.Duration(*policy.CutoffMinutes)*time.Minute {
+		return "", true
+	}
+
+	if elapsed <= grace+time.Duration(policy.LateWindowMinutes)*time.Minute {
+		return models.StatusTerlambat, false
+	}
+
+	return models.StatusAlpa, false
+}
+
+// resolveLatenessPolicy finds the policy for a schedule: class, then course, then global, then the built-in default
+func (s *AttendanceService) resolveLatenessPolicy(ctx context.Context, schedule *models.Schedule, class *models.Class) (*models.LatenessPolicy, error) {
+	if class != nil {
+		policy, err := s.repo.GetActiveLatenessPolicy(ctx, models.PolicyScopeClass, class.ID)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	courseID := schedule.CourseID
+	if class != nil {
+		courseID = &class.CourseID
+	}
+	if courseID != nil {
+		policy, err := s.repo.GetActiveLatenessPolicy(ctx, models.PolicyScopeCourse, *courseID)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	policy, err := s.repo.GetActiveLatenessPolicy(ctx, models.PolicyScopeGlobal, "")
+	if err != nil || policy != nil {
+		return policy, err
+	}
+
+	return defaultLatenessPolicy(), nil
+}
+
+// GetEffectiveLatenessPolicy gets the lateness policy that applies to a schedule
+func (s *AttendanceService) GetEffectiveLatenessPolicy(ctx context.Context, scheduleID string) (*models.LatenessPolicy, error) {
+	if scheduleID == "" {
+		return nil, apperrors.NewValidationError("schedule_id is required")
+	}
+
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+	}
+
+	policy, err := s.resolveLatenessPolicy(ctx, schedule, class)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve lateness policy", err)
+	}
+
+	return policy, nil
+}
+
+// CreateLatenessPolicyRequest represents create lateness policy request
+type CreateLatenessPolicyRequest struct {
+	Scope             string  `json:"scope" binding:"required,oneof=GLOBAL COURSE CLASS"`
+	CourseID          *string `json:"course_id,omitempty"`
+	ClassID           *string `json:"class_id,omitempty"`
+	GraceMinutes      int     `json:"grace_minutes" binding:"min=0"`
+	LateWindowMinutes int     `json:"late_window_minutes" binding:"min=0"`
+	CutoffMinutes     *int    `json:"cutoff_minutes,omitempty" binding:"omitempty,min=0"`
+}
+
+// CreateLatenessPolicy creates a lateness policy.
+// Global and course policies are managed by staff, class policies also by the class dosen.
+func (s *AttendanceService) CreateLatenessPolicy(ctx context.Context, userID string, role string, req CreateLatenessPolicyRequest) (*models.LatenessPolicy, error) {
+	policy := &models.LatenessPolicy{
+		Scope:             models.PolicyScope(req.Scope),
+		GraceMinutes:      req.GraceMinutes,
+		LateWindowMinutes: req.LateWindowMinutes,
+		CutoffMinutes:     req.CutoffMinutes,
+		IsActive:          true,
+		CreatedBy:         userID,
+	}
+
+	switch policy.Scope {
+	case models.PolicyScopeCourse:
+		if req.CourseID == nil {
+			return nil, apperrors.NewValidationError("course_id is required for COURSE scope")
+		}
+		if _, err := s.courseRepo.GetCourseByID(ctx, *req.CourseID); err != nil {
+			return nil, apperrors.NewNotFoundError("course", *req.CourseID)
+		}
+		policy.CourseID = req.CourseID
+	case models.PolicyScopeClass:
+		if req.ClassID == nil {
+			return nil, apperrors.NewValidationError("class_id is required for CLASS scope")
+		}
+		class, err := s.courseRepo.GetClassByID(ctx, *req.ClassID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("class", *req.ClassID)
+		}
+		policy.ClassID = &class.ID
+		policy.CourseID = &class.CourseID
+	}
+
+	if err := s.checkLatenessPolicyAccess(ctx, userID, role, policy); err != nil {
+		return nil, err
+	}
+
+	if err := validateLatenessPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	// A target has at most one active policy, the new one replaces it
+	targetID := ""
+	if policy.ClassID != nil {
+		targetID = *policy.ClassID
+	} else if policy.CourseID != nil {
+		targetID = *policy.CourseID
+	}
+	existing, err := s.repo.GetActiveLatenessPolicy(ctx, policy.Scope, targetID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check existing lateness policy", err)
+	}
+	if existing != nil {
+		return nil, apperrors.NewConflictError("an active lateness policy already exists for this scope, update it instead")
+	}
+
+	if err := s.repo.CreateLatenessPolicy(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalError("failed to create lateness policy", err)
+	}
+
+	return policy, nil
+}
+
+// GetLatenessPoliciesRequest represents get lateness policies request
+type GetLatenessPoliciesRequest struct {
+	Scope    *string `form:"scope"`
+	CourseID *string `form:"course_id"`
+	ClassID  *string `form:"class_id"`
+}
+
+// GetLatenessPolicies gets lateness policies
+func (s *AttendanceService) GetLatenessPolicies(ctx context.Context, req GetLatenessPoliciesRequest) ([]models.LatenessPolicy, error) {
+	policies, err := s.repo.GetLatenessPolicies(ctx, req.Scope, req.CourseID, req.ClassID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get lateness policies", err)
+	}
+	return policies, nil
+}
+
+// UpdateLatenessPolicyRequest represents update lateness policy request
+type UpdateLatenessPolicyRequest struct {
+	GraceMinutes      *int  `json:"grace_minutes,omitempty" binding:"omitempty,min=0"`
+	LateWindowMinutes *int  `json:"late_window_minutes,omitempty" binding:"omitempty,min=0"`
+	CutoffMinutes     *int  `json:"cutoff_minutes,omitempty" binding:"omitempty,min=0"`
+	ClearCutoff       bool  `json:"clear_cutoff,omitempty"`
+	IsActive          *bool `json:"is_active,omitempty"`
+}
+
+// UpdateLatenessPolicy updates a lateness policy
+func (s *AttendanceService) UpdateLatenessPolicy(ctx context.Context, userID string, role string, id string, req UpdateLatenessPolicyRequest) (*models.LatenessPolicy, error) {
+	policy, err := s.repo.GetLatenessPolicyByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("lateness policy", id)
+	}
+
+	if err := s.checkLatenessPolicyAccess(ctx, userID, role, policy); err != nil {
+		return nil, err
+	}
+
+	if req.GraceMinutes != nil {
+		policy.GraceMinutes = *req.GraceMinutes
+	}
+	if req.LateWindowMinutes != nil {
+		policy.LateWindowMinutes = *req.LateWindowMinutes
+	}
+	if req.CutoffMinutes != nil {
+		policy.CutoffMinutes = req.CutoffMinutes
+	}
+	if req.ClearCutoff {
+		policy.CutoffMinutes = nil
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+
+	if err := validateLatenessPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateLatenessPolicy(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalError("failed to update lateness policy", err)
+	}
+
+	return policy, nil
+}
+
+// DeleteLatenessPolicy deletes a lateness policy
+func (s *AttendanceService) DeleteLatenessPolicy(ctx context.Context, userID string, role string, id string) error {
+	policy, err := s.repo.GetLatenessPolicyByID(ctx, id)
+	if err != nil {
+		return apperrors.NewNotFoundError("lateness policy", id)
+	}
+
+	if err := s.checkLatenessPolicyAccess(ctx, userID, role, policy); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLatenessPolicy(ctx, id)
+}
+
+// checkLatenessPolicyAccess checks that the user may manage the policy
+func (s *AttendanceService) checkLatenessPolicyAccess(ctx context.Context, userID string, role string, policy *models.LatenessPolicy) error {
+	if role == string(models.RoleStaff) {
+		return nil
+	}
+
+	if policy.Scope == models.PolicyScopeClass && policy.ClassID != nil {
+		class, err := s.courseRepo.GetClassByID(ctx, *policy.ClassID)
+		if err != nil {
+			return apperrors.NewNotFoundError("class", *policy.ClassID)
+		}
+		if class.DosenID == userID {
+			return nil
+		}
+	}
+
+	return apperrors.NewForbiddenError("not authorized to manage this lateness policy")
+}
+
+// validateLatenessPolicy checks that the cutoff does not fall inside the grace period
+func validateLatenessPolicy(policy *models.LatenessPolicy) error {
+	if policy.CutoffMinutes != nil && *policy.CutoffMinutes < policy.GraceMinutes {
+		return apperrors.NewValidationError("cutoff_minutes must not be shorter than grace_minutes")
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Live roster event types
+const (
+	RosterEventAccepted = "accepted"
+	RosterEventRejected = "rejected"
+)
+
+// rosterChannel returns the Redis pub/sub channel of a meeting's live roster.
+// Scans are published per schedule so the stream survives QR session rotation.
+func rosterChannel(scheduleID string) string {
+	return "attendance:roster:" + scheduleID
+}
+
+// RosterCounts represents running attendance counts of a meeting against its enrolled total
+type RosterCounts struct {
+	Enrolled  int `json:"enrolled"`
+	Hadir     int `json:"hadir"`
+	Terlambat int `json:"terlambat"`
+	Alpa      int `json:"alpa"`
+	Izin      int `json:"izin"`
+	Sakit     int `json:"sakit"`
+	Guests    int `json:"guests"`
+	Rejected  int `json:"rejected"` // Students with a refused scan and no record
+	Pending   int `json:"pending"`  // Enrolled students without a record yet
+}
+
+// RosterEvent represents an accepted or rejected scan on the live roster
+type RosterEvent struct {
+	Type       string                     `json:"type"`
+	ScheduleID string                     `json:"schedule_id"`
+	SessionID  *string                    `json:"session_id,omitempty"`
+	UserID     string                     `json:"user_id"`
+	Nama       string                     `json:"nama"`
+	NIM        string                     `json:"nim"`
+	Status     models.AttendanceStatus    `json:"status,omitempty"`
+	Reason     models.ScanRejectionReason `json:"reason,omitempty"`
+	Message    string                     `json:"message,omitempty"`
+	IsGuest    bool                       `json:"is_guest,omitempty"`
+	Time       time.Time                  `json:"time"`
+	Counts     *RosterCounts              `json:"counts,omitempty"`
+}
+
+// LiveRosterSnapshot represents the roster of a meeting when a lecturer starts watching it
+type LiveRosterSnapshot struct {
+	ScheduleID string        `json:"schedule_id"`
+	Counts     RosterCounts  `json:"counts"`
+	Events     []RosterEvent `json:"events"` // Scans so far, oldest first
+}
+
+// rosterCounts counts the records and refused students of a meeting
+func rosterCounts(enrolled int, attendances []models.Attendance, rejections []models.AttendanceScanRejection) RosterCounts {
+	counts := RosterCounts{Enrolled: enrolled}
+
+	recorded := map[string]bool{}
+	for _, attendance := range attendances {
+		if recorded[attendance.UserID] {
+			continue
+		}
+		recorded[attendance.UserID] = true
+
+		switch attendance.Status {
+		case models.StatusHadir:
+			counts.Hadir++
+		case models.StatusTerlambat:
+			counts.Terlambat++
+		case models.StatusAlpa:
+			counts.Alpa++
+		case models.StatusIzin:
+			counts.Izin++
+		case models.StatusSakit:
+			counts.Sakit++
+		}
+		if attendance.IsGuest {
+			counts.Guests++
+		}
+	}
+
+	rejected := map[string]bool{}
+	for _, rejection := range rejections {
+		if !recorded[rejection.UserID] && !rejected[rejection.UserID] {
+			rejected[rejection.UserID] = true
+			counts.Rejected++
+		}
+	}
+
+	counts.Pending = enrolled - (len(recorded) - counts.Guests)
+	if counts.Pending < 0 {
+		counts.Pending = 0
+	}
+	return counts
+}
+
+// loadRoster gets the records, refused scans and counts of a meeting
+func (s *AttendanceService) loadRoster(ctx context.Context, schedule *models.Schedule) ([]models.Attendance, []models.AttendanceScanRejection, RosterCounts, error) {
+	attendances, err := s.repo.GetAttendancesByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return nil, nil, RosterCounts{}, err
+	}
+
+	rejections, err := s.repo.GetScanRejectionsByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return nil, nil, RosterCounts{}, err
+	}
+
+	enrolled := 0
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, nil, RosterCounts{}, err
+	}
+	if class != nil {
+		enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, class.ID)
+		if err != nil {
+			return nil, nil, RosterCounts{}, err
+		}
+		enrolled = len(missingEnrollees(enrollments, nil))
+	}
+
+	return attendances, rejections, rosterCounts(enrolled, attendances, rejections), nil
+}
+
+// publishRosterEvent publishes a scan to lecturers watching the meeting on any replica.
+// Errors are ignored, the scan has been handled either way.
+func (s *AttendanceService) publishRosterEvent(ctx context.Context, event RosterEvent) {
+	if s.redis == nil || event.ScheduleID == "" {
+		return
+	}
+
+	schedule, err := s.repo.GetScheduleByID(ctx, event.ScheduleID)
+	if err != nil {
+		return
+	}
+
+	if _, _, counts, err := s.loadRoster(ctx, schedule); err == nil {
+		event.Counts = &counts
+	}
+
+	if profiles, err := s.repo.GetMahasiswaByUserIDs(ctx, []string{event.UserID}); err == nil && len(profiles) > 0 {
+		event.Nama, event.NIM = profiles[0].Nama, profiles[0].NIM
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_ = s.redis.Publish(ctx, rosterChannel(event.ScheduleID), payload).Err()
+}
+
+// WatchLiveRoster returns the current roster of the meeting behind a session and subscribes to its scans,
+// allowed for the meeting's dosen and staff. The subscription ends when ctx is done.
+func (s *AttendanceService) WatchLiveRoster(ctx context.Context, userID string, role string, sessionID string) (*LiveRosterSnapshot, <-chan RosterEvent, error) {
+	if s.redis == nil {
+		return nil, nil, apperrors.NewInternalError("live roster is unavailable", nil)
+	}
+
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, apperrors.NewNotFoundError("session", sessionID)
+	}
+	if session.ScheduleID == nil {
+		return nil, nil, apperrors.NewValidationError("session is not linked to a class schedule")
+	}
+
+	schedule, err := s.repo.GetScheduleByID(ctx, *session.ScheduleID)
+	if err != nil {
+		return nil, nil, apperrors.NewNotFoundError("schedule", *session.ScheduleID)
+	}
+
+	if role != string(models.RoleStaff) {
+		canReview, err := s.canReviewSchedule(ctx, userID, schedule)
+		if err != nil {
+			return nil, nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+		}
+		if !canReview && schedule.DosenID != userID {
+			return nil, nil, apperrors.NewForbiddenError("not authorized to watch this session")
+		}
+	}
+
+	// Subscribe before loading the snapshot so no scan falls in between
+	pubsub := s.redis.Subscribe(ctx, rosterChannel(schedule.ID))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, apperrors.NewInternalError("failed to subscribe to live roster", err)
+	}
+
+	attendances, rejections, counts, err := s.loadRoster(ctx, schedule)
+	if err != nil {
+		pubsub.Close()
+		return nil, nil, apperrors.NewInternalError("failed to load roster", err)
+	}
+
+	snapshot, err := s.rosterSnapshot(ctx, schedule, attendances, rejections, counts)
+	if err != nil {
+		pubsub.Close()
+		return nil, nil, apperrors.NewInternalError("failed to load roster", err)
+	}
+
+	events := make(chan RosterEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event RosterEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return snapshot, events, nil
+}
+
+// rosterSnapshot builds the snapshot events from the records and refused scans so far
+func (s *AttendanceService) rosterSnapshot(ctx context.Context, schedule *models.Schedule, attendances []models.Attendance, rejections []models.AttendanceScanRejection, counts RosterCounts) (*LiveRosterSnapshot, error) {
+	userIDs := make([]string, 0, len(attendances)+len(rejections))
+	for _, attendance := range attendances {
+		userIDs = append(userIDs, attendance.UserID)
+	}
+	for _, rejection := range rejections {
+		userIDs = append(userIDs, rejection.UserID)
+	}
+
+	profiles, err := s.repo.GetMahasiswaByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	profileByUser := make(map[string]models.Mahasiswa, len(profiles))
+	for _, profile := range profiles {
+		profileByUser[profile.UserID] = profile
+	}
+
+	events := make([]RosterEvent, 0, len(userIDs))
+	for _, attendance := range attendances {
+		scannedAt := attendance.CreatedAt
+		if attendance.CheckInTime != nil {
+			scannedAt = *attendance.CheckInTime
+		}
+		events = append(events, RosterEvent{
+			Type:       RosterEventAccepted,
+			ScheduleID: schedule.ID,
+			SessionID:  attendance.SessionID,
+			UserID:     attendance.UserID,
+			Nama:       profileByUser[attendance.UserID].Nama,
+			NIM:        profileByUser[attendance.UserID].NIM,
+			Status:     attendance.Status,
+			IsGuest:    attendance.IsGuest,
+			Time:       scannedAt,
+		})
+	}
+	for _, rejection := range rejections {
+		sessionID := rejection.SessionID
+		events = append(events, RosterEvent{
+			Type:       RosterEventRejected,
+			ScheduleID: schedule.ID,
+			SessionID:  &sessionID,
+			UserID:     rejection.UserID,
+			Nama:       profileByUser[rejection.UserID].Nama,
+			NIM:        profileByUser[rejection.UserID].NIM,
+			Reason:     rejection.Reason,
+			Message:    rejection.Message,
+			Time:       rejection.CreatedAt,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	return &LiveRosterSnapshot{
+		ScheduleID: schedule.ID,
+		Counts:     counts,
+		Events:     events,
+	}, nil
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/qrcode"
+)
+
+// attestationClockSkew is how far in the future an attestation may be issued, covering controller clock drift
+const attestationClockSkew = time.Minute
+
+// CampusNetworkPolicy holds how the network of a work check-in is verified
+type CampusNetworkPolicy struct {
+	Prefixes          []netip.Prefix // Campus address ranges
+	BSSIDs            []string       // Campus access points, normalized
+	AttestationKey    string         // Shared with the Wi-Fi controller, empty disables attestations
+	AttestationMaxAge time.Duration  // Oldest attestation accepted
+}
+
+// CampusNetwork parses the campus address ranges and access points.
+// Ranges may be CIDRs or single addresses.
+func CampusNetwork(cidrs, bssids []string, attestationKey string, attestationMaxAge time.Duration) (CampusNetworkPolicy, error) {
+	policy := CampusNetworkPolicy{
+		AttestationKey:    attestationKey,
+		AttestationMaxAge: attestationMaxAge,
+	}
+
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return policy, fmt.Errorf("invalid campus range %q: %w", cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		policy.Prefixes = append(policy.Prefixes, prefix.Masked())
+	}
+
+	for _, bssid := range bssids {
+		policy.BSSIDs = append(policy.BSSIDs, normalizeBSSID(bssid))
+	}
+
+	return policy, nil
+}
+
+// WifiAttestation is a statement signed by the campus Wi-Fi controller that the user's device
+// is associated to an access point. IssuedAt is RFC 3339.
+type WifiAttestation struct {
+	BSSID     string `json:"bssid" binding:"required"`
+	IssuedAt  string `json:"issued_at" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// normalizeBSSID lowercases a BSSID and uses colons as separators
+func normalizeBSSID(bssid string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(bssid)), "-", ":")
+}
+
+// wifiAttestationPayload builds the message signed by the Wi-Fi controller.
+// The user ID is part of it so an attestation cannot be handed to someone else.
+func wifiAttestationPayload(userID string, attestation WifiAttestation) string {
+	return strings.Join([]string{"wifi-attestation", userID, normalizeBSSID(attestation.BSSID), attestation.IssuedAt}, "\n")
+}
+
+// verifyWifiAttestation checks an attestation is signed for the user, recent and names a campus access point
+func (p CampusNetworkPolicy) verifyWifiAttestation(userID string, attestation *WifiAttestation, now time.Time) bool {
+	if attestation == nil || p.AttestationKey == "" {
+		return false
+	}
+
+	expected := qrcode.HMAC([]byte(p.AttestationKey), wifiAttestationPayload(userID, *attestation))
+	if !hmac.Equal([]byte(attestation.Signature), []byte(expected)) {
+		return false
+	}
+
+	issuedAt, err := time.Parse(time.RFC3339, attestation.IssuedAt)
+	if err != nil || issuedAt.After(now.Add(attestationClockSkew)) || now.Sub(issuedAt) > p.AttestationMaxAge {
+		return false
+	}
+
+	return slices.Contains(p.BSSIDs, normalizeBSSID(attestation.BSSID))
+}
+
+// isCampusIP reports whether an address falls in a campus range
+func (p CampusNetworkPolicy) isCampusIP(ip string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range p.Prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyNetwork decides the network a check-in came from.
+// A verified Wi-Fi attestation wins over the client IP, its BSSID is returned with the verdict.
+func (p CampusNetworkPolicy) classifyNetwork(userID, clientIP string, attestation *WifiAttestation, now time.Time) (models.NetworkVerdict, *string) {
+	if p.verifyWifiAttestation(userID, attestation, now) {
+		bssid := normalizeBSSID(attestation.BSSID)
+		return models.NetworkVerdictCampusWiFi, &bssid
+	}
+	if p.isCampusIP(clientIP) {
+		return models.NetworkVerdictCampusNetwork, nil
+	}
+	return models.NetworkVerdictOffCampus, nil
+}
+
+// onCampusNetwork reports whether a verdict places the device on the campus network
+func onCampusNetwork(verdict models.NetworkVerdict) *bool {
+	onCampus := verdict != models.NetworkVerdictOffCampus
+	return &onCampus
+}
+
+// geofenceID returns the ID of a geofence, nil when there is none
+func geofenceID(geofence *models.Geofence) *string {
+	if geofence == nil {
+		return nil
+	}
+	return &geofence.ID
+}
+
+// checkInRequirementMet reports whether a check-in satisfies a unit requirement
+func checkInRequirementMet(requirement models.CheckInRequirementType, onCampusNetwork, inGeofence bool) bool {
+	switch requirement {
+	case models.CheckInRequireCampusNetwork:
+		return onCampusNetwork
+	case models.CheckInRequireGeofence:
+		return inGeofence
+	case models.CheckInRequireNetworkOrGeofence:
+		return onCampusNetwork || inGeofence
+	case models.CheckInRequireNetworkAndGeofence:
+		return onCampusNetwork && inGeofence
+	default:
+		return true
+	}
+}
+
+// checkInRequirementMessage explains a requirement that was not met
+func checkInRequirementMessage(requirement models.CheckInRequirementType) string {
+	switch requirement {
+	case models.CheckInRequireCampusNetwork:
+		return "check-in requires the campus network"
+	case models.CheckInRequireGeofence:
+		return "check-in requires a location inside a campus area"
+	case models.CheckInRequireNetworkOrGeofence:
+		return "check-in requires the campus network or a location inside a campus area"
+	default:
+		return "check-in requires the campus network and a location inside a campus area"
+	}
+}
+
+// locateGeofence finds the active geofence a located event falls in, nil when unlocated or outside all of them
+func (s *AttendanceService) locateGeofence(ctx context.Context, lat, lng *float64) *models.Geofence {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	geofence, err := s.locationRepo.CheckLocationInGeofence(ctx, *lat, *lng)
+	if err != nil {
+		return nil
+	}
+	return geofence
+}
+
+// enforceCheckInRequirement refuses a check-in that does not meet the requirement of the user's unit
+func (s *AttendanceService) enforceCheckInRequirement(ctx context.Context, userID string, verdict models.NetworkVerdict, geofence *models.Geofence) error {
+	unit, err := s.repo.GetUserUnit(ctx, userID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to resolve unit", err)
+	}
+	if unit == "" {
+		return nil
+	}
+
+	requirement, err := s.repo.GetCheckInRequirementByUnit(ctx, unit)
+	if err != nil {
+		return apperrors.NewInternalError("failed to get check-in requirement", err)
+	}
+	if requirement == nil {
+		return nil
+	}
+
+	if !checkInRequirementMet(requirement.Requirement, verdict != models.NetworkVerdictOffCampus, geofence != nil) {
+		return apperrors.NewForbiddenError(checkInRequirementMessage(requirement.Requirement))
+	}
+	return nil
+}
+
+// SetCheckInRequirementRequest represents set check-in requirement request
+type SetCheckInRequirementRequest struct {
+	Unit        string `json:"unit" binding:"required"`
+	Requirement string `json:"requirement" binding:"required,oneof=NONE CAMPUS_NETWORK GEOFENCE NETWORK_OR_GEOFENCE NETWORK_AND_GEOFENCE"`
+}
+
+// SetCheckInRequirement sets the check-in requirement of a unit, replacing the current one
+func (s *AttendanceService) SetCheckInRequirement(ctx context.Context, userID string, req SetCheckInRequirementRequest) (*models.CheckInRequirement, error) {
+	unit := strings.TrimSpace(req.Unit)
+	if unit == "" {
+		return nil, apperrors.NewValidationError("unit is required")
+	}
+
+	requirement, err := s.repo.GetCheckInRequirementByUnit(ctx, unit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get check-in requirement", err)
+	}
+	if requirement == nil {
+		requirement = &models.CheckInRequirement{Unit: unit}
+	}
+	requirement.Requirement = models.CheckInRequirementType(req.Requirement)
+	requirement.UpdatedBy = userID
+
+	if err := s.repo.SaveCheckInRequirement(ctx, requirement); err != nil {
+		return nil, apperrors.NewInternalError("failed to save check-in requirement", err)
+	}
+
+	return requirement, nil
+}
+
+// GetCheckInRequirements gets the check-in requirements of all units
+func (s *AttendanceService) GetCheckInRequirements(ctx context.Context) ([]models.CheckInRequirement, error) {
+	requirements, err := s.repo.GetCheckInRequirements(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get check-in requirements", err)
+	}
+	return requirements, nil
+}
+
+// DeleteCheckInRequirement removes a unit requirement, the unit then requires nothing
+func (s *AttendanceService) DeleteCheckInRequirement(ctx context.Context, id string) error {
+	if _, err := s.repo.GetCheckInRequirementByID(ctx, id); err != nil {
+		return apperrors.NewNotFoundError("check-in requirement", id)
+	}
+
+	if err := s.repo.DeleteCheckInRequirement(ctx, id); err != nil {
+		return apperrors.NewInternalError("failed to delete check-in requirement", err)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/qrcode"
+)
+
+// ScanConfig holds the settings used to verify scans and check-ins
+type ScanConfig struct {
+	QRSigningKey       string              // Shared with the QR service to sign attendance QR payloads
+	ClockSkewTolerance time.Duration       // Max difference between a syncing device's clock and the server
+	MaxOfflineAge      time.Duration       // Oldest offline capture accepted on sync
+	DeviceBinding      string              // Device binding mode: off, monitor or enforce
+	Anomaly            AnomalyPolicy       // Location anomaly engine thresholds
+	WorkLocation       *time.Location      // Time zone of work days and shift windows
+	CampusNetwork      CampusNetworkPolicy // How work check-ins are verified to come from campus
+	Kiosk              KioskPolicy         // Kiosk QR sessions for work check-ins
+	Sweep              SweepPolicy         // Daily sweep of the previous work day
+}
+
+// OfflineScanReason represents why an offline scan was not recorded
+type OfflineScanReason string
+
+const (
+	OfflineReasonDeviceKeyMissing OfflineScanReason = "DEVICE_KEY_NOT_REGISTERED"
+	OfflineReasonInvalidSignature OfflineScanReason = "INVALID_SIGNATURE"
+	OfflineReasonInvalidQR        OfflineScanReason = "INVALID_QR"
+	OfflineReasonInvalidCapture   OfflineScanReason = "INVALID_CAPTURE_TIME"
+	OfflineReasonClockSkew        OfflineScanReason = "CLOCK_SKEW"
+	OfflineReasonCaptureTooOld    OfflineScanReason = "CAPTURE_TOO_OLD"
+	OfflineReasonCapturedInFuture OfflineScanReason = "CAPTURED_IN_FUTURE"
+	OfflineReasonSessionNotValid  OfflineScanReason = "SESSION_NOT_VALID"
+	OfflineReasonAlreadyRecorded  OfflineScanReason = "ALREADY_RECORDED"
+	OfflineReasonAttendanceClosed OfflineScanReason = "ATTENDANCE_CLOSED"
+	OfflineReasonRefused          OfflineScanReason = "REFUSED"
+	OfflineReasonError            OfflineScanReason = "ERROR"
+)
+
+// OfflinePolicyResponse represents how a device signs offline captures and the limits applied on sync
+type OfflinePolicyResponse struct {
+	Algorithm                 string `json:"algorithm"`
+	DeviceID                  string `json:"device_id,omitempty"` // Bound device whose key is registered
+	KeyRegistered             bool   `json:"key_registered"`
+	ClockSkewToleranceSeconds int    `json:"clock_skew_tolerance_seconds"`
+	MaxOfflineAgeSeconds      int    `json:"max_offline_age_seconds"`
+}
+
+// OfflineScanItem represents a scan captured while the device was offline.
+// Signature is the Ed25519 signature (base64url) with the private key of the bound device,
+// registered at device binding, of client_id, qr_data, captured_at, latitude, longitude (%.6f)
+// and accuracy (%.1f, empty when missing) joined by newlines.
+type OfflineScanItem struct {
+	ClientID   string   `json:"client_id" binding:"required"`
+	QRData     string   `json:"qr_data" binding:"required"`
+	CapturedAt string   `json:"captured_at" binding:"required"` // RFC3339 on the device clock
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+	Accuracy   *float64 `json:"accuracy,omitempty"` // GPS accuracy in meters
+	Signature  string   `json:"signature" binding:"required"`
+}
+
+// SyncOfflineScansRequest represents a batch of offline scans
+type SyncOfflineScansRequest struct {
+	DeviceTime string            `json:"device_time" binding:"required"` // RFC3339 on the device clock when sending
+	DeviceID   string            `json:"device_id" binding:"required"`   // Bound device that signed the captures
+	Scans      []OfflineScanItem `json:"scans" binding:"required,min=1,max=100,dive"`
+}
+
+// OfflineScanResult represents the outcome of one offline scan
+type OfflineScanResult struct {
+	ClientID     string            `json:"client_id"`
+	Accepted     bool              `json:"accepted"`
+	AttendanceID string            `json:"attendance_id,omitempty"`
+	Status       string            `json:"status,omitempty"`
+	CorrectionID string            `json:"correction_request_id,omitempty"` // Sent to the lecturer, the meeting closed before the sync
+	CapturedAt   *time.Time        `json:"captured_at,omitempty"`           // Capture time on the server clock
+	Reason       OfflineScanReason `json:"reason,omitempty"`
+	Message      string            `json:"message"`
+}
+
+// SyncOfflineScansResponse represents offline sync response
+type SyncOfflineScansResponse struct {
+	ClockSkewSeconds float64             `json:"clock_skew_seconds"`
+	Accepted         int                 `json:"accepted"`
+	Rejected         int                 `json:"rejected"`
+	Results          []OfflineScanResult `json:"results"`
+}
+
+// offlineScanPayload builds the message signed by the device for an offline scan
+func offlineScanPayload(item OfflineScanItem) string {
+	format := func(v *float64, verb string) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf(verb, *v)
+	}
+	return strings.Join([]string{
+		item.ClientID, item.QRData, item.CapturedAt,
+		format(item.Latitude, "%.6f"), format(item.Longitude, "%.6f"), format(item.Accuracy, "%.1f"),
+	}, "\n")
+}
+
+// parseDevicePublicKey decodes a base64url Ed25519 public key registered for a device
+func parseDevicePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// verifyOfflineScan checks the device signature of an offline scan against the device's public key
+func verifyOfflineScan(publicKey ed25519.PublicKey, item OfflineScanItem) bool {
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(item.Signature, "="))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(publicKey, []byte(offlineScanPayload(item)), signature)
+}
+
+// checkOfflineCapture converts a capture time from the device clock to the server clock
+// and checks it is neither in the future nor older than maxAge
+func checkOfflineCapture(capturedAt time.Time, skew time.Duration, now time.Time, tolerance, maxAge time.Duration) (time.Time, OfflineScanReason) {
+	captured := capturedAt.Add(skew)
+	if captured.After(now.Add(tolerance)) {
+		return captured, OfflineReasonCapturedInFuture
+	}
+	if now.Sub(captured) > maxAge {
+		return captured, OfflineReasonCaptureTooOld
+	}
+	return captured, ""
+}
+
+// sessionValidAt reports whether a session's QR code could be scanned at the given time.
+// A session deactivated before the capture, e.g. rotated after a scan, no longer counts.
+func sessionValidAt(session *models.AttendanceSession, at time.Time) bool {
+	if at.Before(session.CreatedAt) || at.After(session.ExpiresAt) {
+		return false
+	}
+	return session.IsActive || !session.UpdatedAt.Before(at)
+}
+
+// GetOfflinePolicy gets how the user's device signs offline captures and whether its key is registered.
+// The private key never leaves the device, the server only holds the public key registered at binding.
+func (s *AttendanceService) GetOfflinePolicy(ctx context.Context, userID string) (*OfflinePolicyResponse, error) {
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+
+	response := &OfflinePolicyResponse{
+		Algorithm:                 "Ed25519",
+		ClockSkewToleranceSeconds: int(s.scan.ClockSkewTolerance.Seconds()),
+		MaxOfflineAgeSeconds:      int(s.scan.MaxOfflineAge.Seconds()),
+	}
+	if binding != nil {
+		response.DeviceID = binding.DeviceID
+		response.KeyRegistered = binding.PublicKey != ""
+	}
+	return response, nil
+}
+
+// offlineSigningKey gets the public key of the bound device that signed a batch of offline captures.
+// Returns nil when the device is not the account's bound device or has no registered key.
+func (s *AttendanceService) offlineSigningKey(ctx context.Context, userID, deviceID string) (ed25519.PublicKey, error) {
+	binding, err := s.repo.GetActiveDeviceBinding(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get device binding", err)
+	}
+	if binding == nil || binding.DeviceID != deviceID || binding.PublicKey == "" {
+		return nil, nil
+	}
+
+	key, err := parseDevicePublicKey(binding.PublicKey)
+	if err != nil {
+		return nil, nil
+	}
+	return key, nil
+}
+
+// SyncOfflineScans records scans captured while the device was offline.
+// Each scan is checked against the session at its capture time rather than its arrival time.
+func (s *AttendanceService) SyncOfflineScans(ctx context.Context, userID, role string, req SyncOfflineScansRequest) (*SyncOfflineScansResponse, error) {
+	deviceTime, err := time.Parse(time.RFC3339Nano, req.DeviceTime)
+	if err != nil {
+		return nil, apperrors.NewBadRequestError("invalid device_time format, use RFC3339")
+	}
+
+	now := time.Now()
+	skew := now.Sub(deviceTime)
+	response := &SyncOfflineScansResponse{
+		ClockSkewSeconds: skew.Seconds(),
+		Results:          make([]OfflineScanResult, 0, len(req.Scans)),
+	}
+
+	deviceKey, err := s.offlineSigningKey(ctx, userID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	clockRejected := skew > s.scan.ClockSkewTolerance || -skew > s.scan.ClockSkewTolerance
+	for _, item := range req.Scans {
+		var result OfflineScanResult
+		switch {
+		case deviceKey == nil:
+			result = OfflineScanResult{
+				ClientID: item.ClientID,
+				Reason:   OfflineReasonDeviceKeyMissing,
+				Message:  "offline scans must be signed by your bound device with its registered key",
+			}
+		case clockRejected:
+			result = OfflineScanResult{
+				ClientID: item.ClientID,
+				Reason:   OfflineReasonClockSkew,
+				Message:  fmt.Sprintf("device clock is %.0f seconds off the server clock", skew.Seconds()),
+			}
+		default:
+			result = s.syncOfflineScan(ctx, userID, role, deviceKey, req.DeviceID, skew, now, item)
+		}
+
+		if result.Accepted {
+			response.Accepted++
+		} else {
+			response.Rejected++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// syncOfflineScan checks and records one offline scan
+func (s *AttendanceService) syncOfflineScan(ctx context.Context, userID, role string, deviceKey ed25519.PublicKey, deviceID string, skew time.Duration, now time.Time, item OfflineScanItem) OfflineScanResult {
+	result := OfflineScanResult{ClientID: item.ClientID}
+	reject := func(reason OfflineScanReason, message string) OfflineScanResult {
+		result.Reason, result.Message = reason, message
+		return result
+	}
+
+	if !verifyOfflineScan(deviceKey, item) {
+		return reject(OfflineReasonInvalidSignature, "device signature is invalid")
+	}
+
+	capturedAt, err := time.Parse(time.RFC3339Nano, item.CapturedAt)
+	if err != nil {
+		return reject(OfflineReasonInvalidCapture, "invalid captured_at format, use RFC3339")
+	}
+
+	captured, reason := checkOfflineCapture(capturedAt, skew, now, s.scan.ClockSkewTolerance, s.scan.MaxOfflineAge)
+	result.CapturedAt = &captured
+	switch reason {
+	case OfflineReasonCapturedInFuture:
+		return reject(reason, "capture time is in the future")
+	case OfflineReasonCaptureTooOld:
+		return reject(reason, "capture is too old to be synced")
+	}
+
+	qrData, err := qrcode.ParseQRData(item.QRData)
+	if err != nil {
+		return reject(OfflineReasonInvalidQR, "invalid QR code data")
+	}
+	if !qrcode.Verify(qrData, []byte(s.scan.QRSigningKey)) {
+		return reject(OfflineReasonInvalidQR, "QR code signature is invalid")
+	}
+
+	session, err := s.repo.GetSessionByID(ctx, qrData.SessionID)
+	if err != nil || !sessionValidAt(session, captured) {
+		return reject(OfflineReasonSessionNotValid, "QR code was not valid at capture time")
+	}
+
+	scan, err := s.recordScan(ctx, userID, role, session, captured, ScanQRRequest{
+		QRData:    item.QRData,
+		Latitude:  item.Latitude,
+		Longitude: item.Longitude,
+		Accuracy:  item.Accuracy,
+		DeviceID:  deviceID,
+	}, true)
+	if err != nil {
+		message := err.Error()
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) {
+			return reject(OfflineReasonError, message)
+		}
+		message = appErr.Message
+		switch appErr.Code {
+		case apperrors.ErrCodeForbidden:
+			return reject(OfflineReasonRefused, message)
+		case apperrors.ErrCodeConflict:
+			return reject(OfflineReasonAlreadyRecorded, message)
+		case apperrors.ErrCodeBadRequest:
+			return reject(OfflineReasonAttendanceClosed, message)
+		}
+		return reject(OfflineReasonError, message)
+	}
+
+	result.Accepted = true
+	result.AttendanceID = scan.AttendanceID
+	result.CorrectionID = scan.CorrectionRequestID
+	result.Status = scan.Status
+	result.Message = scan.Message
+	return result
+}
+
+// submitClosedScanReview sends an offline scan captured before its meeting closed to the lecturer.
+// The absence marked on closing is never overwritten by a sync, the scan becomes a correction
+// request the class dosen approves or rejects; any record other than that absence means a duplicate.
+func (s *AttendanceService) submitClosedScanReview(ctx context.Context, attendance *models.Attendance) (*models.AttendanceCorrectionRequest, error) {
+	scheduleID := *attendance.ScheduleID
+	existing, err := s.repo.GetAttendanceByUserAndSchedule(ctx, attendance.UserID, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check attendance", err)
+	}
+	if existing != nil && (existing.Status != models.StatusAlpa || existing.SessionID != nil) {
+		return nil, apperrors.NewConflictError("attendance already recorded for this meeting")
+	}
+
+	pending, err := s.repo.GetPendingCorrectionRequest(ctx, attendance.UserID, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check correction requests", err)
+	}
+	if pending != nil {
+		return nil, apperrors.NewConflictError("a correction request for this meeting is already pending")
+	}
+
+	request := &models.AttendanceCorrectionRequest{
+		UserID:          attendance.UserID,
+		ScheduleID:      scheduleID,
+		SessionID:       attendance.SessionID,
+		RequestedStatus: attendance.Status,
+		Reason:          fmt.Sprintf("Offline scan captured at %s, synced after the meeting closed", attendance.CheckInTime.Format(time.RFC3339)),
+		Status:          models.CorrectionStatusPending,
+	}
+	if existing != nil {
+		request.AttendanceID = &existing.ID
+	}
+
+	if err := s.repo.CreateCorrectionRequest(ctx, request); err != nil {
+		return nil, apperrors.NewInternalError("failed to create correction request", err)
+	}
+
+	return request, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/geo"
+)
+
+// defaultLocationToleranceMeters is added to the room area when the class does not set its own tolerance
+const defaultLocationToleranceMeters = 25.0
+
+// roomArea represents the circular area a class room occupies
+type roomArea struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters float64
+}
+
+// resolveRoomArea finds the area of the room a meeting is held in.
+// Returns nil when the room is unknown or has neither a geofence nor a footprint.
+func (s *AttendanceService) resolveRoomArea(ctx context.Context, schedule *models.Schedule, class *models.Class) (*roomArea, error) {
+	var room *models.Room
+	var err error
+
+	switch {
+	case class != nil && class.RoomID != nil:
+		room, err = s.masterDataRepo.GetRoomByID(ctx, *class.RoomID)
+	case schedule.Room != "":
+		room, err = s.masterDataRepo.GetRoomByCode(ctx, schedule.Room)
+	case class != nil && class.Room != "":
+		room, err = s.masterDataRepo.GetRoomByCode(ctx, class.Room)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		// Free-text room names that do not match a room are not verified
+		return nil, nil
+	}
+
+	if room.GeofenceID != nil {
+		geofence, err := s.locationRepo.GetGeofenceByID(ctx, *room.GeofenceID)
+		if err == nil && geofence.IsActive {
+			return &roomArea{
+				Latitude:     geofence.Latitude,
+				Longitude:    geofence.Longitude,
+				RadiusMeters: geofence.Radius,
+			}, nil
+		}
+	}
+
+	if room.Latitude != nil && room.Longitude != nil && room.RadiusMeters != nil {
+		return &roomArea{
+			Latitude:     *room.Latitude,
+			Longitude:    *room.Longitude,
+			RadiusMeters: *room.RadiusMeters,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// classLocationTolerance returns the tolerance in meters configured for a class
+func classLocationTolerance(class *models.Class) float64 {
+	if class != nil && class.LocationToleranceMeters != nil {
+		return *class.LocationToleranceMeters
+	}
+	return defaultLocationToleranceMeters
+}
+
+// checkRoomLocation returns the distance from the room area center and whether it is within the area plus tolerance
+func checkRoomLocation(area *roomArea, toleranceMeters float64, latitude, longitude float64) (float64, bool) {
+	distance := geo.Distance(area.Latitude, area.Longitude, latitude, longitude)
+	return distance, distance <= area.RadiusMeters+toleranceMeters
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"unsri-backend/internal/attendance/repository"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// CloseAttendanceResponse represents the outcome of closing attendance for a meeting
+type CloseAttendanceResponse struct {
+	ScheduleID  string    `json:"schedule_id"`
+	ClosedAt    time.Time `json:"closed_at"`
+	MarkedAlpa  int       `json:"marked_alpa"`
+	MarkedIzin  int       `json:"marked_izin"`
+	MarkedSakit int       `json:"marked_sakit"`
+	Warned      int       `json:"warned"` // Students sent an eligibility warning
+}
+
+// CloseScheduleAttendance closes attendance for a meeting, allowed for its dosen and staff.
+// Approved enrollees without a record are marked alpa, or izin/sakit when covered by approved leave.
+func (s *AttendanceService) CloseScheduleAttendance(ctx context.Context, userID string, role string, scheduleID string) (*CloseAttendanceResponse, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	if role != string(models.RoleStaff) && schedule.DosenID != userID {
+		return nil, apperrors.NewForbiddenError("not authorized to close attendance for this schedule")
+	}
+
+	if schedule.AttendanceClosedAt != nil {
+		return nil, apperrors.NewConflictError("attendance for this schedule is already closed")
+	}
+
+	result, err := s.closeSchedule(ctx, schedule, &userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttendanceAlreadyClosed) {
+			return nil, apperrors.NewConflictError("attendance for this schedule is already closed")
+		}
+		return nil, apperrors.NewInternalError("failed to close attendance", err)
+	}
+
+	return result, nil
+}
+
+// CloseExpiredSessions closes attendance for meetings whose class QR sessions have all expired.
+// A meeting that fails to close is logged and left for the next run. Returns the number of meetings closed.
+func (s *AttendanceService) CloseExpiredSessions(ctx context.Context) (int, error) {
+	schedules, err := s.repo.GetSchedulesWithExpiredSessions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range schedules {
+		if _, err := s.closeSchedule(ctx, &schedules[i], nil); err != nil {
+			if !errors.Is(err, repository.ErrAttendanceAlreadyClosed) { // Unless closed by the lecturer in the meantime
+				s.log.Errorf("Failed to close attendance of schedule %s: %v", schedules[i].ID, err)
+			}
+			continue
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
+// closeSchedule generates the missing records and marks the schedule closed
+func (s *AttendanceService) closeSchedule(ctx context.Context, schedule *models.Schedule, closedBy *string) (*CloseAttendanceResponse, error) {
+	class, err := s.resolveScheduleClass(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.buildAbsenceRecords(ctx, schedule, class, closedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule.AttendanceClosedAt = &now
+	schedule.AttendanceClosedBy = closedBy
+
+	if err := s.repo.CloseScheduleAttendance(ctx, schedule, records); err != nil {
+		return nil, err
+	}
+
+	result := &CloseAttendanceResponse{
+		ScheduleID: schedule.ID,
+		ClosedAt:   now,
+	}
+	for _, record := range records {
+		switch record.Status {
+		case models.StatusAlpa:
+			result.MarkedAlpa++
+		case models.StatusIzin:
+			result.MarkedIzin++
+		case models.StatusSakit:
+			result.MarkedSakit++
+		}
+	}
+
+	s.prefillJournalOnClose(ctx, schedule, class)
+
+	// The meeting now counts as held, warn students whose attendance dropped below a level.
+	// Held meetings are counted by class, so legacy schedules without one are left out.
+	if class != nil && schedule.ClassID != nil {
+		warned, err := s.sendEligibilityWarnings(ctx, class)
+		if err != nil {
+			// The attendance is already closed, a failed warning does not undo it
+			s.log.Errorf("Attendance of schedule %s closed but eligibility warnings failed: %v", schedule.ID, err)
+		}
+		result.Warned = warned
+	}
+
+	return result, nil
+}
+
+// buildAbsenceRecords builds records for approved enrollees of the schedule's class who have none
+func (s *AttendanceService) buildAbsenceRecords(ctx context.Context, schedule *models.Schedule, class *models.Class, createdBy *string) ([]models.Attendance, error) {
+	if class == nil {
+		// Legacy schedule without a class, there is no roster to complete
+		return nil, nil
+	}
+
+	enrollments, err := s.courseRepo.GetEnrollmentsByClassID(ctx, class.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := s.repo.GetAttendanceUserIDsByScheduleID(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := missingEnrollees(enrollments, recorded)
+	leaves, err := s.leaveRepo.GetApprovedLeavesCoveringDate(ctx, missing, schedule.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	return absenceRecords(schedule, missing, leaves, createdBy), nil
+}
+
+// missingEnrollees returns the approved enrollees without an attendance record
+func missingEnrollees(enrollments []models.Enrollment, recordedUserIDs []string) []string {
+	recorded := make(map[string]bool, len(recordedUserIDs))
+	for _, id := range recordedUserIDs {
+		recorded[id] = true
+	}
+
+	missing := []string{}
+	for _, enrollment := range enrollments {
+		if enrollment.Status != "APPROVED" || recorded[enrollment.StudentID] {
+			continue
+		}
+		recorded[enrollment.StudentID] = true
+		missing = append(missing, enrollment.StudentID)
+	}
+	return missing
+}
+
+// absenceRecords builds alpa records for the missing students, or izin/sakit when their approved leave covers the meeting
+func absenceRecords(schedule *models.Schedule, missing []string, leaves []models.LeaveRequest, createdBy *string) []models.Attendance {
+	leaveByUser := make(map[string]models.LeaveRequest, len(leaves))
+	for _, leave := range leaves {
+		leaveByUser[leave.UserID] = leave
+	}
+
+	records := make([]models.Attendance, 0, len(missing))
+	for _, userID := range missing {
+		status := models.StatusAlpa
+		notes := "Marked absent when attendance was closed"
+		if leave, ok := leaveByUser[userID]; ok {
+			status = models.StatusIzin
+			if leave.LeaveType == models.LeaveTypeSick {
+				status = models.StatusSakit
+			}
+			notes = "Covered by approved leave request " + leave.ID
+		}
+
+		records = append(records, models.Attendance{
+			UserID:     userID,
+			ScheduleID: &schedule.ID,
+			Type:       models.AttendanceTypeKelas,
+			Status:     status,
+			Date:       schedule.Date,
+			Notes:      notes,
+			CreatedBy:  createdBy,
+		})
+	}
+	return records
+}
@@ -3,10 +3,21 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"unsri-backend/internal/attendance/repository"
+	calendarRepo "unsri-backend/internal/calendar/repository"
+	courseRepo "unsri-backend/internal/course/repository"
+	fileRepo "unsri-backend/internal/file-storage/repository"
+	leaveRepo "unsri-backend/internal/leave/repository"
+	locationRepo "unsri-backend/internal/location/repository"
+	masterDataRepo "unsri-backend/internal/master-data/repository"
+	notificationRepo "unsri-backend/internal/notification/repository"
+	"unsri-backend/internal/shared/conflict"
 	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/logger"
 	"unsri-backend/internal/shared/models"
 	"unsri-backend/pkg/jwt"
 	"unsri-backend/pkg/qrcode"
@@ -14,23 +25,44 @@ import (
 
 // AttendanceService handles attendance business logic
 type AttendanceService struct {
-	repo *repository.AttendanceRepository
-	jwt  *jwt.JWT
+	repo             *repository.AttendanceRepository
+	courseRepo       *courseRepo.CourseRepository
+	masterDataRepo   *masterDataRepo.MasterDataRepository
+	locationRepo     *locationRepo.LocationRepository
+	leaveRepo        *leaveRepo.LeaveRepository
+	calendarRepo     *calendarRepo.CalendarRepository // Holidays for timesheets
+	fileRepo         *fileRepo.FileRepository
+	notificationRepo *notificationRepo.NotificationRepository
+	redis            *redis.Client // Live roster fan-out across replicas
+	scan             ScanConfig
+	jwt              *jwt.JWT
+	log              logger.Logger // Failures of best effort steps
 }
 
 // NewAttendanceService creates a new attendance service
-func NewAttendanceService(repo *repository.AttendanceRepository, jwtToken *jwt.JWT) *AttendanceService {
+func NewAttendanceService(repo *repository.AttendanceRepository, courseRepo *courseRepo.CourseRepository, masterDataRepo *masterDataRepo.MasterDataRepository, locationRepo *locationRepo.LocationRepository, leaveRepo *leaveRepo.LeaveRepository, calendarRepo *calendarRepo.CalendarRepository, fileRepo *fileRepo.FileRepository, notificationRepo *notificationRepo.NotificationRepository, redisClient *redis.Client, scanConfig ScanConfig, jwtToken *jwt.JWT, log logger.Logger) *AttendanceService {
 	return &AttendanceService{
-		repo: repo,
-		jwt:  jwtToken,
+		repo:             repo,
+		courseRepo:       courseRepo,
+		masterDataRepo:   masterDataRepo,
+		locationRepo:     locationRepo,
+		leaveRepo:        leaveRepo,
+		calendarRepo:     calendarRepo,
+		fileRepo:         fileRepo,
+		notificationRepo: notificationRepo,
+		redis:            redisClient,
+		scan:             scanConfig,
+		jwt:              jwtToken,
+		log:              log,
 	}
 }
 
 // GenerateQRRequest represents request to generate QR code
 type GenerateQRRequest struct {
-	ScheduleID *string `json:"schedule_id,omitempty"`
-	Type       string  `json:"type" binding:"required,oneof=kelas kampus"`
-	Duration   int     `json:"duration"` // Duration in minutes, default 15
+	ScheduleID  *string `json:"schedule_id,omitempty"`
+	Type        string  `json:"type" binding:"required,oneof=kelas kampus"`
+	Duration    int     `json:"duration"`               // Duration in minutes, default 15
+	AllowGuests bool    `json:"allow_guests,omitempty"` // Accept scans from users not enrolled in the class
 }
 
 // GenerateQRResponse represents QR code generation response
@@ -50,14 +82,30 @@ func (s *AttendanceService) GenerateQRCode(ctx context.Context, userID string, r
 		duration = req.Duration
 	}
 
+	var schedule *models.Schedule
+	if req.ScheduleID != nil {
+		var err error
+		schedule, err = s.repo.GetScheduleByID(ctx, *req.ScheduleID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("schedule", *req.ScheduleID)
+		}
+		if schedule.AttendanceClosedAt != nil {
+			return nil, apperrors.NewBadRequestError("attendance for this schedule has been closed")
+		}
+		if schedule.CancelledAt != nil {
+			return nil, apperrors.NewBadRequestError("this meeting has been cancelled")
+		}
+	}
+
 	expiresAt := time.Now().Add(time.Duration(duration) * time.Minute)
 
 	session := &models.AttendanceSession{
-		ScheduleID: req.ScheduleID,
-		CreatedBy:  userID,
-		Type:       models.AttendanceType(req.Type),
-		ExpiresAt:  expiresAt,
-		IsActive:   true,
+		ScheduleID:  req.ScheduleID,
+		CreatedBy:   userID,
+		Type:        models.AttendanceType(req.Type),
+		ExpiresAt:   expiresAt,
+		AllowGuests: req.AllowGuests,
+		IsActive:    true,
 	}
 
 	if err := s.repo.CreateSession(ctx, session); err != nil {
@@ -76,6 +124,8 @@ func (s *AttendanceService) GenerateQRCode(ctx context.Context, userID string, r
 		qrData.ScheduleID = *req.ScheduleID
 	}
 
+	qrcode.Sign(&qrData, []byte(s.scan.QRSigningKey))
+
 	// Generate QR code image
 	qrImage, err := qrcode.GenerateQRCode(qrData)
 	if err != nil {
@@ -88,6 +138,10 @@ func (s *AttendanceService) GenerateQRCode(ctx context.Context, userID string, r
 	// Ignore error, QR code already generated
 	_ = s.repo.UpdateSession(ctx, session)
 
+	if schedule != nil {
+		s.prefillJournalOnOpen(ctx, schedule, session)
+	}
+
 	return &GenerateQRResponse{
 		SessionID: session.ID,
 		QRCode:    string(qrImage), // In production, return base64 or URL
@@ -100,23 +154,31 @@ type ScanQRRequest struct {
 	QRData    string   `json:"qr_data" binding:"required"`
 	Latitude  *float64 `json:"latitude,omitempty"`
 	Longitude *float64 `json:"longitude,omitempty"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`  // GPS accuracy in meters
+	DeviceID  string   `json:"device_id,omitempty"` // Installation identifier of the scanning device
 }
 
 // ScanQRResponse represents QR scan response
 type ScanQRResponse struct {
-	AttendanceID string `json:"attendance_id"`
-	Status       string `json:"status"`
-	Message      string `json:"message"`
+	AttendanceID        string `json:"attendance_id"`
+	Status              string `json:"status"`
+	IsGuest             bool   `json:"is_guest,omitempty"`
+	CorrectionRequestID string `json:"correction_request_id,omitempty"` // Offline scan of a closed meeting, sent to the lecturer
+	Message             string `json:"message"`
 }
 
 // ScanQRCode scans a QR code and records attendance
-func (s *AttendanceService) ScanQRCode(ctx context.Context, userID string, req ScanQRRequest) (*ScanQRResponse, error) {
+func (s *AttendanceService) ScanQRCode(ctx context.Context, userID string, role string, req ScanQRRequest) (*ScanQRResponse, error) {
 	// Parse QR data
 	qrData, err := qrcode.ParseQRData(req.QRData)
 	if err != nil {
 		return nil, apperrors.NewBadRequestError("invalid QR code data")
 	}
 
+	if !qrcode.Verify(qrData, []byte(s.scan.QRSigningKey)) {
+		return nil, apperrors.NewBadRequestError("QR code signature is invalid")
+	}
+
 	// Get session
 	session, err := s.repo.GetSessionByID(ctx, qrData.SessionID)
 	if err != nil {
@@ -128,8 +190,160 @@ func (s *AttendanceService) ScanQRCode(ctx context.Context, userID string, req S
 		return nil, apperrors.NewBadRequestError("QR code has expired")
 	}
 
+	return s.recordScan(ctx, userID, role, session, time.Now(), req, false)
+}
+
+// recordScan checks a scan of a valid session made at scannedAt and records the attendance.
+// Offline scans are synced after the fact: one captured before its meeting closed is sent to the
+// lecturer as a correction request, and they leave the session active for the students still scanning it.
+func (s *AttendanceService) recordScan(ctx context.Context, userID string, role string, session *models.AttendanceSession, scannedAt time.Time, req ScanQRRequest, offline bool) (*ScanQRResponse, error) {
+	date := scannedAt
+	status := models.StatusHadir
+	closedAfterScan := false
+
+	// Check the scanning device against the account's bound device
+	deviceReason, err := s.verifyDevice(ctx, userID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if deviceReason != "" {
+		if session.Type == models.AttendanceTypeKelas && session.ScheduleID != nil {
+			return nil, s.refuseScan(ctx, newScanRejection(session, nil, userID, deviceReason, deviceRejectionMessage(deviceReason)), req)
+		}
+		return nil, apperrors.NewForbiddenError(deviceRejectionMessage(deviceReason))
+	}
+
+	// Evaluate the scan location against the user's other located events
+	anomalies, err := s.detectAnomalies(ctx, userID, locationEvent(models.LocationSourceAttendance, req.Latitude, req.Longitude, req.Accuracy, scannedAt))
+	if err != nil {
+		return nil, err
+	}
+	if s.anomaliesBlock(anomalies) {
+		s.saveAnomalies(ctx, anomalies, nil)
+		if session.Type == models.AttendanceTypeKelas && session.ScheduleID != nil {
+			return nil, s.refuseScan(ctx, newScanRejection(session, nil, userID, models.RejectionLocationAnomaly, anomalyMessage(anomalies)), req)
+		}
+		return nil, apperrors.NewForbiddenError(anomalyMessage(anomalies))
+	}
+
+	// Class attendance is restricted to students enrolled in the class behind the schedule
+	isGuest := false
+	var distanceMeters *float64
+	var locationValid *bool
+	var classID *string
+	if session.Type == models.AttendanceTypeKelas && session.ScheduleID != nil {
+		schedule, err := s.repo.GetScheduleByID(ctx, *session.ScheduleID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("schedule", *session.ScheduleID)
+		}
+
+		if schedule.AttendanceClosedAt != nil {
+			if !offline || !scannedAt.Before(*schedule.AttendanceClosedAt) {
+				return nil, apperrors.NewBadRequestError("attendance for this meeting has been closed")
+			}
+			closedAfterScan = true
+		}
+		if schedule.CancelledAt != nil {
+			return nil, apperrors.NewBadRequestError("this meeting has been cancelled")
+		}
+
+		class, err := s.resolveScheduleClass(ctx, schedule)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to resolve class for schedule", err)
+		}
+
+		if class != nil {
+			classID = &class.ID
+			guest, rejection, err := s.checkScanEligibility(ctx, userID, role, class, session)
+			if err != nil {
+				return nil, apperrors.NewInternalError("failed to check enrollment", err)
+			}
+			if rejection != nil {
+				return nil, s.refuseScan(ctx, rejection, req)
+			}
+			isGuest = guest
+		}
+
+		// Verify the student is in the class room, hybrid meetings are measured but not enforced
+		if schedule.MeetingMode != models.MeetingModeOnline {
+			area, err := s.resolveRoomArea(ctx, schedule, class)
+			if err != nil {
+				return nil, apperrors.NewInternalError("failed to resolve room area", err)
+			}
+
+			if area != nil && req.Latitude != nil && req.Longitude != nil {
+				distance, valid := checkRoomLocation(area, classLocationTolerance(class), *req.Latitude, *req.Longitude)
+				distanceMeters, locationValid = &distance, &valid
+			}
+
+			if area != nil && schedule.MeetingMode != models.MeetingModeHybrid {
+				if locationValid == nil {
+					rejection := newScanRejection(session, class, userID, models.RejectionLocationRequired,
+						"location is required to record attendance for this class")
+					return nil, s.refuseScan(ctx, rejection, req)
+				}
+				if !*locationValid {
+					rejection := newScanRejection(session, class, userID, models.RejectionOutsideRoomArea,
+						fmt.Sprintf("you are %.0f meters away from the class room", *distanceMeters))
+					rejection.DistanceMeters = distanceMeters
+					return nil, s.refuseScan(ctx, rejection, req)
+				}
+			}
+		}
+
+		// Classify the scan against the schedule start time
+		policy, err := s.resolveLatenessPolicy(ctx, schedule, class)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to resolve lateness policy", err)
+		}
+
+		var refused bool
+		status, refused = classifyScan(policy, s.meetingStartAt(schedule), date)
+		if refused {
+			rejection := newScanRejection(session, class, userID, models.RejectionCutoffPassed,
+				"attendance scanning for this meeting has closed")
+			return nil, s.refuseScan(ctx, rejection, req)
+		}
+	}
+
+	// Create attendance record
+	attendance := &models.Attendance{
+		UserID:         userID,
+		SessionID:      &session.ID,
+		ScheduleID:     session.ScheduleID,
+		Type:           session.Type,
+		Status:         status,
+		Date:           date,
+		CheckInTime:    &date,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		AccuracyMeters: req.Accuracy,
+		IsGuest:        isGuest,
+		DistanceMeters: distanceMeters,
+		LocationValid:  locationValid,
+		DeviceID:       optionalDeviceID(req.DeviceID),
+	}
+
+	if closedAfterScan {
+		request, err := s.submitClosedScanReview(ctx, attendance)
+		if err != nil {
+			return nil, err
+		}
+		s.saveAnomalies(ctx, anomalies, request.AttendanceID)
+
+		response := &ScanQRResponse{
+			Status:              string(attendance.Status),
+			IsGuest:             attendance.IsGuest,
+			CorrectionRequestID: request.ID,
+			Message:             "Meeting closed before the sync, scan sent to the lecturer for review",
+		}
+		if request.AttendanceID != nil {
+			response.AttendanceID = *request.AttendanceID
+		}
+		return response, nil
+	}
+
 	// Check if attendance already exists
-	date := time.Now()
 	exists, err := s.repo.CheckAttendanceExists(ctx, userID, date, session.ScheduleID)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to check attendance", err)
@@ -139,38 +353,170 @@ func (s *AttendanceService) ScanQRCode(ctx context.Context, userID string, req S
 		return nil, apperrors.NewConflictError("attendance already recorded for today")
 	}
 
-	// Create attendance record
-	attendance := &models.Attendance{
-		UserID:      userID,
-		SessionID:   &session.ID,
-		ScheduleID:  session.ScheduleID,
-		Type:        session.Type,
-		Status:      models.StatusHadir,
-		Date:        date,
-		CheckInTime: &date,
-		Latitude:    req.Latitude,
-		Longitude:   req.Longitude,
-	}
-
 	if err := s.repo.CreateAttendance(ctx, attendance); err != nil {
 		return nil, apperrors.NewInternalError("failed to record attendance", err)
 	}
+	s.saveAnomalies(ctx, anomalies, &attendance.ID)
 
 	// If this is a class attendance QR, deactivate the session so QR will regenerate
 	// The QR service will handle regeneration when generating new QR for the schedule
 	if session.Type == models.AttendanceTypeKelas && session.ScheduleID != nil {
-		session.IsActive = false
-		// Deactivate so new QR can be generated
-		_ = s.repo.UpdateSession(ctx, session)
+		if !offline {
+			session.IsActive = false
+			// Deactivate so new QR can be generated
+			_ = s.repo.UpdateSession(ctx, session)
+		}
+
+		s.flagClassDevice(ctx, *session.ScheduleID, classID, date, attendance.DeviceID)
+		s.publishRosterEvent(ctx, RosterEvent{
+			Type:       RosterEventAccepted,
+			ScheduleID: *session.ScheduleID,
+			SessionID:  &session.ID,
+			UserID:     userID,
+			Status:     attendance.Status,
+			IsGuest:    attendance.IsGuest,
+			Time:       date,
+		})
+	}
+
+	message := "Attendance recorded successfully"
+	switch attendance.Status {
+	case models.StatusTerlambat:
+		message = "Attendance recorded as late"
+	case models.StatusAlpa:
+		message = "Scan recorded after the late window, marked as absent"
 	}
 
 	return &ScanQRResponse{
 		AttendanceID: attendance.ID,
 		Status:       string(attendance.Status),
-		Message:      "Attendance recorded successfully",
+		IsGuest:      attendance.IsGuest,
+		Message:      message,
 	}, nil
 }
 
+// newScanRejection builds a scan rejection for a class session
+func newScanRejection(session *models.AttendanceSession, class *models.Class, userID string, reason models.ScanRejectionReason, message string) *models.AttendanceScanRejection {
+	rejection := &models.AttendanceScanRejection{
+		SessionID:  session.ID,
+		ScheduleID: session.ScheduleID,
+		UserID:     userID,
+		Reason:     reason,
+		Message:    message,
+	}
+	if class != nil {
+		rejection.ClassID = &class.ID
+	}
+	return rejection
+}
+
+// refuseScan stores a scan rejection for lecturer review and returns the error for the student
+func (s *AttendanceService) refuseScan(ctx context.Context, rejection *models.AttendanceScanRejection, req ScanQRRequest) error {
+	rejection.Latitude = req.Latitude
+	rejection.Longitude = req.Longitude
+	rejection.DeviceID = optionalDeviceID(req.DeviceID)
+	// Ignore error, the scan is refused either way
+	_ = s.repo.CreateScanRejection(ctx, rejection)
+
+	if rejection.ScheduleID != nil {
+		s.flagClassDevice(ctx, *rejection.ScheduleID, rejection.ClassID, time.Now(), rejection.DeviceID)
+		s.publishRosterEvent(ctx, RosterEvent{
+			Type:       RosterEventRejected,
+			ScheduleID: *rejection.ScheduleID,
+			SessionID:  &rejection.SessionID,
+			UserID:     rejection.UserID,
+			Reason:     rejection.Reason,
+			Message:    rejection.Message,
+			Time:       time.Now(),
+		})
+	}
+
+	return apperrors.NewForbiddenError(rejection.Message)
+}
+
+// resolveScheduleClass finds the class behind a schedule.
+// Returns nil when the schedule is not linked to any class (legacy schedules).
+func (s *AttendanceService) resolveScheduleClass(ctx context.Context, schedule *models.Schedule) (*models.Class, error) {
+	if schedule.ClassID != nil {
+		return s.courseRepo.GetClassByID(ctx, *schedule.ClassID)
+	}
+
+	if schedule.CourseID == nil {
+		return nil, nil
+	}
+
+	// Fall back to the dosen's class of the course held on the same day
+	classes, _, err := s.courseRepo.GetAllClasses(ctx, schedule.CourseID, &schedule.DosenID, nil, 50, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range classes {
+		if classes[i].IsActive && classes[i].DayOfWeek == schedule.DayOfWeek {
+			return &classes[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkScanEligibility checks that the user may record attendance for the class.
+// Returns whether the user attends as a guest, or the rejection to record when refused.
+func (s *AttendanceService) checkScanEligibility(ctx context.Context, userID string, role string, class *models.Class, session *models.AttendanceSession) (bool, *models.AttendanceScanRejection, error) {
+	reason, message := models.ScanRejectionReason(""), ""
+
+	if role != string(models.RoleMahasiswa) {
+		reason, message = models.RejectionNotStudent, "only students enrolled in this class can record attendance"
+	} else {
+		enrollment, err := s.courseRepo.GetEnrollmentByStudentAndClass(ctx, userID, class.ID)
+		if err != nil {
+			return false, nil, err
+		}
+
+		switch {
+		case enrollment == nil:
+			reason, message = models.RejectionNotEnrolled, "you are not enrolled in this class"
+		case enrollment.Status == "APPROVED":
+			return false, nil, nil
+		case enrollment.Status == "DROPPED":
+			reason, message = models.RejectionEnrollmentDropped, "your enrollment in this class has been dropped"
+		default:
+			reason, message = models.RejectionEnrollmentNotApproved, "your enrollment in this class is not approved"
+		}
+	}
+
+	if session.AllowGuests {
+		return true, nil, nil
+	}
+
+	return false, &models.AttendanceScanRejection{
+		SessionID:  session.ID,
+		ScheduleID: session.ScheduleID,
+		ClassID:    &class.ID,
+		UserID:     userID,
+		Reason:     reason,
+		Message:    message,
+	}, nil
+}
+
+// GetScanRejections gets refused scans for a schedule, visible to its dosen and staff
+func (s *AttendanceService) GetScanRejections(ctx context.Context, userID string, role string, scheduleID string) ([]models.AttendanceScanRejection, error) {
+	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
+	}
+
+	if role != string(models.RoleStaff) && schedule.DosenID != userID {
+		return nil, apperrors.NewForbiddenError("not authorized to view rejections for this schedule")
+	}
+
+	rejections, err := s.repo.GetScanRejectionsByScheduleID(ctx, scheduleID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get scan rejections", err)
+	}
+
+	return rejections, nil
+}
+
 // GetAttendancesRequest represents request to get attendances
 type GetAttendancesRequest struct {
 	UserID    *string `form:"user_id"`
@@ -258,7 +604,14 @@ func (s *AttendanceService) CreateManualAttendance(ctx context.Context, createdB
 		CreatedBy:  &createdBy,
 	}
 
-	if err := s.repo.CreateAttendance(ctx, attendance); err != nil {
+	changeLog := &models.AttendanceChangeLog{
+		ChangedBy: createdBy,
+		Source:    models.ChangeSourceManual,
+		NewStatus: attendance.Status,
+		Notes:     req.Notes,
+	}
+
+	if err := s.repo.SaveAttendanceWithLog(ctx, attendance, changeLog); err != nil {
 		return nil, apperrors.NewInternalError("failed to create attendance", err)
 	}
 
@@ -272,18 +625,27 @@ type UpdateAttendanceRequest struct {
 }
 
 // UpdateAttendance updates an attendance record
-func (s *AttendanceService) UpdateAttendance(ctx context.Context, attendanceID string, req UpdateAttendanceRequest) (*models.Attendance, error) {
+func (s *AttendanceService) UpdateAttendance(ctx context.Context, changedBy string, attendanceID string, req UpdateAttendanceRequest) (*models.Attendance, error) {
 	attendance, err := s.repo.GetAttendanceByID(ctx, attendanceID)
 	if err != nil {
 		return nil, apperrors.NewNotFoundError("attendance", attendanceID)
 	}
 
+	oldStatus := attendance.Status
 	attendance.Status = models.AttendanceStatus(req.Status)
 	if req.Notes != "" {
 		attendance.Notes = req.Notes
 	}
 
-	if err := s.repo.UpdateAttendance(ctx, attendance); err != nil {
+	changeLog := &models.AttendanceChangeLog{
+		ChangedBy: changedBy,
+		Source:    models.ChangeSourceUpdate,
+		OldStatus: &oldStatus,
+		NewStatus: attendance.Status,
+		Notes:     req.Notes,
+	}
+
+	if err := s.repo.SaveAttendanceWithLog(ctx, attendance, changeLog); err != nil {
 		return nil, apperrors.NewInternalError("failed to update attendance", err)
 	}
 
@@ -395,6 +757,8 @@ func (s *AttendanceService) GetAttendanceOverview(ctx context.Context, userID st
 type TapInRequest struct {
 	Latitude  *float64 `json:"latitude,omitempty"`
 	Longitude *float64 `json:"longitude,omitempty"`
+	Accuracy  *float64 `json:"accuracy,omitempty"` // GPS accuracy in meters
+	DeviceID  string   `json:"device_id,omitempty"`
 }
 
 // TapIn performs tap in for campus attendance
@@ -409,20 +773,39 @@ func (s *AttendanceService) TapIn(ctx context.Context, userID string, req TapInR
 		return nil, apperrors.NewConflictError("already tapped in today")
 	}
 
+	deviceReason, err := s.verifyDevice(ctx, userID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if deviceReason != "" {
+		return nil, apperrors.NewForbiddenError(deviceRejectionMessage(deviceReason))
+	}
+
 	now := time.Now()
+	anomalies, err := s.detectAnomalies(ctx, userID, locationEvent(models.LocationSourceAttendance, req.Latitude, req.Longitude, req.Accuracy, now))
+	if err != nil {
+		return nil, err
+	}
+	if s.anomaliesBlock(anomalies) {
+		return nil, s.blockAnomalies(ctx, anomalies)
+	}
+
 	attendance := &models.Attendance{
-		UserID:      userID,
-		Type:        models.AttendanceTypeKampus,
-		Status:      models.StatusHadir,
-		Date:        now,
-		CheckInTime: &now,
-		Latitude:    req.Latitude,
-		Longitude:   req.Longitude,
+		UserID:         userID,
+		Type:           models.AttendanceTypeKampus,
+		Status:         models.StatusHadir,
+		Date:           now,
+		CheckInTime:    &now,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		AccuracyMeters: req.Accuracy,
+		DeviceID:       optionalDeviceID(req.DeviceID),
 	}
 
 	if err := s.repo.CreateAttendance(ctx, attendance); err != nil {
 		return nil, apperrors.NewInternalError("failed to record tap in", err)
 	}
+	s.saveAnomalies(ctx, anomalies, &attendance.ID)
 
 	return attendance, nil
 }
@@ -457,19 +840,22 @@ func (s *AttendanceService) TapOut(ctx context.Context, userID string, req TapIn
 
 // CreateScheduleRequest represents request to create schedule
 type CreateScheduleRequest struct {
-	CourseID   *string `json:"course_id,omitempty"`
-	CourseCode string  `json:"course_code"`
-	CourseName string  `json:"course_name"`
-	DosenID    string  `json:"dosen_id" binding:"required"`
-	Room       string  `json:"room"`
-	DayOfWeek  int     `json:"day_of_week" binding:"required,min=0,max=6"`
-	StartTime  string  `json:"start_time" binding:"required"`
-	EndTime    string  `json:"end_time" binding:"required"`
-	Date       string  `json:"date" binding:"required"`
+	CourseID          *string `json:"course_id,omitempty"`
+	ClassID           *string `json:"class_id,omitempty"`
+	CourseCode        string  `json:"course_code"`
+	CourseName        string  `json:"course_name"`
+	DosenID           string  `json:"dosen_id" binding:"required"`
+	Room              string  `json:"room"`
+	DayOfWeek         int     `json:"day_of_week" binding:"required,min=0,max=6"`
+	StartTime         string  `json:"start_time" binding:"required"`
+	EndTime           string  `json:"end_time" binding:"required"`
+	Date              string  `json:"date" binding:"required"`
+	MeetingMode       string  `json:"meeting_mode,omitempty" binding:"omitempty,oneof=offline online hybrid"`
+	OverrideConflicts bool    `json:"override_conflicts,omitempty"` // Staff only: create despite room or lecturer conflicts
 }
 
 // CreateSchedule creates a new schedule
-func (s *AttendanceService) CreateSchedule(ctx context.Context, req CreateScheduleRequest) (*models.Schedule, error) {
+func (s *AttendanceService) CreateSchedule(ctx context.Context, role string, req CreateScheduleRequest) (*models.Schedule, error) {
 	startTime, err := time.Parse("15:04", req.StartTime)
 	if err != nil {
 		return nil, apperrors.NewValidationError("invalid start_time format, use HH:MM")
@@ -490,16 +876,26 @@ func (s *AttendanceService) CreateSchedule(ctx context.Context, req CreateSchedu
 	endDateTime := time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location())
 
 	schedule := &models.Schedule{
-		CourseID:   req.CourseID,
-		CourseCode: req.CourseCode,
-		CourseName: req.CourseName,
-		DosenID:    req.DosenID,
-		Room:       req.Room,
-		DayOfWeek:  req.DayOfWeek,
-		StartTime:  startDateTime,
-		EndTime:    endDateTime,
-		Date:       date,
-		IsActive:   true,
+		CourseID:    req.CourseID,
+		ClassID:     req.ClassID,
+		CourseCode:  req.CourseCode,
+		CourseName:  req.CourseName,
+		DosenID:     req.DosenID,
+		Room:        req.Room,
+		DayOfWeek:   req.DayOfWeek,
+		StartTime:   startDateTime,
+		EndTime:     endDateTime,
+		Date:        date,
+		MeetingMode: models.MeetingModeOffline,
+		IsActive:    true,
+	}
+	if req.MeetingMode != "" {
+		schedule.MeetingMode = models.MeetingMode(req.MeetingMode)
+	}
+
+	class, _ := s.resolveScheduleClass(ctx, schedule)
+	if err := conflict.Check(ctx, s.repo, conflict.MeetingSlot(schedule, class), req.OverrideConflicts, role); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
@@ -551,16 +947,18 @@ func (s *AttendanceService) GetSchedules(ctx context.Context, req GetSchedulesRe
 
 // UpdateScheduleRequest represents request to update schedule
 type UpdateScheduleRequest struct {
-	CourseCode *string `json:"course_code,omitempty"`
-	CourseName *string `json:"course_name,omitempty"`
-	Room       *string `json:"room,omitempty"`
-	StartTime  *string `json:"start_time,omitempty"`
-	EndTime    *string `json:"end_time,omitempty"`
-	IsActive   *bool   `json:"is_active,omitempty"`
+	CourseCode        *string `json:"course_code,omitempty"`
+	CourseName        *string `json:"course_name,omitempty"`
+	Room              *string `json:"room,omitempty"`
+	StartTime         *string `json:"start_time,omitempty"`
+	EndTime           *string `json:"end_time,omitempty"`
+	MeetingMode       *string `json:"meeting_mode,omitempty" binding:"omitempty,oneof=offline online hybrid"`
+	IsActive          *bool   `json:"is_active,omitempty"`
+	OverrideConflicts bool    `json:"override_conflicts,omitempty"` // Staff only: update despite room or lecturer conflicts
 }
 
 // UpdateSchedule updates a schedule
-func (s *AttendanceService) UpdateSchedule(ctx context.Context, scheduleID string, req UpdateScheduleRequest) (*models.Schedule, error) {
+func (s *AttendanceService) UpdateSchedule(ctx context.Context, role string, scheduleID string, req UpdateScheduleRequest) (*models.Schedule, error) {
 	schedule, err := s.repo.GetScheduleByID(ctx, scheduleID)
 	if err != nil {
 		return nil, apperrors.NewNotFoundError("schedule", scheduleID)
@@ -591,10 +989,22 @@ func (s *AttendanceService) UpdateSchedule(ctx context.Context, scheduleID strin
 		schedule.EndTime = time.Date(schedule.Date.Year(), schedule.Date.Month(), schedule.Date.Day(),
 			endTime.Hour(), endTime.Minute(), 0, 0, schedule.Date.Location())
 	}
+	if req.MeetingMode != nil {
+		schedule.MeetingMode = models.MeetingMode(*req.MeetingMode)
+	}
 	if req.IsActive != nil {
 		schedule.IsActive = *req.IsActive
 	}
 
+	// Only a change of room, time or mode, or a reactivation, can make a meeting collide
+	moved := req.Room != nil || req.StartTime != nil || req.EndTime != nil || req.MeetingMode != nil || (req.IsActive != nil && *req.IsActive)
+	if moved && schedule.IsActive && schedule.CancelledAt == nil {
+		class, _ := s.resolveScheduleClass(ctx, schedule)
+		if err := conflict.Check(ctx, s.repo, conflict.MeetingSlot(schedule, class), req.OverrideConflicts, role); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
 		return nil, apperrors.NewInternalError("failed to update schedule", err)
 	}
@@ -761,6 +1171,8 @@ type CreateUserShiftRequest struct {
 	ShiftID        string  `json:"shift_id" binding:"required"`
 	EffectiveFrom  string  `json:"effective_from" binding:"required"` // YYYY-MM-DD
 	EffectiveUntil *string `json:"effective_until,omitempty"`         // YYYY-MM-DD
+	Rotation       string  `json:"rotation,omitempty"`                // Shift codes or OFF per day, e.g. "PAGI,PAGI,MALAM,MALAM,OFF,OFF"
+	WorkDays       string  `json:"work_days,omitempty"`               // Weekdays of a fixed shift, 1=Monday to 7=Sunday, e.g. "1,2,3,4,5"
 }
 
 // CreateUserShift creates a new user shift assignment
@@ -780,11 +1192,18 @@ func (s *AttendanceService) CreateUserShift(ctx context.Context, req CreateUserS
 		effectiveUntil = &effUntil
 	}
 
+	rotation, err := s.validateShiftAssignment(ctx, req.Rotation, req.WorkDays)
+	if err != nil {
+		return nil, err
+	}
+
 	userShift := &models.UserShift{
 		UserID:         req.UserID,
 		ShiftID:        req.ShiftID,
 		EffectiveFrom:  effectiveFrom,
 		EffectiveUntil: effectiveUntil,
+		Rotation:       rotation,
+		WorkDays:       req.WorkDays,
 		IsActive:       true,
 	}
 
@@ -841,12 +1260,7 @@ func (s *AttendanceService) CreateWorkSchedule(ctx context.Context, req CreateWo
 	}
 
 	// Get day of week
-	dayOfWeek := int(scheduleDate.Weekday())
-	if dayOfWeek == 0 {
-		dayOfWeek = 7 // Sunday = 7
-	} else {
-		dayOfWeek-- // Monday = 1, Tuesday = 2, etc.
-	}
+	dayOfWeek := isoWeekday(scheduleDate) // Monday = 1, Sunday = 7
 
 	workSchedule := &models.WorkSchedule{
 		UserID:       req.UserID,
@@ -912,57 +1326,116 @@ func (s *AttendanceService) GetWorkSchedules(ctx context.Context, req GetWorkSch
 
 // CheckInRequest represents check-in request
 type CheckInRequest struct {
-	ScheduleID     *string  `json:"schedule_id,omitempty"`
-	Latitude       *float64 `json:"latitude,omitempty"`
-	Longitude      *float64 `json:"longitude,omitempty"`
-	IsViaUNSRIWiFi *bool    `json:"is_via_unsri_wifi,omitempty"`
-	Notes          string   `json:"notes,omitempty"`
+	ScheduleID      *string          `json:"schedule_id,omitempty"`
+	Latitude        *float64         `json:"latitude,omitempty"`
+	Longitude       *float64         `json:"longitude,omitempty"`
+	Accuracy        *float64         `json:"accuracy,omitempty"` // GPS accuracy in meters
+	WifiAttestation *WifiAttestation `json:"wifi_attestation,omitempty"`
+	KioskQR         string           `json:"kiosk_qr,omitempty"` // QR data scanned from a unit kiosk
+	DeviceID        string           `json:"device_id,omitempty"`
+	Notes           string           `json:"notes,omitempty"`
+	ClientIP        string           `json:"-"` // Set by the handler from the gateway, never bound from the body
 }
 
 // CheckIn performs check-in for work attendance
 func (s *AttendanceService) CheckIn(ctx context.Context, userID string, req CheckInRequest) (*models.WorkAttendanceRecord, error) {
 	now := time.Now()
+	loc := s.workLocation()
 
-	// Check if already checked in today
-	existingRecord, err := s.repo.GetTodayWorkAttendanceRecord(ctx, userID, "CHECK_IN")
-	if err == nil && existingRecord != nil {
-		return nil, apperrors.NewConflictError("already checked in today")
-	}
-
-	// Get work schedule if provided
+	// Get the shift the check-in belongs to, a night shift started yesterday may still be open
 	var schedule *models.WorkSchedule
+	var err error
 	if req.ScheduleID != nil {
 		schedule, err = s.repo.GetWorkScheduleByID(ctx, *req.ScheduleID)
 		if err != nil {
 			return nil, apperrors.NewNotFoundError("work schedule", *req.ScheduleID)
 		}
 	} else {
-		// Get today's schedule for user
-		schedules, err := s.repo.GetWorkSchedulesByUserID(ctx, userID, &now, &now)
-		if err == nil && len(schedules) > 0 {
-			schedule = &schedules[0]
+		yesterday := workDay(now, loc).AddDate(0, 0, -1)
+		today := workDay(now, loc)
+		schedules, err := s.repo.GetWorkSchedulesByUserID(ctx, userID, &yesterday, &today)
+		if err == nil {
+			schedule = attributeCheckIn(schedules, now, loc)
 		}
 	}
 
-	// Determine status based on schedule
-	status := models.StatusCheckIn
+	workDate := workDay(now, loc)
 	if schedule != nil {
-		scheduleStartTime := time.Date(now.Year(), now.Month(), now.Day(),
-			schedule.StartTime.Hour(), schedule.StartTime.Minute(), 0, 0, now.Location())
-		if now.After(scheduleStartTime.Add(15 * time.Minute)) {
-			status = models.StatusLateIn
+		workDate = workDay(schedule.ScheduleDate, time.UTC)
+	}
+
+	// Check if already checked in for the work day
+	existingRecord, err := s.repo.GetWorkAttendanceRecordByWorkDate(ctx, userID, workDate, "CHECK_IN")
+	if err == nil && existingRecord != nil {
+		return nil, apperrors.NewConflictError("already checked in for this work day")
+	}
+
+	deviceReason, err := s.verifyDevice(ctx, userID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if deviceReason != "" {
+		return nil, apperrors.NewForbiddenError(deviceRejectionMessage(deviceReason))
+	}
+
+	anomalies, err := s.detectAnomalies(ctx, userID, locationEvent(models.LocationSourceWorkAttendance, req.Latitude, req.Longitude, req.Accuracy, now))
+	if err != nil {
+		return nil, err
+	}
+	if s.anomaliesBlock(anomalies) {
+		return nil, s.blockAnomalies(ctx, anomalies)
+	}
+
+	kiosk, err := s.verifyKioskScan(ctx, userID, req.KioskQR, now)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict, bssid := s.scan.CampusNetwork.classifyNetwork(userID, req.ClientIP, req.WifiAttestation, now)
+	geofence := s.locateGeofence(ctx, req.Latitude, req.Longitude)
+	// A kiosk scan is recorded on top of, never instead of, the unit's requirement
+	if err := s.enforceCheckInRequirement(ctx, userID, verdict, geofence); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.resolveWorkPolicy(ctx, userID, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve work attendance policy", err)
+	}
+	if schedule != nil && policy.RefuseOutsideWindow {
+		opens, closes := checkInWindow(policy, schedule, loc)
+		if now.Before(opens) || now.After(closes) {
+			return nil, apperrors.NewForbiddenError("check-in is outside the allowed window for this shift")
 		}
 	}
+	effectiveAt := roundWorkTime(policy, now)
+	policyID, policyVersion := workPolicyRef(policy)
+
+	var scheduleID *string
+	if schedule != nil {
+		scheduleID = &schedule.ID
+	}
 
 	record := &models.WorkAttendanceRecord{
-		ScheduleID:     req.ScheduleID,
+		SessionID:      kioskSessionID(kiosk),
+		ScheduleID:     scheduleID,
 		UserID:         userID,
 		AttendanceType: "CHECK_IN",
 		RecordedAt:     now,
-		Status:         status,
-		IsViaUNSRIWiFi: req.IsViaUNSRIWiFi,
+		WorkDate:       &workDate,
+		Status:         checkInStatus(policy, schedule, effectiveAt, loc),
+		EffectiveAt:    &effectiveAt,
+		PolicyID:       policyID,
+		PolicyVersion:  policyVersion,
+		IsViaUNSRIWiFi: onCampusNetwork(verdict),
+		NetworkVerdict: verdict,
+		ClientIP:       req.ClientIP,
+		WifiBSSID:      bssid,
 		Latitude:       req.Latitude,
 		Longitude:      req.Longitude,
+		AccuracyMeters: req.Accuracy,
+		GeofenceID:     geofenceID(geofence),
+		DeviceID:       optionalDeviceID(req.DeviceID),
 		Notes:          req.Notes,
 	}
 
@@ -970,32 +1443,61 @@ func (s *AttendanceService) CheckIn(ctx context.Context, userID string, req Chec
 		return nil, apperrors.NewInternalError("failed to create check-in record", err)
 	}
 
+	s.flagWorkDevice(ctx, now, record.DeviceID)
+	s.saveAnomalies(ctx, anomalies, &record.ID)
+
 	return record, nil
 }
 
 // CheckOutRequest represents check-out request
 type CheckOutRequest struct {
-	ScheduleID     *string  `json:"schedule_id,omitempty"`
-	Latitude       *float64 `json:"latitude,omitempty"`
-	Longitude      *float64 `json:"longitude,omitempty"`
-	IsViaUNSRIWiFi *bool    `json:"is_via_unsri_wifi,omitempty"`
-	Notes          string   `json:"notes,omitempty"`
+	ScheduleID      *string          `json:"schedule_id,omitempty"`
+	Latitude        *float64         `json:"latitude,omitempty"`
+	Longitude       *float64         `json:"longitude,omitempty"`
+	Accuracy        *float64         `json:"accuracy,omitempty"` // GPS accuracy in meters
+	WifiAttestation *WifiAttestation `json:"wifi_attestation,omitempty"`
+	KioskQR         string           `json:"kiosk_qr,omitempty"` // QR data scanned from a unit kiosk
+	DeviceID        string           `json:"device_id,omitempty"`
+	Notes           string           `json:"notes,omitempty"`
+	ClientIP        string           `json:"-"` // Set by the handler from the gateway, never bound from the body
 }
 
 // CheckOut performs check-out for work attendance
 func (s *AttendanceService) CheckOut(ctx context.Context, userID string, req CheckOutRequest) (*models.WorkAttendanceRecord, error) {
 	now := time.Now()
+	loc := s.workLocation()
 
-	// Check if checked in today
-	checkInRecord, err := s.repo.GetTodayWorkAttendanceRecord(ctx, userID, "CHECK_IN")
+	// The check-out closes the latest check-in, which may be on the previous calendar day for night shifts
+	checkInRecord, err := s.repo.GetLatestWorkCheckIn(ctx, userID, now.Add(-maxWorkShiftSpan))
 	if err != nil || checkInRecord == nil {
 		return nil, apperrors.NewValidationError("must check in first before check out")
 	}
 
-	// Check if already checked out today
-	existingRecord, err := s.repo.GetTodayWorkAttendanceRecord(ctx, userID, "CHECK_OUT")
+	workDate := workDay(checkInRecord.RecordedAt, loc)
+	if checkInRecord.WorkDate != nil {
+		workDate = *checkInRecord.WorkDate
+	}
+
+	// Check if already checked out for the work day
+	existingRecord, err := s.repo.GetWorkAttendanceRecordByWorkDate(ctx, userID, workDate, "CHECK_OUT")
 	if err == nil && existingRecord != nil {
-		return nil, apperrors.NewConflictError("already checked out today")
+		return nil, apperrors.NewConflictError("already checked out for this work day")
+	}
+
+	deviceReason, err := s.verifyDevice(ctx, userID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if deviceReason != "" {
+		return nil, apperrors.NewForbiddenError(deviceRejectionMessage(deviceReason))
+	}
+
+	anomalies, err := s.detectAnomalies(ctx, userID, locationEvent(models.LocationSourceWorkAttendance, req.Latitude, req.Longitude, req.Accuracy, now))
+	if err != nil {
+		return nil, err
+	}
+	if s.anomaliesBlock(anomalies) {
+		return nil, s.blockAnomalies(ctx, anomalies)
 	}
 
 	// Get work schedule
@@ -1014,25 +1516,50 @@ func (s *AttendanceService) CheckOut(ctx context.Context, userID string, req Che
 		}
 	}
 
-	// Determine status
-	status := models.StatusCheckOut
-	if schedule != nil {
-		scheduleEndTime := time.Date(now.Year(), now.Month(), now.Day(),
-			schedule.EndTime.Hour(), schedule.EndTime.Minute(), 0, 0, now.Location())
-		if now.Before(scheduleEndTime.Add(-15 * time.Minute)) {
-			status = models.StatusEarlyOut
-		}
+	scheduleID := req.ScheduleID
+	if scheduleID == nil {
+		scheduleID = checkInRecord.ScheduleID
+	}
+
+	kiosk, err := s.verifyKioskScan(ctx, userID, req.KioskQR, now)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict, bssid := s.scan.CampusNetwork.classifyNetwork(userID, req.ClientIP, req.WifiAttestation, now)
+	geofence := s.locateGeofence(ctx, req.Latitude, req.Longitude)
+
+	policy, err := s.resolveWorkPolicy(ctx, userID, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve work attendance policy", err)
+	}
+	effectiveAt := roundWorkTime(policy, now)
+	policyID, policyVersion := workPolicyRef(policy)
+	checkInAt := checkInRecord.RecordedAt
+	if checkInRecord.EffectiveAt != nil {
+		checkInAt = *checkInRecord.EffectiveAt
 	}
 
 	record := &models.WorkAttendanceRecord{
-		ScheduleID:     req.ScheduleID,
+		SessionID:      kioskSessionID(kiosk),
+		ScheduleID:     scheduleID,
 		UserID:         userID,
 		AttendanceType: "CHECK_OUT",
 		RecordedAt:     now,
-		Status:         status,
-		IsViaUNSRIWiFi: req.IsViaUNSRIWiFi,
+		WorkDate:       &workDate,
+		Status:         checkOutStatus(policy, schedule, checkInAt, effectiveAt, loc),
+		EffectiveAt:    &effectiveAt,
+		PolicyID:       policyID,
+		PolicyVersion:  policyVersion,
+		IsViaUNSRIWiFi: onCampusNetwork(verdict),
+		NetworkVerdict: verdict,
+		ClientIP:       req.ClientIP,
+		WifiBSSID:      bssid,
 		Latitude:       req.Latitude,
 		Longitude:      req.Longitude,
+		AccuracyMeters: req.Accuracy,
+		GeofenceID:     geofenceID(geofence),
+		DeviceID:       optionalDeviceID(req.DeviceID),
 		Notes:          req.Notes,
 	}
 
@@ -1040,6 +1567,9 @@ func (s *AttendanceService) CheckOut(ctx context.Context, userID string, req Che
 		return nil, apperrors.NewInternalError("failed to create check-out record", err)
 	}
 
+	s.flagWorkDevice(ctx, now, record.DeviceID)
+	s.saveAnomalies(ctx, anomalies, &record.ID)
+
 	return record, nil
 }
 
@@ -1,12 +1,22 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"unsri-backend/internal/attendance/repository"
 	apperrors "unsri-backend/internal/shared/errors"
 	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/qrcode"
+	"unsri-backend/pkg/spreadsheet"
 )
 
 // Test helper functions
@@ -539,14 +549,14 @@ func TestCheckInRequest(t *testing.T) {
 			req: CheckInRequest{
 				Latitude:        &latitude,
 				Longitude:       &longitude,
-				IsViaUNSRIWiFi:  func() *bool { v := true; return &v }(),
+				ClientIP:        "10.10.1.20",
 			},
 			wantErr: false,
 		},
 		{
 			name: "valid request without location",
 			req: CheckInRequest{
-				IsViaUNSRIWiFi: func() *bool { v := false; return &v }(),
+				ClientIP: "203.0.113.7",
 			},
 			wantErr: false,
 		},
@@ -580,14 +590,14 @@ func TestCheckOutRequest(t *testing.T) {
 			req: CheckOutRequest{
 				Latitude:        &latitude,
 				Longitude:       &longitude,
-				IsViaUNSRIWiFi:  func() *bool { v := true; return &v }(),
+				ClientIP:        "10.10.1.20",
 			},
 			wantErr: false,
 		},
 		{
 			name: "valid request without location",
 			req: CheckOutRequest{
-				IsViaUNSRIWiFi: func() *bool { v := false; return &v }(),
+				ClientIP: "203.0.113.7",
 			},
 			wantErr: false,
 		},
@@ -605,3 +615,1135 @@ func TestCheckOutRequest(t *testing.T) {
 		})
 	}
 }
+
+// Test scan eligibility for users who are not students
+func TestCheckScanEligibilityNonStudent(t *testing.T) {
+	s := &AttendanceService{}
+	class := &models.Class{ID: uuid.New().String()}
+
+	t.Run("rejected without guests", func(t *testing.T) {
+		session := createTestAttendanceSession()
+		guest, rejection, err := s.checkScanEligibility(context.Background(), uuid.New().String(), "dosen", class, session)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if guest {
+			t.Error("Expected non-guest result")
+		}
+		if rejection == nil || rejection.Reason != models.RejectionNotStudent {
+			t.Errorf("Expected %s rejection, got %+v", models.RejectionNotStudent, rejection)
+		}
+		if rejection != nil && (rejection.ClassID == nil || *rejection.ClassID != class.ID) {
+			t.Error("Rejection should reference the class")
+		}
+	})
+
+	t.Run("admitted as guest", func(t *testing.T) {
+		session := createTestAttendanceSession()
+		session.AllowGuests = true
+		guest, rejection, err := s.checkScanEligibility(context.Background(), uuid.New().String(), "staff", class, session)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !guest {
+			t.Error("Expected guest result")
+		}
+		if rejection != nil {
+			t.Errorf("Expected no rejection, got %+v", rejection)
+		}
+	})
+}
+
+// Test AttendanceScanRejection model
+func TestAttendanceScanRejectionModel(t *testing.T) {
+	rejection := models.AttendanceScanRejection{}
+	if rejection.TableName() != "attendance_scan_rejections" {
+		t.Errorf("Expected table name 'attendance_scan_rejections', got '%s'", rejection.TableName())
+	}
+}
+
+// Test classifyScan against grace period, late window and cutoff
+func TestClassifyScan(t *testing.T) {
+	start := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	cutoff := 45
+	policy := &models.LatenessPolicy{GraceMinutes: 10, LateWindowMinutes: 20, CutoffMinutes: &cutoff}
+
+	tests := []struct {
+		name        string
+		offset      time.Duration
+		wantStatus  models.AttendanceStatus
+		wantRefused bool
+	}{
+		{"early", -5 * time.Minute, models.StatusHadir, false},
+		{"within grace", 10 * time.Minute, models.StatusHadir, false},
+		{"late window", 11 * time.Minute, models.StatusTerlambat, false},
+		{"end of late window", 30 * time.Minute, models.StatusTerlambat, false},
+		{"after late window", 31 * time.Minute, models.StatusAlpa, false},
+		{"after cutoff", 46 * time.Minute, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, refused := classifyScan(policy, start, start.Add(tt.offset))
+			if status != tt.wantStatus || refused != tt.wantRefused {
+				t.Errorf("Expected (%s, %v), got (%s, %v)", tt.wantStatus, tt.wantRefused, status, refused)
+			}
+		})
+	}
+
+	// Without a cutoff, scans are never refused
+	status, refused := classifyScan(defaultLatenessPolicy(), start, start.Add(3*time.Hour))
+	if refused || status != models.StatusAlpa {
+		t.Errorf("Expected (alpa, false), got (%s, %v)", status, refused)
+	}
+}
+
+// Test meeting starts are taken in WIB whatever the server's local zone
+func TestMeetingStartAtIgnoresLocalZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	s := &AttendanceService{}
+	schedule := &models.Schedule{
+		Date:      time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		StartTime: time.Date(2000, 1, 1, 8, 0, 0, 0, time.UTC),
+	}
+	wib := time.FixedZone("WIB", 7*60*60)
+
+	start := s.meetingStartAt(schedule)
+	if !start.Equal(time.Date(2024, 1, 15, 8, 0, 0, 0, wib)) {
+		t.Fatalf("Expected the meeting to start at 08:00 WIB, got %v", start)
+	}
+
+	// An online scan at 08:20 WIB, read from a server clock in UTC
+	cutoff := 45
+	policy := &models.LatenessPolicy{GraceMinutes: 10, LateWindowMinutes: 20, CutoffMinutes: &cutoff}
+	scannedAt := time.Date(2024, 1, 15, 8, 20, 0, 0, wib).In(time.Local)
+	if status, refused := classifyScan(policy, start, scannedAt); refused || status != models.StatusTerlambat {
+		t.Errorf("Expected terlambat, got (%s, %v)", status, refused)
+	}
+	if _, refused := classifyScan(policy, start, scannedAt.Add(30*time.Minute)); !refused {
+		t.Error("Expected a scan at 08:50 WIB to pass the cutoff")
+	}
+}
+
+// Test validateLatenessPolicy
+func TestValidateLatenessPolicy(t *testing.T) {
+	cutoff := 5
+	policy := &models.LatenessPolicy{GraceMinutes: 10, CutoffMinutes: &cutoff}
+	if err := validateLatenessPolicy(policy); err == nil {
+		t.Error("Expected error for cutoff inside grace period")
+	}
+
+	policy.CutoffMinutes = nil
+	if err := validateLatenessPolicy(policy); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// Test checkRoomLocation with class tolerance
+func TestCheckRoomLocation(t *testing.T) {
+	area := &roomArea{Latitude: -2.9914, Longitude: 104.7565, RadiusMeters: 30}
+
+	distance, valid := checkRoomLocation(area, classLocationTolerance(nil), -2.9914, 104.7565)
+	if !valid || distance != 0 {
+		t.Errorf("Expected valid location at the center, got distance %.1f valid %v", distance, valid)
+	}
+
+	// About 67 meters north of the center: outside radius plus default tolerance
+	_, valid = checkRoomLocation(area, classLocationTolerance(nil), -2.9908, 104.7565)
+	if valid {
+		t.Error("Expected location outside the room area to be invalid")
+	}
+
+	tolerance := 50.0
+	class := &models.Class{LocationToleranceMeters: &tolerance}
+	_, valid = checkRoomLocation(area, classLocationTolerance(class), -2.9908, 104.7565)
+	if !valid {
+		t.Error("Expected location within the class tolerance to be valid")
+	}
+}
+
+// Test absence records built when attendance closes
+func TestAbsenceRecords(t *testing.T) {
+	schedule := createTestSchedule()
+	enrollments := []models.Enrollment{
+		{StudentID: "present", Status: "APPROVED"},
+		{StudentID: "absent", Status: "APPROVED"},
+		{StudentID: "sick", Status: "APPROVED"},
+		{StudentID: "personal", Status: "APPROVED"},
+		{StudentID: "dropped", Status: "DROPPED"},
+	}
+
+	missing := missingEnrollees(enrollments, []string{"present"})
+	if len(missing) != 3 {
+		t.Fatalf("Expected 3 missing enrollees, got %v", missing)
+	}
+
+	leaves := []models.LeaveRequest{
+		{ID: "leave-1", UserID: "sick", LeaveType: models.LeaveTypeSick},
+		{ID: "leave-2", UserID: "personal", LeaveType: models.LeaveTypePersonal},
+	}
+
+	records := absenceRecords(schedule, missing, leaves, nil)
+	want := map[string]models.AttendanceStatus{
+		"absent":   models.StatusAlpa,
+		"sick":     models.StatusSakit,
+		"personal": models.StatusIzin,
+	}
+	for _, record := range records {
+		if record.Status != want[record.UserID] {
+			t.Errorf("Expected %s for %s, got %s", want[record.UserID], record.UserID, record.Status)
+		}
+		if record.ScheduleID == nil || *record.ScheduleID != schedule.ID {
+			t.Errorf("Record for %s should reference the schedule", record.UserID)
+		}
+	}
+}
+
+// Test who may review correction requests for a schedule
+func TestIsScheduleReviewer(t *testing.T) {
+	schedule := createTestSchedule()
+	assistantID := "assistant-id"
+	class := &models.Class{DosenID: "class-dosen-id", AssistantDosenID: &assistantID}
+
+	if !isScheduleReviewer(schedule, class, "class-dosen-id") {
+		t.Error("Expected the class dosen to be a reviewer")
+	}
+	if !isScheduleReviewer(schedule, class, assistantID) {
+		t.Error("Expected the assistant dosen to be a reviewer")
+	}
+	if isScheduleReviewer(schedule, class, schedule.DosenID) {
+		t.Error("Expected the class to take precedence over the schedule dosen")
+	}
+	if !isScheduleReviewer(schedule, nil, schedule.DosenID) {
+		t.Error("Expected the schedule dosen to review meetings without a class")
+	}
+	if isScheduleReviewer(schedule, nil, "other-id") {
+		t.Error("Expected other users not to be reviewers")
+	}
+}
+
+// Test attendance percentages against eligibility warning levels
+func TestEligibilityWarningLevels(t *testing.T) {
+	levels, err := parseWarningLevels(" 80, 85,80 ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if formatWarningLevels(levels) != "85,80" {
+		t.Errorf("Expected levels 85,80, got %v", levels)
+	}
+	if _, err := parseWarningLevels("85,abc"); err == nil {
+		t.Error("Expected error for an invalid warning level")
+	}
+
+	if percent := attendancePercent(0, 0); percent != 100 {
+		t.Errorf("Expected 100%% before any meeting, got %v", percent)
+	}
+
+	tests := []struct {
+		percent float64
+		level   float64
+		crossed bool
+	}{
+		{percent: 90, crossed: false},
+		{percent: 85, crossed: false},
+		{percent: 84, level: 85, crossed: true},
+		{percent: 78, level: 80, crossed: true},
+		{percent: 70, level: 75, crossed: true},
+	}
+	for _, tt := range tests {
+		level, crossed := crossedWarningLevel(levels, 75, tt.percent)
+		if crossed != tt.crossed || level != tt.level && tt.crossed {
+			t.Errorf("percent %v: expected level %v crossed %v, got %v %v", tt.percent, tt.level, tt.crossed, level, crossed)
+		}
+	}
+
+	policy := &models.EligibilityPolicy{MinAttendancePercent: 80, WarningLevels: "85,80"}
+	if err := validateEligibilityPolicy(policy); err == nil {
+		t.Error("Expected error for a warning level not above the minimum")
+	}
+}
+
+// Test running counts of the live roster
+func TestRosterCounts(t *testing.T) {
+	attendances := []models.Attendance{
+		{UserID: "a", Status: models.StatusHadir},
+		{UserID: "b", Status: models.StatusTerlambat},
+		{UserID: "guest", Status: models.StatusHadir, IsGuest: true},
+	}
+	rejections := []models.AttendanceScanRejection{
+		{UserID: "c", Reason: models.RejectionOutsideRoomArea},
+		{UserID: "c", Reason: models.RejectionOutsideRoomArea},
+		{UserID: "b", Reason: models.RejectionLocationRequired}, // Later accepted
+	}
+
+	counts := rosterCounts(5, attendances, rejections)
+	if counts.Hadir != 2 || counts.Terlambat != 1 || counts.Guests != 1 {
+		t.Errorf("Unexpected status counts: %+v", counts)
+	}
+	if counts.Rejected != 1 {
+		t.Errorf("Expected 1 rejected student, got %d", counts.Rejected)
+	}
+	if counts.Pending != 3 {
+		t.Errorf("Expected 3 pending enrollees, got %d", counts.Pending)
+	}
+}
+
+// Test offline scan signatures and capture time checks
+func TestOfflineScanChecks(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	lat, lng := -2.9914, 104.7565
+	item := OfflineScanItem{ClientID: "1", QRData: "{}", CapturedAt: "2024-01-01T08:00:00Z", Latitude: &lat, Longitude: &lng}
+	item.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(offlineScanPayload(item))))
+
+	registered, err := parseDevicePublicKey(base64.RawURLEncoding.EncodeToString(publicKey))
+	if err != nil {
+		t.Fatalf("Expected the registered key to parse, got %v", err)
+	}
+	if !verifyOfflineScan(registered, item) {
+		t.Error("Expected a valid device signature")
+	}
+	if verifyOfflineScan(otherKey, item) {
+		t.Error("Expected another device's key to be refused")
+	}
+	tampered := item
+	tampered.CapturedAt = "2024-01-01T07:50:00Z"
+	if verifyOfflineScan(registered, tampered) {
+		t.Error("Expected a tampered capture time to be refused")
+	}
+	// A server-side secret signature is no longer accepted
+	forged := item
+	forged.Signature = qrcode.HMAC([]byte("test-key"), offlineScanPayload(item))
+	if verifyOfflineScan(registered, forged) {
+		t.Error("Expected an HMAC signature to be refused")
+	}
+	if _, err := parseDevicePublicKey("c2hvcnQ"); err == nil {
+		t.Error("Expected a short public key to be refused")
+	}
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	captured, reason := checkOfflineCapture(now.Add(-time.Hour), 30*time.Second, now, 2*time.Minute, 24*time.Hour)
+	if reason != "" || !captured.Equal(now.Add(-time.Hour+30*time.Second)) {
+		t.Errorf("Expected capture corrected by the skew, got %v %s", captured, reason)
+	}
+	if _, reason := checkOfflineCapture(now.Add(10*time.Minute), 0, now, 2*time.Minute, 24*time.Hour); reason != OfflineReasonCapturedInFuture {
+		t.Errorf("Expected %s, got %s", OfflineReasonCapturedInFuture, reason)
+	}
+	if _, reason := checkOfflineCapture(now.Add(-48*time.Hour), 0, now, 2*time.Minute, 24*time.Hour); reason != OfflineReasonCaptureTooOld {
+		t.Errorf("Expected %s, got %s", OfflineReasonCaptureTooOld, reason)
+	}
+
+	session := &models.AttendanceSession{
+		CreatedAt: now.Add(-15 * time.Minute),
+		ExpiresAt: now,
+		IsActive:  false,
+		UpdatedAt: now.Add(-5 * time.Minute), // Rotated after a scan
+	}
+	if !sessionValidAt(session, now.Add(-10*time.Minute)) {
+		t.Error("Expected session valid before it was rotated")
+	}
+	if sessionValidAt(session, now.Add(-2*time.Minute)) {
+		t.Error("Expected session not valid after it was rotated")
+	}
+	if sessionValidAt(session, now.Add(-20*time.Minute)) {
+		t.Error("Expected session not valid before it was created")
+	}
+}
+
+// Test device binding decisions
+func TestDeviceCheck(t *testing.T) {
+	bound := &models.DeviceBinding{UserID: "user-1", DeviceID: "phone-1"}
+	friend := &models.DeviceBinding{UserID: "user-2", DeviceID: "phone-2"}
+
+	tests := []struct {
+		name     string
+		mode     string
+		deviceID string
+		binding  *models.DeviceBinding
+		owner    *models.DeviceBinding
+		bind     bool
+		reason   models.ScanRejectionReason
+	}{
+		{name: "off", mode: DeviceBindingOff, deviceID: "phone-9"},
+		{name: "first use binds", mode: DeviceBindingEnforce, deviceID: "phone-1", bind: true},
+		{name: "bound device", mode: DeviceBindingEnforce, deviceID: "phone-1", binding: bound},
+		{name: "other device enforced", mode: DeviceBindingEnforce, deviceID: "phone-2", binding: bound, reason: models.RejectionDeviceNotBound},
+		{name: "other device monitored", mode: DeviceBindingMonitor, deviceID: "phone-2", binding: bound},
+		{name: "friend's device", mode: DeviceBindingEnforce, deviceID: "phone-2", owner: friend, reason: models.RejectionDeviceNotBound},
+		{name: "friend's device monitored", mode: DeviceBindingMonitor, deviceID: "phone-2", owner: friend},
+		{name: "missing device enforced", mode: DeviceBindingEnforce, reason: models.RejectionDeviceRequired},
+		{name: "missing device monitored", mode: DeviceBindingMonitor},
+	}
+	for _, tt := range tests {
+		bind, reason := deviceCheck(tt.mode, "user-1", tt.deviceID, tt.binding, tt.owner)
+		if bind != tt.bind || reason != tt.reason {
+			t.Errorf("%s: expected bind %v reason %q, got %v %q", tt.name, tt.bind, tt.reason, bind, reason)
+		}
+	}
+}
+
+// Test location anomaly detection
+func TestDetectLocationAnomalies(t *testing.T) {
+	policy := AnomalyPolicy{MaxSpeedKmh: 120, MinTravelMeters: 1000, MaxAccuracyMeters: 100, RepeatThreshold: 2}
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	accuracy := 15.0
+
+	// Indralaya campus, about 32 km from Bukit Besar in Palembang
+	indralaya := repository.LocationEvent{Source: models.LocationSourceAttendance, SourceID: "a", Latitude: -3.2195, Longitude: 104.6497, OccurredAt: now.Add(-10 * time.Minute)}
+	palembang := repository.LocationEvent{Source: models.LocationSourceAttendance, Latitude: -2.9851, Longitude: 104.7327, AccuracyMeters: &accuracy, OccurredAt: now}
+
+	anomalies := detectLocationAnomalies(policy, "user-1", palembang, []repository.LocationEvent{indralaya})
+	if len(anomalies) != 1 || anomalies[0].Type != models.AnomalyImpossibleTravel {
+		t.Fatalf("Expected impossible travel, got %+v", anomalies)
+	}
+	if anomalies[0].SpeedKmh == nil || *anomalies[0].SpeedKmh < 120 {
+		t.Errorf("Expected speed above 120 km/h, got %v", anomalies[0].SpeedKmh)
+	}
+
+	indralaya.OccurredAt = now.Add(-2 * time.Hour)
+	if anomalies := detectLocationAnomalies(policy, "user-1", palembang, []repository.LocationEvent{indralaya}); len(anomalies) != 0 {
+		t.Errorf("Expected a plausible trip, got %+v", anomalies)
+	}
+
+	// An offline scan synced after a later event is compared with it too
+	indralaya.OccurredAt = now.Add(5 * time.Minute)
+	if anomalies := detectLocationAnomalies(policy, "user-1", palembang, []repository.LocationEvent{indralaya}); len(anomalies) != 1 {
+		t.Errorf("Expected impossible travel to the next event, got %+v", anomalies)
+	}
+
+	repeated := []repository.LocationEvent{
+		{Latitude: palembang.Latitude, Longitude: palembang.Longitude, OccurredAt: now.Add(-3 * time.Hour)},
+		{Latitude: palembang.Latitude, Longitude: palembang.Longitude, OccurredAt: now.Add(-2 * time.Hour)},
+	}
+	anomalies = detectLocationAnomalies(policy, "user-1", palembang, repeated)
+	if len(anomalies) != 1 || anomalies[0].Type != models.AnomalyRepeatedCoordinates {
+		t.Errorf("Expected repeated coordinates, got %+v", anomalies)
+	}
+
+	poor := 250.0
+	palembang.AccuracyMeters = &poor
+	anomalies = detectLocationAnomalies(policy, "user-1", palembang, nil)
+	if len(anomalies) != 1 || anomalies[0].Type != models.AnomalyPoorAccuracy {
+		t.Errorf("Expected poor accuracy, got %+v", anomalies)
+	}
+}
+
+func TestJournalCounts(t *testing.T) {
+	attendances := []models.Attendance{
+		{Status: models.StatusHadir},
+		{Status: models.StatusTerlambat},
+		{Status: models.StatusAlpa},
+		{Status: models.StatusIzin},
+		{Status: models.StatusHadir, IsGuest: true},
+	}
+
+	present, absent := countJournalAttendance(attendances)
+	if present != 2 || absent != 2 {
+		t.Errorf("Expected 2 present and 2 absent, got %d and %d", present, absent)
+	}
+
+	classID := "class-1"
+	rows := journalCSVRows([]models.LectureJournal{{
+		ClassID:       &classID,
+		MeetingNumber: 3,
+		Topic:         "Normalisasi, bentuk normal ketiga",
+		Status:        models.JournalStatusSubmitted,
+		Schedule:      models.Schedule{CourseCode: "IF201", Date: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)},
+	}})
+	if len(rows) != 2 || len(rows[1]) != len(rows[0]) {
+		t.Fatalf("Expected a header and one row of the same width, got %v", rows)
+	}
+	if rows[1][3] != "3" || rows[1][4] != "2024-09-02" || rows[1][13] != "SUBMITTED" {
+		t.Errorf("Unexpected journal row %v", rows[1])
+	}
+}
+
+func TestMakeUpSchedule(t *testing.T) {
+	classID := "class-1"
+	original := &models.Schedule{
+		ID:          "schedule-1",
+		ClassID:     &classID,
+		CourseName:  "Basis Data",
+		DosenID:     "dosen-1",
+		Room:        "GK1-201",
+		Date:        time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC),
+		StartTime:   time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC),
+		MeetingMode: models.MeetingModeOffline,
+	}
+
+	replacement, err := makeUpSchedule(original, MakeUpMeetingRequest{Date: "2024-09-14", StartTime: "10:00", EndTime: "11:40"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if replacement.ReplacesScheduleID == nil || *replacement.ReplacesScheduleID != original.ID {
+		t.Errorf("Expected the make-up meeting to link the cancelled one")
+	}
+	if replacement.Room != original.Room || replacement.DayOfWeek != int(time.Saturday) {
+		t.Errorf("Expected room %s on Saturday, got %s on %d", original.Room, replacement.Room, replacement.DayOfWeek)
+	}
+	if replacement.StartTime.Hour() != 10 || replacement.EndTime.Minute() != 40 || replacement.StartTime.Day() != 14 {
+		t.Errorf("Unexpected make-up time %v - %v", replacement.StartTime, replacement.EndTime)
+	}
+
+	if _, err := makeUpSchedule(original, MakeUpMeetingRequest{Date: "2024-09-14", StartTime: "10:00", EndTime: "09:00"}); err == nil {
+		t.Errorf("Expected an end before the start to be refused")
+	}
+
+	original.CancellationReason = "Dosen dinas luar kota"
+	title, message := scheduleChangeMessage(original, replacement)
+	if title != "Class rescheduled" || !strings.Contains(message, "14 Sep 2024, 10:00-11:40 in GK1-201") {
+		t.Errorf("Unexpected notification %q: %q", title, message)
+	}
+}
+
+func TestImportRows(t *testing.T) {
+	var buf bytes.Buffer
+	sheet := [][]string{
+		{"No", "NIM", "Nama", "Keterangan"},
+		{"1", "0901", "Andi", "H"},
+		{"2", "0902", "Budi", "sakit"},
+		{"3", "0903", "Citra", "hadir"},
+		{"4", "0901", "Andi", "A"},
+		{"5", "0999", "Dewi", "H"},
+		{"6", "0904", "Eko", "bolos"},
+		{"7", "0905", "Fajar", "I"},
+	}
+	if err := spreadsheet.Write(&buf, spreadsheet.FormatXLSX, "Presensi", sheet); err != nil {
+		t.Fatalf("Unexpected error writing xlsx: %v", err)
+	}
+	cells, err := spreadsheet.Read(buf.Bytes(), spreadsheet.FormatXLSX)
+	if err != nil {
+		t.Fatalf("Unexpected error reading xlsx: %v", err)
+	}
+
+	rows, err := parseImportRows(cells)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rows) != 7 || rows[0].Row != 2 || rows[0].NIM != "0901" || rows[1].Status != "sakit" {
+		t.Fatalf("Unexpected rows %+v", rows)
+	}
+
+	students := map[string]models.Mahasiswa{
+		"0901": {UserID: "u1", NIM: "0901", Nama: "Andi"},
+		"0902": {UserID: "u2", NIM: "0902", Nama: "Budi"},
+		"0903": {UserID: "u3", NIM: "0903", Nama: "Citra"},
+		"0904": {UserID: "u4", NIM: "0904", Nama: "Eko"},
+		"0905": {UserID: "u5", NIM: "0905", Nama: "Fajar"},
+	}
+	enrolled := map[string]bool{"u1": true, "u2": true, "u3": true, "u4": true}
+	existing := map[string]*models.Attendance{
+		"u2": {UserID: "u2", Status: models.StatusAlpa},
+		"u3": {UserID: "u3", Status: models.StatusHadir},
+	}
+
+	results := validateImportRows(rows, students, enrolled, existing)
+	expected := []string{ImportActionCreate, ImportActionUpdate, ImportActionUnchanged, ImportActionInvalid, ImportActionInvalid, ImportActionInvalid, ImportActionInvalid}
+	for i, result := range results {
+		if result.Action != expected[i] {
+			t.Errorf("Row %d: expected %s, got %s (%s)", result.Row, expected[i], result.Action, result.Error)
+		}
+	}
+	if results[0].Status != string(models.StatusHadir) || !strings.Contains(results[3].Error, "row 2") {
+		t.Errorf("Unexpected results %+v", results)
+	}
+
+	if _, err := parseImportRows([][]string{{"Nama", "Status"}}); err == nil {
+		t.Errorf("Expected a header without nim to be refused")
+	}
+}
+
+func TestAttendanceMatrixRows(t *testing.T) {
+	closed := time.Now()
+	meetings := []models.Schedule{
+		{ID: "m1", Date: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), AttendanceClosedAt: &closed},
+		{ID: "m2", Date: time.Date(2024, 9, 9, 0, 0, 0, 0, time.UTC), AttendanceClosedAt: &closed},
+		{ID: "m3", Date: time.Date(2024, 9, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	students := []matrixStudent{{UserID: "u2", NIM: "0902", Nama: "Budi"}, {UserID: "u1", NIM: "0901", Nama: "Andi"}}
+	m1, m2 := "m1", "m2"
+	attendances := []models.Attendance{
+		{UserID: "u1", ScheduleID: &m1, Status: models.StatusHadir},
+		{UserID: "u1", ScheduleID: &m2, Status: models.StatusTerlambat},
+		{UserID: "u2", ScheduleID: &m1, Status: models.StatusSakit},
+		{UserID: "u2", ScheduleID: &m2, Status: models.StatusAlpa},
+	}
+
+	rows := attendanceMatrixRows(meetings, students, attendances)
+	if len(rows) != 3 || rows[0][3] != "1 (02/09)" || rows[0][len(rows[0])-1] != "% Hadir" {
+		t.Fatalf("Unexpected header %v", rows[0])
+	}
+	if strings.Join(rows[1], ",") != "1,0901,Andi,H,T,,2,0,0,0,100.0" {
+		t.Errorf("Unexpected row %v", rows[1])
+	}
+	if strings.Join(rows[2], ",") != "2,0902,Budi,S,A,,0,0,1,1,0.0" {
+		t.Errorf("Unexpected row %v", rows[2])
+	}
+}
+
+func TestNightShiftAttribution(t *testing.T) {
+	wib := WorkLocation("")
+	policy := defaultWorkPolicy()
+	clock := func(hour, minute int) time.Time { return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC) }
+	night := models.WorkSchedule{ID: "night", ScheduleDate: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), StartTime: clock(22, 0), EndTime: clock(6, 0)}
+	day := models.WorkSchedule{ID: "day", ScheduleDate: time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC), StartTime: clock(8, 0), EndTime: clock(16, 0)}
+
+	start, end := shiftWindow(&night, wib)
+	if !end.Equal(start.Add(8*time.Hour)) || end.Day() != 3 {
+		t.Fatalf("Expected the night shift to end the next morning, got %v - %v", start, end)
+	}
+
+	// 22:10 WIB is 15:10 UTC, still 2 September in WIB
+	checkIn := time.Date(2024, 9, 2, 15, 10, 0, 0, time.UTC)
+	schedule := attributeCheckIn([]models.WorkSchedule{night, day}, checkIn, wib)
+	if schedule == nil || schedule.ID != "night" {
+		t.Fatalf("Expected the night shift, got %+v", schedule)
+	}
+	if status := checkInStatus(policy, schedule, checkIn, wib); status != models.StatusCheckIn {
+		t.Errorf("Expected on time, got %s", status)
+	}
+
+	// 00:30 WIB on 3 September, after midnight but within the night shift
+	lateCheckIn := time.Date(2024, 9, 2, 17, 30, 0, 0, time.UTC)
+	if schedule := attributeCheckIn([]models.WorkSchedule{night, day}, lateCheckIn, wib); schedule == nil || schedule.ID != "night" {
+		t.Errorf("Expected a check-in after midnight to count for the night shift, got %+v", schedule)
+	} else if status := checkInStatus(policy, schedule, lateCheckIn, wib); status != models.StatusLateIn {
+		t.Errorf("Expected late, got %s", status)
+	}
+	if got := workDay(lateCheckIn, wib).Format("2006-01-02"); got != "2024-09-03" {
+		t.Errorf("Expected 3 September in WIB, got %s", got)
+	}
+
+	// 05:00 WIB leaving an hour early, 06:05 WIB on time
+	if status := checkOutStatus(policy, &night, checkIn, time.Date(2024, 9, 2, 22, 0, 0, 0, time.UTC), wib); status != models.StatusEarlyOut {
+		t.Errorf("Expected early out, got %s", status)
+	}
+	if status := checkOutStatus(policy, &night, checkIn, time.Date(2024, 9, 2, 23, 5, 0, 0, time.UTC), wib); status != models.StatusCheckOut {
+		t.Errorf("Expected a regular check-out, got %s", status)
+	}
+
+	// 07:00 WIB belongs to the day shift starting at 08:00
+	if schedule := attributeCheckIn([]models.WorkSchedule{night, day}, time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC), wib); schedule == nil || schedule.ID != "day" {
+		t.Errorf("Expected the day shift, got %+v", schedule)
+	}
+}
+
+func TestCampusNetworkVerdict(t *testing.T) {
+	policy, err := CampusNetwork([]string{"10.10.0.0/16", "103.241.4.7"}, []string{"AA-BB-CC-00-11-22"}, "controller-key", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := CampusNetwork([]string{"campus"}, nil, "", 0); err == nil {
+		t.Error("Expected an invalid range to be rejected")
+	}
+
+	now := time.Date(2024, 9, 2, 1, 0, 0, 0, time.UTC)
+	for ip, want := range map[string]models.NetworkVerdict{
+		"10.10.1.20":         models.NetworkVerdictCampusNetwork,
+		"::ffff:103.241.4.7": models.NetworkVerdictCampusNetwork,
+		"203.0.113.7":        models.NetworkVerdictOffCampus,
+		"":                   models.NetworkVerdictOffCampus,
+	} {
+		if verdict, _ := policy.classifyNetwork("u1", ip, nil, now); verdict != want {
+			t.Errorf("IP %q: expected %s, got %s", ip, want, verdict)
+		}
+	}
+
+	attestation := WifiAttestation{BSSID: "aa:bb:cc:00:11:22", IssuedAt: now.Add(-time.Minute).Format(time.RFC3339)}
+	attestation.Signature = qrcode.HMAC([]byte("controller-key"), wifiAttestationPayload("u1", attestation))
+	verdict, bssid := policy.classifyNetwork("u1", "203.0.113.7", &attestation, now)
+	if verdict != models.NetworkVerdictCampusWiFi || bssid == nil || *bssid != "aa:bb:cc:00:11:22" {
+		t.Errorf("Expected a verified campus Wi-Fi attestation, got %s", verdict)
+	}
+	if verdict, _ := policy.classifyNetwork("u2", "203.0.113.7", &attestation, now); verdict != models.NetworkVerdictOffCampus {
+		t.Error("Expected an attestation signed for another user to be ignored")
+	}
+	if verdict, _ := policy.classifyNetwork("u1", "203.0.113.7", &attestation, now.Add(10*time.Minute)); verdict != models.NetworkVerdictOffCampus {
+		t.Error("Expected an expired attestation to be ignored")
+	}
+
+	if !checkInRequirementMet(models.CheckInRequireNetworkOrGeofence, false, true) {
+		t.Error("Expected a geofence to satisfy NETWORK_OR_GEOFENCE")
+	}
+	if checkInRequirementMet(models.CheckInRequireNetworkAndGeofence, true, false) {
+		t.Error("Expected NETWORK_AND_GEOFENCE to need both")
+	}
+	if checkInRequirementMet(models.CheckInRequireCampusNetwork, false, true) {
+		t.Error("Expected CAMPUS_NETWORK to refuse off-campus check-ins")
+	}
+}
+
+func TestWorkPolicyRules(t *testing.T) {
+	wib := WorkLocation("")
+	clock := func(hour, minute int) time.Time { return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC) }
+	breakMinutes := 60
+	shift := models.WorkSchedule{
+		ScheduleDate: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), StartTime: clock(8, 0), EndTime: clock(16, 0),
+		Shift: &models.ShiftPattern{BreakDurationMinutes: &breakMinutes},
+	}
+	at := func(hour, minute int) time.Time { return time.Date(2024, 9, 2, hour, minute, 0, 0, wib) }
+
+	closes := 30
+	policy := &models.WorkAttendancePolicy{LateGraceMinutes: 5, EarlyOutGraceMinutes: 0, RoundingMinutes: 5, MinWorkMinutes: 7 * 60, CheckInOpensMinutes: 60, CheckInClosesMinutes: &closes}
+
+	if got := roundWorkTime(policy, at(8, 7).Add(20*time.Second)); !got.Equal(at(8, 5)) {
+		t.Errorf("Expected 08:07 to round to 08:05, got %v", got)
+	}
+	if status := checkInStatus(policy, &shift, at(8, 5), wib); status != models.StatusCheckIn {
+		t.Errorf("Expected on time within the grace period, got %s", status)
+	}
+	if status := checkInStatus(policy, &shift, at(8, 10), wib); status != models.StatusLateIn {
+		t.Errorf("Expected late after the grace period, got %s", status)
+	}
+
+	opens, end := checkInWindow(policy, &shift, wib)
+	if !opens.Equal(at(7, 0)) || !end.Equal(at(8, 30)) {
+		t.Errorf("Unexpected check-in window %v - %v", opens, end)
+	}
+
+	// The shift pattern's hour of break counts against the 7 hour minimum
+	if status := checkOutStatus(policy, &shift, at(8, 0), at(16, 0), wib); status != models.StatusCheckOut {
+		t.Errorf("Expected a full day, got %s", status)
+	}
+	if status := checkOutStatus(policy, &shift, at(9, 30), at(16, 0), wib); status != models.StatusEarlyOut {
+		t.Errorf("Expected a short day to be early out, got %s", status)
+	}
+	noBreak := 0
+	policy.BreakDurationMinutes = &noBreak
+	if status := checkOutStatus(policy, &shift, at(8, 30), at(16, 0), wib); status != models.StatusCheckOut {
+		t.Errorf("Expected the policy break to replace the shift's, got %s", status)
+	}
+
+	if id, version := workPolicyRef(defaultWorkPolicy()); id != nil || version != nil {
+		t.Error("Expected the built-in policy not to be referenced")
+	}
+}
+
+func TestComputeTimesheet(t *testing.T) {
+	wib := WorkLocation("")
+	clock := func(hour, minute int) time.Time { return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC) }
+	date := func(day int) time.Time { return time.Date(2024, 9, day, 0, 0, 0, 0, time.UTC) }
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 9, day, hour, minute, 0, 0, wib) }
+	breakMinutes := 60
+	pattern := &models.ShiftPattern{BreakDurationMinutes: &breakMinutes}
+	shift := func(id string, day int) models.WorkSchedule {
+		return models.WorkSchedule{ID: id, ScheduleDate: date(day), StartTime: clock(8, 0), EndTime: clock(16, 0), Shift: pattern}
+	}
+	record := func(kind string, day, hour, minute int) models.WorkAttendanceRecord {
+		workDate := date(day)
+		return models.WorkAttendanceRecord{AttendanceType: kind, RecordedAt: at(day, hour, minute), WorkDate: &workDate}
+	}
+
+	timesheet := &models.WorkTimesheet{UserID: "u1", Year: 2024, Month: 9}
+	computeTimesheet(timesheet, timesheetInput{
+		Schedules: []models.WorkSchedule{shift("s2", 2), shift("s3", 3), shift("s4", 4), shift("s5", 5), shift("s6", 6)},
+		Records: []models.WorkAttendanceRecord{
+			record("CHECK_IN", 2, 8, 30), record("CHECK_OUT", 2, 17, 0), // 30 late, 7h30 worked
+			record("CHECK_IN", 3, 7, 55), record("CHECK_OUT", 3, 15, 0), // an hour early, 6h05 worked
+			record("CHECK_IN", 7, 9, 0), record("CHECK_OUT", 7, 12, 0), // unscheduled Saturday
+		},
+		Leaves:   []models.LeaveRequest{{ID: "leave", LeaveType: models.LeaveTypeSick, StartDate: date(4), EndDate: date(4)}},
+		Holidays: []models.AcademicEvent{{StartDate: at(5, 0, 0), EndDate: at(5, 23, 59)}},
+		Policies: map[string]*models.WorkAttendancePolicy{},
+		Policy:   defaultWorkPolicy(),
+	}, wib)
+
+	if len(timesheet.Days) != 30 {
+		t.Fatalf("Expected a day per day of September, got %d", len(timesheet.Days))
+	}
+	types := make([]string, 0, 6)
+	for _, day := range timesheet.Days[1:7] {
+		types = append(types, string(day.DayType))
+	}
+	if got := strings.Join(types, ","); got != "PRESENT,PRESENT,LEAVE,HOLIDAY,ABSENT,PRESENT" {
+		t.Errorf("Unexpected day types %s", got)
+	}
+
+	monday := timesheet.Days[1]
+	if monday.LateMinutes != 30 || monday.WorkedMinutes != 450 || monday.OvertimeMinutes != 30 || monday.BreakMinutes != 60 {
+		t.Errorf("Unexpected Monday %+v", monday)
+	}
+	if tuesday := timesheet.Days[2]; tuesday.EarlyOutMinutes != 60 || tuesday.LateMinutes != 0 || tuesday.WorkedMinutes != 365 {
+		t.Errorf("Unexpected Tuesday %+v", tuesday)
+	}
+	if saturday := timesheet.Days[6]; saturday.OvertimeMinutes != 180 || saturday.ScheduleID != nil {
+		t.Errorf("Expected unscheduled work to be overtime, got %+v", saturday)
+	}
+
+	if timesheet.ScheduledDays != 4 || timesheet.PresentDays != 3 || timesheet.AbsentDays != 1 || timesheet.LeaveDays != 1 || timesheet.HolidayDays != 1 {
+		t.Errorf("Unexpected day totals %+v", timesheet)
+	}
+	if timesheet.WorkedMinutes != 450+365+180 || timesheet.OvertimeMinutes != 210 || timesheet.Status != models.TimesheetStatusDraft {
+		t.Errorf("Unexpected minute totals %+v", timesheet)
+	}
+}
+
+// newDryRunService creates a service whose repository builds queries without running them: every lookup
+// finds nothing, so no user belongs to a unit or supervises one
+func newDryRunService(t *testing.T) *AttendanceService {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open dry run database: %v", err)
+	}
+	return &AttendanceService{repo: repository.NewAttendanceRepository(db)}
+}
+
+// isForbidden reports whether err is a forbidden application error
+func isForbidden(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	return ok && appErr.Code == apperrors.ErrCodeForbidden
+}
+
+// Test that only staff or a supervisor of the user's unit computes a timesheet, never for oneself
+func TestGenerateTimesheetReviewer(t *testing.T) {
+	s := newDryRunService(t)
+	ctx := context.Background()
+	req := GenerateTimesheetRequest{UserID: "employee", Year: 2024, Month: 9}
+
+	if _, err := s.GenerateTimesheet(ctx, "unrelated-dosen", string(models.RoleDosen), req); !isForbidden(err) {
+		t.Errorf("Expected an unrelated dosen to be forbidden, got %v", err)
+	}
+
+	if _, err := s.GenerateTimesheet(ctx, "employee", string(models.RoleStaff), req); !isForbidden(err) {
+		t.Errorf("Expected computing one's own timesheet to be forbidden, got %v", err)
+	}
+}
+
+// Test that a dosen supervising neither user's unit can neither view nor decide on a shift swap
+func TestShiftSwapReviewer(t *testing.T) {
+	s := newDryRunService(t)
+	ctx := context.Background()
+
+	if _, err := s.GetShiftSwapByID(ctx, "unrelated-dosen", string(models.RoleDosen), "swap"); !isForbidden(err) {
+		t.Errorf("Expected viewing to be forbidden, got %v", err)
+	}
+	if _, err := s.ApproveShiftSwap(ctx, "unrelated-dosen", string(models.RoleDosen), "swap", RespondShiftSwapRequest{}); !isForbidden(err) {
+		t.Errorf("Expected approving to be forbidden, got %v", err)
+	}
+	if _, err := s.RejectShiftSwap(ctx, "unrelated-dosen", string(models.RoleDosen), "swap", RespondShiftSwapRequest{Notes: "no"}); !isForbidden(err) {
+		t.Errorf("Expected rejecting to be forbidden, got %v", err)
+	}
+}
+
+// Test that a dosen who neither holds the session's kiosk device nor supervises its unit cannot close it
+func TestCloseKioskSessionAccess(t *testing.T) {
+	s := newDryRunService(t)
+
+	if _, err := s.CloseKioskSession(context.Background(), "unrelated-dosen", string(models.RoleDosen), "", "session"); !isForbidden(err) {
+		t.Errorf("Expected closing to be forbidden, got %v", err)
+	}
+}
+
+// Test roster planning from rotations and weekdays, and its diff against existing schedules
+func TestPlanRoster(t *testing.T) {
+	wib := WorkLocation("")
+	clock := func(hour int) time.Time { return time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC) }
+	date := func(day int) time.Time { return time.Date(2024, 9, day, 0, 0, 0, 0, time.UTC) }
+	morning := &models.ShiftPattern{ID: "pagi", ShiftCode: "PAGI", StartTime: clock(7), EndTime: clock(15)}
+	night := &models.ShiftPattern{ID: "malam", ShiftCode: "MALAM", StartTime: clock(23), EndTime: clock(7)}
+	office := models.ShiftPattern{ID: "kantor", ShiftCode: "KANTOR", StartTime: clock(8), EndTime: clock(16)}
+	shiftsByCode := map[string]*models.ShiftPattern{"PAGI": morning, "MALAM": night}
+
+	assignments := []models.UserShift{
+		{ID: "rot", UserID: "nurse", ShiftID: "pagi", EffectiveFrom: date(1), Rotation: "PAGI,PAGI,MALAM,MALAM,OFF,OFF"},
+		{ID: "fixed", UserID: "clerk", ShiftID: "kantor", Shift: office, EffectiveFrom: date(1)},
+	}
+	// 2 September 2024 is a Monday; the 4th is a holiday
+	holidays := []models.AcademicEvent{{StartDate: time.Date(2024, 9, 4, 0, 0, 0, 0, wib), EndDate: time.Date(2024, 9, 4, 23, 59, 0, 0, wib)}}
+	plan := planRoster(assignments, shiftsByCode, holidays, date(2), date(8), wib)
+
+	codes := func(entries []rosterEntry) string {
+		var parts []string
+		for _, entry := range entries {
+			parts = append(parts, entry.Date.Format("02")+entry.Shift.ShiftCode)
+		}
+		return strings.Join(parts, " ")
+	}
+	// The rotation started on the 1st: PAGI 1-2, MALAM 3-4, OFF 5-6, PAGI 7-8
+	if got := codes(plan["nurse"]); got != "02PAGI 03MALAM 07PAGI 08PAGI" {
+		t.Errorf("Unexpected rotation roster %q", got)
+	}
+	if got := codes(plan["clerk"]); got != "02KANTOR 03KANTOR 05KANTOR 06KANTOR" {
+		t.Errorf("Unexpected weekday roster %q", got)
+	}
+
+	generated := "rot"
+	existing := []models.WorkSchedule{
+		{ID: "same", UserID: "nurse", ScheduleDate: date(2), ShiftID: &morning.ID, StartTime: clock(7), EndTime: clock(15), UserShiftID: &generated},
+		{ID: "stale", UserID: "nurse", ScheduleDate: date(3), ShiftID: &morning.ID, StartTime: clock(7), EndTime: clock(15), UserShiftID: &generated},
+		{ID: "holiday", UserID: "nurse", ScheduleDate: date(4), ShiftID: &night.ID, StartTime: clock(23), EndTime: clock(7), UserShiftID: &generated},
+		{ID: "manual", UserID: "clerk", ScheduleDate: date(2), StartTime: clock(9), EndTime: clock(17)},
+	}
+	diff := diffRoster(plan, existing, []string{"clerk", "nurse"})
+
+	actions := make(map[string]int)
+	for _, change := range diff.Changes {
+		actions[change.Action]++
+	}
+	want := map[string]int{RosterActionCreate: 5, RosterActionUnchanged: 1, RosterActionUpdate: 1, RosterActionKeep: 1, RosterActionDelete: 1}
+	for action, count := range want {
+		if actions[action] != count {
+			t.Errorf("Expected %d %s changes, got %d", count, action, actions[action])
+		}
+	}
+	if len(diff.Creates) != 5 || len(diff.Updates) != 1 || len(diff.DeleteIDs) != 1 {
+		t.Fatalf("Unexpected writes: %d creates, %d updates, %d deletes", len(diff.Creates), len(diff.Updates), len(diff.DeleteIDs))
+	}
+	if diff.Updates[0].ID != "stale" || *diff.Updates[0].ShiftID != "malam" {
+		t.Errorf("Expected the stale generated entry to move to the night shift, got %+v", diff.Updates[0])
+	}
+	if diff.DeleteIDs[0] != "holiday" {
+		t.Errorf("Expected the generated entry on the holiday to be deleted, got %s", diff.DeleteIDs[0])
+	}
+	for _, schedule := range diff.Creates {
+		if schedule.UserShiftID == nil || schedule.DayOfWeek == nil || *schedule.DayOfWeek != isoWeekday(schedule.ScheduleDate) {
+			t.Errorf("Expected generated schedules to record their assignment and weekday, got %+v", schedule)
+		}
+	}
+}
+
+// Test conflicts when taking over a shift: double booking, rest time and leave
+func TestShiftTakeoverConflicts(t *testing.T) {
+	wib := WorkLocation("")
+	clock := func(hour int) time.Time { return time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC) }
+	date := func(day int) time.Time { return time.Date(2024, 9, day, 0, 0, 0, 0, time.UTC) }
+	shift := func(id string, day, start, end int) models.WorkSchedule {
+		return models.WorkSchedule{ID: id, ScheduleDate: date(day), StartTime: clock(start), EndTime: clock(end)}
+	}
+	incoming := shift("in", 10, 7, 15)
+
+	tests := []struct {
+		name   string
+		others []models.WorkSchedule
+		leaves []models.LeaveRequest
+		want   []string
+	}{
+		{"free day", []models.WorkSchedule{shift("a", 9, 7, 15), shift("b", 11, 7, 15)}, nil, nil},
+		{"double booked", []models.WorkSchedule{shift("a", 10, 13, 21)}, nil, []string{"overlaps"}},
+		{"night shift before leaves too little rest", []models.WorkSchedule{shift("a", 9, 23, 3)}, nil, []string{"4h0m0s rest"}},
+		{"evening shift after leaves too little rest", []models.WorkSchedule{shift("a", 10, 19, 23)}, nil, []string{"4h0m0s rest"}},
+		{"eight hours rest is enough", []models.WorkSchedule{shift("a", 10, 23, 7)}, nil, nil},
+		{"the shift itself is ignored", []models.WorkSchedule{incoming}, nil, nil},
+		{"on leave", nil, []models.LeaveRequest{{StartDate: date(9), EndDate: date(11)}}, []string{"approved leave"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := shiftTakeoverConflicts(&incoming, tt.others, tt.leaves, wib)
+			if len(conflicts) != len(tt.want) {
+				t.Fatalf("Expected %d conflicts, got %v", len(tt.want), conflicts)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(conflicts[i], want) {
+					t.Errorf("Expected conflict %q to mention %q", conflicts[i], want)
+				}
+			}
+		})
+	}
+}
+
+// Test rotating kiosk QR codes
+func TestKioskQR(t *testing.T) {
+	key := []byte("test-signing-key")
+	rotation := 30 * time.Second
+	now := time.Date(2024, 9, 2, 1, 0, 10, 0, time.UTC)
+	closesAt := now.Add(time.Hour)
+	session := &models.WorkAttendanceSession{ID: uuid.New().String(), Status: models.WorkSessionStatusOpen, IsActive: true, ExpiresAt: &closesAt}
+
+	data := kioskQR(session, now, rotation, key)
+	if !data.ExpiresAt.Equal(now.Add(20 * time.Second)) {
+		t.Errorf("Expected the code to rotate at the end of its period, got %v", data.ExpiresAt)
+	}
+	if err := verifyKioskQR(&data, now, rotation, key); err != nil {
+		t.Errorf("Expected the displayed code to verify, got %v", err)
+	}
+	if err := verifyKioskQR(&data, now.Add(45*time.Second), rotation, key); err != nil {
+		t.Errorf("Expected the previous code to stay valid for one period, got %v", err)
+	}
+	if err := verifyKioskQR(&data, now.Add(time.Minute), rotation, key); err == nil {
+		t.Error("Expected an older code to be refused")
+	}
+
+	tampered := data
+	tampered.ExpiresAt = tampered.ExpiresAt.Add(time.Hour)
+	if err := verifyKioskQR(&tampered, now, rotation, key); err == nil {
+		t.Error("Expected a code with a changed expiry to be refused")
+	}
+	classQR := data
+	classQR.Type = "kelas"
+	qrcode.Sign(&classQR, key)
+	if err := verifyKioskQR(&classQR, now, rotation, key); err == nil {
+		t.Error("Expected a class attendance QR code to be refused")
+	}
+
+	if last := kioskQR(session, closesAt.Add(-5*time.Second), rotation, key); !last.ExpiresAt.Equal(closesAt) {
+		t.Errorf("Expected the last code to expire with the session, got %v", last.ExpiresAt)
+	}
+	if kioskSessionOpen(session, closesAt) {
+		t.Error("Expected the session to close at its close time")
+	}
+}
+
+// Test the daily sweep of missing check-outs and absences
+func TestSweepWorkDay(t *testing.T) {
+	wib := WorkLocation("")
+	day := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	at := func(dayOffset, hour, minute int) time.Time { return time.Date(2024, 9, 2+dayOffset, hour, minute, 0, 0, wib) }
+	clock := func(hour int) time.Time { return time.Date(0, 1, 1, hour, 0, 0, 0, time.UTC) }
+	schedule := func(userID string, start, end int) models.WorkSchedule {
+		return models.WorkSchedule{ID: "s-" + userID, UserID: userID, ScheduleDate: day, StartTime: clock(start), EndTime: clock(end), IsActive: true}
+	}
+	checkIn := func(userID string, recordedAt time.Time, status models.WorkAttendanceStatus) models.WorkAttendanceRecord {
+		scheduleID := "s-" + userID
+		return models.WorkAttendanceRecord{UserID: userID, ScheduleID: &scheduleID, AttendanceType: "CHECK_IN", RecordedAt: recordedAt, Status: status}
+	}
+	shiftEnd := defaultWorkPolicy()
+	shiftEnd.MissingCheckOut = models.MissingCheckOutShiftEnd
+	correctableUntil := at(4, 9, 0)
+
+	result := sweepWorkDay(sweepInput{
+		Day: day,
+		Now: at(1, 5, 0),
+		Schedules: []models.WorkSchedule{
+			schedule("no-hours", 8, 16), schedule("shift-end", 8, 16), schedule("absent", 8, 16),
+			schedule("sick", 8, 16), schedule("night", 22, 6), schedule("swept", 8, 16), schedule("done", 8, 16),
+		},
+		Records: []models.WorkAttendanceRecord{
+			checkIn("no-hours", at(0, 7, 55), models.StatusCheckIn),
+			checkIn("shift-end", at(0, 8, 20), models.StatusLateIn),
+			checkIn("night", at(0, 21, 50), models.StatusCheckIn),
+			checkIn("done", at(0, 8, 0), models.StatusCheckIn),
+			{UserID: "done", AttendanceType: "CHECK_OUT", RecordedAt: at(0, 16, 5), Status: models.StatusCheckOut},
+			{UserID: "swept", AttendanceType: attendanceTypeAbsence, Status: models.StatusAbsent},
+		},
+		Leaves:           []models.LeaveRequest{{UserID: "sick", LeaveType: models.LeaveTypeSick, StartDate: day, EndDate: day.AddDate(0, 0, 1)}},
+		Policies:         map[string]*models.WorkAttendancePolicy{"shift-end": shiftEnd},
+		CorrectableUntil: correctableUntil,
+	}, wib)
+
+	if result.Closed != 2 || result.Absent != 1 || result.OnLeave != 1 || len(result.Records) != 4 {
+		t.Fatalf("Expected 2 closed, 1 absent and 1 on leave, got %+v", result)
+	}
+	records := make(map[string]models.WorkAttendanceRecord)
+	for _, record := range result.Records {
+		records[record.UserID] = record
+		if record.CorrectableUntil == nil || !record.CorrectableUntil.Equal(correctableUntil) {
+			t.Errorf("Expected the record of %s to be correctable until %v", record.UserID, correctableUntil)
+		}
+	}
+
+	if r := records["no-hours"]; !r.AutoClosed || !r.EffectiveAt.Equal(at(0, 7, 55)) {
+		t.Errorf("Expected the missing check-out to close with no hours, got %+v", r)
+	}
+	if r := records["shift-end"]; !r.AutoClosed || !r.EffectiveAt.Equal(at(0, 16, 0)) {
+		t.Errorf("Expected the missing check-out to close at the shift end, got %+v", r)
+	}
+	if r := records["absent"]; r.AttendanceType != attendanceTypeAbsence || r.Status != models.StatusAbsent {
+		t.Errorf("Expected an absence, got %+v", r)
+	}
+	if r := records["sick"]; r.Status != models.StatusSickLeave {
+		t.Errorf("Expected sick leave, got %+v", r)
+	}
+	if _, ok := records["night"]; ok {
+		t.Error("Expected the running night shift to be left open")
+	}
+
+	if len(result.Anomalies["shift-end"]) != 2 {
+		t.Errorf("Expected the late check-in and the missing check-out, got %v", result.Anomalies["shift-end"])
+	}
+	for _, userID := range []string{"sick", "night", "swept", "done"} {
+		if len(result.Anomalies[userID]) > 0 {
+			t.Errorf("Expected no anomalies for %s, got %v", userID, result.Anomalies[userID])
+		}
+	}
+}
+
+// Test days missed while the service was down are swept on the next run
+func TestSweepDays(t *testing.T) {
+	yesterday := time.Date(2024, 9, 10, 0, 0, 0, 0, time.UTC)
+
+	if days := sweepDays(nil, yesterday); len(days) != 1 || !days[0].Equal(yesterday) {
+		t.Errorf("Expected only yesterday without a previous sweep, got %v", days)
+	}
+
+	last := time.Date(2024, 9, 7, 0, 0, 0, 0, time.UTC)
+	days := sweepDays(&last, yesterday)
+	if len(days) != 3 || !days[0].Equal(time.Date(2024, 9, 8, 0, 0, 0, 0, time.UTC)) || !days[2].Equal(yesterday) {
+		t.Errorf("Expected 8, 9 and 10 September, got %v", days)
+	}
+
+	if days := sweepDays(&yesterday, yesterday); len(days) != 0 {
+		t.Errorf("Expected nothing to sweep, got %v", days)
+	}
+
+	longAgo := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	days = sweepDays(&longAgo, yesterday)
+	if len(days) != maxSweepCatchUp || !days[len(days)-1].Equal(yesterday) {
+		t.Errorf("Expected the last %d days, got %d", maxSweepCatchUp, len(days))
+	}
+}
+
+// Test tukin deductions from a locked timesheet
+func TestComputeTukinDeduction(t *testing.T) {
+	max := func(minutes int) *int { return &minutes }
+	rules, err := tukinRules([]TukinRuleInput{
+		{Category: "LATE", Code: "TL1", MinMinutes: 1, MaxMinutes: max(30), Percent: 0.5},
+		{Category: "LATE", Code: "TL2", MinMinutes: 31, MaxMinutes: max(60), Percent: 1},
+		{Category: "LATE", Code: "TL3", MinMinutes: 61, Percent: 1.5},
+		{Category: "EARLY_OUT", Code: "PSW1", MinMinutes: 1, MaxMinutes: max(30), Percent: 0.5},
+		{Category: "MISSING_CHECK_OUT", Code: "PSW4", Percent: 1.5},
+		{Category: "ABSENT", Code: "TK", Percent: 5},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid rules, got %v", err)
+	}
+	if _, err := tukinRules([]TukinRuleInput{
+		{Category: "LATE", Code: "TL1", MinMinutes: 1, MaxMinutes: max(30), Percent: 0.5},
+		{Category: "LATE", Code: "TL2", MinMinutes: 30, Percent: 1},
+	}); err == nil {
+		t.Error("Expected overlapping tiers to be refused")
+	}
+
+	date := func(day int) time.Time { return time.Date(2024, 9, day, 0, 0, 0, 0, time.UTC) }
+	out := date(2)
+	timesheet := &models.WorkTimesheet{Days: []models.WorkTimesheetDay{
+		{WorkDate: date(2), DayType: models.TimesheetDayPresent, LateMinutes: 45, EarlyOutMinutes: 10, CheckOutAt: &out},
+		{WorkDate: date(3), DayType: models.TimesheetDayPresent, LateMinutes: 5},
+		{WorkDate: date(4), DayType: models.TimesheetDayPresent, CheckOutAt: &out, MissingCheckOut: true, EarlyOutMinutes: 480},
+		{WorkDate: date(5), DayType: models.TimesheetDayAbsent},
+		{WorkDate: date(6), DayType: models.TimesheetDayLeave},
+		{WorkDate: date(7), DayType: models.TimesheetDayPresent, EarlyOutMinutes: 90, CheckOutAt: &out},
+	}}
+	ruleSet := &models.TukinRuleSet{ID: "v2", Version: 2, MaxMonthlyPercent: 100, Rules: rules}
+
+	deduction := &models.TukinDeduction{}
+	computeTukinDeduction(deduction, timesheet, ruleSet)
+
+	var codes []string
+	for _, line := range deduction.Lines {
+		codes = append(codes, line.Code)
+	}
+	if got := strings.Join(codes, ","); got != "TL2,PSW1,TL1,PSW4,PSW4,TK" {
+		t.Errorf("Expected TL2,PSW1,TL1,PSW4,PSW4,TK, got %s", got)
+	}
+	if deduction.TotalPercent != 10 || deduction.Capped || deduction.RuleSetVersion != 2 {
+		t.Errorf("Expected 10%% with version 2, got %+v", deduction)
+	}
+	if !strings.Contains(deduction.Lines[0].Explanation, "45 minutes late on 2024-09-02") {
+		t.Errorf("Expected the explanation to give the minutes and day, got %q", deduction.Lines[0].Explanation)
+	}
+
+	ruleSet.MaxMonthlyPercent = 7.5
+	computeTukinDeduction(deduction, timesheet, ruleSet)
+	if deduction.TotalPercent != 7.5 || !deduction.Capped || len(deduction.Lines) != 6 {
+		t.Errorf("Expected the total to be capped at 7.5%%, got %+v", deduction)
+	}
+
+	rows := tukinExportRows([]models.TukinDeduction{*deduction}, map[string]tukinEmployee{"": {NIP: "1980", Nama: "Budi", Unit: "Biro Umum"}}, "2024-09")
+	if len(rows) != 8 || rows[7][6] != "TOTAL" || rows[7][8] != "7.50" {
+		t.Errorf("Expected six lines and a total row, got %v", rows)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"time"
+
+	"unsri-backend/internal/shared/models"
+)
+
+const (
+	workCheckInLead  = 2 * time.Hour  // How early before its start a check-in counts for a shift
+	maxWorkShiftSpan = 24 * time.Hour // Check-ins older than this are not closed by a check-out
+)
+
+// WorkLocation loads the time zone work days are evaluated in, falling back to WIB (UTC+7, no DST)
+func WorkLocation(name string) *time.Location {
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}
+
+// workLocation returns the configured work time zone
+func (s *AttendanceService) workLocation() *time.Location {
+	if s.scan.WorkLocation == nil {
+		return WorkLocation("")
+	}
+	return s.scan.WorkLocation
+}
+
+// workDay returns the calendar day of a time in the work time zone, as a date
+func workDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// shiftWindow returns when a scheduled shift starts and ends on its work day.
+// A shift ending at or before its start clock time ends on the next day.
+func shiftWindow(schedule *models.WorkSchedule, loc *time.Location) (time.Time, time.Time) {
+	date := schedule.ScheduleDate
+	start := time.Date(date.Year(), date.Month(), date.Day(), schedule.StartTime.Hour(), schedule.StartTime.Minute(), 0, 0, loc)
+	end := time.Date(date.Year(), date.Month(), date.Day(), schedule.EndTime.Hour(), schedule.EndTime.Minute(), 0, 0, loc)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// attributeCheckIn picks the shift a check-in belongs to among the user's shifts of yesterday and today:
+// the one whose window, opened workCheckInLead early, holds the check-in and starts closest to it,
+// otherwise today's first shift
+func attributeCheckIn(schedules []models.WorkSchedule, now time.Time, loc *time.Location) *models.WorkSchedule {
+	var best *models.WorkSchedule
+	var bestGap time.Duration
+	for i := range schedules {
+		start, end := shiftWindow(&schedules[i], loc)
+		if now.Before(start.Add(-workCheckInLead)) || !now.Before(end) {
+			continue
+		}
+		gap := now.Sub(start)
+		if gap < 0 {
+			gap = -gap
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = &schedules[i], gap
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	today := workDay(now, loc)
+	for i := range schedules {
+		if schedules[i].ScheduleDate.Format("2006-01-02") == today.Format("2006-01-02") {
+			return &schedules[i]
+		}
+	}
+	return nil
+}
+
+// checkInWindow returns when the policy accepts check-ins for a shift
+func checkInWindow(policy *models.WorkAttendancePolicy, schedule *models.WorkSchedule, loc *time.Location) (time.Time, time.Time) {
+	start, end := shiftWindow(schedule, loc)
+	opens := start.Add(-time.Duration(policy.CheckInOpensMinutes) * time.Minute)
+	if policy.CheckInClosesMinutes != nil {
+		end = start.Add(time.Duration(*policy.CheckInClosesMinutes) * time.Minute)
+	}
+	return opens, end
+}
+
+// workBreak returns the break a work day includes: the policy's, otherwise the shift pattern's
+func workBreak(policy *models.WorkAttendancePolicy, schedule *models.WorkSchedule) time.Duration {
+	switch {
+	case policy.BreakDurationMinutes != nil:
+		return time.Duration(*policy.BreakDurationMinutes) * time.Minute
+	case schedule != nil && schedule.Shift != nil && schedule.Shift.BreakDurationMinutes != nil:
+		return time.Duration(*schedule.Shift.BreakDurationMinutes) * time.Minute
+	}
+	return 0
+}
+
+// roundWorkTime rounds a recorded time to the nearest multiple of the policy's rounding interval
+func roundWorkTime(policy *models.WorkAttendancePolicy, t time.Time) time.Time {
+	if policy.RoundingMinutes <= 0 {
+		return t
+	}
+	return t.Round(time.Duration(policy.RoundingMinutes) * time.Minute)
+}
+
+// checkInStatus returns LATE_IN for check-ins after the shift start plus the policy's grace period
+func checkInStatus(policy *models.WorkAttendancePolicy, schedule *models.WorkSchedule, at time.Time, loc *time.Location) models.WorkAttendanceStatus {
+	if schedule != nil {
+		start, _ := shiftWindow(schedule, loc)
+		if at.After(start.Add(time.Duration(policy.LateGraceMinutes) * time.Minute)) {
+			return models.StatusLateIn
+		}
+	}
+	return models.StatusCheckIn
+}
+
+// checkOutStatus returns EARLY_OUT for check-outs before the shift end minus the policy's grace period,
+// or when the time since checkInAt, break excluded, is shorter than the policy's minimum work time
+func checkOutStatus(policy *models.WorkAttendancePolicy, schedule *models.WorkSchedule, checkInAt, at time.Time, loc *time.Location) models.WorkAttendanceStatus {
+	if schedule != nil {
+		_, end := shiftWindow(schedule, loc)
+		if at.Before(end.Add(-time.Duration(policy.EarlyOutGraceMinutes) * time.Minute)) {
+			return models.StatusEarlyOut
+		}
+	}
+	if policy.MinWorkMinutes > 0 {
+		worked := at.Sub(checkInAt) - workBreak(policy, schedule)
+		if worked < time.Duration(policy.MinWorkMinutes)*time.Minute {
+			return models.StatusEarlyOut
+		}
+	}
+	return models.StatusCheckOut
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+const (
+	rotationOff   = "OFF" // Rotation step without a shift
+	maxRosterDays = 93    // Longest range generated at once, about a quarter
+)
+
+// Roster change actions
+const (
+	RosterActionCreate    = "CREATE"
+	RosterActionUpdate    = "UPDATE"
+	RosterActionDelete    = "DELETE"    // Generated entry no longer planned, e.g. on a new holiday
+	RosterActionUnchanged = "UNCHANGED" // Already scheduled as planned
+	RosterActionKeep      = "KEEP"      // Differs from the plan but was entered by hand, left as is
+)
+
+// isoWeekday returns the weekday of a date, 1=Monday to 7=Sunday
+func isoWeekday(date time.Time) int {
+	if date.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(date.Weekday())
+}
+
+// parseRotation splits a rotation into its steps, shift codes or OFF
+func parseRotation(rotation string) []string {
+	var steps []string
+	for _, step := range strings.Split(rotation, ",") {
+		if step = strings.ToUpper(strings.TrimSpace(step)); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// parseWorkDays parses the weekdays of a fixed shift, Monday to Friday when empty
+func parseWorkDays(workDays string) (map[int]bool, error) {
+	days := make(map[int]bool)
+	if strings.TrimSpace(workDays) == "" {
+		for day := 1; day <= 5; day++ {
+			days[day] = true
+		}
+		return days, nil
+	}
+	for _, value := range strings.Split(workDays, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || day < 1 || day > 7 {
+			return nil, fmt.Errorf("invalid weekday %q, use 1 (Monday) to 7 (Sunday)", value)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// rosterEntry represents a planned shift of a user on a day
+type rosterEntry struct {
+	Date      time.Time
+	UserShift *models.UserShift
+	Shift     *models.ShiftPattern
+}
+
+// userShiftOn returns the assignment covering a date, the latest starting one when several do
+func userShiftOn(assignments []models.UserShift, date time.Time) *models.UserShift {
+	var current *models.UserShift
+	for i := range assignments {
+		from := workDay(assignments[i].EffectiveFrom, time.UTC)
+		if from.After(date) {
+			continue
+		}
+		if until := assignments[i].EffectiveUntil; until != nil && workDay(*until, time.UTC).Before(date) {
+			continue
+		}
+		if current == nil || from.After(workDay(current.EffectiveFrom, time.UTC)) {
+			current = &assignments[i]
+		}
+	}
+	return current
+}
+
+// shiftOn returns the shift an assignment plans on a date, nil on days off.
+// A rotation is cycled day by day from the assignment start, otherwise the shift is worked on its weekdays.
+func shiftOn(assignment *models.UserShift, date time.Time, shiftsByCode map[string]*models.ShiftPattern) *models.ShiftPattern {
+	if steps := parseRotation(assignment.Rotation); len(steps) > 0 {
+		index := int(date.Sub(workDay(assignment.EffectiveFrom, time.UTC))/(24*time.Hour)) % len(steps)
+		if steps[index] == rotationOff {
+			return nil
+		}
+		return shiftsByCode[steps[index]]
+	}
+
+	days, err := parseWorkDays(assignment.WorkDays)
+	if err != nil || !days[isoWeekday(date)] {
+		return nil
+	}
+	return &assignment.Shift
+}
+
+// planRoster plans the shifts of each user with assignments from startDate to endDate, skipping holidays
+func planRoster(assignments []models.UserShift, shiftsByCode map[string]*models.ShiftPattern, holidays []models.AcademicEvent, startDate, endDate time.Time, loc *time.Location) map[string][]rosterEntry {
+	byUser := make(map[string][]models.UserShift)
+	for _, assignment := range assignments {
+		byUser[assignment.UserID] = append(byUser[assignment.UserID], assignment)
+	}
+
+	plan := make(map[string][]rosterEntry)
+	for userID, userAssignments := range byUser {
+		for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+			if holidayOn(holidays, date.Format("2006-01-02"), loc) {
+				continue
+			}
+			assignment := userShiftOn(userAssignments, date)
+			if assignment == nil {
+				continue
+			}
+			if shift := shiftOn(assignment, date, shiftsByCode); shift != nil && shift.ID != "" {
+				plan[userID] = append(plan[userID], rosterEntry{Date: date, UserShift: assignment, Shift: shift})
+			}
+		}
+	}
+	return plan
+}
+
+// RosterChange represents the change a roster makes to a user's work schedule on a day
+type RosterChange struct {
+	UserID     string  `json:"user_id"`
+	Date       string  `json:"date"`
+	Action     string  `json:"action"`
+	ShiftCode  string  `json:"shift_code,omitempty"`
+	StartTime  string  `json:"start_time,omitempty"`
+	EndTime    string  `json:"end_time,omitempty"`
+	ScheduleID *string `json:"schedule_id,omitempty"` // Existing work schedule
+}
+
+// rosterDiff holds the changes of a roster and the work schedules to write
+type rosterDiff struct {
+	Changes   []RosterChange
+	Creates   []models.WorkSchedule
+	Updates   []models.WorkSchedule
+	DeleteIDs []string
+}
+
+// diffRoster compares the plan with the existing work schedules of the users in scope.
+// Generated entries follow the plan; entries entered by hand are never changed.
+func diffRoster(plan map[string][]rosterEntry, existing []models.WorkSchedule, userIDs []string) rosterDiff {
+	current := make(map[string]*models.WorkSchedule)
+	for i := range existing {
+		key := existing[i].UserID + "|" + existing[i].ScheduleDate.Format("2006-01-02")
+		if current[key] == nil {
+			current[key] = &existing[i]
+		}
+	}
+
+	var diff rosterDiff
+	planned := make(map[string]bool)
+	for _, userID := range userIDs {
+		for _, entry := range plan[userID] {
+			date := entry.Date.Format("2006-01-02")
+			key := userID + "|" + date
+			planned[key] = true
+
+			change := RosterChange{
+				UserID:    userID,
+				Date:      date,
+				ShiftCode: entry.Shift.ShiftCode,
+				StartTime: entry.Shift.StartTime.Format("15:04"),
+				EndTime:   entry.Shift.EndTime.Format("15:04"),
+			}
+
+			schedule := current[key]
+			switch {
+			case schedule == nil:
+				change.Action = RosterActionCreate
+				dayOfWeek := isoWeekday(entry.Date)
+				diff.Creates = append(diff.Creates, models.WorkSchedule{
+					UserID:       userID,
+					ScheduleDate: entry.Date,
+					DayOfWeek:    &dayOfWeek,
+					ShiftID:      &entry.Shift.ID,
+					StartTime:    entry.Shift.StartTime,
+					EndTime:      entry.Shift.EndTime,
+					UserShiftID:  &entry.UserShift.ID,
+					IsActive:     true,
+				})
+			case schedule.ShiftID != nil && *schedule.ShiftID == entry.Shift.ID &&
+				schedule.StartTime.Format("15:04") == change.StartTime && schedule.EndTime.Format("15:04") == change.EndTime:
+				change.Action = RosterActionUnchanged
+				change.ScheduleID = &schedule.ID
+			case schedule.UserShiftID != nil:
+				change.Action = RosterActionUpdate
+				change.ScheduleID = &schedule.ID
+				updated := *schedule
+				updated.ShiftID = &entry.Shift.ID
+				updated.StartTime = entry.Shift.StartTime
+				updated.EndTime = entry.Shift.EndTime
+				updated.UserShiftID = &entry.UserShift.ID
+				diff.Updates = append(diff.Updates, updated)
+			default:
+				change.Action = RosterActionKeep
+				change.ScheduleID = &schedule.ID
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+
+	for i := range existing {
+		schedule := &existing[i]
+		key := schedule.UserID + "|" + schedule.ScheduleDate.Format("2006-01-02")
+		if planned[key] || schedule.UserShiftID == nil || current[key] != schedule {
+			continue
+		}
+		diff.DeleteIDs = append(diff.DeleteIDs, schedule.ID)
+		diff.Changes = append(diff.Changes, RosterChange{
+			UserID:     schedule.UserID,
+			Date:       schedule.ScheduleDate.Format("2006-01-02"),
+			Action:     RosterActionDelete,
+			ScheduleID: &schedule.ID,
+		})
+	}
+
+	return diff
+}
+
+// GenerateRosterRequest represents generate roster request.
+// Without a unit or user IDs the roster covers every user with a shift assignment.
+type GenerateRosterRequest struct {
+	StartDate string   `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string   `json:"end_date" binding:"required"`   // YYYY-MM-DD
+	Unit      string   `json:"unit,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	Commit    bool     `json:"commit"` // False previews the changes without writing them
+}
+
+// GenerateRosterResponse represents the changes a roster makes
+type GenerateRosterResponse struct {
+	StartDate string         `json:"start_date"`
+	EndDate   string         `json:"end_date"`
+	Committed bool           `json:"committed"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Deleted   int            `json:"deleted"`
+	Unchanged int            `json:"unchanged"`
+	Kept      int            `json:"kept"`
+	Changes   []RosterChange `json:"changes"`
+}
+
+// GenerateRoster materializes work schedules from shift assignments for a date range
+func (s *AttendanceService) GenerateRoster(ctx context.Context, req GenerateRosterRequest) (*GenerateRosterResponse, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid start_date format, use YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid end_date format, use YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, apperrors.NewValidationError("end_date must not be before start_date")
+	}
+	if endDate.Sub(startDate) >= maxRosterDays*24*time.Hour {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("a roster covers at most %d days", maxRosterDays))
+	}
+
+	userIDs, err := s.rosterUserIDs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.GetUserShiftsInRange(ctx, userIDs, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get user shifts", err)
+	}
+
+	shiftsByCode, err := s.rotationShifts(ctx, assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.calendarRepo.GetHolidays(ctx, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get holidays", err)
+	}
+
+	// Users whose assignments ended keep no generated entries
+	if userIDs == nil {
+		seen := make(map[string]bool)
+		userIDs = []string{}
+		for _, assignment := range assignments {
+			if !seen[assignment.UserID] {
+				seen[assignment.UserID] = true
+				userIDs = append(userIDs, assignment.UserID)
+			}
+		}
+	}
+
+	existing, err := s.repo.GetWorkSchedulesInRange(ctx, userIDs, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get work schedules", err)
+	}
+
+	plan := planRoster(assignments, shiftsByCode, holidays, startDate, endDate, s.workLocation())
+	diff := diffRoster(plan, existing, userIDs)
+
+	response := &GenerateRosterResponse{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Changes:   diff.Changes,
+	}
+	for _, change := range diff.Changes {
+		switch change.Action {
+		case RosterActionCreate:
+			response.Created++
+		case RosterActionUpdate:
+			response.Updated++
+		case RosterActionDelete:
+			response.Deleted++
+		case RosterActionUnchanged:
+			response.Unchanged++
+		case RosterActionKeep:
+			response.Kept++
+		}
+	}
+
+	if req.Commit {
+		if err := s.repo.ApplyRoster(ctx, diff.Creates, diff.Updates, diff.DeleteIDs); err != nil {
+			return nil, apperrors.NewInternalError("failed to apply roster", err)
+		}
+		response.Committed = true
+	}
+
+	return response, nil
+}
+
+// rosterUserIDs resolves the users a roster covers, nil for everyone
+func (s *AttendanceService) rosterUserIDs(ctx context.Context, req GenerateRosterRequest) ([]string, error) {
+	if req.Unit == "" {
+		if len(req.UserIDs) == 0 {
+			return nil, nil
+		}
+		return req.UserIDs, nil
+	}
+
+	unitUserIDs, err := s.repo.GetUserIDsByUnit(ctx, req.Unit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get unit users", err)
+	}
+	if len(req.UserIDs) == 0 {
+		return append([]string{}, unitUserIDs...), nil
+	}
+
+	inUnit := make(map[string]bool)
+	for _, userID := range unitUserIDs {
+		inUnit[userID] = true
+	}
+	userIDs := []string{}
+	for _, userID := range req.UserIDs {
+		if inUnit[userID] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+// rotationShifts loads the shift patterns the rotations of the assignments refer to, by code
+func (s *AttendanceService) rotationShifts(ctx context.Context, assignments []models.UserShift) (map[string]*models.ShiftPattern, error) {
+	shiftsByCode := make(map[string]*models.ShiftPattern)
+	for _, assignment := range assignments {
+		for _, code := range parseRotation(assignment.Rotation) {
+			if code == rotationOff || shiftsByCode[code] != nil {
+				continue
+			}
+			shift, err := s.repo.GetShiftPatternByCode(ctx, code)
+			if err != nil {
+				return nil, apperrors.NewValidationError(fmt.Sprintf("user shift %s rotates through unknown shift code %s", assignment.ID, code))
+			}
+			shiftsByCode[code] = shift
+		}
+	}
+	return shiftsByCode, nil
+}
+
+// validateShiftAssignment checks the rotation and weekdays of a shift assignment, returning the normalized rotation
+func (s *AttendanceService) validateShiftAssignment(ctx context.Context, rotation, workDays string) (string, error) {
+	if _, err := parseWorkDays(workDays); err != nil {
+		return "", apperrors.NewValidationError(err.Error())
+	}
+
+	steps := parseRotation(rotation)
+	worked := false
+	for _, code := range steps {
+		if code == rotationOff {
+			continue
+		}
+		if _, err := s.repo.GetShiftPatternByCode(ctx, code); err != nil {
+			return "", apperrors.NewValidationError("unknown shift code in rotation: " + code)
+		}
+		worked = true
+	}
+	if len(steps) > 0 && !worked {
+		return "", apperrors.NewValidationError("rotation must include at least one shift")
+	}
+	return strings.Join(steps, ","), nil
+}
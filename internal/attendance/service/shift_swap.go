@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"unsri-backend/internal/attendance/repository"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// minShiftRest is the least time off a user must have between two shifts
+const minShiftRest = 8 * time.Hour
+
+// shiftTakeoverConflicts lists why a user cannot take over a shift: it overlaps another of their shifts,
+// leaves less than minShiftRest between them, or falls on their approved leave.
+// others are the user's shifts around the day, without the one they give away.
+func shiftTakeoverConflicts(incoming *models.WorkSchedule, others []models.WorkSchedule, leaves []models.LeaveRequest, loc *time.Location) []string {
+	var conflicts []string
+	start, end := shiftWindow(incoming, loc)
+
+	for i := range others {
+		if others[i].ID == incoming.ID {
+			continue
+		}
+		otherStart, otherEnd := shiftWindow(&others[i], loc)
+		label := fmt.Sprintf("the %s shift on %s", otherStart.Format("15:04"), others[i].ScheduleDate.Format("2006-01-02"))
+
+		var rest time.Duration
+		switch {
+		case !otherEnd.After(start):
+			rest = start.Sub(otherEnd)
+		case !end.After(otherStart):
+			rest = otherStart.Sub(end)
+		default:
+			conflicts = append(conflicts, "overlaps "+label)
+			continue
+		}
+		if rest < minShiftRest {
+			conflicts = append(conflicts, fmt.Sprintf("leaves %s rest next to %s, at least %s is required",
+				rest.Round(time.Minute), label, minShiftRest))
+		}
+	}
+
+	if leaveOn(leaves, incoming.ScheduleDate.Format("2006-01-02")) != nil {
+		conflicts = append(conflicts, "falls on approved leave")
+	}
+
+	return conflicts
+}
+
+// checkShiftTakeover refuses a swap when the user cannot take over the incoming shift.
+// outgoingID is the shift the user gives away in exchange, nil for a cover.
+func (s *AttendanceService) checkShiftTakeover(ctx context.Context, who, userID string, incoming *models.WorkSchedule, outgoingID *string) error {
+	date := incoming.ScheduleDate
+	schedules, err := s.repo.GetWorkSchedulesInRange(ctx, []string{userID}, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1))
+	if err != nil {
+		return apperrors.NewInternalError("failed to get work schedules", err)
+	}
+
+	others := make([]models.WorkSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if outgoingID == nil || schedule.ID != *outgoingID {
+			others = append(others, schedule)
+		}
+	}
+
+	leaves, err := s.leaveRepo.GetApprovedLeavesInRange(ctx, userID, date, date)
+	if err != nil {
+		return apperrors.NewInternalError("failed to get leave requests", err)
+	}
+
+	if conflicts := shiftTakeoverConflicts(incoming, others, leaves, s.workLocation()); len(conflicts) > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("%s cannot take the shift on %s: %s",
+			who, date.Format("2006-01-02"), strings.Join(conflicts, "; ")))
+	}
+	return nil
+}
+
+// checkShiftSwap validates both sides of a swap against the current schedules and leave
+func (s *AttendanceService) checkShiftSwap(ctx context.Context, request *models.ShiftSwapRequest) error {
+	if err := s.checkShiftTakeover(ctx, "target user", request.TargetUserID, &request.RequesterSchedule, request.TargetScheduleID); err != nil {
+		return err
+	}
+	if request.TargetSchedule != nil {
+		return s.checkShiftTakeover(ctx, "requester", request.RequesterID, request.TargetSchedule, &request.RequesterScheduleID)
+	}
+	return nil
+}
+
+// swappableSchedule gets an upcoming active work schedule of a user
+func (s *AttendanceService) swappableSchedule(ctx context.Context, id, userID string) (*models.WorkSchedule, error) {
+	schedule, err := s.repo.GetWorkScheduleByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("work schedule", id)
+	}
+	if schedule.UserID != userID || !schedule.IsActive {
+		return nil, apperrors.NewValidationError("work schedule " + id + " is not an active shift of the user")
+	}
+	if schedule.ScheduleDate.Before(workDay(time.Now(), s.workLocation())) {
+		return nil, apperrors.NewValidationError("past shifts cannot be swapped")
+	}
+	return schedule, nil
+}
+
+// CreateShiftSwapRequest represents create shift swap request.
+// Without a target schedule the target user covers the shift without giving one back.
+type CreateShiftSwapRequest struct {
+	ScheduleID       string  `json:"schedule_id" binding:"required"`
+	TargetUserID     string  `json:"target_user_id" binding:"required"`
+	TargetScheduleID *string `json:"target_schedule_id,omitempty"`
+	Reason           string  `json:"reason" binding:"required"`
+}
+
+// CreateShiftSwap proposes to exchange or hand over one of the user's shifts
+func (s *AttendanceService) CreateShiftSwap(ctx context.Context, userID string, req CreateShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	if req.TargetUserID == userID {
+		return nil, apperrors.NewValidationError("cannot swap a shift with yourself")
+	}
+
+	schedule, err := s.swappableSchedule(ctx, req.ScheduleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &models.ShiftSwapRequest{
+		Type:                models.ShiftSwapTypeCover,
+		RequesterID:         userID,
+		RequesterScheduleID: schedule.ID,
+		TargetUserID:        req.TargetUserID,
+		Reason:              req.Reason,
+		Status:              models.ShiftSwapStatusPending,
+		RequesterSchedule:   *schedule,
+	}
+	scheduleIDs := []string{schedule.ID}
+
+	if req.TargetScheduleID != nil {
+		targetSchedule, err := s.swappableSchedule(ctx, *req.TargetScheduleID, req.TargetUserID)
+		if err != nil {
+			return nil, err
+		}
+		request.Type = models.ShiftSwapTypeSwap
+		request.TargetScheduleID = &targetSchedule.ID
+		request.TargetSchedule = targetSchedule
+		scheduleIDs = append(scheduleIDs, targetSchedule.ID)
+	}
+
+	open, err := s.repo.GetOpenShiftSwapForSchedules(ctx, scheduleIDs)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check shift swap requests", err)
+	}
+	if open != nil {
+		return nil, apperrors.NewConflictError("a swap request for this shift is already open")
+	}
+
+	if err := s.checkShiftSwap(ctx, request); err != nil {
+		return nil, err
+	}
+
+	event := &models.ShiftSwapEvent{ActorID: userID, Action: models.ShiftSwapActionRequested, Notes: req.Reason}
+	if err := s.repo.CreateShiftSwap(ctx, request, event); err != nil {
+		return nil, apperrors.NewInternalError("failed to create shift swap request", err)
+	}
+	request.Events = []models.ShiftSwapEvent{*event}
+
+	return request, nil
+}
+
+// GetShiftSwapsRequest represents get shift swap requests request
+type GetShiftSwapsRequest struct {
+	Status  *string `form:"status"`
+	Mine    bool    `form:"mine"` // Only requests made by or to the user, always the case for non-supervisors
+	Page    int     `form:"page,default=1"`
+	PerPage int     `form:"per_page,default=20"`
+}
+
+// GetShiftSwaps gets shift swap requests: staff see all, unit supervisors also those between users of the
+// units they supervise, other users those they are part of
+func (s *AttendanceService) GetShiftSwaps(ctx context.Context, userID string, role string, req GetShiftSwapsRequest) ([]models.ShiftSwapRequest, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	var partyID *string
+	var units []string
+	switch {
+	case req.Mine || !isSupervisorRole(role):
+		partyID = &userID
+	case role != string(models.RoleStaff):
+		supervised, err := s.repo.GetSupervisedUnits(ctx, userID)
+		if err != nil {
+			return nil, 0, apperrors.NewInternalError("failed to get supervised units", err)
+		}
+		partyID, units = &userID, supervised
+	}
+
+	requests, total, err := s.repo.GetShiftSwaps(ctx, partyID, units, req.Status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get shift swap requests", err)
+	}
+
+	return requests, total, nil
+}
+
+// GetShiftSwapByID gets a shift swap request with its history, visible to both users, the supervisors of
+// both their units and staff
+func (s *AttendanceService) GetShiftSwapByID(ctx context.Context, userID string, role string, id string) (*models.ShiftSwapRequest, error) {
+	request, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("shift swap request", id)
+	}
+
+	if request.RequesterID != userID && request.TargetUserID != userID {
+		if err := s.checkShiftSwapReviewer(ctx, userID, role, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return request, nil
+}
+
+// checkShiftSwapReviewer refuses a reviewer who is neither staff nor a supervisor of the units of both users
+// of the swap
+func (s *AttendanceService) checkShiftSwapReviewer(ctx context.Context, reviewerID string, role string, request *models.ShiftSwapRequest) error {
+	if role == string(models.RoleStaff) {
+		return nil
+	}
+	for _, userID := range []string{request.RequesterID, request.TargetUserID} {
+		supervises, err := s.isUnitSupervisorOf(ctx, reviewerID, userID)
+		if err != nil {
+			return apperrors.NewInternalError("failed to check unit supervisor", err)
+		}
+		if !supervises {
+			return apperrors.NewForbiddenError("only a supervisor of both users' units or staff can review this shift swap")
+		}
+	}
+	return nil
+}
+
+// RespondShiftSwapRequest represents accept, decline, cancel, approve or reject shift swap request
+type RespondShiftSwapRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// AcceptShiftSwap accepts a shift swap request as its target user, passing it on to a supervisor
+func (s *AttendanceService) AcceptShiftSwap(ctx context.Context, userID string, id string, req RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	request, err := s.getTargetedShiftSwap(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkShiftSwap(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return s.updateShiftSwap(ctx, request, userID, models.ShiftSwapStatusAccepted, models.ShiftSwapActionAccepted, req.Notes)
+}
+
+// DeclineShiftSwap declines a shift swap request as its target user
+func (s *AttendanceService) DeclineShiftSwap(ctx context.Context, userID string, id string, req RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	request, err := s.getTargetedShiftSwap(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateShiftSwap(ctx, request, userID, models.ShiftSwapStatusDeclined, models.ShiftSwapActionDeclined, req.Notes)
+}
+
+// CancelShiftSwap withdraws a shift swap request before it is approved
+func (s *AttendanceService) CancelShiftSwap(ctx context.Context, userID string, id string, req RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	request, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("shift swap request", id)
+	}
+	if request.RequesterID != userID {
+		return nil, apperrors.NewForbiddenError("only the requester can cancel this shift swap request")
+	}
+	if request.Status != models.ShiftSwapStatusPending && request.Status != models.ShiftSwapStatusAccepted {
+		return nil, apperrors.NewConflictError("shift swap request is already " + strings.ToLower(string(request.Status)))
+	}
+
+	return s.updateShiftSwap(ctx, request, userID, models.ShiftSwapStatusCancelled, models.ShiftSwapActionCancelled, req.Notes)
+}
+
+// ApproveShiftSwap approves an accepted shift swap request and reassigns both schedules.
+// The schedules become hand-entered, so later generated rosters keep them.
+func (s *AttendanceService) ApproveShiftSwap(ctx context.Context, reviewerID string, role string, id string, req RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	request, err := s.getReviewableShiftSwap(ctx, reviewerID, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.RequesterSchedule.UserID != request.RequesterID || !request.RequesterSchedule.IsActive ||
+		(request.TargetSchedule != nil && (request.TargetSchedule.UserID != request.TargetUserID || !request.TargetSchedule.IsActive)) {
+		return nil, apperrors.NewConflictError("a swapped shift was changed since the request was made")
+	}
+	if err := s.checkShiftSwap(ctx, request); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = models.ShiftSwapStatusApproved
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	events := []models.ShiftSwapEvent{
+		{ActorID: reviewerID, Action: models.ShiftSwapActionApproved, Notes: req.Notes},
+		shiftReassignedEvent(reviewerID, request.RequesterScheduleID, request.RequesterID, request.TargetUserID),
+	}
+	if request.TargetScheduleID != nil {
+		events = append(events, shiftReassignedEvent(reviewerID, *request.TargetScheduleID, request.TargetUserID, request.RequesterID))
+	}
+
+	if err := s.repo.ApplyShiftSwap(ctx, request, events); err != nil {
+		if errors.Is(err, repository.ErrScheduleReassigned) {
+			return nil, apperrors.NewConflictError("a swapped shift was changed since the request was made")
+		}
+		return nil, apperrors.NewInternalError("failed to apply shift swap", err)
+	}
+	request.Events = append(request.Events, events...)
+
+	return request, nil
+}
+
+// RejectShiftSwap rejects an accepted shift swap request, a reason is required
+func (s *AttendanceService) RejectShiftSwap(ctx context.Context, reviewerID string, role string, id string, req RespondShiftSwapRequest) (*models.ShiftSwapRequest, error) {
+	if req.Notes == "" {
+		return nil, apperrors.NewValidationError("notes are required when rejecting a shift swap request")
+	}
+
+	request, err := s.getReviewableShiftSwap(ctx, reviewerID, role, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewNotes = req.Notes
+
+	return s.updateShiftSwap(ctx, request, reviewerID, models.ShiftSwapStatusRejected, models.ShiftSwapActionRejected, req.Notes)
+}
+
+// shiftReassignedEvent records a work schedule moving from one user to another
+func shiftReassignedEvent(actorID, scheduleID, fromUserID, toUserID string) models.ShiftSwapEvent {
+	return models.ShiftSwapEvent{
+		ActorID:    actorID,
+		Action:     models.ShiftSwapActionReassigned,
+		ScheduleID: &scheduleID,
+		FromUserID: &fromUserID,
+		ToUserID:   &toUserID,
+	}
+}
+
+// updateShiftSwap moves a shift swap request to a new status and logs the step
+func (s *AttendanceService) updateShiftSwap(ctx context.Context, request *models.ShiftSwapRequest, actorID string, status models.ShiftSwapStatus, action models.ShiftSwapAction, notes string) (*models.ShiftSwapRequest, error) {
+	if action == models.ShiftSwapActionAccepted || action == models.ShiftSwapActionDeclined {
+		now := time.Now()
+		request.RespondedAt = &now
+	}
+	request.Status = status
+
+	event := &models.ShiftSwapEvent{ActorID: actorID, Action: action, Notes: notes}
+	if err := s.repo.UpdateShiftSwap(ctx, request, event); err != nil {
+		return nil, apperrors.NewInternalError("failed to update shift swap request", err)
+	}
+	request.Events = append(request.Events, *event)
+
+	return request, nil
+}
+
+// getTargetedShiftSwap gets a pending shift swap request the user is asked to take part in
+func (s *AttendanceService) getTargetedShiftSwap(ctx context.Context, userID string, id string) (*models.ShiftSwapRequest, error) {
+	request, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("shift swap request", id)
+	}
+	if request.TargetUserID != userID {
+		return nil, apperrors.NewForbiddenError("only the target user can respond to this shift swap request")
+	}
+	if request.Status != models.ShiftSwapStatusPending {
+		return nil, apperrors.NewConflictError("shift swap request is already " + strings.ToLower(string(request.Status)))
+	}
+	return request, nil
+}
+
+// getReviewableShiftSwap gets an accepted shift swap request a supervisor may decide on.
+// Neither user of the swap can review it.
+func (s *AttendanceService) getReviewableShiftSwap(ctx context.Context, reviewerID string, role string, id string) (*models.ShiftSwapRequest, error) {
+	request, err := s.repo.GetShiftSwapByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("shift swap request", id)
+	}
+	if request.RequesterID == reviewerID || request.TargetUserID == reviewerID {
+		return nil, apperrors.NewForbiddenError("you cannot review a shift swap you are part of")
+	}
+	if err := s.checkShiftSwapReviewer(ctx, reviewerID, role, request); err != nil {
+		return nil, err
+	}
+	if request.Status != models.ShiftSwapStatusAccepted {
+		return nil, apperrors.NewConflictError("only shift swap requests accepted by the target user can be reviewed")
+	}
+	return request, nil
+}
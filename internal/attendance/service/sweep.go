@@ -0,0 +1,574 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// attendanceTypeAbsence is the type of the records the daily sweep stores for scheduled users who never checked in
+const attendanceTypeAbsence = "ABSENCE"
+
+// maxSweepCatchUp is how many past work days a sweep run catches up on after downtime
+const maxSweepCatchUp = 31
+
+// SweepPolicy holds the settings of the daily work attendance sweep
+type SweepPolicy struct {
+	Time             string        // Clock time (HH:MM, work time zone) after which the previous work day is swept
+	CorrectionWindow time.Duration // How long supervisors can amend what the sweep recorded
+}
+
+// sweepInput holds what the sweep of a work day works from
+type sweepInput struct {
+	Day              time.Time // Work day swept, as a date
+	Now              time.Time
+	Schedules        []models.WorkSchedule
+	Records          []models.WorkAttendanceRecord
+	Leaves           []models.LeaveRequest // Approved leave covering the day
+	Holidays         []models.AcademicEvent
+	Policies         map[string]*models.WorkAttendancePolicy // By user ID
+	CorrectableUntil time.Time
+}
+
+// sweepResult holds the records a sweep creates and the anomalies found per user
+type sweepResult struct {
+	Records   []models.WorkAttendanceRecord
+	Anomalies map[string][]string // By user ID
+	Closed    int
+	Absent    int
+	OnLeave   int
+}
+
+// sweepWorkDay closes the day's check-ins without a check-out under each user's policy, records the scheduled
+// users who never checked in as absent or on leave, and lists what each user has to fix.
+// Shifts still running at now are left open.
+func sweepWorkDay(in sweepInput, loc *time.Location) sweepResult {
+	result := sweepResult{Anomalies: make(map[string][]string)}
+	dayKey := in.Day.Format("2006-01-02")
+
+	schedules := make(map[string]*models.WorkSchedule)
+	var userIDs []string
+	for i := range in.Schedules {
+		userID := in.Schedules[i].UserID
+		if schedules[userID] == nil {
+			schedules[userID] = &in.Schedules[i]
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	leaves := make(map[string][]models.LeaveRequest)
+	for _, leave := range in.Leaves {
+		leaves[leave.UserID] = append(leaves[leave.UserID], leave)
+	}
+
+	checkIns := make(map[string]*models.WorkAttendanceRecord)
+	checkOuts := make(map[string]*models.WorkAttendanceRecord)
+	swept := make(map[string]bool)
+	for i := range in.Records {
+		record := &in.Records[i]
+		switch record.AttendanceType {
+		case "CHECK_IN":
+			if checkIns[record.UserID] == nil {
+				checkIns[record.UserID] = record
+				if schedules[record.UserID] == nil {
+					userIDs = append(userIDs, record.UserID)
+				}
+			}
+		case "CHECK_OUT":
+			checkOuts[record.UserID] = record
+		case attendanceTypeAbsence:
+			swept[record.UserID] = true
+		}
+	}
+	sort.Strings(userIDs)
+
+	for _, userID := range userIDs {
+		schedule := schedules[userID]
+		checkIn := checkIns[userID]
+		policy := in.Policies[userID]
+		if policy == nil {
+			policy = defaultWorkPolicy()
+		}
+		if schedule != nil && (schedule.IsHoliday || holidayOn(in.Holidays, dayKey, loc)) {
+			schedule = nil
+		}
+
+		if checkIn == nil {
+			if schedule == nil || swept[userID] {
+				continue
+			}
+			status := models.StatusAbsent
+			notes := "no check-in"
+			if leave := leaveOn(leaves[userID], dayKey); leave != nil {
+				status = models.StatusOnLeave
+				if leave.LeaveType == models.LeaveTypeSick {
+					status = models.StatusSickLeave
+				}
+				notes = string(leave.LeaveType)
+				result.OnLeave++
+			} else {
+				start, _ := shiftWindow(schedule, loc)
+				result.Anomalies[userID] = append(result.Anomalies[userID],
+					fmt.Sprintf("absent from the %s shift, no check-in", start.Format("15:04")))
+				result.Absent++
+			}
+			result.Records = append(result.Records, sweptRecord(in, userID, &schedule.ID, attendanceTypeAbsence, status, in.Now, notes))
+			continue
+		}
+
+		checkInAt := recordTime(checkIn)
+		if checkIn.Status == models.StatusLateIn {
+			result.Anomalies[userID] = append(result.Anomalies[userID],
+				fmt.Sprintf("checked in late at %s", checkInAt.In(loc).Format("15:04")))
+		}
+
+		if checkOut := checkOuts[userID]; checkOut != nil {
+			if checkOut.Status == models.StatusEarlyOut {
+				result.Anomalies[userID] = append(result.Anomalies[userID],
+					fmt.Sprintf("checked out early at %s", recordTime(checkOut).In(loc).Format("15:04")))
+			}
+			continue
+		}
+
+		closeAt := checkInAt
+		anomaly := "no check-out, the day was closed with no hours"
+		if schedule != nil {
+			_, end := shiftWindow(schedule, loc)
+			if end.After(in.Now) {
+				continue
+			}
+			if policy.MissingCheckOut == models.MissingCheckOutShiftEnd {
+				closeAt = end
+				anomaly = fmt.Sprintf("no check-out, the day was closed at the shift end (%s)", end.Format("15:04"))
+			}
+		}
+
+		record := sweptRecord(in, userID, checkIn.ScheduleID, "CHECK_OUT", checkOutStatus(policy, schedule, checkInAt, closeAt, loc), closeAt, "missing check-out")
+		record.AutoClosed = true
+		record.PolicyID, record.PolicyVersion = workPolicyRef(policy)
+		result.Records = append(result.Records, record)
+		result.Anomalies[userID] = append(result.Anomalies[userID], anomaly)
+		result.Closed++
+	}
+
+	return result
+}
+
+// sweptRecord builds a record of the daily sweep, counting at effectiveAt
+func sweptRecord(in sweepInput, userID string, scheduleID *string, attendanceType string, status models.WorkAttendanceStatus, effectiveAt time.Time, notes string) models.WorkAttendanceRecord {
+	workDate := in.Day
+	correctableUntil := in.CorrectableUntil
+	return models.WorkAttendanceRecord{
+		ScheduleID:       scheduleID,
+		UserID:           userID,
+		AttendanceType:   attendanceType,
+		RecordedAt:       in.Now,
+		WorkDate:         &workDate,
+		Status:           status,
+		EffectiveAt:      &effectiveAt,
+		Notes:            notes,
+		CorrectableUntil: &correctableUntil,
+	}
+}
+
+// sweepDays returns the work days to sweep up to and including yesterday: every day after the last
+// completed sweep, at most maxSweepCatchUp of them, or only yesterday when nothing was swept yet
+func sweepDays(lastSwept *time.Time, yesterday time.Time) []time.Time {
+	first := yesterday
+	if lastSwept != nil {
+		first = lastSwept.AddDate(0, 0, 1)
+	}
+	if earliest := yesterday.AddDate(0, 0, 1-maxSweepCatchUp); first.Before(earliest) {
+		first = earliest
+	}
+
+	var days []time.Time
+	for day := first; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// RunDailySweep sweeps, once the configured sweep time has passed today, every work day not swept since
+// the last completed sweep, so days missed while the service was down are swept too.
+// Returns the sweeps made, none when it is too early or the days were already swept.
+func (s *AttendanceService) RunDailySweep(ctx context.Context, now time.Time) ([]*models.WorkAttendanceSweep, error) {
+	loc := s.workLocation()
+
+	sweepTime, err := time.Parse("15:04", s.scan.Sweep.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep time %q: %w", s.scan.Sweep.Time, err)
+	}
+	local := now.In(loc)
+	if local.Before(time.Date(local.Year(), local.Month(), local.Day(), sweepTime.Hour(), sweepTime.Minute(), 0, 0, loc)) {
+		return nil, nil
+	}
+
+	last, err := s.repo.GetLatestCompletedWorkSweep(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var lastSwept *time.Time
+	if last != nil {
+		lastSwept = &last.WorkDate
+	}
+
+	var sweeps []*models.WorkAttendanceSweep
+	for _, day := range sweepDays(lastSwept, workDay(now, loc).AddDate(0, 0, -1)) {
+		sweep := &models.WorkAttendanceSweep{
+			WorkDate:         day,
+			StartedAt:        now,
+			CorrectableUntil: now.Add(s.scan.Sweep.CorrectionWindow),
+		}
+		claimed, err := s.repo.ClaimWorkSweep(ctx, sweep)
+		if err != nil {
+			return sweeps, err
+		}
+		if !claimed {
+			continue
+		}
+
+		result, err := s.sweepClaimedDay(ctx, sweep, now)
+		if err != nil {
+			// Release the day and stop, the next run sweeps it again before any later day
+			_ = s.repo.ReleaseWorkSweep(ctx, sweep.ID)
+			return sweeps, err
+		}
+
+		s.notifySweepAnomalies(ctx, sweep, result.Anomalies)
+		sweeps = append(sweeps, sweep)
+	}
+
+	return sweeps, nil
+}
+
+// sweepClaimedDay sweeps the work day of a claimed sweep and stores its records and totals
+func (s *AttendanceService) sweepClaimedDay(ctx context.Context, sweep *models.WorkAttendanceSweep, now time.Time) (*sweepResult, error) {
+	input, err := s.loadSweepInput(ctx, sweep.WorkDate, now)
+	if err != nil {
+		return nil, err
+	}
+	input.CorrectableUntil = sweep.CorrectableUntil
+
+	result := sweepWorkDay(*input, s.workLocation())
+
+	completedAt := time.Now()
+	sweep.CompletedAt = &completedAt
+	sweep.ClosedCheckOuts = result.Closed
+	sweep.AbsentCount = result.Absent
+	sweep.OnLeaveCount = result.OnLeave
+	sweep.NotifiedUsers = len(result.Anomalies)
+	if err := s.repo.CompleteWorkSweep(ctx, sweep, result.Records); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// loadSweepInput loads the schedules, records, leave, holidays and policies of a work day
+func (s *AttendanceService) loadSweepInput(ctx context.Context, day, now time.Time) (*sweepInput, error) {
+	schedules, err := s.repo.GetWorkSchedulesOnDate(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetWorkAttendanceRecordsOnWorkDate(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.calendarRepo.GetHolidays(ctx, day, day)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleOf := make(map[string]*models.WorkSchedule)
+	var scheduledIDs []string
+	for i := range schedules {
+		if scheduleOf[schedules[i].UserID] == nil {
+			scheduleOf[schedules[i].UserID] = &schedules[i]
+			scheduledIDs = append(scheduledIDs, schedules[i].UserID)
+		}
+	}
+
+	var leaves []models.LeaveRequest
+	if len(scheduledIDs) > 0 {
+		if leaves, err = s.leaveRepo.GetApprovedLeavesCoveringDate(ctx, scheduledIDs, day); err != nil {
+			return nil, err
+		}
+	}
+
+	policies := make(map[string]*models.WorkAttendancePolicy)
+	resolve := func(userID string) error {
+		if policies[userID] != nil {
+			return nil
+		}
+		policy, err := s.resolveWorkPolicy(ctx, userID, scheduleOf[userID])
+		if err != nil {
+			return err
+		}
+		policies[userID] = policy
+		return nil
+	}
+	for _, userID := range scheduledIDs {
+		if err := resolve(userID); err != nil {
+			return nil, err
+		}
+	}
+	for _, record := range records {
+		if err := resolve(record.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sweepInput{
+		Day:       day,
+		Now:       now,
+		Schedules: schedules,
+		Records:   records,
+		Leaves:    leaves,
+		Holidays:  holidays,
+		Policies:  policies,
+	}, nil
+}
+
+// notifySweepAnomalies sends each user their anomalies of the swept day, and each unit supervisor
+// a summary of their unit. Failures are ignored, the sweep itself is stored.
+func (s *AttendanceService) notifySweepAnomalies(ctx context.Context, sweep *models.WorkAttendanceSweep, anomalies map[string][]string) {
+	date := sweep.WorkDate.Format("2006-01-02")
+	deadline := sweep.CorrectableUntil.In(s.workLocation()).Format("2006-01-02 15:04")
+
+	userIDs := make([]string, 0, len(anomalies))
+	for userID := range anomalies {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	byUnit := make(map[string][]map[string]interface{})
+	for _, userID := range userIDs {
+		data, _ := json.Marshal(map[string]interface{}{
+			"work_date":         date,
+			"anomalies":         anomalies[userID],
+			"correctable_until": sweep.CorrectableUntil,
+		})
+		_ = s.notificationRepo.CreateNotification(ctx, &models.Notification{
+			UserID:  userID,
+			Title:   "Work attendance anomalies on " + date,
+			Message: strings.Join(anomalies[userID], "; ") + ". Ask your supervisor to correct them before " + deadline + ".",
+			Type:    models.NotificationTypeWarning,
+			Data:    string(data),
+		})
+
+		if unit, err := s.repo.GetUserUnit(ctx, userID); err == nil && unit != "" {
+			byUnit[unit] = append(byUnit[unit], map[string]interface{}{"user_id": userID, "anomalies": anomalies[userID]})
+		}
+	}
+
+	for unit, users := range byUnit {
+		supervisorIDs, err := s.repo.GetUnitSupervisorIDs(ctx, unit)
+		if err != nil {
+			continue
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"work_date":         date,
+			"unit":              unit,
+			"users":             users,
+			"correctable_until": sweep.CorrectableUntil,
+		})
+		for _, supervisorID := range supervisorIDs {
+			_ = s.notificationRepo.CreateNotification(ctx, &models.Notification{
+				UserID:  supervisorID,
+				Title:   "Work attendance anomalies in " + unit + " on " + date,
+				Message: fmt.Sprintf("%d users of %s have attendance anomalies on %s. Review and correct them before %s.", len(users), unit, date, deadline),
+				Type:    models.NotificationTypeWarning,
+				Data:    string(data),
+			})
+		}
+	}
+}
+
+// GetWorkSweepsRequest represents get work attendance sweeps request
+type GetWorkSweepsRequest struct {
+	Page    int `form:"page,default=1"`
+	PerPage int `form:"per_page,default=20"`
+}
+
+// GetWorkSweeps gets the daily sweeps, latest work day first
+func (s *AttendanceService) GetWorkSweeps(ctx context.Context, req GetWorkSweepsRequest) ([]models.WorkAttendanceSweep, int64, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	sweeps, total, err := s.repo.GetWorkSweeps(ctx, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get work attendance sweeps", err)
+	}
+	return sweeps, total, nil
+}
+
+// AmendSweptRecordRequest represents amend swept record request.
+// Auto-closed check-outs take the actual check-out time, absences a status.
+type AmendSweptRecordRequest struct {
+	CheckOutAt *string `json:"check_out_at,omitempty"` // RFC3339
+	Status     *string `json:"status,omitempty" binding:"omitempty,oneof=ABSENT ON_LEAVE SICK_LEAVE"`
+	Notes      string  `json:"notes" binding:"required"`
+}
+
+// isUnitSupervisorOf reports whether the supervisor is assigned to the unit of the user
+// (staff unit, or dosen prodi). Users without a unit have no supervisor.
+func (s *AttendanceService) isUnitSupervisorOf(ctx context.Context, supervisorID, userID string) (bool, error) {
+	unit, err := s.repo.GetUserUnit(ctx, userID)
+	if err != nil || unit == "" {
+		return false, err
+	}
+
+	assignment, err := s.repo.GetUnitSupervisor(ctx, unit, supervisorID)
+	if err != nil {
+		return false, err
+	}
+	return assignment != nil, nil
+}
+
+// AmendSweptRecord corrects a record of the daily sweep within its correction window.
+// Only a supervisor of the record owner's unit can amend it, never their own records.
+func (s *AttendanceService) AmendSweptRecord(ctx context.Context, supervisorID string, id string, req AmendSweptRecordRequest) (*models.WorkAttendanceRecord, error) {
+	record, err := s.repo.GetWorkAttendanceRecordByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("work attendance record", id)
+	}
+	if !record.AutoClosed && record.AttendanceType != attendanceTypeAbsence {
+		return nil, apperrors.NewValidationError("only records of the daily sweep can be amended")
+	}
+	if record.UserID == supervisorID {
+		return nil, apperrors.NewForbiddenError("you cannot amend your own attendance")
+	}
+	supervises, err := s.isUnitSupervisorOf(ctx, supervisorID, record.UserID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check unit supervisor", err)
+	}
+	if !supervises {
+		return nil, apperrors.NewForbiddenError("only a supervisor of the user's unit can amend this record")
+	}
+	now := time.Now()
+	if record.CorrectableUntil == nil || now.After(*record.CorrectableUntil) {
+		return nil, apperrors.NewConflictError("the correction window of this record has closed")
+	}
+
+	if record.AutoClosed {
+		if req.CheckOutAt == nil {
+			return nil, apperrors.NewValidationError("check_out_at is required to amend a missing check-out")
+		}
+		if err := s.amendCheckOut(ctx, record, *req.CheckOutAt, now); err != nil {
+			return nil, err
+		}
+	} else {
+		if req.Status == nil {
+			return nil, apperrors.NewValidationError("status is required to amend an absence")
+		}
+		record.Status = models.WorkAttendanceStatus(*req.Status)
+	}
+
+	record.AmendedBy = &supervisorID
+	record.AmendedAt = &now
+	record.Notes = strings.TrimSpace(record.Notes + "; amended: " + req.Notes)
+
+	if err := s.repo.AmendWorkAttendanceRecord(ctx, record); err != nil {
+		return nil, apperrors.NewInternalError("failed to amend work attendance record", err)
+	}
+
+	return record, nil
+}
+
+// amendCheckOut moves an auto-closed check-out to the actual check-out time and judges it again
+func (s *AttendanceService) amendCheckOut(ctx context.Context, record *models.WorkAttendanceRecord, value string, now time.Time) error {
+	checkOutAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return apperrors.NewValidationError("invalid check_out_at format, use RFC3339")
+	}
+
+	checkIn, err := s.repo.GetWorkAttendanceRecordByWorkDate(ctx, record.UserID, *record.WorkDate, "CHECK_IN")
+	if err != nil || checkIn == nil {
+		return apperrors.NewConflictError("the check-in of this work day no longer exists")
+	}
+	checkInAt := recordTime(checkIn)
+	if !checkOutAt.After(checkInAt) || checkOutAt.Sub(checkInAt) > maxWorkShiftSpan || checkOutAt.After(now) {
+		return apperrors.NewValidationError("check_out_at must be after the check-in, within a shift and not in the future")
+	}
+
+	var schedule *models.WorkSchedule
+	if record.ScheduleID != nil {
+		schedule, _ = s.repo.GetWorkScheduleByID(ctx, *record.ScheduleID)
+	}
+	policy := defaultWorkPolicy()
+	if record.PolicyID != nil {
+		if stored, err := s.repo.GetWorkPolicyByID(ctx, *record.PolicyID); err == nil {
+			policy = stored
+		}
+	}
+
+	record.EffectiveAt = &checkOutAt
+	record.Status = checkOutStatus(policy, schedule, checkInAt, checkOutAt, s.workLocation())
+	return nil
+}
+
+// AssignUnitSupervisorRequest represents assign unit supervisor request
+type AssignUnitSupervisorRequest struct {
+	Unit         string `json:"unit" binding:"required"`
+	SupervisorID string `json:"supervisor_id" binding:"required,uuid"`
+}
+
+// AssignUnitSupervisor makes a user receive the daily anomaly summaries of a unit
+func (s *AttendanceService) AssignUnitSupervisor(ctx context.Context, userID string, req AssignUnitSupervisorRequest) (*models.UnitSupervisor, error) {
+	unit := strings.TrimSpace(req.Unit)
+	if unit == "" {
+		return nil, apperrors.NewValidationError("unit is required")
+	}
+
+	existing, err := s.repo.GetUnitSupervisor(ctx, unit, req.SupervisorID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get unit supervisor", err)
+	}
+	if existing != nil {
+		return nil, apperrors.NewConflictError("user already supervises this unit")
+	}
+
+	supervisor := &models.UnitSupervisor{
+		Unit:         unit,
+		SupervisorID: req.SupervisorID,
+		CreatedBy:    userID,
+	}
+	if err := s.repo.CreateUnitSupervisor(ctx, supervisor); err != nil {
+		return nil, apperrors.NewInternalError("failed to assign unit supervisor", err)
+	}
+
+	return supervisor, nil
+}
+
+// GetUnitSupervisors gets the unit supervisors, optionally of one unit
+func (s *AttendanceService) GetUnitSupervisors(ctx context.Context, unit *string) ([]models.UnitSupervisor, error) {
+	supervisors, err := s.repo.GetUnitSupervisors(ctx, unit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get unit supervisors", err)
+	}
+	return supervisors, nil
+}
+
+// RemoveUnitSupervisor removes a supervisor from a unit
+func (s *AttendanceService) RemoveUnitSupervisor(ctx context.Context, id string) error {
+	deleted, err := s.repo.DeleteUnitSupervisor(ctx, id)
+	if err != nil {
+		return apperrors.NewInternalError("failed to remove unit supervisor", err)
+	}
+	if !deleted {
+		return apperrors.NewNotFoundError("unit supervisor", id)
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// timesheetInput holds what a month's timesheet is computed from
+type timesheetInput struct {
+	Schedules []models.WorkSchedule
+	Records   []models.WorkAttendanceRecord
+	Leaves    []models.LeaveRequest
+	Holidays  []models.AcademicEvent
+	Policies  map[string]*models.WorkAttendancePolicy // By work schedule ID
+	Policy    *models.WorkAttendancePolicy            // For days without a schedule
+}
+
+// monthRange returns the first and last day of a month, as dates
+func monthRange(year, month int) (time.Time, time.Time) {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return first, first.AddDate(0, 1, -1)
+}
+
+// recordTime returns the time a record counts at: its rounded time, otherwise when it was recorded
+func recordTime(record *models.WorkAttendanceRecord) time.Time {
+	if record.EffectiveAt != nil {
+		return *record.EffectiveAt
+	}
+	return record.RecordedAt
+}
+
+// recordWorkDate returns the work day a record counts for
+func recordWorkDate(record *models.WorkAttendanceRecord, loc *time.Location) string {
+	if record.WorkDate != nil {
+		return record.WorkDate.Format("2006-01-02")
+	}
+	return workDay(record.RecordedAt, loc).Format("2006-01-02")
+}
+
+// wholeMinutes converts a duration to whole minutes, negative durations count as zero
+func wholeMinutes(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(d / time.Minute)
+}
+
+// holidayOn reports whether a holiday event covers a day
+func holidayOn(holidays []models.AcademicEvent, day string, loc *time.Location) bool {
+	for _, holiday := range holidays {
+		if workDay(holiday.StartDate, loc).Format("2006-01-02") <= day && day <= workDay(holiday.EndDate, loc).Format("2006-01-02") {
+			return true
+		}
+	}
+	return false
+}
+
+// leaveOn returns the approved leave covering a day, nil when there is none
+func leaveOn(leaves []models.LeaveRequest, day string) *models.LeaveRequest {
+	for i := range leaves {
+		if leaves[i].StartDate.Format("2006-01-02") <= day && day <= leaves[i].EndDate.Format("2006-01-02") {
+			return &leaves[i]
+		}
+	}
+	return nil
+}
+
+// fillWorkedDay computes the minutes of a day with a check-in.
+// schedule is nil for unscheduled days and holidays, whose whole worked time is overtime.
+func fillWorkedDay(day *models.WorkTimesheetDay, policy *models.WorkAttendancePolicy, schedule *models.WorkSchedule, checkIn, checkOut *models.WorkAttendanceRecord, loc *time.Location) {
+	in := recordTime(checkIn)
+	day.CheckInAt = &in
+	if checkOut == nil {
+		day.MissingCheckOut = true
+		day.Notes = "missing check-out"
+		return
+	}
+	out := recordTime(checkOut)
+	day.CheckOutAt = &out
+	if checkOut.AutoClosed && checkOut.AmendedAt == nil {
+		day.MissingCheckOut = true
+		day.Notes = "missing check-out, closed by the daily sweep"
+	}
+
+	// A break is only taken out of days longer than it
+	span := out.Sub(in)
+	breakTime := workBreak(policy, schedule)
+	if span <= breakTime {
+		breakTime = 0
+	}
+	worked := span - breakTime
+	day.BreakMinutes = wholeMinutes(breakTime)
+	day.WorkedMinutes = wholeMinutes(worked)
+
+	if schedule == nil {
+		day.OvertimeMinutes = day.WorkedMinutes
+		return
+	}
+
+	start, end := shiftWindow(schedule, loc)
+	if in.After(start.Add(time.Duration(policy.LateGraceMinutes) * time.Minute)) {
+		day.LateMinutes = wholeMinutes(in.Sub(start))
+	}
+	if out.Before(end.Add(-time.Duration(policy.EarlyOutGraceMinutes) * time.Minute)) {
+		day.EarlyOutMinutes = wholeMinutes(end.Sub(out))
+	}
+	day.OvertimeMinutes = wholeMinutes(worked - (end.Sub(start) - workBreak(policy, schedule)))
+}
+
+// computeTimesheet fills the days and totals of a month's timesheet.
+// A day with a check-in is present; otherwise it is leave, holiday, absent when scheduled, or off.
+func computeTimesheet(timesheet *models.WorkTimesheet, in timesheetInput, loc *time.Location) {
+	schedules := make(map[string]*models.WorkSchedule)
+	for i := range in.Schedules {
+		day := in.Schedules[i].ScheduleDate.Format("2006-01-02")
+		if schedules[day] == nil {
+			schedules[day] = &in.Schedules[i]
+		}
+	}
+
+	// The first check-in and the last check-out of a work day count
+	checkIns := make(map[string]*models.WorkAttendanceRecord)
+	checkOuts := make(map[string]*models.WorkAttendanceRecord)
+	for i := range in.Records {
+		record := &in.Records[i]
+		day := recordWorkDate(record, loc)
+		switch record.AttendanceType {
+		case "CHECK_IN":
+			if checkIns[day] == nil || recordTime(record).Before(recordTime(checkIns[day])) {
+				checkIns[day] = record
+			}
+		case "CHECK_OUT":
+			if checkOuts[day] == nil || recordTime(record).After(recordTime(checkOuts[day])) {
+				checkOuts[day] = record
+			}
+		}
+	}
+
+	*timesheet = models.WorkTimesheet{
+		ID:         timesheet.ID,
+		UserID:     timesheet.UserID,
+		Year:       timesheet.Year,
+		Month:      timesheet.Month,
+		Status:     models.TimesheetStatusDraft,
+		ComputedAt: timesheet.ComputedAt,
+		CreatedAt:  timesheet.CreatedAt,
+	}
+
+	first, last := monthRange(timesheet.Year, timesheet.Month)
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		key := date.Format("2006-01-02")
+		day := models.WorkTimesheetDay{WorkDate: date}
+
+		policy := in.Policy
+		schedule := schedules[key]
+		if schedule != nil {
+			day.ScheduleID = &schedule.ID
+			if scheduled := in.Policies[schedule.ID]; scheduled != nil {
+				policy = scheduled
+			}
+		}
+		day.PolicyID, _ = workPolicyRef(policy)
+
+		holiday := (schedule != nil && schedule.IsHoliday) || holidayOn(in.Holidays, key, loc)
+		workingSchedule := schedule
+		if holiday {
+			workingSchedule = nil
+		}
+		if workingSchedule != nil {
+			timesheet.ScheduledDays++
+		}
+
+		leave := leaveOn(in.Leaves, key)
+		switch {
+		case checkIns[key] != nil:
+			day.DayType = models.TimesheetDayPresent
+			fillWorkedDay(&day, policy, workingSchedule, checkIns[key], checkOuts[key], loc)
+			timesheet.PresentDays++
+		case leave != nil:
+			day.DayType = models.TimesheetDayLeave
+			day.LeaveRequestID = &leave.ID
+			day.Notes = string(leave.LeaveType)
+			timesheet.LeaveDays++
+		case holiday:
+			day.DayType = models.TimesheetDayHoliday
+			timesheet.HolidayDays++
+		case schedule != nil:
+			day.DayType = models.TimesheetDayAbsent
+			timesheet.AbsentDays++
+		default:
+			day.DayType = models.TimesheetDayOff
+		}
+
+		timesheet.WorkedMinutes += day.WorkedMinutes
+		timesheet.LateMinutes += day.LateMinutes
+		timesheet.EarlyOutMinutes += day.EarlyOutMinutes
+		timesheet.OvertimeMinutes += day.OvertimeMinutes
+		timesheet.Days = append(timesheet.Days, day)
+	}
+}
+
+// GenerateTimesheetRequest represents generate timesheet request
+type GenerateTimesheetRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Year   int    `json:"year" binding:"required,min=2000"`
+	Month  int    `json:"month" binding:"required,min=1,max=12"`
+}
+
+// GenerateTimesheet computes or recomputes a user's timesheet for a month, as a supervisor of the user's unit
+// or staff, never for oneself. Locked timesheets are final, an approved one returns to draft.
+func (s *AttendanceService) GenerateTimesheet(ctx context.Context, userID string, role string, req GenerateTimesheetRequest) (*models.WorkTimesheet, error) {
+	if req.UserID == userID {
+		return nil, apperrors.NewForbiddenError("cannot compute your own timesheet")
+	}
+	if err := s.checkTimesheetReviewer(ctx, userID, role, &models.WorkTimesheet{UserID: req.UserID}); err != nil {
+		return nil, err
+	}
+
+	loc := s.workLocation()
+	now := time.Now()
+
+	first, last := monthRange(req.Year, req.Month)
+	if first.After(workDay(now, loc)) {
+		return nil, apperrors.NewValidationError("cannot compute a timesheet for a future month")
+	}
+
+	timesheet, err := s.repo.GetTimesheet(ctx, req.UserID, req.Year, req.Month)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get timesheet", err)
+	}
+	if timesheet != nil && timesheet.Status == models.TimesheetStatusLocked {
+		return nil, apperrors.NewConflictError("timesheet is locked")
+	}
+	if timesheet == nil {
+		timesheet = &models.WorkTimesheet{UserID: req.UserID, Year: req.Year, Month: req.Month}
+	}
+
+	input, err := s.loadTimesheetInput(ctx, req.UserID, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet.ComputedAt = now
+	computeTimesheet(timesheet, *input, loc)
+
+	if err := s.repo.SaveTimesheet(ctx, timesheet); err != nil {
+		return nil, apperrors.NewInternalError("failed to save timesheet", err)
+	}
+
+	return timesheet, nil
+}
+
+// loadTimesheetInput loads a user's schedules, records, approved leave, holidays and policies for a date range
+func (s *AttendanceService) loadTimesheetInput(ctx context.Context, userID string, first, last time.Time) (*timesheetInput, error) {
+	schedules, err := s.repo.GetWorkSchedulesByUserID(ctx, userID, &first, &last)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get work schedules", err)
+	}
+	records, err := s.repo.GetWorkAttendanceRecordsByWorkDates(ctx, userID, first, last)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get work attendance records", err)
+	}
+	leaves, err := s.leaveRepo.GetApprovedLeavesInRange(ctx, userID, first, last)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get leave requests", err)
+	}
+	holidays, err := s.calendarRepo.GetHolidays(ctx, first, last)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get holidays", err)
+	}
+
+	input := &timesheetInput{
+		Schedules: schedules,
+		Records:   records,
+		Leaves:    leaves,
+		Holidays:  holidays,
+		Policies:  make(map[string]*models.WorkAttendancePolicy),
+	}
+	input.Policy, err = s.resolveWorkPolicy(ctx, userID, nil)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve work attendance policy", err)
+	}
+	for i := range schedules {
+		input.Policies[schedules[i].ID], err = s.resolveWorkPolicy(ctx, userID, &schedules[i])
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to resolve work attendance policy", err)
+		}
+	}
+
+	return input, nil
+}
+
+// GetTimesheetsRequest represents get timesheets request
+type GetTimesheetsRequest struct {
+	UserID  *string `form:"user_id"`
+	Year    *int    `form:"year"`
+	Month   *int    `form:"month"`
+	Status  *string `form:"status"`
+	Page    int     `form:"page,default=1"`
+	PerPage int     `form:"per_page,default=20"`
+}
+
+// GetTimesheets gets timesheets without their days. Besides their own, supervisors only see the timesheets
+// of the units they supervise; staff see all.
+func (s *AttendanceService) GetTimesheets(ctx context.Context, userID string, role string, req GetTimesheetsRequest) ([]models.WorkTimesheet, int64, error) {
+	var units []string
+	if role != string(models.RoleStaff) && (req.UserID == nil || *req.UserID != userID) {
+		supervised, err := s.repo.GetSupervisedUnits(ctx, userID)
+		if err != nil {
+			return nil, 0, apperrors.NewInternalError("failed to get supervised units", err)
+		}
+		if len(supervised) == 0 {
+			return []models.WorkTimesheet{}, 0, nil
+		}
+		units = supervised
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	timesheets, total, err := s.repo.GetTimesheets(ctx, req.UserID, units, req.Year, req.Month, req.Status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("failed to get timesheets", err)
+	}
+	return timesheets, total, nil
+}
+
+// GetTimesheet gets a timesheet with its days, visible to its owner, the supervisors of the owner's unit and staff
+func (s *AttendanceService) GetTimesheet(ctx context.Context, userID string, role string, id string) (*models.WorkTimesheet, error) {
+	timesheet, err := s.repo.GetTimesheetByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("timesheet", id)
+	}
+	if timesheet.UserID != userID {
+		if err := s.checkTimesheetReviewer(ctx, userID, role, timesheet); err != nil {
+			return nil, err
+		}
+	}
+	return timesheet, nil
+}
+
+// isSupervisorRole reports whether a role may supervise others, as it approves leave
+func isSupervisorRole(role string) bool {
+	return role == string(models.RoleStaff) || role == string(models.RoleDosen)
+}
+
+// checkTimesheetReviewer refuses a reviewer who is neither staff nor a supervisor of the timesheet owner's unit
+func (s *AttendanceService) checkTimesheetReviewer(ctx context.Context, userID string, role string, timesheet *models.WorkTimesheet) error {
+	if role == string(models.RoleStaff) {
+		return nil
+	}
+	supervises, err := s.isUnitSupervisorOf(ctx, userID, timesheet.UserID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to check unit supervisor", err)
+	}
+	if !supervises {
+		return apperrors.NewForbiddenError("only a supervisor of the user's unit or staff can review this timesheet")
+	}
+	return nil
+}
+
+// ApproveTimesheet approves a draft timesheet. Supervisors cannot approve their own.
+func (s *AttendanceService) ApproveTimesheet(ctx context.Context, userID string, role string, id string) (*models.WorkTimesheet, error) {
+	timesheet, err := s.getReviewableTimesheet(ctx, userID, role, id, models.TimesheetStatusDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timesheet.Status = models.TimesheetStatusApproved
+	timesheet.ApprovedBy = &userID
+	timesheet.ApprovedAt = &now
+
+	if err := s.repo.UpdateTimesheet(ctx, timesheet); err != nil {
+		return nil, apperrors.NewInternalError("failed to approve timesheet", err)
+	}
+	return timesheet, nil
+}
+
+// LockTimesheet locks an approved timesheet, it can no longer be recomputed
+func (s *AttendanceService) LockTimesheet(ctx context.Context, userID string, role string, id string) (*models.WorkTimesheet, error) {
+	timesheet, err := s.getReviewableTimesheet(ctx, userID, role, id, models.TimesheetStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timesheet.Status = models.TimesheetStatusLocked
+	timesheet.LockedBy = &userID
+	timesheet.LockedAt = &now
+
+	if err := s.repo.UpdateTimesheet(ctx, timesheet); err != nil {
+		return nil, apperrors.NewInternalError("failed to lock timesheet", err)
+	}
+	return timesheet, nil
+}
+
+// getReviewableTimesheet gets a timesheet in the expected status that the user may review
+func (s *AttendanceService) getReviewableTimesheet(ctx context.Context, userID string, role string, id string, status models.TimesheetStatus) (*models.WorkTimesheet, error) {
+	timesheet, err := s.repo.GetTimesheetByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("timesheet", id)
+	}
+	if timesheet.UserID == userID {
+		return nil, apperrors.NewForbiddenError("cannot review your own timesheet")
+	}
+	if err := s.checkTimesheetReviewer(ctx, userID, role, timesheet); err != nil {
+		return nil, err
+	}
+	if timesheet.Status != status {
+		return nil, apperrors.NewConflictError("timesheet is " + string(timesheet.Status) + ", expected " + string(status))
+	}
+	return timesheet, nil
+}
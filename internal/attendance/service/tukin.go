@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/spreadsheet"
+)
+
+// roundPercent rounds a percentage to the two decimals deductions are stored with
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
+
+// formatPercent formats a percentage the way finance reads it, with two decimals
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', 2, 64)
+}
+
+// tukinRule finds the rule of a category for a number of minutes; per day categories ignore the minutes
+func tukinRule(rules []models.TukinRule, category models.TukinCategory, minutes int) *models.TukinRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Category != category {
+			continue
+		}
+		if category == models.TukinCategoryAbsent || category == models.TukinCategoryMissingCheckOut {
+			return rule
+		}
+		if minutes >= rule.MinMinutes && (rule.MaxMinutes == nil || minutes <= *rule.MaxMinutes) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// tukinLine builds the deduction line of a rule with its explanation
+func tukinLine(rule *models.TukinRule, day *models.WorkTimesheetDay, minutes int) models.TukinDeductionLine {
+	date := day.WorkDate.Format("2006-01-02")
+	var explanation string
+	switch rule.Category {
+	case models.TukinCategoryLate:
+		explanation = fmt.Sprintf("Checked in %d minutes late on %s", minutes, date)
+	case models.TukinCategoryEarlyOut:
+		explanation = fmt.Sprintf("Checked out %d minutes early on %s", minutes, date)
+	case models.TukinCategoryMissingCheckOut:
+		explanation = fmt.Sprintf("No check-out on %s", date)
+	case models.TukinCategoryAbsent:
+		explanation = fmt.Sprintf("Absent without leave on %s", date)
+	}
+
+	return models.TukinDeductionLine{
+		WorkDate:    day.WorkDate,
+		Category:    rule.Category,
+		Code:        rule.Code,
+		Minutes:     minutes,
+		Percent:     rule.Percent,
+		Explanation: fmt.Sprintf("%s: %s %s%%", explanation, rule.Code, formatPercent(rule.Percent)),
+	}
+}
+
+// computeTukinDeduction applies a rule set to the days of a timesheet.
+// Absent days take the ABSENT rule. Present days take the LATE tier of their late minutes, and either the
+// MISSING_CHECK_OUT rule or the EARLY_OUT tier of their early minutes. The total is capped by the rule set.
+func computeTukinDeduction(deduction *models.TukinDeduction, timesheet *models.WorkTimesheet, ruleSet *models.TukinRuleSet) {
+	deduction.Lines = nil
+	deduction.RuleSetID = ruleSet.ID
+	deduction.RuleSetVersion = ruleSet.Version
+
+	total := 0.0
+	add := func(rule *models.TukinRule, day *models.WorkTimesheetDay, minutes int) {
+		if rule == nil {
+			return
+		}
+		line := tukinLine(rule, day, minutes)
+		deduction.Lines = append(deduction.Lines, line)
+		total += line.Percent
+	}
+
+	for i := range timesheet.Days {
+		day := &timesheet.Days[i]
+		switch day.DayType {
+		case models.TimesheetDayAbsent:
+			add(tukinRule(ruleSet.Rules, models.TukinCategoryAbsent, 0), day, 0)
+		case models.TimesheetDayPresent:
+			if day.LateMinutes > 0 {
+				add(tukinRule(ruleSet.Rules, models.TukinCategoryLate, day.LateMinutes), day, day.LateMinutes)
+			}
+			if day.MissingCheckOut || day.CheckOutAt == nil {
+				add(tukinRule(ruleSet.Rules, models.TukinCategoryMissingCheckOut, 0), day, 0)
+			} else if day.EarlyOutMinutes > 0 {
+				add(tukinRule(ruleSet.Rules, models.TukinCategoryEarlyOut, day.EarlyOutMinutes), day, day.EarlyOutMinutes)
+			}
+		}
+	}
+
+	total = roundPercent(total)
+	deduction.Capped = total > ruleSet.MaxMonthlyPercent
+	if deduction.Capped {
+		total = ruleSet.MaxMonthlyPercent
+	}
+	deduction.TotalPercent = total
+}
+
+// TukinRuleInput represents one row of a deduction table
+type TukinRuleInput struct {
+	Category   string  `json:"category" binding:"required,oneof=LATE EARLY_OUT MISSING_CHECK_OUT ABSENT"`
+	Code       string  `json:"code" binding:"required,max=20"`
+	MinMinutes int     `json:"min_minutes" binding:"min=0"`
+	MaxMinutes *int    `json:"max_minutes,omitempty" binding:"omitempty,min=0"`
+	Percent    float64 `json:"percent" binding:"gt=0,lte=100"`
+}
+
+// tukinRules validates deduction tables and builds their rules: tiers of a category must not overlap,
+// per day categories have a single rule
+func tukinRules(inputs []TukinRuleInput) ([]models.TukinRule, error) {
+	rules := make([]models.TukinRule, 0, len(inputs))
+	for _, input := range inputs {
+		category := models.TukinCategory(input.Category)
+		code := strings.TrimSpace(input.Code)
+		if code == "" {
+			return nil, apperrors.NewValidationError("every rule needs a code")
+		}
+		if input.MaxMinutes != nil && *input.MaxMinutes < input.MinMinutes {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("rule %s: max_minutes must not be less than min_minutes", code))
+		}
+
+		for _, other := range rules {
+			if other.Category != category {
+				continue
+			}
+			if category == models.TukinCategoryAbsent || category == models.TukinCategoryMissingCheckOut {
+				return nil, apperrors.NewValidationError(fmt.Sprintf("%s takes a single rule", category))
+			}
+			if (other.MaxMinutes == nil || input.MinMinutes <= *other.MaxMinutes) &&
+				(input.MaxMinutes == nil || other.MinMinutes <= *input.MaxMinutes) {
+				return nil, apperrors.NewValidationError(fmt.Sprintf("rules %s and %s overlap", other.Code, code))
+			}
+		}
+
+		rules = append(rules, models.TukinRule{
+			Category:   category,
+			Code:       code,
+			MinMinutes: input.MinMinutes,
+			MaxMinutes: input.MaxMinutes,
+			Percent:    roundPercent(input.Percent),
+		})
+	}
+	return rules, nil
+}
+
+// CreateTukinRuleSetRequest represents create tukin rule set request, the rules replace the previous version's
+type CreateTukinRuleSetRequest struct {
+	EffectiveFrom     string           `json:"effective_from" binding:"required"` // YYYY-MM, the first month it applies to
+	MaxMonthlyPercent *float64         `json:"max_monthly_percent,omitempty" binding:"omitempty,gt=0,lte=100"`
+	Notes             string           `json:"notes"`
+	Rules             []TukinRuleInput `json:"rules" binding:"required,min=1,dive"`
+}
+
+// CreateTukinRuleSet stores the next version of the tukin deduction tables. Only the first version can take
+// effect before the current month, so later versions never change months already computed.
+func (s *AttendanceService) CreateTukinRuleSet(ctx context.Context, userID string, req CreateTukinRuleSetRequest) (*models.TukinRuleSet, error) {
+	effectiveFrom, err := time.Parse("2006-01", req.EffectiveFrom)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid effective_from format, use YYYY-MM")
+	}
+
+	rules, err := tukinRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repo.GetLatestTukinRuleSet(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get tukin rule sets", err)
+	}
+	today := workDay(time.Now(), s.workLocation())
+	if latest != nil && effectiveFrom.Before(time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return nil, apperrors.NewValidationError("effective_from must not be before the current month")
+	}
+
+	ruleSet := &models.TukinRuleSet{
+		Version:           1,
+		EffectiveFrom:     effectiveFrom,
+		MaxMonthlyPercent: 100,
+		Notes:             req.Notes,
+		CreatedBy:         userID,
+		Rules:             rules,
+	}
+	if latest != nil {
+		ruleSet.Version = latest.Version + 1
+		ruleSet.PreviousVersionID = &latest.ID
+	}
+	if req.MaxMonthlyPercent != nil {
+		ruleSet.MaxMonthlyPercent = roundPercent(*req.MaxMonthlyPercent)
+	}
+
+	if err := s.repo.CreateTukinRuleSet(ctx, ruleSet); err != nil {
+		return nil, apperrors.NewInternalError("failed to create tukin rule set", err)
+	}
+
+	return ruleSet, nil
+}
+
+// GetTukinRuleSets gets all versions of the tukin deduction tables, latest first
+func (s *AttendanceService) GetTukinRuleSets(ctx context.Context) ([]models.TukinRuleSet, error) {
+	ruleSets, err := s.repo.GetTukinRuleSets(ctx)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get tukin rule sets", err)
+	}
+	return ruleSets, nil
+}
+
+// GetTukinRuleSet gets a version of the tukin deduction tables
+func (s *AttendanceService) GetTukinRuleSet(ctx context.Context, id string) (*models.TukinRuleSet, error) {
+	ruleSet, err := s.repo.GetTukinRuleSetByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("tukin rule set", id)
+	}
+	return ruleSet, nil
+}
+
+// TukinPeriodRequest represents a month of tukin deductions, optionally of one unit
+type TukinPeriodRequest struct {
+	Year  int     `json:"year" form:"year" binding:"required,min=2000"`
+	Month int     `json:"month" form:"month" binding:"required,min=1,max=12"`
+	Unit  *string `json:"unit,omitempty" form:"unit"`
+}
+
+// periodUserIDs returns the users of the requested unit, nil for all users
+func (s *AttendanceService) periodUserIDs(ctx context.Context, req TukinPeriodRequest) ([]string, error) {
+	if req.Unit == nil || strings.TrimSpace(*req.Unit) == "" {
+		return nil, nil
+	}
+	userIDs, err := s.repo.GetUserIDsByUnit(ctx, strings.TrimSpace(*req.Unit))
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get unit users", err)
+	}
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+	return userIDs, nil
+}
+
+// ComputeTukinDeductions computes the deductions of a month's locked timesheets. A timesheet computed
+// before is computed again with the rule set version it used then, so the result does not change.
+func (s *AttendanceService) ComputeTukinDeductions(ctx context.Context, userID string, req TukinPeriodRequest) ([]models.TukinDeduction, error) {
+	userIDs, err := s.periodUserIDs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheets, err := s.repo.GetLockedTimesheets(ctx, userIDs, req.Year, req.Month)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get locked timesheets", err)
+	}
+
+	first, _ := monthRange(req.Year, req.Month)
+	ruleSets := make(map[string]*models.TukinRuleSet)
+	var effective *models.TukinRuleSet
+
+	deductions := make([]models.TukinDeduction, 0, len(timesheets))
+	for i := range timesheets {
+		timesheet := &timesheets[i]
+
+		deduction, err := s.repo.GetTukinDeductionByTimesheet(ctx, timesheet.ID)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to get tukin deduction", err)
+		}
+
+		var ruleSet *models.TukinRuleSet
+		if deduction != nil {
+			if ruleSet = ruleSets[deduction.RuleSetID]; ruleSet == nil {
+				if ruleSet, err = s.repo.GetTukinRuleSetByID(ctx, deduction.RuleSetID); err != nil {
+					return nil, apperrors.NewInternalError("failed to get tukin rule set", err)
+				}
+				ruleSets[ruleSet.ID] = ruleSet
+			}
+		} else {
+			if effective == nil {
+				if effective, err = s.repo.GetEffectiveTukinRuleSet(ctx, first); err != nil {
+					return nil, apperrors.NewInternalError("failed to get tukin rule set", err)
+				}
+				if effective == nil {
+					return nil, apperrors.NewValidationError("no tukin rule set is in effect for " + first.Format("2006-01"))
+				}
+			}
+			ruleSet = effective
+			deduction = &models.TukinDeduction{TimesheetID: timesheet.ID, UserID: timesheet.UserID, Year: timesheet.Year, Month: timesheet.Month}
+		}
+
+		computeTukinDeduction(deduction, timesheet, ruleSet)
+		deduction.ComputedBy = userID
+		deduction.ComputedAt = time.Now()
+
+		if err := s.repo.SaveTukinDeduction(ctx, deduction); err != nil {
+			return nil, apperrors.NewInternalError("failed to save tukin deduction", err)
+		}
+		deductions = append(deductions, *deduction)
+	}
+
+	return deductions, nil
+}
+
+// GetTukinDeductions gets the computed deductions of a month with their lines
+func (s *AttendanceService) GetTukinDeductions(ctx context.Context, req TukinPeriodRequest) ([]models.TukinDeduction, error) {
+	userIDs, err := s.periodUserIDs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deductions, err := s.repo.GetTukinDeductions(ctx, userIDs, req.Year, req.Month)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get tukin deductions", err)
+	}
+	return deductions, nil
+}
+
+// ExportTukinDeductionsRequest represents export tukin deductions request
+type ExportTukinDeductionsRequest struct {
+	TukinPeriodRequest
+	Format string `form:"format,default=xlsx" binding:"omitempty,oneof=csv xlsx"`
+}
+
+// tukinEmployee represents the employee columns of the finance sheet
+type tukinEmployee struct {
+	NIP  string
+	Nama string
+	Unit string
+}
+
+// ExportTukinDeductions exports the computed deductions of a month in the sheet finance imports
+func (s *AttendanceService) ExportTukinDeductions(ctx context.Context, req ExportTukinDeductionsRequest) ([]byte, string, spreadsheet.Format, error) {
+	deductions, err := s.GetTukinDeductions(ctx, req.TukinPeriodRequest)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	userIDs := make([]string, 0, len(deductions))
+	for _, deduction := range deductions {
+		userIDs = append(userIDs, deduction.UserID)
+	}
+	staff, dosen, err := s.repo.GetEmployeesByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to get employees", err)
+	}
+	employees := make(map[string]tukinEmployee, len(staff)+len(dosen))
+	for _, profile := range staff {
+		employees[profile.UserID] = tukinEmployee{NIP: profile.NIP, Nama: profile.Nama, Unit: profile.Unit}
+	}
+	for _, profile := range dosen {
+		employees[profile.UserID] = tukinEmployee{NIP: profile.NIP, Nama: profile.Nama, Unit: profile.Prodi}
+	}
+
+	format := spreadsheet.Format(req.Format)
+	if format == "" {
+		format = spreadsheet.FormatXLSX
+	}
+
+	period := fmt.Sprintf("%04d-%02d", req.Year, req.Month)
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, "Tukin "+period, tukinExportRows(deductions, employees, period)); err != nil {
+		return nil, "", "", apperrors.NewInternalError("failed to export tukin deductions", err)
+	}
+
+	return buf.Bytes(), fmt.Sprintf("potongan-tukin-%s.%s", period, format), format, nil
+}
+
+// tukinExportRows builds finance's sheet: one row per deduction line, then a TOTAL row per employee
+// with the capped monthly percentage. Employees are sorted by NIP.
+func tukinExportRows(deductions []models.TukinDeduction, employees map[string]tukinEmployee, period string) [][]string {
+	sorted := make([]models.TukinDeduction, len(deductions))
+	copy(sorted, deductions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return employees[sorted[i].UserID].NIP < employees[sorted[j].UserID].NIP
+	})
+
+	rows := [][]string{{"No", "NIP", "Nama", "Unit", "Periode", "Tanggal", "Kode", "Menit", "Potongan (%)", "Keterangan", "Versi Aturan"}}
+	no := 0
+	for _, deduction := range sorted {
+		employee := employees[deduction.UserID]
+		version := strconv.Itoa(deduction.RuleSetVersion)
+		for _, line := range deduction.Lines {
+			no++
+			rows = append(rows, []string{
+				strconv.Itoa(no), employee.NIP, employee.Nama, employee.Unit, period,
+				line.WorkDate.Format("2006-01-02"), line.Code, strconv.Itoa(line.Minutes),
+				formatPercent(line.Percent), line.Explanation, version,
+			})
+		}
+
+		note := fmt.Sprintf("%d deductions", len(deduction.Lines))
+		if deduction.Capped {
+			note += ", capped at the monthly maximum"
+		}
+		no++
+		rows = append(rows, []string{
+			strconv.Itoa(no), employee.NIP, employee.Nama, employee.Unit, period,
+			"", "TOTAL", "", formatPercent(deduction.TotalPercent), note, version,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Defaults applied when no work attendance policy is configured
+const (
+	defaultWorkLateGraceMinutes     = 15
+	defaultWorkEarlyOutGraceMinutes = 15
+)
+
+// defaultWorkPolicy returns the built-in policy used when none is configured
+func defaultWorkPolicy() *models.WorkAttendancePolicy {
+	return &models.WorkAttendancePolicy{
+		Scope:                models.PolicyScopeGlobal,
+		LateGraceMinutes:     defaultWorkLateGraceMinutes,
+		EarlyOutGraceMinutes: defaultWorkEarlyOutGraceMinutes,
+		CheckInOpensMinutes:  int(workCheckInLead.Minutes()),
+		MissingCheckOut:      models.MissingCheckOutNoHours,
+		IsActive:             true,
+	}
+}
+
+// workPolicyRef returns the policy version to store on a record, nil for the built-in policy
+func workPolicyRef(policy *models.WorkAttendancePolicy) (*string, *int) {
+	if policy.ID == "" {
+		return nil, nil
+	}
+	return &policy.ID, &policy.Version
+}
+
+// resolveWorkPolicy finds the policy for a user's shift: user, then shift pattern, then unit, then global,
+// then the built-in default
+func (s *AttendanceService) resolveWorkPolicy(ctx context.Context, userID string, schedule *models.WorkSchedule) (*models.WorkAttendancePolicy, error) {
+	policy, err := s.repo.GetActiveWorkPolicy(ctx, models.PolicyScopeUser, userID)
+	if err != nil || policy != nil {
+		return policy, err
+	}
+
+	if schedule != nil && schedule.ShiftID != nil {
+		policy, err := s.repo.GetActiveWorkPolicy(ctx, models.PolicyScopeShift, *schedule.ShiftID)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	unit, err := s.repo.GetUserUnit(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if unit != "" {
+		policy, err := s.repo.GetActiveWorkPolicy(ctx, models.PolicyScopeUnit, unit)
+		if err != nil || policy != nil {
+			return policy, err
+		}
+	}
+
+	policy, err = s.repo.GetActiveWorkPolicy(ctx, models.PolicyScopeGlobal, "")
+	if err != nil || policy != nil {
+		return policy, err
+	}
+
+	return defaultWorkPolicy(), nil
+}
+
+// GetEffectiveWorkPolicy gets the work attendance policy that applies to a user, optionally for one of their shifts
+func (s *AttendanceService) GetEffectiveWorkPolicy(ctx context.Context, userID string, scheduleID string) (*models.WorkAttendancePolicy, error) {
+	if userID == "" {
+		return nil, apperrors.NewValidationError("user_id is required")
+	}
+
+	var schedule *models.WorkSchedule
+	if scheduleID != "" {
+		var err error
+		schedule, err = s.repo.GetWorkScheduleByID(ctx, scheduleID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("work schedule", scheduleID)
+		}
+	}
+
+	policy, err := s.resolveWorkPolicy(ctx, userID, schedule)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to resolve work attendance policy", err)
+	}
+	return policy, nil
+}
+
+// WorkPolicyRules holds the rules of a work attendance policy
+type WorkPolicyRules struct {
+	LateGraceMinutes     int    `json:"late_grace_minutes" binding:"min=0"`
+	EarlyOutGraceMinutes int    `json:"early_out_grace_minutes" binding:"min=0"`
+	RoundingMinutes      int    `json:"rounding_minutes" binding:"min=0,max=60"`
+	MinWorkMinutes       int    `json:"min_work_minutes" binding:"min=0"`
+	BreakDurationMinutes *int   `json:"break_duration_minutes,omitempty" binding:"omitempty,min=0"`
+	CheckInOpensMinutes  int    `json:"check_in_opens_minutes" binding:"min=0"`
+	CheckInClosesMinutes *int   `json:"check_in_closes_minutes,omitempty" binding:"omitempty,min=0"`
+	RefuseOutsideWindow  bool   `json:"refuse_outside_window"`
+	MissingCheckOut      string `json:"missing_check_out,omitempty" binding:"omitempty,oneof=NO_HOURS SHIFT_END"` // Defaults to NO_HOURS
+}
+
+// apply copies the rules onto a policy
+func (r WorkPolicyRules) apply(policy *models.WorkAttendancePolicy) {
+	policy.LateGraceMinutes = r.LateGraceMinutes
+	policy.EarlyOutGraceMinutes = r.EarlyOutGraceMinutes
+	policy.RoundingMinutes = r.RoundingMinutes
+	policy.MinWorkMinutes = r.MinWorkMinutes
+	policy.BreakDurationMinutes = r.BreakDurationMinutes
+	policy.CheckInOpensMinutes = r.CheckInOpensMinutes
+	policy.CheckInClosesMinutes = r.CheckInClosesMinutes
+	policy.RefuseOutsideWindow = r.RefuseOutsideWindow
+	policy.MissingCheckOut = models.MissingCheckOutNoHours
+	if r.MissingCheckOut != "" {
+		policy.MissingCheckOut = models.MissingCheckOutRule(r.MissingCheckOut)
+	}
+}
+
+// validateWorkPolicy checks that check-ins stay acceptable at least until the grace period ends
+func validateWorkPolicy(policy *models.WorkAttendancePolicy) error {
+	if policy.CheckInClosesMinutes != nil && *policy.CheckInClosesMinutes < policy.LateGraceMinutes {
+		return apperrors.NewValidationError("check_in_closes_minutes must not be shorter than late_grace_minutes")
+	}
+	return nil
+}
+
+// CreateWorkPolicyRequest represents create work attendance policy request
+type CreateWorkPolicyRequest struct {
+	Scope   string  `json:"scope" binding:"required,oneof=GLOBAL UNIT SHIFT USER"`
+	UserID  *string `json:"user_id,omitempty"`
+	ShiftID *string `json:"shift_id,omitempty"`
+	Unit    *string `json:"unit,omitempty"`
+	WorkPolicyRules
+}
+
+// CreateWorkPolicy creates the first version of a work attendance policy
+func (s *AttendanceService) CreateWorkPolicy(ctx context.Context, userID string, req CreateWorkPolicyRequest) (*models.WorkAttendancePolicy, error) {
+	policy := &models.WorkAttendancePolicy{
+		Scope:     models.PolicyScope(req.Scope),
+		Version:   1,
+		IsActive:  true,
+		CreatedBy: userID,
+	}
+	req.WorkPolicyRules.apply(policy)
+
+	targetID := ""
+	switch policy.Scope {
+	case models.PolicyScopeUser:
+		if req.UserID == nil {
+			return nil, apperrors.NewValidationError("user_id is required for USER scope")
+		}
+		policy.UserID = req.UserID
+		targetID = *req.UserID
+	case models.PolicyScopeShift:
+		if req.ShiftID == nil {
+			return nil, apperrors.NewValidationError("shift_id is required for SHIFT scope")
+		}
+		if _, err := s.repo.GetShiftPatternByID(ctx, *req.ShiftID); err != nil {
+			return nil, apperrors.NewNotFoundError("shift pattern", *req.ShiftID)
+		}
+		policy.ShiftID = req.ShiftID
+		targetID = *req.ShiftID
+	case models.PolicyScopeUnit:
+		if req.Unit == nil || strings.TrimSpace(*req.Unit) == "" {
+			return nil, apperrors.NewValidationError("unit is required for UNIT scope")
+		}
+		unit := strings.TrimSpace(*req.Unit)
+		policy.Unit = &unit
+		targetID = unit
+	}
+
+	if err := validateWorkPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	// A target has at most one active policy, changes are made by updating it
+	existing, err := s.repo.GetActiveWorkPolicy(ctx, policy.Scope, targetID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check existing work attendance policy", err)
+	}
+	if existing != nil {
+		return nil, apperrors.NewConflictError("an active work attendance policy already exists for this scope, update it instead")
+	}
+
+	if err := s.repo.CreateWorkPolicy(ctx, policy); err != nil {
+		return nil, apperrors.NewInternalError("failed to create work attendance policy", err)
+	}
+
+	return policy, nil
+}
+
+// GetWorkPoliciesRequest represents get work attendance policies request
+type GetWorkPoliciesRequest struct {
+	Scope          *string `form:"scope"`
+	UserID         *string `form:"user_id"`
+	ShiftID        *string `form:"shift_id"`
+	Unit           *string `form:"unit"`
+	IncludeHistory bool    `form:"include_history"`
+}
+
+// GetWorkPolicies gets work attendance policies
+func (s *AttendanceService) GetWorkPolicies(ctx context.Context, req GetWorkPoliciesRequest) ([]models.WorkAttendancePolicy, error) {
+	policies, err := s.repo.GetWorkPolicies(ctx, req.Scope, req.UserID, req.ShiftID, req.Unit, req.IncludeHistory)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get work attendance policies", err)
+	}
+	return policies, nil
+}
+
+// GetWorkPolicy gets a work attendance policy version
+func (s *AttendanceService) GetWorkPolicy(ctx context.Context, id string) (*models.WorkAttendancePolicy, error) {
+	policy, err := s.repo.GetWorkPolicyByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("work attendance policy", id)
+	}
+	return policy, nil
+}
+
+// UpdateWorkPolicyRequest represents update work attendance policy request, the rules replace the current ones
+type UpdateWorkPolicyRequest struct {
+	WorkPolicyRules
+}
+
+// UpdateWorkPolicy stores the new rules as the next version of an active policy.
+// Existing records keep referencing the version that produced their status.
+func (s *AttendanceService) UpdateWorkPolicy(ctx context.Context, userID string, id string, req UpdateWorkPolicyRequest) (*models.WorkAttendancePolicy, error) {
+	current, err := s.repo.GetWorkPolicyByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("work attendance policy", id)
+	}
+	if !current.IsActive {
+		return nil, apperrors.NewConflictError("only the active version of a work attendance policy can be updated")
+	}
+
+	next := &models.WorkAttendancePolicy{
+		Scope:             current.Scope,
+		UserID:            current.UserID,
+		ShiftID:           current.ShiftID,
+		Unit:              current.Unit,
+		Version:           current.Version + 1,
+		PreviousVersionID: &current.ID,
+		IsActive:          true,
+		CreatedBy:         userID,
+	}
+	req.WorkPolicyRules.apply(next)
+
+	if err := validateWorkPolicy(next); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateWorkPolicyVersion(ctx, current, next); err != nil {
+		return nil, apperrors.NewInternalError("failed to update work attendance policy", err)
+	}
+
+	return next, nil
+}
+
+// DeactivateWorkPolicy deactivates a work attendance policy, the next broader scope then applies
+func (s *AttendanceService) DeactivateWorkPolicy(ctx context.Context, id string) error {
+	if _, err := s.repo.GetWorkPolicyByID(ctx, id); err != nil {
+		return apperrors.NewNotFoundError("work attendance policy", id)
+	}
+
+	if err := s.repo.DeactivateWorkPolicy(ctx, id); err != nil {
+		return apperrors.NewInternalError("failed to deactivate work attendance policy", err)
+	}
+	return nil
+}
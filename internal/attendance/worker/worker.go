@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"unsri-backend/internal/attendance/service"
+	"unsri-backend/internal/shared/logger"
+)
+
+// Worker runs periodic attendance jobs
+type Worker struct {
+	service  *service.AttendanceService
+	log      logger.Logger
+	interval time.Duration
+}
+
+// NewWorker creates a new attendance worker
+func NewWorker(service *service.AttendanceService, log logger.Logger, interval time.Duration) *Worker {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Worker{
+		service:  service,
+		log:      log,
+		interval: interval,
+	}
+}
+
+// Start runs the jobs every interval until the context is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+// run executes one round of jobs
+func (w *Worker) run(ctx context.Context) {
+	closed, err := w.service.CloseExpiredSessions(ctx)
+	if err != nil {
+		w.log.Errorf("Failed to close expired attendance sessions: %v", err)
+	}
+	if closed > 0 {
+		w.log.Infof("Closed attendance for %d meetings with expired sessions", closed)
+	}
+
+	expired, err := w.service.ExpireKioskSessions(ctx)
+	if err != nil {
+		w.log.Errorf("Failed to close expired kiosk sessions: %v", err)
+	}
+	if expired > 0 {
+		w.log.Infof("Closed %d kiosk sessions at their close time", expired)
+	}
+
+	sweeps, err := w.service.RunDailySweep(ctx, time.Now())
+	if err != nil {
+		w.log.Errorf("Failed to sweep work attendance: %v", err)
+	}
+	for _, sweep := range sweeps {
+		w.log.Infof("Swept work day %s: %d missing check-outs closed, %d absent, %d on leave",
+			sweep.WorkDate.Format("2006-01-02"), sweep.ClosedCheckOuts, sweep.AbsentCount, sweep.OnLeaveCount)
+	}
+}
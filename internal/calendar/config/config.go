@@ -12,6 +12,7 @@ type Config struct {
 	Database        DatabaseConfig
 	JWT             JWTConfig
 	LogLevel        string
+	FeedBaseURL     string // Public base URL of the API, used in calendar feed URLs
 }
 
 // DatabaseConfig holds database configuration
@@ -43,12 +44,14 @@ func Load() *Config {
 	viper.SetDefault("DATABASE_NAME", "unsri_db")
 	viper.SetDefault("DATABASE_SSLMODE", "disable")
 	viper.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
+	viper.SetDefault("CALENDAR_FEED_BASE_URL", "http://localhost:8080")
 
 	viper.AutomaticEnv()
 
 	return &Config{
 		Port:     viper.GetString("PORT"),
 		LogLevel: viper.GetString("LOG_LEVEL"),
+		FeedBaseURL: viper.GetString("CALENDAR_FEED_BASE_URL"),
 		Database: DatabaseConfig{
 			Host:            viper.GetString("DATABASE_HOST"),
 			Port:            viper.GetString("DATABASE_PORT"),
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CreateCalendarFeed handles create calendar feed request
+func (h *CalendarHandler) CreateCalendarFeed(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	result, err := h.service.CreateCalendarFeed(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetCalendarFeed handles get calendar feed request
+func (h *CalendarHandler) GetCalendarFeed(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	result, err := h.service.GetCalendarFeed(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// RevokeCalendarFeed handles revoke calendar feed request
+func (h *CalendarHandler) RevokeCalendarFeed(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.service.RevokeCalendarFeed(c.Request.Context(), userID); err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Calendar feed revoked successfully"})
+}
+
+// ServeCalendarFeed handles a calendar client fetching a feed by its token
+func (h *CalendarHandler) ServeCalendarFeed(c *gin.Context) {
+	content, err := h.service.RenderCalendarFeed(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="unsri.ics"`)
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", content)
+}
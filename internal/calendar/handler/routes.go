@@ -23,5 +23,14 @@ func SetupRoutes(router *gin.Engine, handler *CalendarHandler, jwtToken *jwt.JWT
 		v1.PUT("/:id", middleware.RoleMiddleware("staff"), handler.UpdateEvent)
 		v1.DELETE("/:id", middleware.RoleMiddleware("staff"), handler.DeleteEvent)
 	}
+
+	// Personal iCalendar feed; calendar clients fetch it by the token in its URL, without logging in
+	feed := router.Group("/api/v1/calendar/feed")
+	{
+		feed.GET("/:token", handler.ServeCalendarFeed)
+		feed.GET("", middleware.AuthMiddleware(jwtToken), handler.GetCalendarFeed)
+		feed.POST("", middleware.AuthMiddleware(jwtToken), handler.CreateCalendarFeed)
+		feed.DELETE("", middleware.AuthMiddleware(jwtToken), handler.RevokeCalendarFeed)
+	}
 }
 
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateCalendarFeed stores a new feed of a user, revoking the previous ones in one transaction
+func (r *CalendarRepository) CreateCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CalendarFeed{}).
+			Where("user_id = ? AND revoked_at IS NULL", feed.UserID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(feed).Error
+	})
+}
+
+// GetActiveCalendarFeed gets the feed of a user not revoked, nil when there is none
+func (r *CalendarRepository) GetActiveCalendarFeed(ctx context.Context, userID string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// GetCalendarFeedByTokenHash gets the feed not revoked with a token hash
+func (r *CalendarRepository) GetCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// RevokeCalendarFeeds revokes the feeds of a user and returns how many were active
+func (r *CalendarRepository) RevokeCalendarFeeds(ctx context.Context, userID string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.CalendarFeed{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// TouchCalendarFeed records when a feed was fetched
+func (r *CalendarRepository) TouchCalendarFeed(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.CalendarFeed{}).Where("id = ?", id).Update("last_fetched_at", at).Error
+}
+
+// GetFeedSchedules gets the meetings of a user in a date range: those they teach or assist, and those of the
+// classes they are enrolled in. Cancelled and deactivated meetings are included.
+func (r *CalendarRepository) GetFeedSchedules(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	db := r.db.WithContext(ctx)
+	if err := db.
+		Where("date >= ? AND date <= ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Where("dosen_id = ? OR class_id IN (?) OR class_id IN (?)", userID,
+			db.Model(&models.Class{}).Select("id").Where("assistant_dosen_id = ?", userID),
+			db.Model(&models.Enrollment{}).Select("class_id").Where("student_id = ? AND status = ?", userID, "APPROVED")).
+		Order("date ASC, start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetFeedEvents gets the academic events overlapping a date range, deactivated ones included
+func (r *CalendarRepository) GetFeedEvents(ctx context.Context, startDate, endDate time.Time) ([]models.AcademicEvent, error) {
+	var events []models.AcademicEvent
+	if err := r.db.WithContext(ctx).
+		Where("DATE(start_date) <= ? AND DATE(end_date) >= ?", endDate.Format("2006-01-02"), startDate.Format("2006-01-02")).
+		Order("start_date ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetFeedLeaves gets the approved leave of a user overlapping a date range, and the leave cancelled after
+// its approval so subscribed calendars remove it
+func (r *CalendarRepository) GetFeedLeaves(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.LeaveRequest, error) {
+	var leaves []models.LeaveRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND start_date <= ? AND end_date >= ?", userID, endDate.Format("2006-01-02"), startDate.Format("2006-01-02")).
+		Where("status = ? OR (status = ? AND approved_at IS NOT NULL)", models.LeaveStatusApproved, models.LeaveStatusCancelled).
+		Order("start_date ASC").
+		Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
@@ -79,6 +79,19 @@ func (r *CalendarRepository) GetEventsByMonth(ctx context.Context, year, month i
 	return events, nil
 }
 
+// GetHolidays gets active holiday events overlapping a date range
+func (r *CalendarRepository) GetHolidays(ctx context.Context, startDate, endDate time.Time) ([]models.AcademicEvent, error) {
+	var events []models.AcademicEvent
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ? AND LOWER(event_type) = ? AND DATE(start_date) <= ? AND DATE(end_date) >= ?",
+			true, "holiday", endDate.Format("2006-01-02"), startDate.Format("2006-01-02")).
+		Order("start_date ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
 // GetUpcomingEvents gets upcoming events
 func (r *CalendarRepository) GetUpcomingEvents(ctx context.Context, limit int) ([]models.AcademicEvent, error) {
 	now := time.Now()
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+const (
+	feedLookBack  = 90  // Days of past meetings and events in a feed
+	feedLookAhead = 365 // Days of upcoming meetings and events in a feed
+)
+
+// CalendarFeedResponse represents the calendar feed of a user
+type CalendarFeedResponse struct {
+	Active        bool       `json:"active"`
+	URL           string     `json:"url,omitempty"` // Only returned when the feed is created
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+}
+
+// hashFeedToken returns the stored hash of a feed token
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateCalendarFeed creates the calendar feed URL of a user, revoking the previous one.
+// The URL is only returned here; a lost URL is replaced by creating a new one.
+func (s *CalendarService) CreateCalendarFeed(ctx context.Context, userID string) (*CalendarFeedResponse, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, apperrors.NewInternalError("failed to generate feed token", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	feed := &models.CalendarFeed{
+		UserID:    userID,
+		TokenHash: hashFeedToken(token),
+	}
+	if err := s.repo.CreateCalendarFeed(ctx, feed); err != nil {
+		return nil, apperrors.NewInternalError("failed to create calendar feed", err)
+	}
+
+	return &CalendarFeedResponse{
+		Active:    true,
+		URL:       strings.TrimRight(s.feedBaseURL, "/") + "/api/v1/calendar/feed/" + token + ".ics",
+		CreatedAt: &feed.CreatedAt,
+	}, nil
+}
+
+// GetCalendarFeed gets whether a user has a calendar feed and when it was last fetched
+func (s *CalendarService) GetCalendarFeed(ctx context.Context, userID string) (*CalendarFeedResponse, error) {
+	feed, err := s.repo.GetActiveCalendarFeed(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get calendar feed", err)
+	}
+	if feed == nil {
+		return &CalendarFeedResponse{Active: false}, nil
+	}
+	return &CalendarFeedResponse{Active: true, CreatedAt: &feed.CreatedAt, LastFetchedAt: feed.LastFetchedAt}, nil
+}
+
+// RevokeCalendarFeed revokes the calendar feed of a user; its URL stops working
+func (s *CalendarService) RevokeCalendarFeed(ctx context.Context, userID string) error {
+	revoked, err := s.repo.RevokeCalendarFeeds(ctx, userID)
+	if err != nil {
+		return apperrors.NewInternalError("failed to revoke calendar feed", err)
+	}
+	if revoked == 0 {
+		return apperrors.NewNotFoundError("calendar feed", userID)
+	}
+	return nil
+}
+
+// RenderCalendarFeed renders the iCalendar file of a feed token: the user's meetings (taught, assisted or
+// enrolled), the academic calendar including exam periods, and their approved leave
+func (s *CalendarService) RenderCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	token = strings.TrimSuffix(token, ".ics")
+	feed, err := s.repo.GetCalendarFeedByTokenHash(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("calendar feed", "token")
+	}
+
+	loc := feedLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	startDate, endDate := today.AddDate(0, 0, -feedLookBack), today.AddDate(0, 0, feedLookAhead)
+
+	schedules, err := s.repo.GetFeedSchedules(ctx, feed.UserID, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get schedules", err)
+	}
+	academicEvents, err := s.repo.GetFeedEvents(ctx, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get academic events", err)
+	}
+	leaves, err := s.repo.GetFeedLeaves(ctx, feed.UserID, startDate, endDate)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get leave", err)
+	}
+
+	events := make([]icsEvent, 0, len(schedules)+len(academicEvents)+len(leaves))
+	for i := range schedules {
+		events = append(events, scheduleEvent(&schedules[i], loc))
+	}
+	for i := range academicEvents {
+		events = append(events, academicEvent(&academicEvents[i], loc))
+	}
+	for i := range leaves {
+		events = append(events, leaveEvent(&leaves[i], loc))
+	}
+
+	// Fetch tracking only informs the user; a failure must not break their calendar
+	_ = s.repo.TouchCalendarFeed(ctx, feed.ID, now)
+
+	return renderICS("Jadwal UNSRI", events, now), nil
+}
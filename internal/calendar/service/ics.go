@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"unsri-backend/internal/shared/models"
+)
+
+// feedTimezone is the time zone feed events are written in
+const feedTimezone = "Asia/Jakarta"
+
+// feedLocation loads the feed time zone, falling back to WIB (UTC+7, no DST)
+func feedLocation() *time.Location {
+	if loc, err := time.LoadLocation(feedTimezone); err == nil {
+		return loc
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}
+
+// icsEvent is one VEVENT of a feed
+type icsEvent struct {
+	UID         string // Stable across fetches, so clients update the event instead of duplicating it
+	Summary     string
+	Description string
+	Location    string
+	Category    string
+	Start       time.Time // Wall clock in the feed time zone, or the first day of an all-day event
+	End         time.Time // Exclusive: the day after the last day of an all-day event
+	AllDay      bool
+	Cancelled   bool
+	Created     time.Time
+	Modified    time.Time
+}
+
+// leaveSummaries names leave types in the feed
+var leaveSummaries = map[models.LeaveType]string{
+	models.LeaveTypeAnnual:    "Cuti Tahunan",
+	models.LeaveTypeSick:      "Cuti Sakit",
+	models.LeaveTypePersonal:  "Cuti Alasan Penting",
+	models.LeaveTypeEmergency: "Cuti Darurat",
+	models.LeaveTypeUnpaid:    "Cuti di Luar Tanggungan",
+}
+
+// scheduleEvent returns the event of a meeting. Meeting times are wall clock times on the meeting date.
+func scheduleEvent(schedule *models.Schedule, loc *time.Location) icsEvent {
+	date := schedule.Date
+	summary := strings.TrimSpace(schedule.CourseCode + " " + schedule.CourseName)
+	if summary == "" {
+		summary = "Perkuliahan"
+	}
+
+	var notes []string
+	if schedule.ReplacesScheduleID != nil {
+		notes = append(notes, "Kelas pengganti")
+	}
+	if schedule.MeetingMode == models.MeetingModeOnline {
+		notes = append(notes, "Daring (online)")
+	}
+	if schedule.CancelledAt != nil {
+		note := "Dibatalkan"
+		if schedule.CancellationReason != "" {
+			note += ": " + schedule.CancellationReason
+		}
+		notes = append(notes, note)
+	}
+
+	return icsEvent{
+		UID:         "schedule-" + schedule.ID + "@unsri-backend",
+		Summary:     summary,
+		Description: strings.Join(notes, "\n"),
+		Location:    schedule.Room,
+		Category:    "CLASS",
+		Start:       time.Date(date.Year(), date.Month(), date.Day(), schedule.StartTime.Hour(), schedule.StartTime.Minute(), 0, 0, loc),
+		End:         time.Date(date.Year(), date.Month(), date.Day(), schedule.EndTime.Hour(), schedule.EndTime.Minute(), 0, 0, loc),
+		Cancelled:   schedule.CancelledAt != nil || !schedule.IsActive,
+		Created:     schedule.CreatedAt,
+		Modified:    schedule.UpdatedAt,
+	}
+}
+
+// academicEvent returns the event of an academic calendar entry; exams and holidays get their own category
+func academicEvent(event *models.AcademicEvent, loc *time.Location) icsEvent {
+	category := strings.ToUpper(strings.TrimSpace(event.EventType))
+	if category == "" {
+		category = "ACADEMIC"
+	}
+
+	result := icsEvent{
+		UID:         "event-" + event.ID + "@unsri-backend",
+		Summary:     event.Title,
+		Description: event.Description,
+		Location:    event.Location,
+		Category:    category,
+		AllDay:      event.IsAllDay,
+		Cancelled:   !event.IsActive,
+		Created:     event.CreatedAt,
+		Modified:    event.UpdatedAt,
+	}
+	if event.IsAllDay {
+		start, end := event.StartDate.In(loc), event.EndDate.In(loc)
+		result.Start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		result.End = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	} else {
+		result.Start, result.End = event.StartDate.In(loc), event.EndDate.In(loc)
+	}
+	return result
+}
+
+// leaveEvent returns the all-day event of an approved leave, cancelled when the leave was cancelled
+func leaveEvent(leave *models.LeaveRequest, loc *time.Location) icsEvent {
+	summary, ok := leaveSummaries[leave.LeaveType]
+	if !ok {
+		summary = "Cuti"
+	}
+	return icsEvent{
+		UID:         "leave-" + leave.ID + "@unsri-backend",
+		Summary:     summary,
+		Description: leave.Reason,
+		Category:    "LEAVE",
+		Start:       time.Date(leave.StartDate.Year(), leave.StartDate.Month(), leave.StartDate.Day(), 0, 0, 0, 0, loc),
+		End:         time.Date(leave.EndDate.Year(), leave.EndDate.Month(), leave.EndDate.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1),
+		AllDay:      true,
+		Cancelled:   leave.Status == models.LeaveStatusCancelled,
+		Created:     leave.CreatedAt,
+		Modified:    leave.UpdatedAt,
+	}
+}
+
+// icsText escapes a TEXT value (RFC 5545, 3.3.11)
+func icsText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// writeICSLine writes a content line folded at 75 octets, without splitting a UTF-8 character (RFC 5545, 3.1)
+func writeICSLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // The leading space counts
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// renderICS writes a feed as an iCalendar file with the Asia/Jakarta time zone.
+// Cancelled events stay in the feed with STATUS:CANCELLED so subscribed clients remove them.
+func renderICS(name string, events []icsEvent, stamp time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(s string) { writeICSLine(&buf, s) }
+	utc := func(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Universitas Sriwijaya//unsri-backend//ID")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + icsText(name))
+	line("X-WR-TIMEZONE:" + feedTimezone)
+	line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	line("X-PUBLISHED-TTL:PT1H")
+
+	line("BEGIN:VTIMEZONE")
+	line("TZID:" + feedTimezone)
+	line("X-LIC-LOCATION:" + feedTimezone)
+	line("BEGIN:STANDARD")
+	line("TZOFFSETFROM:+0700")
+	line("TZOFFSETTO:+0700")
+	line("TZNAME:WIB")
+	line("DTSTART:19700101T000000")
+	line("END:STANDARD")
+	line("END:VTIMEZONE")
+
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + utc(stamp))
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + event.End.Format("20060102"))
+		} else {
+			line("DTSTART;TZID=" + feedTimezone + ":" + event.Start.Format("20060102T150405"))
+			line("DTEND;TZID=" + feedTimezone + ":" + event.End.Format("20060102T150405"))
+		}
+		line("SUMMARY:" + icsText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + icsText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION:" + icsText(event.Location))
+		}
+		line("CATEGORIES:" + icsText(event.Category))
+		if event.Cancelled {
+			line("STATUS:CANCELLED")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		if !event.Created.IsZero() {
+			line("CREATED:" + utc(event.Created))
+		}
+		if !event.Modified.IsZero() {
+			line("LAST-MODIFIED:" + utc(event.Modified))
+			// Grows with every change, so clients replace their copy
+			if sequence := int64(event.Modified.Sub(event.Created) / time.Second); !event.Created.IsZero() && sequence > 0 {
+				line("SEQUENCE:" + strconv.FormatInt(sequence, 10))
+			}
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return buf.Bytes()
+}
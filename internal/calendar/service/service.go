@@ -11,12 +11,13 @@ import (
 
 // CalendarService handles calendar business logic
 type CalendarService struct {
-	repo *repository.CalendarRepository
+	repo        *repository.CalendarRepository
+	feedBaseURL string // Public base URL calendar clients fetch feeds from
 }
 
 // NewCalendarService creates a new calendar service
-func NewCalendarService(repo *repository.CalendarRepository) *CalendarService {
-	return &CalendarService{repo: repo}
+func NewCalendarService(repo *repository.CalendarRepository, feedBaseURL string) *CalendarService {
+	return &CalendarService{repo: repo, feedBaseURL: feedBaseURL}
 }
 
 // CreateEventRequest represents create event request
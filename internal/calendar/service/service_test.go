@@ -1,6 +1,7 @@
 package service
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -146,3 +147,104 @@ func TestCreateEventRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderICS(t *testing.T) {
+	loc := feedLocation()
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	cancelledAt := date.Add(-24 * time.Hour)
+	created := date.AddDate(0, -1, 0)
+
+	meeting := &models.Schedule{
+		ID:                 "meeting-1",
+		CourseCode:         "IF101",
+		CourseName:         "Algoritma, Dasar; Pemrograman",
+		Room:               "GK-201",
+		Date:               date,
+		StartTime:          time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		EndTime:            time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+		CancelledAt:        &cancelledAt,
+		CancellationReason: "Dosen sakit",
+		IsActive:           true,
+		CreatedAt:          created,
+		UpdatedAt:          cancelledAt,
+	}
+	exam := &models.AcademicEvent{
+		ID:        "exam-1",
+		Title:     "Ujian Tengah Semester " + strings.Repeat("Genap ", 15),
+		EventType: "exam",
+		StartDate: time.Date(2026, 3, 23, 0, 0, 0, 0, loc),
+		EndDate:   time.Date(2026, 3, 27, 0, 0, 0, 0, loc),
+		IsAllDay:  true,
+		IsActive:  true,
+	}
+	leave := &models.LeaveRequest{
+		ID:        "leave-1",
+		LeaveType: models.LeaveTypeAnnual,
+		StartDate: time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 4, 7, 0, 0, 0, 0, time.UTC),
+		Status:    models.LeaveStatusApproved,
+	}
+
+	events := []icsEvent{scheduleEvent(meeting, loc), academicEvent(exam, loc), leaveEvent(leave, loc)}
+	content := string(renderICS("Jadwal UNSRI", events, date))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:Asia/Jakarta\r\n",
+		"UID:schedule-meeting-1@unsri-backend\r\n",
+		"DTSTART;TZID=Asia/Jakarta:20260302T080000\r\n",
+		"DTEND;TZID=Asia/Jakarta:20260302T100000\r\n",
+		`SUMMARY:IF101 Algoritma\, Dasar\; Pemrograman`,
+		"STATUS:CANCELLED\r\n",
+		"UID:event-exam-1@unsri-backend\r\n",
+		"DTSTART;VALUE=DATE:20260323\r\nDTEND;VALUE=DATE:20260328\r\n",
+		"CATEGORIES:EXAM\r\n",
+		"DTSTART;VALUE=DATE:20260406\r\nDTEND;VALUE=DATE:20260408\r\n",
+		"SUMMARY:Cuti Tahunan\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected feed to contain %q", want)
+		}
+	}
+	if strings.Count(content, "STATUS:CANCELLED") != 1 || strings.Count(content, "STATUS:CONFIRMED") != 2 {
+		t.Errorf("Expected only the cancelled meeting to be cancelled")
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded at 75 octets, got %d: %q", len(line), line)
+		}
+	}
+
+	// The same meeting renders with the same UID on every fetch
+	again := string(renderICS("Jadwal UNSRI", []icsEvent{scheduleEvent(meeting, loc)}, date.Add(time.Hour)))
+	if !strings.Contains(again, "UID:schedule-meeting-1@unsri-backend\r\n") {
+		t.Error("Expected a stable UID")
+	}
+}
+
+func TestRenderCancelledLeave(t *testing.T) {
+	loc := feedLocation()
+	approvedAt := time.Date(2026, 3, 30, 9, 0, 0, 0, time.UTC)
+	leave := &models.LeaveRequest{
+		ID:         "leave-2",
+		LeaveType:  models.LeaveTypeSick,
+		StartDate:  time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC),
+		Status:     models.LeaveStatusCancelled,
+		ApprovedAt: &approvedAt,
+		UpdatedAt:  approvedAt.Add(24 * time.Hour),
+	}
+
+	content := string(renderICS("Jadwal UNSRI", []icsEvent{leaveEvent(leave, loc)}, approvedAt))
+	for _, want := range []string{
+		"UID:leave-leave-2@unsri-backend\r\n",
+		"DTSTART;VALUE=DATE:20260406\r\nDTEND;VALUE=DATE:20260407\r\n",
+		"STATUS:CANCELLED\r\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected feed to contain %q", want)
+		}
+	}
+}
@@ -113,13 +113,15 @@ func (h *CourseHandler) DeleteCourse(c *gin.Context) {
 
 // CreateClass handles create class request
 func (h *CourseHandler) CreateClass(c *gin.Context) {
+	userRole := c.GetString("user_role")
+
 	var req service.CreateClassRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, 400, err)
 		return
 	}
 
-	result, err := h.service.CreateClass(c.Request.Context(), req)
+	result, err := h.service.CreateClass(c.Request.Context(), userRole, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -141,6 +143,48 @@ func (h *CourseHandler) GetClass(c *gin.Context) {
 	utils.SuccessResponse(c, 200, result)
 }
 
+// UpdateClassLocation handles update class location settings request
+func (h *CourseHandler) UpdateClassLocation(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	classID := c.Param("id")
+
+	var req service.UpdateClassLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err)
+		return
+	}
+
+	result, err := h.service.UpdateClassLocation(c.Request.Context(), userID, userRole, classID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, result)
+}
+
+// UpdateClassRep handles set class rep request
+func (h *CourseHandler) UpdateClassRep(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+	classID := c.Param("id")
+
+	var req service.UpdateClassRepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err)
+		return
+	}
+
+	result, err := h.service.UpdateClassRep(c.Request.Context(), userID, userRole, classID, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, 200, result)
+}
+
 // GetClasses handles get classes request
 func (h *CourseHandler) GetClasses(c *gin.Context) {
 	var req service.GetClassesRequest
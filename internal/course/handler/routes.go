@@ -30,6 +30,8 @@ func SetupRoutes(router *gin.Engine, handler *CourseHandler, jwtToken *jwt.JWT)
 		classes.GET("", handler.GetClasses)
 		classes.GET("/:id", handler.GetClass)
 		classes.POST("", middleware.RoleMiddleware("dosen", "staff"), handler.CreateClass)
+		classes.PUT("/:id/location", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateClassLocation)
+		classes.PUT("/:id/class-rep", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateClassRep)
 		classes.GET("/:id/enrollments", handler.GetEnrollmentsByClass)
 	}
 
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"unsri-backend/internal/shared/conflict"
+)
+
+// GetConflictCandidates gets the meetings, classes and room bookings planned around a slot
+func (r *CourseRepository) GetConflictCandidates(ctx context.Context, slot conflict.Slot) (*conflict.Existing, error) {
+	return conflict.Load(ctx, r.db, slot)
+}
@@ -99,6 +99,18 @@ func (r *CourseRepository) GetClassByID(ctx context.Context, id string) (*models
 	return &class, nil
 }
 
+// GetRoomByID gets a room by ID
+func (r *CourseRepository) GetRoomByID(ctx context.Context, id string) (*models.Room, error) {
+	var room models.Room
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&room).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("room not found")
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
 // GetAllClasses gets all classes with filters
 func (r *CourseRepository) GetAllClasses(ctx context.Context, courseID *string, dosenID *string, semester *string, limit, offset int) ([]models.Class, int64, error) {
 	var classes []models.Class
@@ -233,6 +245,21 @@ func (r *CourseRepository) GetEnrollmentsByClassID(ctx context.Context, classID
 	return enrollments, nil
 }
 
+// GetEnrollmentByStudentAndClass gets a student's enrollment in a class, preferring an approved one
+func (r *CourseRepository) GetEnrollmentByStudentAndClass(ctx context.Context, studentID, classID string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	if err := r.db.WithContext(ctx).
+		Where("student_id = ? AND class_id = ?", studentID, classID).
+		Order("CASE WHEN status = 'APPROVED' THEN 0 ELSE 1 END, updated_at DESC").
+		First(&enrollment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // Not enrolled
+		}
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
 // UpdateEnrollment updates an enrollment
 func (r *CourseRepository) UpdateEnrollment(ctx context.Context, enrollment *models.Enrollment) error {
 	return r.db.WithContext(ctx).Save(enrollment).Error
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"unsri-backend/internal/course/repository"
+	"unsri-backend/internal/shared/conflict"
 	apperrors "unsri-backend/internal/shared/errors"
 	"unsri-backend/internal/shared/models"
 )
@@ -140,22 +141,25 @@ func (s *CourseService) DeleteCourse(ctx context.Context, id string) error {
 
 // CreateClassRequest represents create class request
 type CreateClassRequest struct {
-	CourseID         string  `json:"course_id" binding:"required"`
-	ClassCode        string  `json:"class_code" binding:"required"`
-	ClassName        string  `json:"class_name,omitempty"`
-	Semester         string  `json:"semester" binding:"required"`
-	AcademicYear     string  `json:"academic_year,omitempty"`
-	Capacity         int     `json:"capacity,omitempty"`
-	DosenID          string  `json:"dosen_id" binding:"required"`
-	AssistantDosenID *string `json:"assistant_dosen_id,omitempty"`
-	Room             string  `json:"room,omitempty"`
-	DayOfWeek        int     `json:"day_of_week" binding:"required,min=0,max=6"`
-	StartTime        string  `json:"start_time" binding:"required"`
-	EndTime          string  `json:"end_time" binding:"required"`
+	CourseID                string   `json:"course_id" binding:"required"`
+	ClassCode               string   `json:"class_code" binding:"required"`
+	ClassName               string   `json:"class_name,omitempty"`
+	Semester                string   `json:"semester" binding:"required"`
+	AcademicYear            string   `json:"academic_year,omitempty"`
+	Capacity                int      `json:"capacity,omitempty"`
+	DosenID                 string   `json:"dosen_id" binding:"required"`
+	AssistantDosenID        *string  `json:"assistant_dosen_id,omitempty"`
+	Room                    string   `json:"room,omitempty"`
+	RoomID                  *string  `json:"room_id,omitempty"`
+	LocationToleranceMeters *float64 `json:"location_tolerance_meters,omitempty" binding:"omitempty,min=0"`
+	DayOfWeek               int      `json:"day_of_week" binding:"required,min=0,max=6"`
+	StartTime               string   `json:"start_time" binding:"required"`
+	EndTime                 string   `json:"end_time" binding:"required"`
+	OverrideConflicts       bool     `json:"override_conflicts,omitempty"` // Staff only: create despite room or lecturer conflicts
 }
 
 // CreateClass creates a new class
-func (s *CourseService) CreateClass(ctx context.Context, req CreateClassRequest) (*models.Class, error) {
+func (s *CourseService) CreateClass(ctx context.Context, role string, req CreateClassRequest) (*models.Class, error) {
 	// Parse times
 	startTime, err := time.Parse("15:04", req.StartTime)
 	if err != nil {
@@ -173,20 +177,26 @@ func (s *CourseService) CreateClass(ctx context.Context, req CreateClassRequest)
 	endDateTime := time.Date(now.Year(), now.Month(), now.Day(), endTime.Hour(), endTime.Minute(), 0, 0, now.Location())
 
 	class := &models.Class{
-		CourseID:         req.CourseID,
-		ClassCode:        req.ClassCode,
-		ClassName:        req.ClassName,
-		Semester:         req.Semester,
-		AcademicYear:     req.AcademicYear,
-		Capacity:         req.Capacity,
-		DosenID:          req.DosenID,
-		AssistantDosenID: req.AssistantDosenID,
-		Room:             req.Room,
-		DayOfWeek:        req.DayOfWeek,
-		StartTime:        startDateTime,
-		EndTime:          endDateTime,
-		IsActive:         true,
-		Enrolled:         0,
+		CourseID:                req.CourseID,
+		ClassCode:               req.ClassCode,
+		ClassName:               req.ClassName,
+		Semester:                req.Semester,
+		AcademicYear:            req.AcademicYear,
+		Capacity:                req.Capacity,
+		DosenID:                 req.DosenID,
+		AssistantDosenID:        req.AssistantDosenID,
+		Room:                    req.Room,
+		RoomID:                  req.RoomID,
+		LocationToleranceMeters: req.LocationToleranceMeters,
+		DayOfWeek:               req.DayOfWeek,
+		StartTime:               startDateTime,
+		EndTime:                 endDateTime,
+		IsActive:                true,
+		Enrolled:                0,
+	}
+
+	if err := conflict.Check(ctx, s.repo, conflict.ClassSlot(class), req.OverrideConflicts, role); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.CreateClass(ctx, class); err != nil {
@@ -235,6 +245,80 @@ func (s *CourseService) GetClasses(ctx context.Context, req GetClassesRequest) (
 	return s.repo.GetAllClasses(ctx, courseIDPtr, dosenIDPtr, semesterPtr, perPage, (page-1)*perPage)
 }
 
+// UpdateClassLocationRequest represents update class location settings request
+type UpdateClassLocationRequest struct {
+	RoomID                  *string  `json:"room_id,omitempty"`
+	LocationToleranceMeters *float64 `json:"location_tolerance_meters,omitempty" binding:"omitempty,min=0"`
+}
+
+// UpdateClassLocation updates the room and scan tolerance used to verify attendance locations
+func (s *CourseService) UpdateClassLocation(ctx context.Context, userID string, role string, id string, req UpdateClassLocationRequest) (*models.Class, error) {
+	class, err := s.repo.GetClassByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("class", id)
+	}
+
+	if role != string(models.RoleStaff) && class.DosenID != userID {
+		return nil, apperrors.NewForbiddenError("only the class dosen or staff can change location settings")
+	}
+
+	if req.RoomID != nil {
+		// Empty string unlinks the room
+		class.RoomID = nil
+		if *req.RoomID != "" {
+			if _, err := s.repo.GetRoomByID(ctx, *req.RoomID); err != nil {
+				return nil, apperrors.NewNotFoundError("room", *req.RoomID)
+			}
+			class.RoomID = req.RoomID
+		}
+	}
+	if req.LocationToleranceMeters != nil {
+		class.LocationToleranceMeters = req.LocationToleranceMeters
+	}
+
+	if err := s.repo.UpdateClass(ctx, class); err != nil {
+		return nil, apperrors.NewInternalError("failed to update class", err)
+	}
+
+	return class, nil
+}
+
+// UpdateClassRepRequest represents set class rep request
+type UpdateClassRepRequest struct {
+	StudentID *string `json:"student_id"` // Nil or empty clears the class rep
+}
+
+// UpdateClassRep sets the class rep (ketua kelas), who must be an approved enrollee of the class
+func (s *CourseService) UpdateClassRep(ctx context.Context, userID string, role string, id string, req UpdateClassRepRequest) (*models.Class, error) {
+	class, err := s.repo.GetClassByID(ctx, id)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("class", id)
+	}
+
+	if role != string(models.RoleStaff) && class.DosenID != userID {
+		return nil, apperrors.NewForbiddenError("only the class dosen or staff can set the class rep")
+	}
+
+	if req.StudentID == nil || *req.StudentID == "" {
+		class.ClassRepID = nil
+	} else {
+		enrollment, err := s.repo.GetEnrollmentByStudentAndClass(ctx, *req.StudentID, id)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to check enrollment", err)
+		}
+		if enrollment == nil || enrollment.Status != "APPROVED" {
+			return nil, apperrors.NewValidationError("the class rep must be an approved student of the class")
+		}
+		class.ClassRepID = req.StudentID
+	}
+
+	if err := s.repo.UpdateClass(ctx, class); err != nil {
+		return nil, apperrors.NewInternalError("failed to update class", err)
+	}
+
+	return class, nil
+}
+
 // GetClassesByStudent gets classes for a student
 func (s *CourseService) GetClassesByStudent(ctx context.Context, studentID string) ([]models.Class, error) {
 	return s.repo.GetClassesByStudentID(ctx, studentID)
@@ -87,6 +87,35 @@ func (r *LeaveRepository) GetLeaveRequestsByUserID(ctx context.Context, userID s
 	return leaveRequests, nil
 }
 
+// GetApprovedLeavesCoveringDate gets approved leave requests of the users that cover a date
+func (r *LeaveRepository) GetApprovedLeavesCoveringDate(ctx context.Context, userIDs []string, date time.Time) ([]models.LeaveRequest, error) {
+	var leaveRequests []models.LeaveRequest
+	if len(userIDs) == 0 {
+		return leaveRequests, nil
+	}
+
+	day := date.Format("2006-01-02")
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?", userIDs, models.LeaveStatusApproved, day, day).
+		Find(&leaveRequests).Error; err != nil {
+		return nil, err
+	}
+	return leaveRequests, nil
+}
+
+// GetApprovedLeavesInRange gets a user's approved leave requests overlapping a date range
+func (r *LeaveRepository) GetApprovedLeavesInRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]models.LeaveRequest, error) {
+	var leaveRequests []models.LeaveRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
+			userID, models.LeaveStatusApproved, endDate.Format("2006-01-02"), startDate.Format("2006-01-02")).
+		Order("start_date ASC").
+		Find(&leaveRequests).Error; err != nil {
+		return nil, err
+	}
+	return leaveRequests, nil
+}
+
 // UpdateLeaveRequest updates a leave request
 func (r *LeaveRepository) UpdateLeaveRequest(ctx context.Context, leaveRequest *models.LeaveRequest) error {
 	return r.db.WithContext(ctx).Save(leaveRequest).Error
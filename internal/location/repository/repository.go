@@ -3,10 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
-	"math"
 	"time"
 
 	"unsri-backend/internal/shared/models"
+	"unsri-backend/pkg/geo"
 
 	"gorm.io/gorm"
 )
@@ -102,31 +102,6 @@ func (r *LocationRepository) GetCurrentTapInStatus(ctx context.Context, userID s
 	return &history, nil
 }
 
-// haversineDistance calculates the distance between two points using Haversine formula
-// Returns distance in meters
-func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	const earthRadiusMeters = 6371000 // Earth radius in meters
-
-	// Convert latitude and longitude from degrees to radians
-	lat1Rad := lat1 * math.Pi / 180
-	lon1Rad := lon1 * math.Pi / 180
-	lat2Rad := lat2 * math.Pi / 180
-	lon2Rad := lon2 * math.Pi / 180
-
-	// Haversine formula
-	deltaLat := lat2Rad - lat1Rad
-	deltaLon := lon2Rad - lon1Rad
-
-	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
-		math.Cos(lat1Rad)*math.Cos(lat2Rad)*
-			math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
-
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	distance := earthRadiusMeters * c
-
-	return distance
-}
-
 // CheckLocationInGeofence checks if location is within geofence using Haversine formula
 func (r *LocationRepository) CheckLocationInGeofence(ctx context.Context, latitude, longitude float64) (*models.Geofence, error) {
 	var geofences []models.Geofence
@@ -137,7 +112,7 @@ func (r *LocationRepository) CheckLocationInGeofence(ctx context.Context, latitu
 	// Check if location is within any active geofence
 	for _, geofence := range geofences {
 		// Calculate distance using Haversine formula (in meters)
-		distance := haversineDistance(
+		distance := geo.Distance(
 			geofence.Latitude,
 			geofence.Longitude,
 			latitude,
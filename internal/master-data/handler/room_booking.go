@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/master-data/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// CreateRoomBooking handles create room booking request
+func (h *MasterDataHandler) CreateRoomBooking(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.CreateRoomBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.CreateRoomBooking(c.Request.Context(), userID, userRole, c.Param("id"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, result)
+}
+
+// GetRoomBookings handles get room bookings request
+func (h *MasterDataHandler) GetRoomBookings(c *gin.Context) {
+	var req service.GetRoomBookingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GetRoomBookings(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// CancelRoomBooking handles cancel room booking request
+func (h *MasterDataHandler) CancelRoomBooking(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	result, err := h.service.CancelRoomBooking(c.Request.Context(), userID, userRole, c.Param("id"), c.Param("bookingId"))
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
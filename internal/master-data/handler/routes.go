@@ -44,6 +44,11 @@ func SetupRoutes(router *gin.Engine, handler *MasterDataHandler, jwtToken *jwt.J
 		rooms.POST("", middleware.RoleMiddleware("dosen", "staff"), handler.CreateRoom)
 		rooms.PUT("/:id", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateRoom)
 		rooms.DELETE("/:id", middleware.RoleMiddleware("dosen", "staff"), handler.DeleteRoom)
+
+		// One-off room bookings, checked against meetings and classes
+		rooms.GET("/:id/bookings", handler.GetRoomBookings)
+		rooms.POST("/:id/bookings", middleware.RoleMiddleware("dosen", "staff"), handler.CreateRoomBooking)
+		rooms.DELETE("/:id/bookings/:bookingId", middleware.RoleMiddleware("dosen", "staff"), handler.CancelRoomBooking)
 	}
 }
 
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/conflict"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateRoomBooking creates a room booking
+func (r *MasterDataRepository) CreateRoomBooking(ctx context.Context, booking *models.RoomBooking) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(booking).Error
+}
+
+// GetRoomBookingByID gets a room booking by ID
+func (r *MasterDataRepository) GetRoomBookingByID(ctx context.Context, id string) (*models.RoomBooking, error) {
+	var booking models.RoomBooking
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&booking).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("room booking not found")
+		}
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetRoomBookings gets the bookings of a room in an optional date range
+func (r *MasterDataRepository) GetRoomBookings(ctx context.Context, roomID string, startDate, endDate *time.Time, includeCancelled bool) ([]models.RoomBooking, error) {
+	var bookings []models.RoomBooking
+	query := r.db.WithContext(ctx).Where("room_id = ?", roomID)
+
+	if startDate != nil {
+		query = query.Where("date >= ?", startDate.Format("2006-01-02"))
+	}
+	if endDate != nil {
+		query = query.Where("date <= ?", endDate.Format("2006-01-02"))
+	}
+	if !includeCancelled {
+		query = query.Where("cancelled_at IS NULL")
+	}
+
+	if err := query.Order("date ASC, start_time ASC").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// UpdateRoomBooking updates a room booking
+func (r *MasterDataRepository) UpdateRoomBooking(ctx context.Context, booking *models.RoomBooking) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(booking).Error
+}
+
+// GetConflictCandidates gets the meetings, classes and room bookings planned around a slot
+func (r *MasterDataRepository) GetConflictCandidates(ctx context.Context, slot conflict.Slot) (*conflict.Existing, error) {
+	return conflict.Load(ctx, r.db, slot)
+}
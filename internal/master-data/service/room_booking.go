@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"unsri-backend/internal/shared/conflict"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// CreateRoomBookingRequest represents create room booking request
+type CreateRoomBookingRequest struct {
+	Title             string `json:"title" binding:"required"`
+	Date              string `json:"date" binding:"required"`       // YYYY-MM-DD
+	StartTime         string `json:"start_time" binding:"required"` // HH:MM
+	EndTime           string `json:"end_time" binding:"required"`   // HH:MM
+	OverrideConflicts bool   `json:"override_conflicts,omitempty"`  // Staff only: book despite conflicts
+}
+
+// CreateRoomBooking books a room for a one-off use. The room must be free of meetings, classes and other bookings.
+func (s *MasterDataService) CreateRoomBooking(ctx context.Context, userID string, role string, roomID string, req CreateRoomBookingRequest) (*models.RoomBooking, error) {
+	room, err := s.repo.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("room", roomID)
+	}
+	if !room.IsActive {
+		return nil, apperrors.NewValidationError("room is not active")
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid date format, use YYYY-MM-DD")
+	}
+	startTime, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid start_time format, use HH:MM")
+	}
+	endTime, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid end_time format, use HH:MM")
+	}
+	if !endTime.After(startTime) {
+		return nil, apperrors.NewValidationError("end_time must be after start_time")
+	}
+
+	booking := &models.RoomBooking{
+		RoomID:    &room.ID,
+		Room:      room.Code,
+		Title:     strings.TrimSpace(req.Title),
+		Date:      date,
+		StartTime: time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location()),
+		EndTime:   time.Date(date.Year(), date.Month(), date.Day(), endTime.Hour(), endTime.Minute(), 0, 0, date.Location()),
+		BookedBy:  userID,
+	}
+
+	if err := conflict.Check(ctx, s.repo, conflict.BookingSlot(booking), req.OverrideConflicts, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRoomBooking(ctx, booking); err != nil {
+		return nil, apperrors.NewInternalError("failed to create room booking", err)
+	}
+
+	return booking, nil
+}
+
+// GetRoomBookingsRequest represents get room bookings request
+type GetRoomBookingsRequest struct {
+	StartDate        string `form:"start_date"`
+	EndDate          string `form:"end_date"`
+	IncludeCancelled bool   `form:"include_cancelled"`
+}
+
+// GetRoomBookings gets the bookings of a room
+func (s *MasterDataService) GetRoomBookings(ctx context.Context, roomID string, req GetRoomBookingsRequest) ([]models.RoomBooking, error) {
+	if _, err := s.repo.GetRoomByID(ctx, roomID); err != nil {
+		return nil, apperrors.NewNotFoundError("room", roomID)
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		t, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid start_date format, use YYYY-MM-DD")
+		}
+		startDate = &t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid end_date format, use YYYY-MM-DD")
+		}
+		endDate = &t
+	}
+
+	bookings, err := s.repo.GetRoomBookings(ctx, roomID, startDate, endDate, req.IncludeCancelled)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get room bookings", err)
+	}
+	return bookings, nil
+}
+
+// CancelRoomBooking cancels a room booking; only the user who booked it or staff can
+func (s *MasterDataService) CancelRoomBooking(ctx context.Context, userID string, role string, roomID string, bookingID string) (*models.RoomBooking, error) {
+	booking, err := s.repo.GetRoomBookingByID(ctx, bookingID)
+	if err != nil || booking.RoomID == nil || *booking.RoomID != roomID {
+		return nil, apperrors.NewNotFoundError("room booking", bookingID)
+	}
+	if role != string(models.RoleStaff) && booking.BookedBy != userID {
+		return nil, apperrors.NewForbiddenError("only the user who booked the room or staff can cancel the booking")
+	}
+	if booking.CancelledAt != nil {
+		return nil, apperrors.NewConflictError("room booking is already cancelled")
+	}
+
+	now := time.Now()
+	booking.CancelledAt = &now
+	booking.CancelledBy = &userID
+	if err := s.repo.UpdateRoomBooking(ctx, booking); err != nil {
+		return nil, apperrors.NewInternalError("failed to cancel room booking", err)
+	}
+	return booking, nil
+}
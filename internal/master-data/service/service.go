@@ -376,13 +376,17 @@ func (s *MasterDataService) DeleteAcademicPeriod(ctx context.Context, id string)
 
 // CreateRoomRequest represents create room request
 type CreateRoomRequest struct {
-	Code       string `json:"code" binding:"required"`
-	Name       string `json:"name" binding:"required"`
-	Building   string `json:"building,omitempty"`
-	Floor      *int   `json:"floor,omitempty"`
-	Capacity   *int   `json:"capacity,omitempty"`
-	RoomType   string `json:"room_type,omitempty"`
-	Facilities string `json:"facilities,omitempty"`
+	Code         string   `json:"code" binding:"required"`
+	Name         string   `json:"name" binding:"required"`
+	Building     string   `json:"building,omitempty"`
+	Floor        *int     `json:"floor,omitempty"`
+	Capacity     *int     `json:"capacity,omitempty"`
+	RoomType     string   `json:"room_type,omitempty"`
+	Facilities   string   `json:"facilities,omitempty"`
+	GeofenceID   *string  `json:"geofence_id,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	RadiusMeters *float64 `json:"radius_meters,omitempty"`
 }
 
 // CreateRoom creates a new room
@@ -394,14 +398,22 @@ func (s *MasterDataService) CreateRoom(ctx context.Context, req CreateRoomReques
 	}
 
 	room := &models.Room{
-		Code:       req.Code,
-		Name:       req.Name,
-		Building:   req.Building,
-		Floor:      req.Floor,
-		Capacity:   req.Capacity,
-		RoomType:   req.RoomType,
-		Facilities: req.Facilities,
-		IsActive:   true,
+		Code:         req.Code,
+		Name:         req.Name,
+		Building:     req.Building,
+		Floor:        req.Floor,
+		Capacity:     req.Capacity,
+		RoomType:     req.RoomType,
+		Facilities:   req.Facilities,
+		GeofenceID:   req.GeofenceID,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		RadiusMeters: req.RadiusMeters,
+		IsActive:     true,
+	}
+
+	if err := validateRoomArea(room); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.CreateRoom(ctx, room); err != nil {
@@ -453,13 +465,17 @@ func (s *MasterDataService) GetRooms(ctx context.Context, req GetRoomsRequest) (
 
 // UpdateRoomRequest represents update room request
 type UpdateRoomRequest struct {
-	Name       *string `json:"name,omitempty"`
-	Building   *string `json:"building,omitempty"`
-	Floor      *int    `json:"floor,omitempty"`
-	Capacity   *int    `json:"capacity,omitempty"`
-	RoomType   *string `json:"room_type,omitempty"`
-	Facilities *string `json:"facilities,omitempty"`
-	IsActive   *bool   `json:"is_active,omitempty"`
+	Name         *string  `json:"name,omitempty"`
+	Building     *string  `json:"building,omitempty"`
+	Floor        *int     `json:"floor,omitempty"`
+	Capacity     *int     `json:"capacity,omitempty"`
+	RoomType     *string  `json:"room_type,omitempty"`
+	Facilities   *string  `json:"facilities,omitempty"`
+	GeofenceID   *string  `json:"geofence_id,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	RadiusMeters *float64 `json:"radius_meters,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"`
 }
 
 // UpdateRoom updates a room
@@ -487,10 +503,30 @@ func (s *MasterDataService) UpdateRoom(ctx context.Context, id string, req Updat
 	if req.Facilities != nil {
 		room.Facilities = *req.Facilities
 	}
+	if req.GeofenceID != nil {
+		// Empty string unlinks the geofence
+		room.GeofenceID = req.GeofenceID
+		if *req.GeofenceID == "" {
+			room.GeofenceID = nil
+		}
+	}
+	if req.Latitude != nil {
+		room.Latitude = req.Latitude
+	}
+	if req.Longitude != nil {
+		room.Longitude = req.Longitude
+	}
+	if req.RadiusMeters != nil {
+		room.RadiusMeters = req.RadiusMeters
+	}
 	if req.IsActive != nil {
 		room.IsActive = *req.IsActive
 	}
 
+	if err := validateRoomArea(room); err != nil {
+		return nil, err
+	}
+
 	if err := s.repo.UpdateRoom(ctx, room); err != nil {
 		return nil, apperrors.NewInternalError("failed to update room", err)
 	}
@@ -498,6 +534,23 @@ func (s *MasterDataService) UpdateRoom(ctx context.Context, id string, req Updat
 	return room, nil
 }
 
+// validateRoomArea checks that a room footprint is either complete or absent
+func validateRoomArea(room *models.Room) error {
+	set := 0
+	for _, v := range []*float64{room.Latitude, room.Longitude, room.RadiusMeters} {
+		if v != nil {
+			set++
+		}
+	}
+	if set != 0 && set != 3 {
+		return apperrors.NewValidationError("latitude, longitude and radius_meters must be set together")
+	}
+	if room.RadiusMeters != nil && *room.RadiusMeters <= 0 {
+		return apperrors.NewValidationError("radius_meters must be positive")
+	}
+	return nil
+}
+
 // DeleteRoom deletes a room
 func (s *MasterDataService) DeleteRoom(ctx context.Context, id string) error {
 	_, err := s.repo.GetRoomByID(ctx, id)
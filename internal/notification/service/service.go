@@ -101,6 +101,7 @@ func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID string)
 type RegisterDeviceTokenRequest struct {
 	Token    string `json:"token" binding:"required"`
 	Platform string `json:"platform" binding:"required,oneof=ios android web"`
+	DeviceID string `json:"device_id,omitempty"` // Installation identifier, ties the token to the device bound for attendance
 }
 
 // RegisterDeviceToken registers a device token for push notifications
@@ -111,6 +112,7 @@ func (s *NotificationService) RegisterDeviceToken(ctx context.Context, userID st
 		// Update if exists
 		existing.UserID = userID
 		existing.Platform = req.Platform
+		existing.DeviceID = req.DeviceID
 		existing.IsActive = true
 		if err := s.repo.UpdateDeviceToken(ctx, existing); err != nil {
 			return nil, apperrors.NewInternalError("failed to update device token", err)
@@ -122,6 +124,7 @@ func (s *NotificationService) RegisterDeviceToken(ctx context.Context, userID st
 		UserID:   userID,
 		Token:    req.Token,
 		Platform: req.Platform,
+		DeviceID: req.DeviceID,
 		IsActive: true,
 	}
 
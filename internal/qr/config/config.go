@@ -13,6 +13,7 @@ type Config struct {
 	Redis           RedisConfig
 	JWT             JWTConfig
 	LogLevel        string
+	QRSigningKey    string // Shared with the attendance service to sign attendance QR payloads
 }
 
 // DatabaseConfig holds database configuration
@@ -54,12 +55,14 @@ func Load() *Config {
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
+	viper.SetDefault("QR_SIGNING_KEY", "your-qr-signing-key-change-in-production")
 
 	viper.AutomaticEnv()
 
 	return &Config{
-		Port:     viper.GetString("PORT"),
-		LogLevel: viper.GetString("LOG_LEVEL"),
+		Port:         viper.GetString("PORT"),
+		LogLevel:     viper.GetString("LOG_LEVEL"),
+		QRSigningKey: viper.GetString("QR_SIGNING_KEY"),
 		Database: DatabaseConfig{
 			Host:            viper.GetString("DATABASE_HOST"),
 			Port:            viper.GetString("DATABASE_PORT"),
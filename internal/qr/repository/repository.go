@@ -72,6 +72,18 @@ func (r *QRRepository) GetActiveSessionByScheduleID(ctx context.Context, schedul
 	return &session, nil
 }
 
+// GetScheduleByID gets a schedule by ID
+func (r *QRRepository) GetScheduleByID(ctx context.Context, id string) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("schedule not found")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
 // GetUserAccessQR gets user access QR by user ID
 func (r *QRRepository) GetUserAccessQR(ctx context.Context, userID string) (*models.UserAccessQR, error) {
 	var userQR models.UserAccessQR
@@ -18,15 +18,17 @@ import (
 
 // QRService handles QR code business logic
 type QRService struct {
-	repo     *repository.QRRepository
-	userRepo *userRepo.UserRepository
+	repo       *repository.QRRepository
+	userRepo   *userRepo.UserRepository
+	signingKey []byte // Signs attendance QR payloads, shared with the attendance service
 }
 
 // NewQRService creates a new QR service
-func NewQRService(repo *repository.QRRepository, userRepo *userRepo.UserRepository) *QRService {
+func NewQRService(repo *repository.QRRepository, userRepo *userRepo.UserRepository, signingKey string) *QRService {
 	return &QRService{
-		repo:     repo,
-		userRepo: userRepo,
+		repo:       repo,
+		userRepo:   userRepo,
+		signingKey: []byte(signingKey),
 	}
 }
 
@@ -84,6 +86,7 @@ func (s *QRService) GenerateQR(ctx context.Context, createdBy string, req Genera
 	}
 
 	qrData.SessionID = session.ID
+	qrcode.Sign(&qrData, s.signingKey)
 	qrImage, _ := qrcode.GenerateQRCode(qrData)
 
 	return &GenerateQRResponse{
@@ -115,6 +118,13 @@ func (s *QRService) ValidateQR(ctx context.Context, req ValidateQRRequest) (*Val
 		}, nil
 	}
 
+	if !qrcode.Verify(qrData, s.signingKey) {
+		return &ValidateQRResponse{
+			Valid:   false,
+			Message: "QR code signature is invalid",
+		}, nil
+	}
+
 	session, err := s.repo.GetSessionByID(ctx, qrData.SessionID)
 	if err != nil {
 		return &ValidateQRResponse{
@@ -152,8 +162,9 @@ func (s *QRService) GetQRByID(ctx context.Context, id string) (*models.Attendanc
 
 // GenerateClassQRRequest represents generate class QR request
 type GenerateClassQRRequest struct {
-	ScheduleID string `json:"schedule_id" binding:"required"`
-	Duration   int    `json:"duration,omitempty"`
+	ScheduleID  string `json:"schedule_id" binding:"required"`
+	Duration    int    `json:"duration,omitempty"`
+	AllowGuests bool   `json:"allow_guests,omitempty"` // Accept scans from users not enrolled in the class
 }
 
 // GenerateClassQR generates a class attendance QR code
@@ -164,6 +175,17 @@ func (s *QRService) GenerateClassQR(ctx context.Context, createdBy string, req G
 		duration = req.Duration
 	}
 
+	schedule, err := s.repo.GetScheduleByID(ctx, req.ScheduleID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("schedule", req.ScheduleID)
+	}
+	if schedule.AttendanceClosedAt != nil {
+		return nil, apperrors.NewBadRequestError("attendance for this schedule has been closed")
+	}
+	if schedule.CancelledAt != nil {
+		return nil, apperrors.NewBadRequestError("this meeting has been cancelled")
+	}
+
 	expiresAt := time.Now().Add(time.Duration(duration) * time.Minute)
 
 	// Check if there's an active session for this schedule
@@ -178,11 +200,12 @@ func (s *QRService) GenerateClassQR(ctx context.Context, createdBy string, req G
 
 	// Create new session
 	session := &models.AttendanceSession{
-		ScheduleID: &req.ScheduleID,
-		CreatedBy:  createdBy,
-		Type:       models.AttendanceTypeKelas,
-		ExpiresAt:  expiresAt,
-		IsActive:   true,
+		ScheduleID:  &req.ScheduleID,
+		CreatedBy:   createdBy,
+		Type:        models.AttendanceTypeKelas,
+		ExpiresAt:   expiresAt,
+		AllowGuests: req.AllowGuests,
+		IsActive:    true,
 	}
 
 	if err := s.repo.CreateSession(ctx, session); err != nil {
@@ -195,6 +218,7 @@ func (s *QRService) GenerateClassQR(ctx context.Context, createdBy string, req G
 		ExpiresAt:  expiresAt,
 		Type:       "kelas",
 	}
+	qrcode.Sign(&qrData, s.signingKey)
 
 	qrImage, err := qrcode.GenerateQRCode(qrData)
 	if err != nil {
@@ -212,18 +236,21 @@ func (s *QRService) GenerateClassQR(ctx context.Context, createdBy string, req G
 // This is called after attendance is recorded
 func (s *QRService) RegenerateClassQR(ctx context.Context, scheduleID string, createdBy string) (*GenerateQRResponse, error) {
 	// Deactivate current session
+	allowGuests := false
 	existingSession, _ := s.repo.GetActiveSessionByScheduleID(ctx, scheduleID)
 	if existingSession != nil {
+		allowGuests = existingSession.AllowGuests
 		existingSession.IsActive = false
 		if err := s.repo.UpdateSession(ctx, existingSession); err != nil {
 			return nil, apperrors.NewInternalError("failed to deactivate existing session", err)
 		}
 	}
 
-	// Generate new QR with same schedule
+	// Generate new QR with same schedule, keeping the guest setting
 	req := GenerateClassQRRequest{
-		ScheduleID: scheduleID,
-		Duration:   15, // Default 15 minutes
+		ScheduleID:  scheduleID,
+		Duration:    15, // Default 15 minutes
+		AllowGuests: allowGuests,
 	}
 
 	return s.GenerateClassQR(ctx, createdBy, req)
@@ -42,13 +42,11 @@ func (r *ReportRepository) GetAttendanceReport(ctx context.Context, studentID *s
 
 // GetAttendanceSummary gets attendance summary
 func (r *ReportRepository) GetAttendanceSummary(ctx context.Context, studentID *string, courseID *string, startDate, endDate time.Time) (map[string]interface{}, error) {
-	var total, present, absent, late, excused int64
-
 	query := r.db.WithContext(ctx).Model(&models.Attendance{}).
-		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
+		Where("attendances.created_at >= ? AND attendances.created_at <= ?", startDate, endDate)
 
 	if studentID != nil {
-		query = query.Where("user_id = ?", *studentID)
+		query = query.Where("attendances.user_id = ?", *studentID)
 	}
 
 	if courseID != nil {
@@ -56,23 +54,40 @@ func (r *ReportRepository) GetAttendanceSummary(ctx context.Context, studentID *
 			Where("schedules.course_id = ?", *courseID)
 	}
 
-	query.Count(&total)
-	query.Where("status = ?", "present").Count(&present)
-	query.Where("status = ?", "absent").Count(&absent)
-	query.Where("status = ?", "late").Count(&late)
-	query.Where("status = ?", "excused").Count(&excused)
+	var statusCounts []struct {
+		Status string
+		Count  int64
+	}
+	if err := query.Select("attendances.status, COUNT(*) as count").
+		Group("attendances.status").
+		Scan(&statusCounts).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.AttendanceStatus]int64)
+	var total int64
+	for _, sc := range statusCounts {
+		counts[models.AttendanceStatus(sc.Status)] = sc.Count
+		total += sc.Count
+	}
+
+	present := counts[models.StatusHadir]
+	late := counts[models.StatusTerlambat]
+	absent := counts[models.StatusAlpa]
+	excused := counts[models.StatusIzin] + counts[models.StatusSakit]
 
+	// Late arrivals still count as attended
 	attendanceRate := float64(0)
 	if total > 0 {
-		attendanceRate = float64(present) / float64(total) * 100
+		attendanceRate = float64(present+late) / float64(total) * 100
 	}
 
 	return map[string]interface{}{
-		"total":          total,
-		"present":        present,
-		"absent":         absent,
-		"late":           late,
-		"excused":        excused,
+		"total":           total,
+		"present":         present,
+		"absent":          absent,
+		"late":            late,
+		"excused":         excused,
 		"attendance_rate": attendanceRate,
 	}, nil
 }
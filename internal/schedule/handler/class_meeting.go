@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"unsri-backend/internal/schedule/service"
+	"unsri-backend/internal/shared/utils"
+)
+
+// GenerateClassMeetings handles generate class meetings request
+func (h *ScheduleHandler) GenerateClassMeetings(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.GenerateClassMeetingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.GenerateClassMeetings(c.Request.Context(), userID, userRole, c.Param("classId"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
+// UpdateClassPattern handles update class day, time and room request
+func (h *ScheduleHandler) UpdateClassPattern(c *gin.Context) {
+	userID := c.GetString("user_id")
+	userRole := c.GetString("user_role")
+
+	var req service.UpdateClassPatternRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.UpdateClassPattern(c.Request.Context(), userID, userRole, c.Param("classId"), req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
@@ -26,13 +26,15 @@ func NewScheduleHandler(service *service.ScheduleService, logger logger.Logger)
 
 // CreateSchedule handles create schedule request
 func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	userRole := c.GetString("user_role")
+
 	var req service.CreateScheduleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
 
-	result, err := h.service.CreateSchedule(c.Request.Context(), req)
+	result, err := h.service.CreateSchedule(c.Request.Context(), userRole, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -141,6 +143,7 @@ func (h *ScheduleHandler) GetCalendarView(c *gin.Context) {
 
 // UpdateSchedule handles update schedule request
 func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	userRole := c.GetString("user_role")
 	id := c.Param("id")
 
 	var req service.UpdateScheduleRequest
@@ -149,7 +152,7 @@ func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.UpdateSchedule(c.Request.Context(), id, req)
+	result, err := h.service.UpdateSchedule(c.Request.Context(), userRole, id, req)
 	if err != nil {
 		utils.ErrorResponse(c, 0, err)
 		return
@@ -23,6 +23,10 @@ func SetupRoutes(router *gin.Engine, handler *ScheduleHandler, jwtToken *jwt.JWT
 		v1.POST("", middleware.RoleMiddleware("dosen", "staff"), handler.CreateSchedule)
 		v1.PUT("/:id", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateSchedule)
 		v1.DELETE("/:id", middleware.RoleMiddleware("dosen", "staff"), handler.DeleteSchedule)
+
+		// Semester meetings generated from the class day, time and room
+		v1.POST("/classes/:classId/generate", middleware.RoleMiddleware("dosen", "staff"), handler.GenerateClassMeetings)
+		v1.PUT("/classes/:classId/pattern", middleware.RoleMiddleware("dosen", "staff"), handler.UpdateClassPattern)
 	}
 }
 
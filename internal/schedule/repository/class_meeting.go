@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"unsri-backend/internal/shared/models"
+)
+
+// GetClassByID gets a class with its course
+func (r *ScheduleRepository) GetClassByID(ctx context.Context, id string) (*models.Class, error) {
+	var class models.Class
+	if err := r.db.WithContext(ctx).Preload("Course").Where("id = ?", id).First(&class).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("class not found")
+		}
+		return nil, err
+	}
+	return &class, nil
+}
+
+// GetAcademicPeriodByID gets an academic period by ID
+func (r *ScheduleRepository) GetAcademicPeriodByID(ctx context.Context, id string) (*models.AcademicPeriod, error) {
+	var period models.AcademicPeriod
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&period).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("academic period not found")
+		}
+		return nil, err
+	}
+	return &period, nil
+}
+
+// GetActiveAcademicPeriod gets the active academic period, nil when none is active
+func (r *ScheduleRepository) GetActiveAcademicPeriod(ctx context.Context) (*models.AcademicPeriod, error) {
+	var period models.AcademicPeriod
+	if err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("start_date DESC").First(&period).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &period, nil
+}
+
+// GetNonTeachingEvents gets the holidays and exam periods of the academic calendar overlapping a date range
+func (r *ScheduleRepository) GetNonTeachingEvents(ctx context.Context, startDate, endDate time.Time) ([]models.AcademicEvent, error) {
+	var events []models.AcademicEvent
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ? AND LOWER(event_type) IN ? AND DATE(start_date) <= ? AND DATE(end_date) >= ?",
+			true, []string{"holiday", "exam"}, endDate.Format("2006-01-02"), startDate.Format("2006-01-02")).
+		Order("start_date ASC").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetClassMeetingsInRange gets the meetings of a class in a date range, cancelled ones included
+func (r *ScheduleRepository) GetClassMeetingsInRange(ctx context.Context, classID string, startDate, endDate time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if err := r.db.WithContext(ctx).
+		Where("class_id = ? AND date >= ? AND date <= ?", classID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("date ASC, start_time ASC").
+		Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetScheduleIDsWithAttendance returns which of the given schedules already have attendance recorded
+func (r *ScheduleRepository) GetScheduleIDsWithAttendance(ctx context.Context, scheduleIDs []string) ([]string, error) {
+	var ids []string
+	if len(scheduleIDs) == 0 {
+		return ids, nil
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Attendance{}).
+		Where("schedule_id IN ?", scheduleIDs).
+		Distinct("schedule_id").
+		Pluck("schedule_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ApplyClassMeetings stores a class pattern and the meetings planned from it in one transaction.
+// A nil class leaves the class unchanged; deleted meetings are soft deleted.
+func (r *ScheduleRepository) ApplyClassMeetings(ctx context.Context, class *models.Class, creates, updates []models.Schedule, deleteIDs []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if class != nil {
+			if err := tx.Model(class).Select("day_of_week", "start_time", "end_time", "room").Updates(class).Error; err != nil {
+				return err
+			}
+		}
+		if len(creates) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&creates).Error; err != nil {
+				return err
+			}
+		}
+		for i := range updates {
+			if err := tx.Omit(clause.Associations).Save(&updates[i]).Error; err != nil {
+				return err
+			}
+		}
+		if len(deleteIDs) > 0 {
+			if err := tx.Delete(&models.Schedule{}, "id IN ?", deleteIDs).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"unsri-backend/internal/shared/conflict"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Actions of a class meeting change
+const (
+	MeetingCreate    = "CREATE"    // A planned meeting is added
+	MeetingUpdate    = "UPDATE"    // An upcoming meeting moves to the class pattern
+	MeetingDelete    = "DELETE"    // An upcoming meeting is no longer planned
+	MeetingUnchanged = "UNCHANGED" // The meeting already matches the class pattern
+	MeetingKeep      = "KEEP"      // Cancelled, make-up, or already attended meetings are never changed
+	MeetingSkip      = "SKIP"      // The class day falls on a holiday or in an exam period
+)
+
+// ClassMeetingChange represents the change the class pattern makes to one meeting
+type ClassMeetingChange struct {
+	Date       string  `json:"date"`
+	Action     string  `json:"action"`
+	StartTime  string  `json:"start_time,omitempty"`
+	EndTime    string  `json:"end_time,omitempty"`
+	Room       string  `json:"room,omitempty"`
+	Reason     string  `json:"reason,omitempty"`      // Why a day is skipped or a meeting kept
+	ScheduleID *string `json:"schedule_id,omitempty"` // Existing meeting
+}
+
+// classMeetingDiff holds the changes of a class pattern and the meetings to write
+type classMeetingDiff struct {
+	Changes   []ClassMeetingChange
+	Creates   []models.Schedule
+	Updates   []models.Schedule
+	DeleteIDs []string
+}
+
+// dateOnly returns the calendar date of t, as a date
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// clockTime returns a clock time on today's date, in UTC like the meetings planned from it
+func clockTime(now time.Time, clock time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+}
+
+// nonTeachingEvent returns the holiday or exam period covering a date, nil when classes are held
+func nonTeachingEvent(events []models.AcademicEvent, date time.Time) *models.AcademicEvent {
+	day := date.Format("2006-01-02")
+	for i := range events {
+		if events[i].StartDate.Format("2006-01-02") <= day && day <= events[i].EndDate.Format("2006-01-02") {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// classMeeting builds the meeting of a class on a date, linked to its class and course
+func classMeeting(class *models.Class, date time.Time) models.Schedule {
+	courseID := class.CourseID
+	classID := class.ID
+	return models.Schedule{
+		CourseID:    &courseID,
+		ClassID:     &classID,
+		CourseCode:  class.Course.Code,
+		CourseName:  class.Course.Name,
+		DosenID:     class.DosenID,
+		Room:        class.Room,
+		DayOfWeek:   int(date.Weekday()),
+		StartTime:   time.Date(date.Year(), date.Month(), date.Day(), class.StartTime.Hour(), class.StartTime.Minute(), 0, 0, time.UTC),
+		EndTime:     time.Date(date.Year(), date.Month(), date.Day(), class.EndTime.Hour(), class.EndTime.Minute(), 0, 0, time.UTC),
+		Date:        date,
+		MeetingMode: models.MeetingModeOffline,
+		IsActive:    true,
+	}
+}
+
+// meetingMatches reports whether an existing meeting already follows the planned one
+func meetingMatches(existing, planned *models.Schedule) bool {
+	return existing.StartTime.Equal(planned.StartTime) && existing.EndTime.Equal(planned.EndTime) &&
+		existing.Room == planned.Room && existing.DosenID == planned.DosenID && existing.DayOfWeek == planned.DayOfWeek &&
+		existing.CourseID != nil && *existing.CourseID == *planned.CourseID
+}
+
+// diffClassMeetings compares the meetings a class pattern plans from `from` to `to` with the existing ones.
+// Cancelled meetings, make-up meetings and meetings with attendance are kept; a kept regular meeting still
+// covers its day. held lists the meetings with attendance recorded.
+func diffClassMeetings(class *models.Class, from, to time.Time, events []models.AcademicEvent, existing []models.Schedule, held map[string]bool) classMeetingDiff {
+	var diff classMeetingDiff
+	byDate := make(map[string][]*models.Schedule)
+	for i := range existing {
+		date := existing[i].Date.Format("2006-01-02")
+		byDate[date] = append(byDate[date], &existing[i])
+	}
+
+	keep := func(meeting *models.Schedule) string {
+		switch {
+		case meeting.CancelledAt != nil:
+			return "cancelled"
+		case meeting.ReplacesScheduleID != nil:
+			return "make-up meeting"
+		case meeting.AttendanceClosedAt != nil || held[meeting.ID]:
+			return "attendance recorded"
+		}
+		return ""
+	}
+
+	planned := make(map[string]bool)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if int(date.Weekday()) != class.DayOfWeek {
+			continue
+		}
+		day := date.Format("2006-01-02")
+		if event := nonTeachingEvent(events, date); event != nil {
+			diff.Changes = append(diff.Changes, ClassMeetingChange{Date: day, Action: MeetingSkip, Reason: strings.ToLower(event.EventType) + ": " + event.Title})
+			continue
+		}
+		planned[day] = true
+		meeting := classMeeting(class, date)
+		change := ClassMeetingChange{Date: day, StartTime: meeting.StartTime.Format("15:04"), EndTime: meeting.EndTime.Format("15:04"), Room: meeting.Room}
+
+		var current *models.Schedule
+		for _, candidate := range byDate[day] {
+			if candidate.ReplacesScheduleID == nil {
+				current = candidate
+				break
+			}
+		}
+
+		switch {
+		case current == nil:
+			change.Action = MeetingCreate
+			diff.Creates = append(diff.Creates, meeting)
+		case keep(current) != "":
+			change.Action = MeetingKeep
+			change.Reason = keep(current)
+			change.ScheduleID = &current.ID
+		case meetingMatches(current, &meeting):
+			change.Action = MeetingUnchanged
+			change.ScheduleID = &current.ID
+		default:
+			change.Action = MeetingUpdate
+			change.ScheduleID = &current.ID
+			updated := *current
+			updated.CourseID, updated.ClassID = meeting.CourseID, meeting.ClassID
+			updated.DosenID, updated.Room, updated.DayOfWeek = meeting.DosenID, meeting.Room, meeting.DayOfWeek
+			updated.StartTime, updated.EndTime = meeting.StartTime, meeting.EndTime
+			diff.Updates = append(diff.Updates, updated)
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	// Meetings on days no longer planned, and second regular meetings on a planned day
+	covered := make(map[string]bool)
+	for i := range existing {
+		meeting := &existing[i]
+		day := meeting.Date.Format("2006-01-02")
+		if planned[day] && meeting.ReplacesScheduleID == nil && !covered[day] {
+			covered[day] = true
+			continue
+		}
+		change := ClassMeetingChange{Date: day, ScheduleID: &meeting.ID, StartTime: meeting.StartTime.Format("15:04"), EndTime: meeting.EndTime.Format("15:04"), Room: meeting.Room}
+		if reason := keep(meeting); reason != "" {
+			change.Action = MeetingKeep
+			change.Reason = reason
+		} else {
+			change.Action = MeetingDelete
+			diff.DeleteIDs = append(diff.DeleteIDs, meeting.ID)
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	return diff
+}
+
+// GenerateClassMeetingsRequest represents generate class meetings request
+type GenerateClassMeetingsRequest struct {
+	AcademicPeriodID  *string `json:"academic_period_id,omitempty"` // Defaults to the active academic period
+	Commit            bool    `json:"commit"`                       // False previews the changes without writing them
+	OverrideConflicts bool    `json:"override_conflicts,omitempty"` // Staff only: commit despite room or lecturer conflicts
+}
+
+// ClassMeetingsResponse represents the changes a class pattern makes to the meetings of a semester
+type ClassMeetingsResponse struct {
+	ClassID          string               `json:"class_id"`
+	AcademicPeriodID string               `json:"academic_period_id"`
+	From             string               `json:"from"`
+	To               string               `json:"to"`
+	Committed        bool                 `json:"committed"`
+	Created          int                  `json:"created"`
+	Updated          int                  `json:"updated"`
+	Deleted          int                  `json:"deleted"`
+	Changes          []ClassMeetingChange `json:"changes"`
+	Conflicts        []conflict.Conflict  `json:"conflicts,omitempty"` // Rooms and lecturers already booked, a commit is refused unless staff override
+}
+
+// getOwnClass gets a class its dosen or staff may plan
+func (s *ScheduleService) getOwnClass(ctx context.Context, userID string, role string, classID string) (*models.Class, error) {
+	class, err := s.repo.GetClassByID(ctx, classID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("class", classID)
+	}
+	if role != string(models.RoleStaff) && class.DosenID != userID {
+		return nil, apperrors.NewForbiddenError("only the class dosen or staff can plan its meetings")
+	}
+	return class, nil
+}
+
+// GenerateClassMeetings plans the weekly meetings of a class for a semester from its day, time and room.
+// Today and the days already past are never touched.
+func (s *ScheduleService) GenerateClassMeetings(ctx context.Context, userID string, role string, classID string, req GenerateClassMeetingsRequest) (*ClassMeetingsResponse, error) {
+	class, err := s.getOwnClass(ctx, userID, role, classID)
+	if err != nil {
+		return nil, err
+	}
+	return s.syncClassMeetings(ctx, role, class, nil, req.AcademicPeriodID, req.Commit, req.OverrideConflicts)
+}
+
+// UpdateClassPatternRequest represents update class pattern request
+type UpdateClassPatternRequest struct {
+	DayOfWeek         *int    `json:"day_of_week,omitempty" binding:"omitempty,min=0,max=6"` // 0=Sunday
+	StartTime         *string `json:"start_time,omitempty"`                                  // HH:MM
+	EndTime           *string `json:"end_time,omitempty"`                                    // HH:MM
+	Room              *string `json:"room,omitempty"`
+	AcademicPeriodID  *string `json:"academic_period_id,omitempty"` // Defaults to the active academic period
+	Commit            bool    `json:"commit"`                       // False previews the changes without writing them
+	OverrideConflicts bool    `json:"override_conflicts,omitempty"` // Staff only: commit despite room or lecturer conflicts
+}
+
+// UpdateClassPattern changes the day, time or room of a class and moves its upcoming meetings to match.
+// Today's and past meetings keep the pattern they were planned or held with.
+func (s *ScheduleService) UpdateClassPattern(ctx context.Context, userID string, role string, classID string, req UpdateClassPatternRequest) (*ClassMeetingsResponse, error) {
+	class, err := s.getOwnClass(ctx, userID, role, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clock times are stored on today's date, as when the class was created
+	now := time.Now()
+	if req.DayOfWeek != nil {
+		class.DayOfWeek = *req.DayOfWeek
+	}
+	if req.StartTime != nil {
+		startTime, err := time.Parse("15:04", *req.StartTime)
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid start_time format, use HH:MM")
+		}
+		class.StartTime = clockTime(now, startTime)
+	}
+	if req.EndTime != nil {
+		endTime, err := time.Parse("15:04", *req.EndTime)
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid end_time format, use HH:MM")
+		}
+		class.EndTime = clockTime(now, endTime)
+	}
+	if req.Room != nil {
+		class.Room = strings.TrimSpace(*req.Room)
+	}
+
+	return s.syncClassMeetings(ctx, role, class, class, req.AcademicPeriodID, req.Commit, req.OverrideConflicts)
+}
+
+// syncClassMeetings plans the meetings of a class from tomorrow to the end of the semester and, on commit,
+// writes them along with the changed class pattern (nil when the pattern is unchanged). A commit is refused
+// when the new pattern or a created or moved meeting collides with another, unless staff override.
+func (s *ScheduleService) syncClassMeetings(ctx context.Context, role string, class *models.Class, changed *models.Class, periodID *string, commit bool, override bool) (*ClassMeetingsResponse, error) {
+	if class.StartTime.Hour()*60+class.StartTime.Minute() >= class.EndTime.Hour()*60+class.EndTime.Minute() {
+		return nil, apperrors.NewValidationError("the class must end after it starts")
+	}
+
+	var period *models.AcademicPeriod
+	var err error
+	if periodID != nil && *periodID != "" {
+		if period, err = s.repo.GetAcademicPeriodByID(ctx, *periodID); err != nil {
+			return nil, apperrors.NewNotFoundError("academic period", *periodID)
+		}
+	} else {
+		if period, err = s.repo.GetActiveAcademicPeriod(ctx); err != nil {
+			return nil, apperrors.NewInternalError("failed to get active academic period", err)
+		}
+		if period == nil {
+			return nil, apperrors.NewValidationError("no academic period is active, pass academic_period_id")
+		}
+	}
+
+	from := dateOnly(period.StartDate)
+	// Today's meeting may already have started, so it is left as it is
+	if tomorrow := dateOnly(time.Now()).AddDate(0, 0, 1); tomorrow.After(from) {
+		from = tomorrow
+	}
+	to := dateOnly(period.EndDate)
+	if from.After(to) {
+		return nil, apperrors.NewValidationError("the academic period has already ended")
+	}
+
+	events, err := s.repo.GetNonTeachingEvents(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get academic calendar", err)
+	}
+	existing, err := s.repo.GetClassMeetingsInRange(ctx, class.ID, from, to)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to get class meetings", err)
+	}
+	ids := make([]string, 0, len(existing))
+	for _, meeting := range existing {
+		ids = append(ids, meeting.ID)
+	}
+	heldIDs, err := s.repo.GetScheduleIDsWithAttendance(ctx, ids)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check attendance", err)
+	}
+	held := make(map[string]bool, len(heldIDs))
+	for _, id := range heldIDs {
+		held[id] = true
+	}
+
+	diff := diffClassMeetings(class, from, to, events, existing, held)
+	conflicts, err := s.classMeetingConflicts(ctx, class, changed, &diff)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to check schedule conflicts", err)
+	}
+	response := &ClassMeetingsResponse{
+		ClassID:          class.ID,
+		AcademicPeriodID: period.ID,
+		From:             from.Format("2006-01-02"),
+		To:               to.Format("2006-01-02"),
+		Committed:        commit,
+		Created:          len(diff.Creates),
+		Updated:          len(diff.Updates),
+		Deleted:          len(diff.DeleteIDs),
+		Changes:          diff.Changes,
+		Conflicts:        conflicts,
+	}
+	if !commit {
+		return response, nil
+	}
+	if err := conflict.Refuse(conflicts, override, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ApplyClassMeetings(ctx, changed, diff.Creates, diff.Updates, diff.DeleteIDs); err != nil {
+		return nil, apperrors.NewInternalError("failed to save class meetings", err)
+	}
+	return response, nil
+}
+
+// classMeetingConflicts lists the conflicts of a sync. A changed class pattern is checked as a weekly class,
+// which covers the upcoming meetings and room bookings of its weekday; otherwise each meeting the sync
+// creates or moves is checked on its date, leaving out the meetings the sync deletes.
+func (s *ScheduleService) classMeetingConflicts(ctx context.Context, class *models.Class, changed *models.Class, diff *classMeetingDiff) ([]conflict.Conflict, error) {
+	var slots []conflict.Slot
+	if changed != nil {
+		slots = append(slots, conflict.ClassSlot(changed))
+	} else {
+		meetings := append(append([]models.Schedule{}, diff.Creates...), diff.Updates...)
+		for i := range meetings {
+			slot := conflict.MeetingSlot(&meetings[i], class)
+			slot.Excluded = append(slot.Excluded, diff.DeleteIDs...)
+			slots = append(slots, slot)
+		}
+	}
+
+	var conflicts []conflict.Conflict
+	for _, slot := range slots {
+		existing, err := s.repo.GetConflictCandidates(ctx, slot)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, conflict.Detect(slot, *existing)...)
+	}
+	return conflicts, nil
+}
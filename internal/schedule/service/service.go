@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"unsri-backend/internal/shared/conflict"
 	apperrors "unsri-backend/internal/shared/errors"
 	"unsri-backend/internal/shared/models"
 	"unsri-backend/internal/schedule/repository"
@@ -22,6 +23,7 @@ func NewScheduleService(repo *repository.ScheduleRepository) *ScheduleService {
 // CreateScheduleRequest represents create schedule request
 type CreateScheduleRequest struct {
 	CourseID   *string `json:"course_id,omitempty"`
+	ClassID    *string `json:"class_id,omitempty"`
 	CourseCode string  `json:"course_code"`
 	CourseName string  `json:"course_name"`
 	DosenID    string  `json:"dosen_id" binding:"required"`
@@ -30,10 +32,12 @@ type CreateScheduleRequest struct {
 	StartTime  string  `json:"start_time" binding:"required"`
 	EndTime    string  `json:"end_time" binding:"required"`
 	Date       string  `json:"date" binding:"required"`
+	MeetingMode string `json:"meeting_mode,omitempty" binding:"omitempty,oneof=offline online hybrid"`
+	OverrideConflicts bool `json:"override_conflicts,omitempty"` // Staff only: create despite room or lecturer conflicts
 }
 
 // CreateSchedule creates a new schedule
-func (s *ScheduleService) CreateSchedule(ctx context.Context, req CreateScheduleRequest) (*models.Schedule, error) {
+func (s *ScheduleService) CreateSchedule(ctx context.Context, role string, req CreateScheduleRequest) (*models.Schedule, error) {
 	startTime, err := time.Parse("15:04", req.StartTime)
 	if err != nil {
 		return nil, apperrors.NewValidationError("invalid start_time format, use HH:MM")
@@ -54,6 +58,7 @@ func (s *ScheduleService) CreateSchedule(ctx context.Context, req CreateSchedule
 
 	schedule := &models.Schedule{
 		CourseID:   req.CourseID,
+		ClassID:    req.ClassID,
 		CourseCode: req.CourseCode,
 		CourseName: req.CourseName,
 		DosenID:    req.DosenID,
@@ -62,8 +67,17 @@ func (s *ScheduleService) CreateSchedule(ctx context.Context, req CreateSchedule
 		StartTime:  startDateTime,
 		EndTime:    endDateTime,
 		Date:       date,
+		MeetingMode: models.MeetingModeOffline,
 		IsActive:   true,
 	}
+	if req.MeetingMode != "" {
+		schedule.MeetingMode = models.MeetingMode(req.MeetingMode)
+	}
+
+	class, _ := s.meetingClass(ctx, schedule)
+	if err := conflict.Check(ctx, s.repo, conflict.MeetingSlot(schedule, class), req.OverrideConflicts, role); err != nil {
+		return nil, err
+	}
 
 	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
 		return nil, apperrors.NewInternalError("failed to create schedule", err)
@@ -72,6 +86,14 @@ func (s *ScheduleService) CreateSchedule(ctx context.Context, req CreateSchedule
 	return schedule, nil
 }
 
+// meetingClass gets the class a meeting belongs to, nil for a meeting without a class
+func (s *ScheduleService) meetingClass(ctx context.Context, schedule *models.Schedule) (*models.Class, error) {
+	if schedule.ClassID == nil {
+		return nil, nil
+	}
+	return s.repo.GetClassByID(ctx, *schedule.ClassID)
+}
+
 // GetScheduleByID gets a schedule by ID
 func (s *ScheduleService) GetScheduleByID(ctx context.Context, id string) (*models.Schedule, error) {
 	return s.repo.GetScheduleByID(ctx, id)
@@ -141,11 +163,13 @@ type UpdateScheduleRequest struct {
 	Room       *string `json:"room,omitempty"`
 	StartTime  *string `json:"start_time,omitempty"`
 	EndTime    *string `json:"end_time,omitempty"`
+	MeetingMode *string `json:"meeting_mode,omitempty" binding:"omitempty,oneof=offline online hybrid"`
 	IsActive   *bool   `json:"is_active,omitempty"`
+	OverrideConflicts bool `json:"override_conflicts,omitempty"` // Staff only: update despite room or lecturer conflicts
 }
 
 // UpdateSchedule updates a schedule
-func (s *ScheduleService) UpdateSchedule(ctx context.Context, id string, req UpdateScheduleRequest) (*models.Schedule, error) {
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, role string, id string, req UpdateScheduleRequest) (*models.Schedule, error) {
 	schedule, err := s.repo.GetScheduleByID(ctx, id)
 	if err != nil {
 		return nil, apperrors.NewNotFoundError("schedule", id)
@@ -176,10 +200,22 @@ func (s *ScheduleService) UpdateSchedule(ctx context.Context, id string, req Upd
 		schedule.EndTime = time.Date(schedule.Date.Year(), schedule.Date.Month(), schedule.Date.Day(),
 			endTime.Hour(), endTime.Minute(), 0, 0, schedule.Date.Location())
 	}
+	if req.MeetingMode != nil {
+		schedule.MeetingMode = models.MeetingMode(*req.MeetingMode)
+	}
 	if req.IsActive != nil {
 		schedule.IsActive = *req.IsActive
 	}
 
+	// Only a change of room, time or mode, or a reactivation, can make a meeting collide
+	moved := req.Room != nil || req.StartTime != nil || req.EndTime != nil || req.MeetingMode != nil || (req.IsActive != nil && *req.IsActive)
+	if moved && schedule.IsActive && schedule.CancelledAt == nil {
+		class, _ := s.meetingClass(ctx, schedule)
+		if err := conflict.Check(ctx, s.repo, conflict.MeetingSlot(schedule, class), req.OverrideConflicts, role); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
 		return nil, apperrors.NewInternalError("failed to update schedule", err)
 	}
@@ -182,3 +182,105 @@ func TestCreateScheduleRequest(t *testing.T) {
 	}
 }
 
+
+func TestDiffClassMeetings(t *testing.T) {
+	at := func(day, clock string) time.Time {
+		value, _ := time.Parse("2006-01-02 15:04", day+" "+clock)
+		return value
+	}
+	date := func(day string) time.Time {
+		value, _ := time.Parse("2006-01-02", day)
+		return value
+	}
+	class := &models.Class{
+		ID:        uuid.New().String(),
+		CourseID:  uuid.New().String(),
+		DosenID:   uuid.New().String(),
+		Room:      "A1",
+		DayOfWeek: 1, // Monday
+		StartTime: at("2026-01-01", "08:00"),
+		EndTime:   at("2026-01-01", "10:00"),
+		Course:    models.Course{Code: "IF101", Name: "Algoritma"},
+	}
+	meeting := func(day, start, end string) models.Schedule {
+		m := classMeeting(class, date(day))
+		m.ID = uuid.New().String()
+		m.StartTime, m.EndTime = at(day, start), at(day, end)
+		return m
+	}
+
+	events := []models.AcademicEvent{
+		{Title: "Nyepi", EventType: "holiday", StartDate: date("2026-03-09"), EndDate: date("2026-03-09")},
+		{Title: "UTS", EventType: "exam", StartDate: date("2026-03-23"), EndDate: date("2026-03-27")},
+	}
+
+	unchanged := meeting("2026-03-16", "08:00", "10:00")
+	moved := meeting("2026-03-30", "10:00", "12:00")
+	cancelled := meeting("2026-03-23", "08:00", "10:00")
+	cancelledAt := at("2026-03-20", "09:00")
+	cancelled.CancelledAt = &cancelledAt
+	stale := meeting("2026-03-11", "08:00", "10:00")
+	stale.DayOfWeek = 3
+	attended := meeting("2026-03-18", "08:00", "10:00")
+	attended.DayOfWeek = 3
+
+	existing := []models.Schedule{unchanged, moved, cancelled, stale, attended}
+	held := map[string]bool{attended.ID: true}
+
+	diff := diffClassMeetings(class, date("2026-03-01"), date("2026-03-31"), events, existing, held)
+
+	actions := make(map[string][]string)
+	for _, change := range diff.Changes {
+		actions[change.Date] = append(actions[change.Date], change.Action)
+	}
+	want := map[string][]string{
+		"2026-03-02": {MeetingCreate},
+		"2026-03-09": {MeetingSkip},
+		"2026-03-16": {MeetingUnchanged},
+		"2026-03-23": {MeetingSkip, MeetingKeep},
+		"2026-03-30": {MeetingUpdate},
+		"2026-03-11": {MeetingDelete},
+		"2026-03-18": {MeetingKeep},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("Expected changes on %d days, got %v", len(want), actions)
+	}
+	for day, expected := range want {
+		got := actions[day]
+		if len(got) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", day, expected, got)
+			continue
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("%s: expected %v, got %v", day, expected, got)
+			}
+		}
+	}
+
+	if len(diff.Creates) != 1 || diff.Creates[0].ClassID == nil || *diff.Creates[0].ClassID != class.ID ||
+		diff.Creates[0].CourseID == nil || *diff.Creates[0].CourseID != class.CourseID {
+		t.Errorf("Expected one meeting created and linked to the class and course, got %+v", diff.Creates)
+	}
+	if len(diff.Updates) != 1 || diff.Updates[0].ID != moved.ID || !diff.Updates[0].StartTime.Equal(at("2026-03-30", "08:00")) {
+		t.Errorf("Expected the 30 March meeting moved to 08:00, got %+v", diff.Updates)
+	}
+	if len(diff.DeleteIDs) != 1 || diff.DeleteIDs[0] != stale.ID {
+		t.Errorf("Expected only the Wednesday meeting deleted, got %v", diff.DeleteIDs)
+	}
+}
+
+// Test that class times set from another time zone plan meetings at the same clock time
+func TestClockTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 23, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+	clock, _ := time.Parse("15:04", "08:00")
+	class := &models.Class{StartTime: clockTime(now, clock), EndTime: clockTime(now, clock.Add(2*time.Hour))}
+
+	meeting := classMeeting(class, dateOnly(now))
+	if want := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC); !meeting.StartTime.Equal(want) {
+		t.Errorf("Expected the meeting to start at %v, got %v", want, meeting.StartTime)
+	}
+	if want := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC); !meeting.EndTime.Equal(want) {
+		t.Errorf("Expected the meeting to end at %v, got %v", want, meeting.EndTime)
+	}
+}
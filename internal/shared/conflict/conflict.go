@@ -0,0 +1,457 @@
+// Package conflict detects rooms and lecturers booked twice across meetings, weekly classes and room bookings
+package conflict
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+// Resources a conflict is about
+const (
+	ResourceRoom           = "ROOM"
+	ResourceDosen          = "DOSEN"
+	ResourceAssistantDosen = "ASSISTANT_DOSEN"
+)
+
+// Sources of a conflict
+const (
+	SourceSchedule    = "SCHEDULE"
+	SourceClass       = "CLASS"
+	SourceRoomBooking = "ROOM_BOOKING"
+)
+
+// Conflict represents a room or lecturer already in use at the time of a slot
+type Conflict struct {
+	Resource  string `json:"resource"` // ROOM, DOSEN or ASSISTANT_DOSEN
+	Value     string `json:"value"`    // The room, or the user ID of the lecturer
+	Source    string `json:"source"`   // SCHEDULE, CLASS or ROOM_BOOKING
+	SourceID  string `json:"source_id"`
+	Title     string `json:"title"`
+	Date      string `json:"date,omitempty"`        // Meetings and room bookings
+	DayOfWeek *int   `json:"day_of_week,omitempty"` // Weekly classes, 0=Sunday
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// Slot is the meeting, weekly class or room booking being planned
+type Slot struct {
+	Room             string
+	RoomID           *string
+	Online           bool // Online meetings use no room
+	DosenID          string
+	AssistantDosenID *string
+	Date             *time.Time // Nil for a weekly class
+	DayOfWeek        int        // 0=Sunday
+	StartTime        time.Time  // Only the clock time is compared
+	EndTime          time.Time
+	ClassID          *string // The class itself, or the class a meeting belongs to
+	Semester         string  // Weekly classes only meet classes of the same semester
+	AcademicYear     string
+	Excluded         []string // Meetings left out: the meeting being moved, or those it replaces
+}
+
+// Existing holds what is already planned around a slot
+type Existing struct {
+	Schedules  []models.Schedule // Cancelled meetings included: they tell which class days are not held
+	Classes    []models.Class
+	Bookings   []models.RoomBooking
+	Assistants map[string]string // Assistant dosen of the classes the schedules belong to, by class ID
+}
+
+// MeetingSlot returns the slot of a meeting; class is the class it belongs to, nil when unknown
+func MeetingSlot(schedule *models.Schedule, class *models.Class) Slot {
+	date := schedule.Date
+	slot := Slot{
+		Room:      schedule.Room,
+		Online:    schedule.MeetingMode == models.MeetingModeOnline,
+		DosenID:   schedule.DosenID,
+		Date:      &date,
+		DayOfWeek: int(date.Weekday()),
+		StartTime: schedule.StartTime,
+		EndTime:   schedule.EndTime,
+		ClassID:   schedule.ClassID,
+	}
+	if schedule.ID != "" {
+		slot.Excluded = []string{schedule.ID}
+	}
+	if class != nil {
+		slot.AssistantDosenID = class.AssistantDosenID
+	}
+	return slot
+}
+
+// ClassSlot returns the slot of a weekly class
+func ClassSlot(class *models.Class) Slot {
+	classID := class.ID
+	return Slot{
+		Room:             class.Room,
+		RoomID:           class.RoomID,
+		DosenID:          class.DosenID,
+		AssistantDosenID: class.AssistantDosenID,
+		DayOfWeek:        class.DayOfWeek,
+		StartTime:        class.StartTime,
+		EndTime:          class.EndTime,
+		ClassID:          &classID,
+		Semester:         class.Semester,
+		AcademicYear:     class.AcademicYear,
+	}
+}
+
+// BookingSlot returns the slot of a room booking
+func BookingSlot(booking *models.RoomBooking) Slot {
+	date := booking.Date
+	return Slot{
+		Room:      booking.Room,
+		RoomID:    booking.RoomID,
+		Date:      &date,
+		DayOfWeek: int(date.Weekday()),
+		StartTime: booking.StartTime,
+		EndTime:   booking.EndTime,
+	}
+}
+
+// clock returns the minutes since midnight of a time
+func clock(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// overlaps reports whether two clock ranges overlap
+func overlaps(slot *Slot, startTime, endTime time.Time) bool {
+	return clock(slot.StartTime) < clock(endTime) && clock(startTime) < clock(slot.EndTime)
+}
+
+// sameRoom reports whether a slot uses a room
+func sameRoom(slot *Slot, room string, roomID *string) bool {
+	if slot.Online {
+		return false
+	}
+	if slot.RoomID != nil && roomID != nil && *slot.RoomID == *roomID {
+		return true
+	}
+	return strings.TrimSpace(slot.Room) != "" && strings.EqualFold(strings.TrimSpace(slot.Room), strings.TrimSpace(room))
+}
+
+// people returns the conflicts of a slot's lecturers with those of a planned item
+func people(slot *Slot, dosenID string, assistantID string) []Conflict {
+	var conflicts []Conflict
+	busy := func(userID string) bool {
+		return userID != "" && (userID == dosenID || userID == assistantID)
+	}
+	if busy(slot.DosenID) {
+		conflicts = append(conflicts, Conflict{Resource: ResourceDosen, Value: slot.DosenID})
+	}
+	if slot.AssistantDosenID != nil && *slot.AssistantDosenID != slot.DosenID && busy(*slot.AssistantDosenID) {
+		conflicts = append(conflicts, Conflict{Resource: ResourceAssistantDosen, Value: *slot.AssistantDosenID})
+	}
+	return conflicts
+}
+
+// Detect lists the rooms and lecturers of a slot already in use at its time.
+// A meeting is checked against the meetings and room bookings of its date, and against the weekly classes
+// of its weekday that have no meeting on that date. A weekly class is checked against the other classes of
+// its semester, and against the upcoming meetings and room bookings on its weekday; the meetings of a
+// conflicting class are covered by the class.
+func Detect(slot Slot, existing Existing) []Conflict {
+	var conflicts []Conflict
+	add := func(found []Conflict, source, sourceID, title string, date *time.Time, dayOfWeek *int, startTime, endTime time.Time) {
+		for _, c := range found {
+			c.Source, c.SourceID, c.Title = source, sourceID, title
+			if date != nil {
+				c.Date = date.Format("2006-01-02")
+			}
+			c.DayOfWeek = dayOfWeek
+			c.StartTime, c.EndTime = startTime.Format("15:04"), endTime.Format("15:04")
+			conflicts = append(conflicts, c)
+		}
+	}
+	sameDay := func(date time.Time) bool {
+		if slot.Date != nil {
+			return date.Format("2006-01-02") == slot.Date.Format("2006-01-02")
+		}
+		return int(date.Weekday()) == slot.DayOfWeek
+	}
+
+	// Classes meeting on the slot's date follow their meetings, not their weekly pattern
+	classHeld := make(map[string]bool)
+	if slot.Date != nil {
+		for _, schedule := range existing.Schedules {
+			if schedule.ClassID != nil && sameDay(schedule.Date) {
+				classHeld[*schedule.ClassID] = true
+			}
+		}
+	}
+
+	excluded := make(map[string]bool, len(slot.Excluded))
+	for _, id := range slot.Excluded {
+		excluded[id] = true
+	}
+
+	classConflicts := make(map[string]bool)
+	for i := range existing.Classes {
+		class := &existing.Classes[i]
+		if !class.IsActive || class.DayOfWeek != slot.DayOfWeek || classHeld[class.ID] ||
+			(slot.ClassID != nil && *slot.ClassID == class.ID) || !overlaps(&slot, class.StartTime, class.EndTime) {
+			continue
+		}
+		if slot.Date == nil && (!strings.EqualFold(class.Semester, slot.Semester) || class.AcademicYear != slot.AcademicYear) {
+			continue
+		}
+		var found []Conflict
+		if sameRoom(&slot, class.Room, class.RoomID) {
+			found = append(found, Conflict{Resource: ResourceRoom, Value: class.Room})
+		}
+		assistantID := ""
+		if class.AssistantDosenID != nil {
+			assistantID = *class.AssistantDosenID
+		}
+		found = append(found, people(&slot, class.DosenID, assistantID)...)
+		if len(found) == 0 {
+			continue
+		}
+		classConflicts[class.ID] = true
+		dayOfWeek := class.DayOfWeek
+		title := strings.TrimSpace(class.Course.Code + " " + class.Course.Name + " " + class.ClassCode)
+		add(found, SourceClass, class.ID, title, nil, &dayOfWeek, class.StartTime, class.EndTime)
+	}
+
+	for i := range existing.Schedules {
+		schedule := &existing.Schedules[i]
+		if excluded[schedule.ID] || schedule.CancelledAt != nil || !schedule.IsActive || !sameDay(schedule.Date) || !overlaps(&slot, schedule.StartTime, schedule.EndTime) {
+			continue
+		}
+		if slot.Date == nil && schedule.ClassID != nil && (classConflicts[*schedule.ClassID] || (slot.ClassID != nil && *slot.ClassID == *schedule.ClassID)) {
+			continue
+		}
+		var found []Conflict
+		if schedule.MeetingMode != models.MeetingModeOnline && sameRoom(&slot, schedule.Room, nil) {
+			found = append(found, Conflict{Resource: ResourceRoom, Value: schedule.Room})
+		}
+		assistantID := ""
+		if schedule.ClassID != nil {
+			assistantID = existing.Assistants[*schedule.ClassID]
+		}
+		found = append(found, people(&slot, schedule.DosenID, assistantID)...)
+		title := strings.TrimSpace(schedule.CourseCode + " " + schedule.CourseName)
+		add(found, SourceSchedule, schedule.ID, title, &schedule.Date, nil, schedule.StartTime, schedule.EndTime)
+	}
+
+	for i := range existing.Bookings {
+		booking := &existing.Bookings[i]
+		if booking.CancelledAt != nil || !sameDay(booking.Date) || !overlaps(&slot, booking.StartTime, booking.EndTime) ||
+			!sameRoom(&slot, booking.Room, booking.RoomID) {
+			continue
+		}
+		add([]Conflict{{Resource: ResourceRoom, Value: booking.Room}}, SourceRoomBooking, booking.ID, booking.Title, &booking.Date, nil, booking.StartTime, booking.EndTime)
+	}
+
+	return conflicts
+}
+
+// Refuse returns the error for the conflicts of a slot, nil when there are none or staff override them.
+// Only staff may override.
+func Refuse(conflicts []Conflict, override bool, role string) error {
+	if override && role != string(models.RoleStaff) {
+		return apperrors.NewForbiddenError("only staff can override schedule conflicts")
+	}
+	if len(conflicts) == 0 || override {
+		return nil
+	}
+
+	first := conflicts[0]
+	when := first.Date
+	if first.DayOfWeek != nil {
+		when = "every " + time.Weekday(*first.DayOfWeek).String()
+	}
+	what := "room " + first.Value
+	switch first.Resource {
+	case ResourceDosen:
+		what = "the dosen"
+	case ResourceAssistantDosen:
+		what = "the assistant dosen"
+	}
+	message := fmt.Sprintf("%s is already booked for %s on %s from %s to %s", what, first.Title, when, first.StartTime, first.EndTime)
+	if len(conflicts) > 1 {
+		message += fmt.Sprintf(" (%d more conflicts)", len(conflicts)-1)
+	}
+	return apperrors.NewConflictErrorWithDetails(message, map[string]interface{}{"conflicts": conflicts})
+}
+
+// Loader gets what is already planned around a slot; the repositories of the services planning slots
+// implement it with Load
+type Loader interface {
+	GetConflictCandidates(ctx context.Context, slot Slot) (*Existing, error)
+}
+
+// Check refuses a slot whose room, dosen or assistant dosen is already booked at its time.
+// Staff may override.
+func Check(ctx context.Context, loader Loader, slot Slot, override bool, role string) error {
+	if override {
+		return Refuse(nil, override, role)
+	}
+
+	existing, err := loader.GetConflictCandidates(ctx, slot)
+	if err != nil {
+		return apperrors.NewInternalError("failed to check schedule conflicts", err)
+	}
+	return Refuse(Detect(slot, *existing), false, role)
+}
+
+// anyOf joins conditions with OR, nil when there are none
+func anyOf(conditions []string, args []interface{}) (string, []interface{}) {
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// Load gets what is already planned around a slot from the database. Meetings are checked against the
+// weekly classes of the active academic period, when it covers their date. Weekly classes are checked
+// against the meetings and room bookings from today to the end of their academic period, when it is known.
+func Load(ctx context.Context, db *gorm.DB, slot Slot) (*Existing, error) {
+	existing := &Existing{Assistants: make(map[string]string)}
+	db = db.WithContext(ctx)
+
+	from := time.Now().Format("2006-01-02")
+	until := ""
+	if slot.Date == nil {
+		var period models.AcademicPeriod
+		err := db.Where("academic_year = ? AND LOWER(semester_type) = LOWER(?)", slot.AcademicYear, slot.Semester).
+			Order("start_date DESC").First(&period).Error
+		switch {
+		case err == nil:
+			if start := period.StartDate.Format("2006-01-02"); start > from {
+				from = start
+			}
+			until = period.EndDate.Format("2006-01-02")
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	var userIDs []string
+	if slot.DosenID != "" {
+		userIDs = append(userIDs, slot.DosenID)
+	}
+	if slot.AssistantDosenID != nil && *slot.AssistantDosenID != "" {
+		userIDs = append(userIDs, *slot.AssistantDosenID)
+	}
+	var room, roomID string
+	if !slot.Online {
+		room = strings.ToLower(strings.TrimSpace(slot.Room))
+		if slot.RoomID != nil {
+			roomID = *slot.RoomID
+		}
+	}
+
+	var roomConditions []string
+	var roomArgs []interface{}
+	if room != "" {
+		roomConditions = append(roomConditions, "LOWER(TRIM(room)) = ?")
+		roomArgs = append(roomArgs, room)
+	}
+	if roomID != "" {
+		roomConditions = append(roomConditions, "room_id = ?")
+		roomArgs = append(roomArgs, roomID)
+	}
+
+	// Classes of the slot's weekday using the room or the lecturers
+	classConditions := append([]string{}, roomConditions...)
+	classArgs := append([]interface{}{}, roomArgs...)
+	if len(userIDs) > 0 {
+		classConditions = append(classConditions, "dosen_id IN ?", "assistant_dosen_id IN ?")
+		classArgs = append(classArgs, userIDs, userIDs)
+	}
+	if condition, args := anyOf(classConditions, classArgs); condition != "" {
+		classes := db.Preload("Course").Where("is_active = ? AND day_of_week = ?", true, slot.DayOfWeek).Where(condition, args...)
+		if slot.Date != nil {
+			var period models.AcademicPeriod
+			day := slot.Date.Format("2006-01-02")
+			err := db.Where("is_active = ? AND start_date <= ? AND end_date >= ?", true, day, day).Order("start_date DESC").First(&period).Error
+			switch {
+			case err == nil:
+				classes = classes.Where("academic_year = ? AND LOWER(semester) = LOWER(?)", period.AcademicYear, period.SemesterType)
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				classes = nil
+			default:
+				return nil, err
+			}
+		} else {
+			classes = classes.Where("academic_year = ? AND LOWER(semester) = LOWER(?)", slot.AcademicYear, slot.Semester)
+		}
+		if classes != nil {
+			if err := classes.Find(&existing.Classes).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Meetings: every meeting of a date, or the upcoming ones of a weekday using the room or the lecturers
+	var scheduleConditions []string
+	var scheduleArgs []interface{}
+	if room != "" {
+		scheduleConditions = append(scheduleConditions, "LOWER(TRIM(room)) = ?")
+		scheduleArgs = append(scheduleArgs, room)
+	}
+	if len(userIDs) > 0 {
+		scheduleConditions = append(scheduleConditions, "dosen_id IN ?", "class_id IN (?)")
+		scheduleArgs = append(scheduleArgs, userIDs, db.Model(&models.Class{}).Select("id").Where("assistant_dosen_id IN ?", userIDs))
+	}
+	schedules := db.Where("is_active = ?", true)
+	if slot.Date != nil {
+		schedules = schedules.Where("date = ?", slot.Date.Format("2006-01-02"))
+	} else if condition, args := anyOf(scheduleConditions, scheduleArgs); condition != "" {
+		schedules = schedules.Where("cancelled_at IS NULL AND day_of_week = ? AND date >= ?", slot.DayOfWeek, from).
+			Where(condition, args...)
+		if until != "" {
+			schedules = schedules.Where("date <= ?", until)
+		}
+	} else {
+		schedules = nil
+	}
+	if schedules != nil {
+		if err := schedules.Order("date ASC, start_time ASC").Find(&existing.Schedules).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var classIDs []string
+	for _, schedule := range existing.Schedules {
+		if schedule.ClassID != nil {
+			classIDs = append(classIDs, *schedule.ClassID)
+		}
+	}
+	if len(classIDs) > 0 {
+		var assisted []models.Class
+		if err := db.Select("id", "assistant_dosen_id").Where("id IN ? AND assistant_dosen_id IS NOT NULL", classIDs).Find(&assisted).Error; err != nil {
+			return nil, err
+		}
+		for _, class := range assisted {
+			existing.Assistants[class.ID] = *class.AssistantDosenID
+		}
+	}
+
+	// Room bookings of the room
+	if condition, args := anyOf(roomConditions, roomArgs); condition != "" {
+		bookings := db.Where("cancelled_at IS NULL").Where(condition, args...)
+		if slot.Date != nil {
+			bookings = bookings.Where("date = ?", slot.Date.Format("2006-01-02"))
+		} else {
+			bookings = bookings.Where("EXTRACT(DOW FROM date) = ? AND date >= ?", slot.DayOfWeek, from)
+			if until != "" {
+				bookings = bookings.Where("date <= ?", until)
+			}
+		}
+		if err := bookings.Order("date ASC, start_time ASC").Find(&existing.Bookings).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,154 @@
+package conflict
+
+import (
+	"testing"
+	"time"
+
+	apperrors "unsri-backend/internal/shared/errors"
+	"unsri-backend/internal/shared/models"
+)
+
+func at(day, clock string) time.Time {
+	value, _ := time.Parse("2006-01-02 15:04", day+" "+clock)
+	return value
+}
+
+func date(day string) time.Time {
+	value, _ := time.Parse("2006-01-02", day)
+	return value
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func sources(conflicts []Conflict) map[string]string {
+	found := make(map[string]string)
+	for _, c := range conflicts {
+		found[c.SourceID] = c.Resource
+	}
+	return found
+}
+
+func TestDetectMeeting(t *testing.T) {
+	day := "2026-03-02" // Monday
+	meeting := &models.Schedule{
+		Room:      "A1",
+		DosenID:   "dosen",
+		Date:      date(day),
+		StartTime: at(day, "08:00"),
+		EndTime:   at(day, "10:00"),
+	}
+	slot := MeetingSlot(meeting, &models.Class{AssistantDosenID: strPtr("assistant")})
+
+	cancelledAt := at("2026-02-27", "12:00")
+	existing := Existing{
+		Schedules: []models.Schedule{
+			{ID: "room", Room: "a1 ", DosenID: "other", Date: date(day), StartTime: at(day, "09:00"), EndTime: at(day, "11:00"), IsActive: true},
+			{ID: "dosen", Room: "B2", DosenID: "dosen", Date: date(day), StartTime: at(day, "09:00"), EndTime: at(day, "10:00"), IsActive: true},
+			{ID: "cancelled", Room: "A1", DosenID: "other", ClassID: strPtr("held"), Date: date(day), StartTime: at(day, "08:00"), EndTime: at(day, "10:00"), IsActive: true, CancelledAt: &cancelledAt},
+			{ID: "online", Room: "A1", DosenID: "other", ClassID: strPtr("assisted"), MeetingMode: models.MeetingModeOnline, Date: date(day), StartTime: at(day, "08:00"), EndTime: at(day, "09:00"), IsActive: true},
+			{ID: "other-day", Room: "A1", DosenID: "dosen", Date: date("2026-03-09"), StartTime: at("2026-03-09", "08:00"), EndTime: at("2026-03-09", "10:00"), IsActive: true},
+		},
+		Classes: []models.Class{
+			{ID: "taught", Room: "X", DosenID: "other", AssistantDosenID: strPtr("dosen"), DayOfWeek: 1, StartTime: at(day, "07:00"), EndTime: at(day, "08:30"), IsActive: true},
+			{ID: "held", Room: "A1", DosenID: "other", DayOfWeek: 1, StartTime: at(day, "08:00"), EndTime: at(day, "10:00"), IsActive: true},
+		},
+		Bookings: []models.RoomBooking{
+			{ID: "touching", Room: "A1", Date: date(day), StartTime: at(day, "10:00"), EndTime: at(day, "11:00")},
+			{ID: "overlaps", Room: "A1", Date: date(day), StartTime: at(day, "07:30"), EndTime: at(day, "08:15")},
+		},
+		Assistants: map[string]string{"assisted": "assistant"},
+	}
+
+	got := sources(Detect(slot, existing))
+	want := map[string]string{
+		"room":     ResourceRoom,
+		"dosen":    ResourceDosen,
+		"online":   ResourceAssistantDosen,
+		"taught":   ResourceDosen,
+		"overlaps": ResourceRoom,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected conflicts %v, got %v", want, got)
+	}
+	for id, resource := range want {
+		if got[id] != resource {
+			t.Errorf("%s: expected %s conflict, got %q", id, resource, got[id])
+		}
+	}
+}
+
+func TestDetectLeavesOutMovedMeeting(t *testing.T) {
+	day := "2026-03-02"
+	meeting := &models.Schedule{ID: "moved", Room: "A1", DosenID: "dosen", Date: date(day), StartTime: at(day, "09:00"), EndTime: at(day, "11:00")}
+	slot := MeetingSlot(meeting, nil)
+	slot.Excluded = append(slot.Excluded, "replaced")
+
+	existing := Existing{Schedules: []models.Schedule{
+		{ID: "moved", Room: "A1", DosenID: "dosen", Date: date(day), StartTime: at(day, "08:00"), EndTime: at(day, "10:00"), IsActive: true},
+		{ID: "replaced", Room: "A1", DosenID: "dosen", Date: date(day), StartTime: at(day, "10:00"), EndTime: at(day, "12:00"), IsActive: true},
+		{ID: "other", Room: "A1", DosenID: "other", Date: date(day), StartTime: at(day, "10:30"), EndTime: at(day, "12:00"), IsActive: true},
+	}}
+
+	got := sources(Detect(slot, existing))
+	if len(got) != 1 || got["other"] != ResourceRoom {
+		t.Errorf("Expected only the room conflict with other, got %v", got)
+	}
+}
+func TestDetectWeeklyClass(t *testing.T) {
+	day := "2026-03-02" // Monday
+	class := &models.Class{
+		ID:           "new",
+		Room:         "A1",
+		DosenID:      "dosen",
+		DayOfWeek:    1,
+		StartTime:    at(day, "08:00"),
+		EndTime:      at(day, "10:00"),
+		Semester:     "Ganjil",
+		AcademicYear: "2025/2026",
+	}
+	slot := ClassSlot(class)
+
+	existing := Existing{
+		Classes: []models.Class{
+			{ID: "same-room", Room: "A1", DosenID: "other", DayOfWeek: 1, StartTime: at(day, "09:00"), EndTime: at(day, "11:00"), IsActive: true, Semester: "GANJIL", AcademicYear: "2025/2026"},
+			{ID: "last-year", Room: "A1", DosenID: "dosen", DayOfWeek: 1, StartTime: at(day, "08:00"), EndTime: at(day, "10:00"), IsActive: true, Semester: "Ganjil", AcademicYear: "2024/2025"},
+			{ID: "tuesday", Room: "A1", DosenID: "dosen", DayOfWeek: 2, StartTime: at(day, "08:00"), EndTime: at(day, "10:00"), IsActive: true, Semester: "Ganjil", AcademicYear: "2025/2026"},
+		},
+		Schedules: []models.Schedule{
+			{ID: "class-meeting", Room: "A1", DosenID: "other", ClassID: strPtr("same-room"), Date: date("2026-03-09"), StartTime: at("2026-03-09", "09:00"), EndTime: at("2026-03-09", "11:00"), IsActive: true},
+			{ID: "seminar", Room: "B2", DosenID: "dosen", Date: date("2026-03-16"), StartTime: at("2026-03-16", "07:00"), EndTime: at("2026-03-16", "08:30"), IsActive: true},
+		},
+	}
+
+	got := sources(Detect(slot, existing))
+	if len(got) != 2 || got["same-room"] != ResourceRoom || got["seminar"] != ResourceDosen {
+		t.Errorf("Expected the room taken by a class and the dosen by a meeting, got %v", got)
+	}
+}
+
+func TestRefuse(t *testing.T) {
+	conflicts := []Conflict{{Resource: ResourceRoom, Value: "A1", Source: SourceSchedule, SourceID: "s1", Title: "IF101", Date: "2026-03-02", StartTime: "08:00", EndTime: "10:00"}}
+
+	if err := Refuse(nil, false, "dosen"); err != nil {
+		t.Errorf("Expected no error without conflicts, got %v", err)
+	}
+	if err := Refuse(conflicts, true, string(models.RoleStaff)); err != nil {
+		t.Errorf("Expected staff to override, got %v", err)
+	}
+
+	err := Refuse(conflicts, true, "dosen")
+	if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != apperrors.ErrCodeForbidden {
+		t.Errorf("Expected a dosen override to be forbidden, got %v", err)
+	}
+
+	err = Refuse(conflicts, false, string(models.RoleStaff))
+	appErr, ok := err.(*apperrors.AppError)
+	if !ok || appErr.Code != apperrors.ErrCodeConflict {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+	if details, ok := appErr.Details["conflicts"].([]Conflict); !ok || len(details) != 1 {
+		t.Errorf("Expected the conflicts in the error details, got %v", appErr.Details)
+	}
+}
@@ -7,6 +7,7 @@ type AppError struct {
 	Code    string
 	Message string
 	Err     error
+	Details map[string]interface{} // Structured details returned to the client
 }
 
 func (e *AppError) Error() string {
@@ -84,6 +85,15 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+// NewConflictErrorWithDetails creates a conflict error carrying structured details for the client
+func NewConflictErrorWithDetails(message string, details map[string]interface{}) *AppError {
+	return &AppError{
+		Code:    ErrCodeConflict,
+		Message: message,
+		Details: details,
+	}
+}
+
 func NewTooManyRequestsError(message string) *AppError {
 	return &AppError{
 		Code:    ErrCodeTooManyRequests,
@@ -16,6 +16,8 @@ type AccessLog struct {
 	IsAllowed   bool      `gorm:"default:true" json:"is_allowed"`
 	Reason      string    `gorm:"type:text" json:"reason,omitempty"`
 	QRCodeID    *string   `gorm:"type:uuid" json:"qr_code_id,omitempty"`
+	Latitude    *float64  `json:"latitude,omitempty"`  // Gate location, when reported by the gate system
+	Longitude   *float64  `json:"longitude,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 
 	// Relations
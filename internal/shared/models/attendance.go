@@ -34,6 +34,7 @@ type AttendanceSession struct {
 	Type       AttendanceType `gorm:"type:varchar(20);not null" json:"type"`
 	QRCode     string    `gorm:"type:text" json:"qr_code"` // QR code data
 	ExpiresAt  time.Time `gorm:"not null" json:"expires_at"`
+	AllowGuests bool     `gorm:"default:false" json:"allow_guests"` // Accept scans from users not enrolled in the class
 	IsActive   bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -69,14 +70,20 @@ type Attendance struct {
 	CheckOutTime *time.Time `json:"check_out_time"` // For tap out
 	Latitude  *float64 `json:"latitude"` // Location latitude
 	Longitude *float64 `json:"longitude"` // Location longitude
+	AccuracyMeters *float64 `json:"accuracy_meters,omitempty"` // GPS accuracy reported by the device
 	Notes     string   `gorm:"type:text" json:"notes"`
+	IsGuest   bool     `gorm:"default:false" json:"is_guest"` // Attended without an approved enrollment
+	DistanceMeters *float64 `json:"distance_meters,omitempty"` // Distance from the class room area center
+	LocationValid  *bool    `json:"location_valid,omitempty"`  // Nil when the location was not verified
+	DeviceID  *string  `gorm:"type:varchar(255);index" json:"device_id,omitempty"` // Device the scan was made from
 	CreatedBy *string  `gorm:"type:uuid" json:"created_by"` // For manual entry
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	User    User                  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	History []AttendanceChangeLog `gorm:"foreignKey:AttendanceID" json:"history,omitempty"`
 }
 
 // TableName specifies the table name
@@ -92,10 +99,21 @@ func (a *Attendance) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// MeetingMode represents how a class meeting is held.
+// Room location is only enforced on scans for offline meetings.
+type MeetingMode string
+
+const (
+	MeetingModeOffline MeetingMode = "offline"
+	MeetingModeOnline  MeetingMode = "online"
+	MeetingModeHybrid  MeetingMode = "hybrid"
+)
+
 // Schedule represents a class schedule (expandable for academic system)
 type Schedule struct {
 	ID        string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	CourseID  *string   `gorm:"type:uuid;index" json:"course_id"` // For future expansion
+	ClassID   *string   `gorm:"type:uuid;index" json:"class_id"`  // Class (kelas) this meeting belongs to
 	CourseCode string   `gorm:"type:varchar(50)" json:"course_code"` // Temporary, until course service is ready
 	CourseName string   `gorm:"type:varchar(255)" json:"course_name"` // Temporary
 	DosenID   string    `gorm:"type:uuid;not null;index" json:"dosen_id"`
@@ -104,6 +122,14 @@ type Schedule struct {
 	StartTime time.Time `gorm:"not null" json:"start_time"`
 	EndTime   time.Time `gorm:"not null" json:"end_time"`
 	Date      time.Time `gorm:"not null;index" json:"date"` // Specific date for this schedule
+	MeetingMode MeetingMode `gorm:"type:varchar(20);default:'offline'" json:"meeting_mode"`
+	AttendanceClosedAt *time.Time `json:"attendance_closed_at,omitempty"` // Set once the roster is final
+	AttendanceClosedBy *string    `gorm:"type:uuid" json:"attendance_closed_by,omitempty"` // Nil when closed by the background worker
+	CancelledAt        *time.Time `gorm:"index" json:"cancelled_at,omitempty"`
+	CancelledBy        *string    `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CancellationReason string     `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	ReplacementScheduleID *string `gorm:"type:uuid;index" json:"replacement_schedule_id,omitempty"` // Make-up meeting (kelas pengganti) of a cancelled meeting
+	ReplacesScheduleID    *string `gorm:"type:uuid;index" json:"replaces_schedule_id,omitempty"`    // Cancelled meeting this make-up meeting replaces
 	IsActive  bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -126,3 +152,51 @@ func (s *Schedule) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+
+// ScanRejectionReason represents why an attendance scan was refused
+type ScanRejectionReason string
+
+const (
+	RejectionNotStudent            ScanRejectionReason = "NOT_STUDENT"
+	RejectionNotEnrolled           ScanRejectionReason = "NOT_ENROLLED"
+	RejectionEnrollmentDropped     ScanRejectionReason = "ENROLLMENT_DROPPED"
+	RejectionEnrollmentNotApproved ScanRejectionReason = "ENROLLMENT_NOT_APPROVED"
+	RejectionCutoffPassed          ScanRejectionReason = "CUTOFF_PASSED"
+	RejectionLocationRequired      ScanRejectionReason = "LOCATION_REQUIRED"
+	RejectionOutsideRoomArea       ScanRejectionReason = "OUTSIDE_ROOM_AREA"
+	RejectionDeviceRequired        ScanRejectionReason = "DEVICE_REQUIRED"
+	RejectionDeviceNotBound        ScanRejectionReason = "DEVICE_NOT_BOUND"
+	RejectionLocationAnomaly       ScanRejectionReason = "LOCATION_ANOMALY"
+)
+
+// AttendanceScanRejection records a refused attendance scan for lecturer review
+type AttendanceScanRejection struct {
+	ID         string              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SessionID  string              `gorm:"type:uuid;not null;index" json:"session_id"`
+	ScheduleID *string             `gorm:"type:uuid;index" json:"schedule_id"`
+	ClassID    *string             `gorm:"type:uuid;index" json:"class_id"`
+	UserID     string              `gorm:"type:uuid;not null;index" json:"user_id"`
+	Reason     ScanRejectionReason `gorm:"type:varchar(50);not null" json:"reason"`
+	Message    string              `gorm:"type:text" json:"message"`
+	Latitude   *float64            `json:"latitude"`
+	Longitude  *float64            `json:"longitude"`
+	DistanceMeters *float64        `json:"distance_meters,omitempty"`
+	DeviceID   *string             `gorm:"type:varchar(255);index" json:"device_id,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name
+func (AttendanceScanRejection) TableName() string {
+	return "attendance_scan_rejections"
+}
+
+// BeforeCreate hook to generate UUID
+func (a *AttendanceScanRejection) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
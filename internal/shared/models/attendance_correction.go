@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CorrectionStatus represents attendance correction request status
+type CorrectionStatus string
+
+const (
+	CorrectionStatusPending  CorrectionStatus = "PENDING"
+	CorrectionStatusApproved CorrectionStatus = "APPROVED"
+	CorrectionStatusRejected CorrectionStatus = "REJECTED"
+)
+
+// AttendanceCorrectionRequest represents a student's request to correct their class attendance
+type AttendanceCorrectionRequest struct {
+	ID               string           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           string           `gorm:"type:uuid;not null;index" json:"user_id"`
+	ScheduleID       string           `gorm:"type:uuid;not null;index" json:"schedule_id"`
+	SessionID        *string          `gorm:"type:uuid" json:"session_id,omitempty"`
+	AttendanceID     *string          `gorm:"type:uuid;index" json:"attendance_id,omitempty"` // Existing record to correct, nil when none was made
+	RequestedStatus  AttendanceStatus `gorm:"type:varchar(20);not null" json:"requested_status"`
+	Reason           string           `gorm:"type:text;not null" json:"reason"`
+	AttachmentFileID *string          `gorm:"type:uuid" json:"attachment_file_id,omitempty"` // File uploaded to the file-storage service
+	Status           CorrectionStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	ReviewedBy       *string          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time       `json:"reviewed_at,omitempty"`
+	ReviewNotes      string           `gorm:"type:text" json:"review_notes,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt   `gorm:"index" json:"-"`
+
+	// Relations
+	User       User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Schedule   Schedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	Attachment *File    `gorm:"foreignKey:AttachmentFileID" json:"attachment,omitempty"`
+}
+
+// TableName specifies the table name
+func (AttendanceCorrectionRequest) TableName() string {
+	return "attendance_correction_requests"
+}
+
+// BeforeCreate hook
+func (a *AttendanceCorrectionRequest) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AttendanceChangeSource represents what caused a change to an attendance record
+type AttendanceChangeSource string
+
+const (
+	ChangeSourceManual     AttendanceChangeSource = "MANUAL"     // Created by a lecturer or staff
+	ChangeSourceUpdate     AttendanceChangeSource = "UPDATE"     // Edited by a lecturer or staff
+	ChangeSourceCorrection AttendanceChangeSource = "CORRECTION" // Approved correction request
+	ChangeSourceImport     AttendanceChangeSource = "IMPORT"     // Imported from a paper sign-in sheet
+)
+
+// AttendanceChangeLog records a change to an attendance record
+type AttendanceChangeLog struct {
+	ID                  string                 `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AttendanceID        string                 `gorm:"type:uuid;not null;index" json:"attendance_id"`
+	ChangedBy           string                 `gorm:"type:uuid;not null" json:"changed_by"`
+	Source              AttendanceChangeSource `gorm:"type:varchar(20);not null" json:"source"`
+	OldStatus           *AttendanceStatus      `gorm:"type:varchar(20)" json:"old_status,omitempty"` // Nil when the record was created
+	NewStatus           AttendanceStatus       `gorm:"type:varchar(20);not null" json:"new_status"`
+	Notes               string                 `gorm:"type:text" json:"notes,omitempty"`
+	CorrectionRequestID *string                `gorm:"type:uuid" json:"correction_request_id,omitempty"`
+	CreatedAt           time.Time              `json:"created_at"`
+
+	// Relations
+	ChangedByUser User `gorm:"foreignKey:ChangedBy" json:"changed_by_user,omitempty"`
+}
+
+// TableName specifies the table name
+func (AttendanceChangeLog) TableName() string {
+	return "attendance_change_logs"
+}
+
+// BeforeCreate hook
+func (a *AttendanceChangeLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
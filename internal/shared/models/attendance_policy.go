@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PolicyScope represents the level an attendance policy applies to
+type PolicyScope string
+
+const (
+	PolicyScopeGlobal       PolicyScope = "GLOBAL"
+	PolicyScopeCourse       PolicyScope = "COURSE"
+	PolicyScopeClass        PolicyScope = "CLASS"
+	PolicyScopeStudyProgram PolicyScope = "STUDY_PROGRAM" // Matched against the course prodi
+)
+
+// LatenessPolicy represents lateness rules for class attendance scans.
+// Minutes are counted from the schedule start time: scans within GraceMinutes are hadir,
+// within the following LateWindowMinutes terlambat, and later scans are alpa.
+// Scans after CutoffMinutes are refused.
+type LatenessPolicy struct {
+	ID                string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope             PolicyScope    `gorm:"type:varchar(20);not null;index" json:"scope"`
+	CourseID          *string        `gorm:"type:uuid;index" json:"course_id,omitempty"`
+	ClassID           *string        `gorm:"type:uuid;index" json:"class_id,omitempty"`
+	GraceMinutes      int            `gorm:"not null;default:15" json:"grace_minutes"`
+	LateWindowMinutes int            `gorm:"not null;default:15" json:"late_window_minutes"`
+	CutoffMinutes     *int           `gorm:"type:integer" json:"cutoff_minutes,omitempty"` // Nil = accept scans until the QR expires
+	IsActive          bool           `gorm:"default:true" json:"is_active"`
+	CreatedBy         string         `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (LatenessPolicy) TableName() string {
+	return "lateness_policies"
+}
+
+// BeforeCreate hook
+func (l *LatenessPolicy) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// EligibilityPolicy represents the minimum class attendance needed to sit the final exam (UAS).
+// WarningLevels are comma separated percentages, e.g. "85,80"; students dropping below a level
+// are warned once, together with their academic advisor.
+type EligibilityPolicy struct {
+	ID                   string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope                PolicyScope    `gorm:"type:varchar(20);not null;index" json:"scope"`
+	CourseID             *string        `gorm:"type:uuid;index" json:"course_id,omitempty"`
+	Prodi                *string        `gorm:"type:varchar(255);index" json:"prodi,omitempty"`
+	MinAttendancePercent float64        `gorm:"not null;default:75" json:"min_attendance_percent"`
+	WarningLevels        string         `gorm:"type:varchar(100)" json:"warning_levels"`
+	IsActive             bool           `gorm:"default:true" json:"is_active"`
+	CreatedBy            string         `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (EligibilityPolicy) TableName() string {
+	return "eligibility_policies"
+}
+
+// BeforeCreate hook
+func (e *EligibilityPolicy) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// EligibilityWarning records a warning sent to a student whose attendance dropped below a level
+type EligibilityWarning struct {
+	ID                string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID            string    `gorm:"type:uuid;not null;uniqueIndex:idx_eligibility_warning" json:"user_id"`
+	ClassID           string    `gorm:"type:uuid;not null;uniqueIndex:idx_eligibility_warning" json:"class_id"`
+	Level             float64   `gorm:"not null;uniqueIndex:idx_eligibility_warning" json:"level"`
+	AttendancePercent float64   `gorm:"not null" json:"attendance_percent"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name
+func (EligibilityWarning) TableName() string {
+	return "eligibility_warnings"
+}
+
+// BeforeCreate hook
+func (e *EligibilityWarning) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
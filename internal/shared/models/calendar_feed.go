@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CalendarFeed represents a user's personal iCalendar subscription. Its URL carries a random token of which
+// only the SHA-256 hash is stored; revoking the feed, or creating a new one, invalidates the URL.
+type CalendarFeed struct {
+	ID            string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID        string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash     string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"` // Last time a calendar client fetched the feed
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
+
+// BeforeCreate hook
+func (f *CalendarFeed) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -51,7 +51,10 @@ type Class struct {
 	Enrolled        int       `gorm:"default:0" json:"enrolled"`
 	DosenID         string    `gorm:"type:uuid;not null;index" json:"dosen_id"`
 	AssistantDosenID *string  `gorm:"type:uuid;index" json:"assistant_dosen_id"`
+	ClassRepID      *string   `gorm:"type:uuid;index" json:"class_rep_id,omitempty"` // Ketua kelas (student user ID), counter-signs lecture journals
 	Room            string    `gorm:"type:varchar(100)" json:"room"`
+	RoomID          *string   `gorm:"type:uuid;index" json:"room_id,omitempty"`
+	LocationToleranceMeters *float64 `json:"location_tolerance_meters,omitempty"` // Added to the room area on attendance scans
 	DayOfWeek       int       `gorm:"not null" json:"day_of_week"`
 	StartTime       time.Time `gorm:"not null" json:"start_time"`
 	EndTime         time.Time `gorm:"not null" json:"end_time"`
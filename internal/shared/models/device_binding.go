@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceBinding ties an account to the one device it may record attendance from
+type DeviceBinding struct {
+	ID            string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID        string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	DeviceID      string         `gorm:"type:varchar(255);not null;index" json:"device_id"` // Installation identifier sent by the app
+	Platform      string         `gorm:"type:varchar(20)" json:"platform,omitempty"`        // ios, android, web
+	DeviceTokenID *string        `gorm:"type:uuid" json:"device_token_id,omitempty"`        // Push token registered from the same device
+	PublicKey     string         `gorm:"type:varchar(64)" json:"public_key,omitempty"`      // Ed25519 key (base64url) the device signs offline captures with
+	IsActive      bool           `gorm:"default:true;index" json:"is_active"`
+	BoundAt       time.Time      `gorm:"not null" json:"bound_at"`
+	RevokedAt     *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (DeviceBinding) TableName() string {
+	return "device_bindings"
+}
+
+// BeforeCreate hook
+func (d *DeviceBinding) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// DeviceRebindRequest represents a request to move an account's binding to a new device
+type DeviceRebindRequest struct {
+	ID          string           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      string           `gorm:"type:uuid;not null;index" json:"user_id"`
+	DeviceID    string           `gorm:"type:varchar(255);not null" json:"device_id"`
+	Platform    string           `gorm:"type:varchar(20)" json:"platform,omitempty"`
+	PublicKey   string           `gorm:"type:varchar(64)" json:"public_key,omitempty"` // Key of the new device, moved to the binding on approval
+	Reason      string           `gorm:"type:text;not null" json:"reason"`
+	Status      CorrectionStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	ReviewedBy  *string          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+	ReviewNotes string           `gorm:"type:text" json:"review_notes,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `gorm:"index" json:"-"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name
+func (DeviceRebindRequest) TableName() string {
+	return "device_rebind_requests"
+}
+
+// BeforeCreate hook
+func (d *DeviceRebindRequest) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SharedDeviceFlag records one device recording attendance for several accounts,
+// within a class meeting or within a day of work check-ins
+type SharedDeviceFlag struct {
+	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type         AttendanceType `gorm:"type:varchar(20);not null" json:"type"` // kelas, or kampus for work check-ins
+	ScopeKey     string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_shared_device_scope" json:"-"`
+	DeviceID     string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_shared_device_scope" json:"device_id"`
+	ScheduleID   *string        `gorm:"type:uuid;index" json:"schedule_id,omitempty"` // Class meeting, nil for work check-ins
+	ClassID      *string        `gorm:"type:uuid;index" json:"class_id,omitempty"`
+	Date         time.Time      `gorm:"type:date;not null;index" json:"date"`
+	UserIDs      string         `gorm:"type:text;not null" json:"user_ids"` // Comma separated accounts seen on the device
+	AccountCount int            `gorm:"not null" json:"account_count"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (SharedDeviceFlag) TableName() string {
+	return "shared_device_flags"
+}
+
+// BeforeCreate hook
+func (s *SharedDeviceFlag) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
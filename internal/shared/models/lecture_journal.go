@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LectureJournalStatus represents the signing stage of a lecture journal
+type LectureJournalStatus string
+
+const (
+	JournalStatusDraft         LectureJournalStatus = "DRAFT"         // Prefilled, not yet signed by the lecturer
+	JournalStatusSubmitted     LectureJournalStatus = "SUBMITTED"     // Signed by the lecturer
+	JournalStatusCountersigned LectureJournalStatus = "COUNTERSIGNED" // Counter-signed by the class rep
+)
+
+// LectureJournal represents the Berita Acara Perkuliahan (BAP) of one class meeting
+type LectureJournal struct {
+	ID                string               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScheduleID        string               `gorm:"type:uuid;not null;uniqueIndex" json:"schedule_id"`
+	ClassID           *string              `gorm:"type:uuid;index" json:"class_id,omitempty"`
+	DosenID           string               `gorm:"type:uuid;not null;index" json:"dosen_id"`
+	Semester          string               `gorm:"type:varchar(20);index" json:"semester,omitempty"` // From the class
+	AcademicYear      string               `gorm:"type:varchar(20)" json:"academic_year,omitempty"`
+	MeetingNumber     int                  `gorm:"not null" json:"meeting_number"` // Pertemuan ke-
+	Topic             string               `gorm:"type:text" json:"topic"`
+	TeachingMethod    string               `gorm:"type:varchar(50)" json:"teaching_method"` // ceramah, diskusi, praktikum, presentasi, daring, ...
+	Notes             string               `gorm:"type:text" json:"notes"`
+	IsHeld            bool                 `gorm:"default:false" json:"is_held"`   // The lecturer actually held the class
+	LecturerCheckInAt *time.Time           `json:"lecturer_check_in_at,omitempty"` // First attendance session opened by the lecturer
+	PresentCount      int                  `gorm:"default:0" json:"present_count"` // hadir and terlambat, filled when attendance closes
+	AbsentCount       int                  `gorm:"default:0" json:"absent_count"`  // alpa, izin and sakit
+	Status            LectureJournalStatus `gorm:"type:varchar(20);not null;default:'DRAFT';index" json:"status"`
+	SubmittedAt       *time.Time           `json:"submitted_at,omitempty"`
+	CountersignedBy   *string              `gorm:"type:uuid" json:"countersigned_by,omitempty"`
+	CountersignedAt   *time.Time           `json:"countersigned_at,omitempty"`
+	CountersignNotes  string               `gorm:"type:text" json:"countersign_notes,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt       `gorm:"index" json:"-"`
+
+	// Relations
+	Schedule Schedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+}
+
+// TableName specifies the table name
+func (LectureJournal) TableName() string {
+	return "lecture_journals"
+}
+
+// BeforeCreate hook
+func (l *LectureJournal) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
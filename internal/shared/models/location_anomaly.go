@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LocationAnomalyType represents what made a located event implausible
+type LocationAnomalyType string
+
+const (
+	AnomalyImpossibleTravel    LocationAnomalyType = "IMPOSSIBLE_TRAVEL"    // Too fast from the previous located event
+	AnomalyRepeatedCoordinates LocationAnomalyType = "REPEATED_COORDINATES" // Same coordinates as earlier events, a sign of spoofing
+	AnomalyPoorAccuracy        LocationAnomalyType = "POOR_ACCURACY"        // GPS accuracy too poor to trust
+)
+
+// LocationEventSource represents the table a located event is stored in
+type LocationEventSource string
+
+const (
+	LocationSourceAttendance     LocationEventSource = "attendance"
+	LocationSourceWorkAttendance LocationEventSource = "work_attendance"
+	LocationSourceLocation       LocationEventSource = "location_history"
+	LocationSourceAccess         LocationEventSource = "access_log"
+)
+
+// LocationAnomaly records an implausible located event for staff review
+type LocationAnomaly struct {
+	ID               string               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID           string               `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type             LocationAnomalyType  `gorm:"type:varchar(30);not null;index" json:"type"`
+	Source           LocationEventSource  `gorm:"type:varchar(30);not null" json:"source"`
+	SourceID         *string              `gorm:"type:uuid;index" json:"source_id,omitempty"` // Nil when the event was blocked
+	Latitude         float64              `json:"latitude"`
+	Longitude        float64              `json:"longitude"`
+	AccuracyMeters   *float64             `json:"accuracy_meters,omitempty"`
+	OccurredAt       time.Time            `gorm:"not null;index" json:"occurred_at"`
+	PreviousSource   *LocationEventSource `gorm:"type:varchar(30)" json:"previous_source,omitempty"`
+	PreviousSourceID *string              `gorm:"type:uuid" json:"previous_source_id,omitempty"`
+	DistanceMeters   *float64             `json:"distance_meters,omitempty"`
+	SpeedKmh         *float64             `json:"speed_kmh,omitempty"`
+	Message          string               `gorm:"type:text" json:"message"`
+	Blocked          bool                 `gorm:"default:false" json:"blocked"`
+	ReviewedBy       *string              `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time           `json:"reviewed_at,omitempty"`
+	ReviewNotes      string               `gorm:"type:text" json:"review_notes,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name
+func (LocationAnomaly) TableName() string {
+	return "location_anomalies"
+}
+
+// BeforeCreate hook
+func (l *LocationAnomaly) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
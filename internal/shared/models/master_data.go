@@ -75,6 +75,11 @@ type Room struct {
 	Capacity   *int      `gorm:"type:integer" json:"capacity,omitempty"`
 	RoomType   string    `gorm:"type:varchar(50)" json:"room_type"` // classroom, lab, auditorium, etc.
 	Facilities string    `gorm:"type:text" json:"facilities"`
+	// Area used to verify class attendance scans: a linked geofence, or the room's own footprint
+	GeofenceID   *string  `gorm:"type:uuid;index" json:"geofence_id,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	RadiusMeters *float64 `json:"radius_meters,omitempty"`
 	IsActive   bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -54,6 +54,7 @@ type DeviceToken struct {
 	UserID    string    `gorm:"type:uuid;not null;index" json:"user_id"`
 	Token     string    `gorm:"type:text;not null;uniqueIndex" json:"token"`
 	Platform  string    `gorm:"type:varchar(20)" json:"platform"` // ios, android, web
+	DeviceID  string    `gorm:"type:varchar(255);index" json:"device_id,omitempty"` // Installation identifier, used for attendance device binding
 	IsActive  bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
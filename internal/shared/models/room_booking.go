@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoomBooking represents a one-off use of a room outside the class timetable, e.g. a thesis defense or a meeting
+type RoomBooking struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RoomID      *string        `gorm:"type:uuid;index" json:"room_id,omitempty"`
+	Room        string         `gorm:"type:varchar(100);not null;index" json:"room"` // Room code, as written on schedules and classes
+	Title       string         `gorm:"type:varchar(255);not null" json:"title"`
+	Date        time.Time      `gorm:"type:date;not null;index" json:"date"`
+	StartTime   time.Time      `gorm:"not null" json:"start_time"`
+	EndTime     time.Time      `gorm:"not null" json:"end_time"`
+	BookedBy    string         `gorm:"type:uuid;not null;index" json:"booked_by"`
+	CancelledAt *time.Time     `json:"cancelled_at,omitempty"`
+	CancelledBy *string        `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (RoomBooking) TableName() string {
+	return "room_bookings"
+}
+
+// BeforeCreate hook
+func (b *RoomBooking) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+	return nil
+}
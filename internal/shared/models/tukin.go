@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TukinCategory represents what a performance allowance (tunjangan kinerja) deduction is for
+type TukinCategory string
+
+const (
+	TukinCategoryLate            TukinCategory = "LATE"              // Terlambat (TL), by minutes late
+	TukinCategoryEarlyOut        TukinCategory = "EARLY_OUT"         // Pulang sebelum waktunya (PSW), by minutes early
+	TukinCategoryMissingCheckOut TukinCategory = "MISSING_CHECK_OUT" // Checked in but never out, per day
+	TukinCategoryAbsent          TukinCategory = "ABSENT"            // Tanpa keterangan (TK), per scheduled day absent without leave
+)
+
+// TukinRuleSet represents a version of the tukin deduction tables. Rule sets are never changed: a change is
+// stored as the next version, effective from a month on. Each month is computed with the version in effect
+// on its first day, and computed deductions keep the version they used.
+type TukinRuleSet struct {
+	ID                string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Version           int       `gorm:"not null;uniqueIndex" json:"version"`
+	PreviousVersionID *string   `gorm:"type:uuid" json:"previous_version_id,omitempty"`
+	EffectiveFrom     time.Time `gorm:"type:date;not null;index" json:"effective_from"` // First day of the first month it applies to
+	MaxMonthlyPercent float64   `gorm:"type:numeric(5,2);not null;default:100" json:"max_monthly_percent"`
+	Notes             string    `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy         string    `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Relations
+	Rules []TukinRule `gorm:"foreignKey:RuleSetID" json:"rules,omitempty"`
+}
+
+// TableName specifies the table name
+func (TukinRuleSet) TableName() string {
+	return "tukin_rule_sets"
+}
+
+// BeforeCreate hook
+func (t *TukinRuleSet) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TukinRule represents one row of a deduction table. LATE and EARLY_OUT rules are tiers by minutes,
+// MISSING_CHECK_OUT and ABSENT rules apply per day.
+type TukinRule struct {
+	ID         string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RuleSetID  string        `gorm:"type:uuid;not null;index" json:"rule_set_id"`
+	Category   TukinCategory `gorm:"type:varchar(30);not null" json:"category"`
+	Code       string        `gorm:"type:varchar(20);not null" json:"code"` // As finance knows it, e.g. TL1, PSW2, TK
+	MinMinutes int           `gorm:"not null;default:0" json:"min_minutes"`
+	MaxMinutes *int          `gorm:"type:integer" json:"max_minutes,omitempty"` // Nil = no upper bound
+	Percent    float64       `gorm:"type:numeric(5,2);not null" json:"percent"`
+}
+
+// TableName specifies the table name
+func (TukinRule) TableName() string {
+	return "tukin_rules"
+}
+
+// BeforeCreate hook
+func (t *TukinRule) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TukinDeduction represents the tukin deduction of a locked monthly timesheet
+type TukinDeduction struct {
+	ID             string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TimesheetID    string    `gorm:"type:uuid;not null;uniqueIndex" json:"timesheet_id"`
+	UserID         string    `gorm:"type:uuid;not null;index:idx_tukin_deduction_period" json:"user_id"`
+	Year           int       `gorm:"not null;index:idx_tukin_deduction_period" json:"year"`
+	Month          int       `gorm:"not null;index:idx_tukin_deduction_period" json:"month"`
+	RuleSetID      string    `gorm:"type:uuid;not null" json:"rule_set_id"`
+	RuleSetVersion int       `gorm:"not null" json:"rule_set_version"`
+	TotalPercent   float64   `gorm:"type:numeric(5,2);not null;default:0" json:"total_percent"` // Sum of the lines, capped by the rule set
+	Capped         bool      `gorm:"default:false" json:"capped"`
+	ComputedBy     string    `gorm:"type:uuid;not null" json:"computed_by"`
+	ComputedAt     time.Time `gorm:"type:timestamp;not null" json:"computed_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relations
+	Lines []TukinDeductionLine `gorm:"foreignKey:DeductionID" json:"lines,omitempty"`
+}
+
+// TableName specifies the table name
+func (TukinDeduction) TableName() string {
+	return "tukin_deductions"
+}
+
+// BeforeCreate hook
+func (t *TukinDeduction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TukinDeductionLine represents one deduction of a day, with the rule that produced it
+type TukinDeductionLine struct {
+	ID          string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DeductionID string        `gorm:"type:uuid;not null;index" json:"deduction_id"`
+	WorkDate    time.Time     `gorm:"type:date;not null" json:"work_date"`
+	Category    TukinCategory `gorm:"type:varchar(30);not null" json:"category"`
+	Code        string        `gorm:"type:varchar(20);not null" json:"code"`
+	Minutes     int           `gorm:"not null;default:0" json:"minutes"`
+	Percent     float64       `gorm:"type:numeric(5,2);not null" json:"percent"`
+	Explanation string        `gorm:"type:text;not null" json:"explanation"`
+}
+
+// TableName specifies the table name
+func (TukinDeductionLine) TableName() string {
+	return "tukin_deduction_lines"
+}
+
+// BeforeCreate hook
+func (t *TukinDeductionLine) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -48,15 +48,16 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 
 // Mahasiswa represents a student
 type Mahasiswa struct {
-	ID        string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID    string         `gorm:"type:uuid;uniqueIndex;not null" json:"user_id"`
-	NIM       string         `gorm:"column:nim;uniqueIndex;not null" json:"nim"`
-	Nama      string         `gorm:"not null" json:"nama"`
-	Prodi     string         `json:"prodi"` // Program Studi
-	Angkatan  int            `json:"angkatan"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID            string         `gorm:"type:uuid;uniqueIndex;not null" json:"user_id"`
+	NIM               string         `gorm:"column:nim;uniqueIndex;not null" json:"nim"`
+	Nama              string         `gorm:"not null" json:"nama"`
+	Prodi             string         `json:"prodi"` // Program Studi
+	Angkatan          int            `json:"angkatan"`
+	AcademicAdvisorID *string        `gorm:"type:uuid;index" json:"academic_advisor_id,omitempty"` // Dosen pembimbing akademik (user ID)
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -55,6 +55,8 @@ type UserShift struct {
 	ShiftID        string         `gorm:"type:uuid;not null;index" json:"shift_id"`
 	EffectiveFrom  time.Time      `gorm:"type:date;not null" json:"effective_from"`
 	EffectiveUntil *time.Time     `gorm:"type:date" json:"effective_until,omitempty"`
+	Rotation       string         `gorm:"type:varchar(255)" json:"rotation,omitempty"` // Shift codes and OFF cycled day by day from EffectiveFrom, e.g. "PAGI,PAGI,MALAM,MALAM,OFF,OFF"
+	WorkDays       string         `gorm:"type:varchar(20)" json:"work_days,omitempty"` // Weekdays the shift is worked without a rotation, 1=Monday to 7=Sunday; empty = Monday to Friday
 	IsActive       bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
@@ -83,13 +85,14 @@ type WorkSchedule struct {
 	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID       string         `gorm:"type:uuid;not null;index" json:"user_id"`
 	ScheduleDate time.Time      `gorm:"type:date;not null" json:"schedule_date"`
-	DayOfWeek    *int           `gorm:"type:integer" json:"day_of_week,omitempty"` // 0=Sunday, 1=Monday, etc.
+	DayOfWeek    *int           `gorm:"type:integer" json:"day_of_week,omitempty"` // 1=Monday to 7=Sunday
 	ShiftID      *string        `gorm:"type:uuid;index" json:"shift_id,omitempty"`
 	StartTime    time.Time      `gorm:"type:time;not null" json:"start_time"`
 	EndTime      time.Time      `gorm:"type:time;not null" json:"end_time"`
 	WorkType     string         `gorm:"type:varchar(50)" json:"work_type"`
 	Location     string         `gorm:"type:varchar(255)" json:"location"`
 	IsHoliday    bool           `gorm:"default:false" json:"is_holiday"`
+	UserShiftID  *string        `gorm:"type:uuid;index" json:"user_shift_id,omitempty"` // Assignment the roster generator created it from, nil = entered by hand
 	IsActive     bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -113,19 +116,30 @@ func (w *WorkSchedule) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Work attendance session statuses
+const (
+	WorkSessionStatusOpen    = "OPEN"
+	WorkSessionStatusClosed  = "CLOSED"  // Closed by staff
+	WorkSessionStatusExpired = "EXPIRED" // Closed on its own at ExpiresAt
+)
+
 // WorkAttendanceSession represents a work attendance session
 type WorkAttendanceSession struct {
-	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	ScheduleID  *string        `gorm:"type:uuid;index" json:"schedule_id,omitempty"`
-	SessionDate time.Time      `gorm:"type:date;not null" json:"session_date"`
-	QRCodeData  *string        `gorm:"type:varchar(255);uniqueIndex" json:"qr_code_data,omitempty"`
-	ExpiresAt   *time.Time     `gorm:"type:timestamp" json:"expires_at,omitempty"`
-	Status      string         `gorm:"type:varchar(20)" json:"status"` // OPEN, CLOSED, EXPIRED
-	CreatedBy   *string        `gorm:"type:uuid;index" json:"created_by,omitempty"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScheduleID    *string        `gorm:"type:uuid;index" json:"schedule_id,omitempty"`
+	Unit          string         `gorm:"type:varchar(255);index" json:"unit,omitempty"`    // Unit whose kiosk opened the session, only its users may scan
+	KioskDeviceID *string        `gorm:"type:uuid;index" json:"kiosk_device_id,omitempty"` // Kiosk device that opened the session, the only one shown its QR
+	SessionDate   time.Time      `gorm:"type:date;not null" json:"session_date"`
+	QRCodeData    *string        `gorm:"type:varchar(255);uniqueIndex" json:"qr_code_data,omitempty"`
+	ExpiresAt     *time.Time     `gorm:"type:timestamp" json:"expires_at,omitempty"` // When the session closes on its own
+	Status        string         `gorm:"type:varchar(20)" json:"status"`             // OPEN, CLOSED, EXPIRED
+	CreatedBy     *string        `gorm:"type:uuid;index" json:"created_by,omitempty"`
+	ClosedBy      *string        `gorm:"type:uuid" json:"closed_by,omitempty"`
+	ClosedAt      *time.Time     `gorm:"type:timestamp" json:"closed_at,omitempty"`
+	IsActive      bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
 	Schedule *WorkSchedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
@@ -146,20 +160,33 @@ func (w *WorkAttendanceSession) BeforeCreate(tx *gorm.DB) error {
 
 // WorkAttendanceRecord represents a work attendance record
 type WorkAttendanceRecord struct {
-	ID             string               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	SessionID      *string              `gorm:"type:uuid;index" json:"session_id,omitempty"`
-	ScheduleID     *string              `gorm:"type:uuid;index" json:"schedule_id,omitempty"`
-	UserID         string               `gorm:"type:uuid;not null;index" json:"user_id"`
-	AttendanceType string               `gorm:"type:varchar(20);not null" json:"attendance_type"` // CHECK_IN, CHECK_OUT
-	RecordedAt     time.Time            `gorm:"type:timestamp;not null" json:"recorded_at"`
-	Status         WorkAttendanceStatus `gorm:"type:varchar(20);not null" json:"status"`
-	IsViaUNSRIWiFi *bool                `gorm:"type:boolean" json:"is_via_unsri_wifi,omitempty"`
-	Latitude       *float64             `json:"latitude,omitempty"`
-	Longitude      *float64             `json:"longitude,omitempty"`
-	GeofenceID     *string              `gorm:"type:uuid;index" json:"geofence_id,omitempty"`
-	Notes          string               `gorm:"type:text" json:"notes"`
-	CreatedAt      time.Time            `json:"created_at"`
-	UpdatedAt      time.Time            `json:"updated_at"`
+	ID               string               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SessionID        *string              `gorm:"type:uuid;index" json:"session_id,omitempty"`
+	ScheduleID       *string              `gorm:"type:uuid;index" json:"schedule_id,omitempty"`
+	UserID           string               `gorm:"type:uuid;not null;index" json:"user_id"`
+	AttendanceType   string               `gorm:"type:varchar(20);not null" json:"attendance_type"` // CHECK_IN, CHECK_OUT, ABSENCE (recorded by the daily sweep)
+	RecordedAt       time.Time            `gorm:"type:timestamp;not null" json:"recorded_at"`
+	WorkDate         *time.Time           `gorm:"type:date;index" json:"work_date,omitempty"` // Work day the record counts for, the shift's start date
+	Status           WorkAttendanceStatus `gorm:"type:varchar(20);not null" json:"status"`
+	EffectiveAt      *time.Time           `gorm:"type:timestamp" json:"effective_at,omitempty"` // RecordedAt after the policy's rounding, used for status and hours
+	PolicyID         *string              `gorm:"type:uuid;index" json:"policy_id,omitempty"`   // Policy version that produced the status, nil = built-in rules
+	PolicyVersion    *int                 `gorm:"type:integer" json:"policy_version,omitempty"`
+	IsViaUNSRIWiFi   *bool                `gorm:"type:boolean" json:"is_via_unsri_wifi,omitempty"` // Set from NetworkVerdict, never by the client
+	NetworkVerdict   NetworkVerdict       `gorm:"type:varchar(20)" json:"network_verdict,omitempty"`
+	ClientIP         string               `gorm:"type:varchar(45)" json:"client_ip,omitempty"`  // As forwarded by the gateway
+	WifiBSSID        *string              `gorm:"type:varchar(17)" json:"wifi_bssid,omitempty"` // Access point of a verified Wi-Fi attestation
+	Latitude         *float64             `json:"latitude,omitempty"`
+	Longitude        *float64             `json:"longitude,omitempty"`
+	AccuracyMeters   *float64             `json:"accuracy_meters,omitempty"` // GPS accuracy reported by the device
+	GeofenceID       *string              `gorm:"type:uuid;index" json:"geofence_id,omitempty"`
+	DeviceID         *string              `gorm:"type:varchar(255);index" json:"device_id,omitempty"` // Device the check-in was made from
+	Notes            string               `gorm:"type:text" json:"notes"`
+	AutoClosed       bool                 `gorm:"default:false" json:"auto_closed,omitempty"`        // Check-out recorded by the daily sweep for a missing one
+	CorrectableUntil *time.Time           `gorm:"type:timestamp" json:"correctable_until,omitempty"` // Until when a supervisor can amend a record of the daily sweep
+	AmendedBy        *string              `gorm:"type:uuid" json:"amended_by,omitempty"`
+	AmendedAt        *time.Time           `gorm:"type:timestamp" json:"amended_at,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
 
 	// Relations
 	User     User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NetworkVerdict represents the server's classification of the network a work check-in came from
+type NetworkVerdict string
+
+const (
+	NetworkVerdictCampusNetwork NetworkVerdict = "CAMPUS_NETWORK" // Client IP inside a campus range
+	NetworkVerdictCampusWiFi    NetworkVerdict = "CAMPUS_WIFI"    // Signed attestation of a campus access point
+	NetworkVerdictOffCampus     NetworkVerdict = "OFF_CAMPUS"
+)
+
+// CheckInRequirementType represents what a unit requires before a work check-in is accepted
+type CheckInRequirementType string
+
+const (
+	CheckInRequireNone               CheckInRequirementType = "NONE"
+	CheckInRequireCampusNetwork      CheckInRequirementType = "CAMPUS_NETWORK"
+	CheckInRequireGeofence           CheckInRequirementType = "GEOFENCE"
+	CheckInRequireNetworkOrGeofence  CheckInRequirementType = "NETWORK_OR_GEOFENCE"
+	CheckInRequireNetworkAndGeofence CheckInRequirementType = "NETWORK_AND_GEOFENCE"
+)
+
+// CheckInRequirement represents the check-in requirement of a unit.
+// Unit is matched against the staff unit, or the prodi of a dosen. Units without one require nothing.
+type CheckInRequirement struct {
+	ID          string                 `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Unit        string                 `gorm:"type:varchar(255);not null;uniqueIndex" json:"unit"`
+	Requirement CheckInRequirementType `gorm:"type:varchar(30);not null" json:"requirement"`
+	UpdatedBy   string                 `gorm:"type:uuid;not null" json:"updated_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CheckInRequirement) TableName() string {
+	return "check_in_requirements"
+}
+
+// BeforeCreate hook
+func (c *CheckInRequirement) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// KioskDevice represents a unit front desk tablet registered by staff to display kiosk QR codes.
+// The tablet authenticates with the token issued at registration, only its hash is stored.
+type KioskDevice struct {
+	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Unit         string         `gorm:"type:varchar(255);not null;index" json:"unit"`
+	Name         string         `gorm:"type:varchar(255);not null" json:"name"`
+	TokenHash    string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	IsActive     bool           `gorm:"default:true;index" json:"is_active"`
+	RegisteredBy string         `gorm:"type:uuid;not null" json:"registered_by"`
+	RevokedAt    *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (KioskDevice) TableName() string {
+	return "kiosk_devices"
+}
+
+// BeforeCreate hook
+func (k *KioskDevice) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Work attendance policy scopes, from most to least specific: user, shift pattern, unit, global
+const (
+	PolicyScopeUser  PolicyScope = "USER"
+	PolicyScopeShift PolicyScope = "SHIFT"
+	PolicyScopeUnit  PolicyScope = "UNIT" // Matched against the staff unit, or the prodi of a dosen
+)
+
+// MissingCheckOutRule represents how the daily sweep closes a work day checked into but never out of
+type MissingCheckOutRule string
+
+const (
+	MissingCheckOutNoHours  MissingCheckOutRule = "NO_HOURS"  // Closed at the check-in, the day has no hours
+	MissingCheckOutShiftEnd MissingCheckOutRule = "SHIFT_END" // Closed at the scheduled shift end, unscheduled days have no hours
+)
+
+// WorkAttendancePolicy represents the rules work check-ins and check-outs are judged by.
+// Minutes are counted from the shift start and end. Policies are versioned: an update stores a new
+// version and deactivates the previous one, so records keep pointing at the rules that produced them.
+type WorkAttendancePolicy struct {
+	ID                   string              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Scope                PolicyScope         `gorm:"type:varchar(20);not null;index" json:"scope"`
+	UserID               *string             `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	ShiftID              *string             `gorm:"type:uuid;index" json:"shift_id,omitempty"`
+	Unit                 *string             `gorm:"type:varchar(255);index" json:"unit,omitempty"`
+	Version              int                 `gorm:"not null;default:1" json:"version"`
+	PreviousVersionID    *string             `gorm:"type:uuid" json:"previous_version_id,omitempty"`
+	LateGraceMinutes     int                 `gorm:"not null;default:15" json:"late_grace_minutes"`         // Check-ins later than the start plus this are LATE_IN
+	EarlyOutGraceMinutes int                 `gorm:"not null;default:15" json:"early_out_grace_minutes"`    // Check-outs earlier than the end minus this are EARLY_OUT
+	RoundingMinutes      int                 `gorm:"not null;default:0" json:"rounding_minutes"`            // Recorded times are rounded to the nearest multiple, 0 = none
+	MinWorkMinutes       int                 `gorm:"not null;default:0" json:"min_work_minutes"`            // Shorter days, breaks excluded, are EARLY_OUT, 0 = none
+	BreakDurationMinutes *int                `gorm:"type:integer" json:"break_duration_minutes,omitempty"`  // Nil = the shift pattern's break
+	CheckInOpensMinutes  int                 `gorm:"not null;default:120" json:"check_in_opens_minutes"`    // How long before the start check-ins are accepted
+	CheckInClosesMinutes *int                `gorm:"type:integer" json:"check_in_closes_minutes,omitempty"` // How long after the start, nil = until the shift ends
+	RefuseOutsideWindow  bool                `gorm:"default:false" json:"refuse_outside_window"`            // Refuse check-ins outside the window instead of accepting them
+	MissingCheckOut      MissingCheckOutRule `gorm:"type:varchar(20);not null;default:'NO_HOURS'" json:"missing_check_out"`
+	IsActive             bool                `gorm:"default:true" json:"is_active"`
+	CreatedBy            string              `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt      `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name
+func (WorkAttendancePolicy) TableName() string {
+	return "work_attendance_policies"
+}
+
+// BeforeCreate hook
+func (w *WorkAttendancePolicy) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShiftSwapType represents what a shift swap request exchanges
+type ShiftSwapType string
+
+const (
+	ShiftSwapTypeSwap  ShiftSwapType = "SWAP"  // Both users take over each other's shift
+	ShiftSwapTypeCover ShiftSwapType = "COVER" // The target user takes over the requester's shift
+)
+
+// ShiftSwapStatus represents shift swap request status
+type ShiftSwapStatus string
+
+const (
+	ShiftSwapStatusPending   ShiftSwapStatus = "PENDING"  // Awaiting the target user
+	ShiftSwapStatusAccepted  ShiftSwapStatus = "ACCEPTED" // Accepted by the target user, awaiting a supervisor
+	ShiftSwapStatusApproved  ShiftSwapStatus = "APPROVED" // Approved, the schedules were exchanged
+	ShiftSwapStatusDeclined  ShiftSwapStatus = "DECLINED" // Declined by the target user
+	ShiftSwapStatusRejected  ShiftSwapStatus = "REJECTED" // Rejected by a supervisor
+	ShiftSwapStatusCancelled ShiftSwapStatus = "CANCELLED"
+)
+
+// ShiftSwapRequest represents a request to exchange or hand over a work schedule entry between two users
+type ShiftSwapRequest struct {
+	ID                  string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type                ShiftSwapType   `gorm:"type:varchar(20);not null" json:"type"`
+	RequesterID         string          `gorm:"type:uuid;not null;index" json:"requester_id"`
+	RequesterScheduleID string          `gorm:"type:uuid;not null;index" json:"requester_schedule_id"`
+	TargetUserID        string          `gorm:"type:uuid;not null;index" json:"target_user_id"`
+	TargetScheduleID    *string         `gorm:"type:uuid;index" json:"target_schedule_id,omitempty"` // Nil for a cover
+	Reason              string          `gorm:"type:text;not null" json:"reason"`
+	Status              ShiftSwapStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	RespondedAt         *time.Time      `gorm:"type:timestamp" json:"responded_at,omitempty"`
+	ReviewedBy          *string         `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt          *time.Time      `gorm:"type:timestamp" json:"reviewed_at,omitempty"`
+	ReviewNotes         string          `gorm:"type:text" json:"review_notes,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt  `gorm:"index" json:"-"`
+
+	// Relations
+	RequesterSchedule WorkSchedule     `gorm:"foreignKey:RequesterScheduleID" json:"requester_schedule,omitempty"`
+	TargetSchedule    *WorkSchedule    `gorm:"foreignKey:TargetScheduleID" json:"target_schedule,omitempty"`
+	Events            []ShiftSwapEvent `gorm:"foreignKey:SwapRequestID" json:"events,omitempty"`
+}
+
+// TableName specifies the table name
+func (ShiftSwapRequest) TableName() string {
+	return "shift_swap_requests"
+}
+
+// BeforeCreate hook
+func (s *ShiftSwapRequest) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ShiftSwapAction represents a step in the history of a shift swap request
+type ShiftSwapAction string
+
+const (
+	ShiftSwapActionRequested  ShiftSwapAction = "REQUESTED"
+	ShiftSwapActionAccepted   ShiftSwapAction = "ACCEPTED"
+	ShiftSwapActionDeclined   ShiftSwapAction = "DECLINED"
+	ShiftSwapActionApproved   ShiftSwapAction = "APPROVED"
+	ShiftSwapActionRejected   ShiftSwapAction = "REJECTED"
+	ShiftSwapActionCancelled  ShiftSwapAction = "CANCELLED"
+	ShiftSwapActionReassigned ShiftSwapAction = "REASSIGNED" // A work schedule moved to another user
+)
+
+// ShiftSwapEvent records a step of a shift swap request, the audit trail of the exchange
+type ShiftSwapEvent struct {
+	ID            string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	SwapRequestID string          `gorm:"type:uuid;not null;index" json:"swap_request_id"`
+	ActorID       string          `gorm:"type:uuid;not null" json:"actor_id"`
+	Action        ShiftSwapAction `gorm:"type:varchar(20);not null" json:"action"`
+	ScheduleID    *string         `gorm:"type:uuid" json:"schedule_id,omitempty"` // Reassigned work schedule
+	FromUserID    *string         `gorm:"type:uuid" json:"from_user_id,omitempty"`
+	ToUserID      *string         `gorm:"type:uuid" json:"to_user_id,omitempty"`
+	Notes         string          `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// TableName specifies the table name
+func (ShiftSwapEvent) TableName() string {
+	return "shift_swap_events"
+}
+
+// BeforeCreate hook
+func (s *ShiftSwapEvent) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkAttendanceSweep records the daily sweep of a work day: missing check-outs closed, absences recorded
+// and anomaly summaries sent. One sweep runs per work day.
+type WorkAttendanceSweep struct {
+	ID               string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkDate         time.Time  `gorm:"type:date;not null;uniqueIndex" json:"work_date"`
+	StartedAt        time.Time  `gorm:"type:timestamp;not null" json:"started_at"`
+	CompletedAt      *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	CorrectableUntil time.Time  `gorm:"type:timestamp;not null" json:"correctable_until"`
+	ClosedCheckOuts  int        `gorm:"not null;default:0" json:"closed_check_outs"`
+	AbsentCount      int        `gorm:"not null;default:0" json:"absent_count"`
+	OnLeaveCount     int        `gorm:"not null;default:0" json:"on_leave_count"`
+	NotifiedUsers    int        `gorm:"not null;default:0" json:"notified_users"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (WorkAttendanceSweep) TableName() string {
+	return "work_attendance_sweeps"
+}
+
+// BeforeCreate hook
+func (w *WorkAttendanceSweep) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// UnitSupervisor represents a user who supervises the work attendance of a unit.
+// Unit is matched against the staff unit, or the prodi of a dosen.
+type UnitSupervisor struct {
+	ID           string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Unit         string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_unit_supervisor" json:"unit"`
+	SupervisorID string    `gorm:"type:uuid;not null;uniqueIndex:idx_unit_supervisor" json:"supervisor_id"`
+	CreatedBy    string    `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (UnitSupervisor) TableName() string {
+	return "unit_supervisors"
+}
+
+// BeforeCreate hook
+func (u *UnitSupervisor) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	return nil
+}
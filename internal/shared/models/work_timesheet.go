@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TimesheetStatus represents the review state of a monthly timesheet
+type TimesheetStatus string
+
+const (
+	TimesheetStatusDraft    TimesheetStatus = "DRAFT"    // Computed, may be recomputed
+	TimesheetStatusApproved TimesheetStatus = "APPROVED" // Reviewed by a supervisor, recomputing resets it to draft
+	TimesheetStatusLocked   TimesheetStatus = "LOCKED"   // Final, used for payroll and allowances
+)
+
+// TimesheetDayType represents how a day of a timesheet is counted
+type TimesheetDayType string
+
+const (
+	TimesheetDayPresent TimesheetDayType = "PRESENT"
+	TimesheetDayAbsent  TimesheetDayType = "ABSENT"
+	TimesheetDayLeave   TimesheetDayType = "LEAVE"
+	TimesheetDayHoliday TimesheetDayType = "HOLIDAY"
+	TimesheetDayOff     TimesheetDayType = "OFF" // Not scheduled and not worked
+)
+
+// WorkTimesheet represents a user's work attendance for a month, computed from work schedules,
+// check-ins and check-outs, approved leave and the academic calendar
+type WorkTimesheet struct {
+	ID              string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          string          `gorm:"type:uuid;not null;uniqueIndex:idx_work_timesheet_period" json:"user_id"`
+	Year            int             `gorm:"not null;uniqueIndex:idx_work_timesheet_period" json:"year"`
+	Month           int             `gorm:"not null;uniqueIndex:idx_work_timesheet_period" json:"month"`
+	Status          TimesheetStatus `gorm:"type:varchar(20);not null;default:'DRAFT'" json:"status"`
+	ScheduledDays   int             `gorm:"not null;default:0" json:"scheduled_days"`
+	PresentDays     int             `gorm:"not null;default:0" json:"present_days"`
+	AbsentDays      int             `gorm:"not null;default:0" json:"absent_days"`
+	LeaveDays       int             `gorm:"not null;default:0" json:"leave_days"`
+	HolidayDays     int             `gorm:"not null;default:0" json:"holiday_days"`
+	WorkedMinutes   int             `gorm:"not null;default:0" json:"worked_minutes"`
+	LateMinutes     int             `gorm:"not null;default:0" json:"late_minutes"`
+	EarlyOutMinutes int             `gorm:"not null;default:0" json:"early_out_minutes"`
+	OvertimeMinutes int             `gorm:"not null;default:0" json:"overtime_minutes"`
+	ComputedAt      time.Time       `gorm:"type:timestamp;not null" json:"computed_at"`
+	ApprovedBy      *string         `gorm:"type:uuid" json:"approved_by,omitempty"`
+	ApprovedAt      *time.Time      `gorm:"type:timestamp" json:"approved_at,omitempty"`
+	LockedBy        *string         `gorm:"type:uuid" json:"locked_by,omitempty"`
+	LockedAt        *time.Time      `gorm:"type:timestamp" json:"locked_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	// Relations
+	Days []WorkTimesheetDay `gorm:"foreignKey:TimesheetID" json:"days,omitempty"`
+}
+
+// TableName specifies the table name
+func (WorkTimesheet) TableName() string {
+	return "work_timesheets"
+}
+
+// BeforeCreate hook
+func (w *WorkTimesheet) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// WorkTimesheetDay represents one work day of a timesheet
+type WorkTimesheetDay struct {
+	ID              string           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TimesheetID     string           `gorm:"type:uuid;not null;index" json:"timesheet_id"`
+	WorkDate        time.Time        `gorm:"type:date;not null" json:"work_date"`
+	DayType         TimesheetDayType `gorm:"type:varchar(20);not null" json:"day_type"`
+	ScheduleID      *string          `gorm:"type:uuid" json:"schedule_id,omitempty"`
+	PolicyID        *string          `gorm:"type:uuid" json:"policy_id,omitempty"` // Work attendance policy version applied, nil = built-in rules
+	LeaveRequestID  *string          `gorm:"type:uuid" json:"leave_request_id,omitempty"`
+	CheckInAt       *time.Time       `gorm:"type:timestamp" json:"check_in_at,omitempty"`
+	CheckOutAt      *time.Time       `gorm:"type:timestamp" json:"check_out_at,omitempty"`
+	BreakMinutes    int              `gorm:"not null;default:0" json:"break_minutes"`
+	WorkedMinutes   int              `gorm:"not null;default:0" json:"worked_minutes"`
+	LateMinutes     int              `gorm:"not null;default:0" json:"late_minutes"`
+	EarlyOutMinutes int              `gorm:"not null;default:0" json:"early_out_minutes"`
+	OvertimeMinutes int              `gorm:"not null;default:0" json:"overtime_minutes"`
+	MissingCheckOut bool             `gorm:"default:false" json:"missing_check_out"` // No check-out, or one closed by the daily sweep and never amended
+	Notes           string           `gorm:"type:text" json:"notes,omitempty"`
+}
+
+// TableName specifies the table name
+func (WorkTimesheetDay) TableName() string {
+	return "work_timesheet_days"
+}
+
+// BeforeCreate hook
+func (w *WorkTimesheetDay) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -168,6 +168,16 @@ func ErrorResponse(c *gin.Context, statusCode int, err error) {
 		errorInfo.Details = details
 	}
 
+	// Include structured details the error carries
+	if len(appErr.Details) > 0 {
+		if errorInfo.Details == nil {
+			errorInfo.Details = make(map[string]interface{})
+		}
+		for key, value := range appErr.Details {
+			errorInfo.Details[key] = value
+		}
+	}
+
 	c.JSON(statusCode, Response{
 		Success: false,
 		Error:   errorInfo,
@@ -107,6 +107,26 @@ func (h *UserHandler) GetMahasiswaByNIM(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, result)
 }
 
+// SetAcademicAdvisor handles set academic advisor of a mahasiswa request
+func (h *UserHandler) SetAcademicAdvisor(c *gin.Context) {
+	userRole := c.GetString("user_role")
+	nim := c.Param("nim")
+
+	var req service.SetAcademicAdvisorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.service.SetAcademicAdvisor(c.Request.Context(), userRole, nim, req)
+	if err != nil {
+		utils.ErrorResponse(c, 0, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
+
 // GetDosenByNIP handles get dosen by NIP request
 func (h *UserHandler) GetDosenByNIP(c *gin.Context) {
 	nip := c.Param("nip")
@@ -23,6 +23,7 @@ func SetupRoutes(router *gin.Engine, handler *UserHandler, jwtToken *jwt.JWT) {
 		v1.GET("/search", handler.SearchUsers)
 		v1.GET("/:id", handler.GetUserByID)
 		v1.GET("/mahasiswa/:nim", handler.GetMahasiswaByNIM)
+		v1.PUT("/mahasiswa/:nim/advisor", handler.SetAcademicAdvisor)
 		v1.GET("/dosen/:nip", handler.GetDosenByNIP)
 		v1.GET("/staff/:nip", handler.GetStaffByNIP)
 	}
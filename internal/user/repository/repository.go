@@ -90,6 +90,13 @@ func (r *UserRepository) UpdateMahasiswa(ctx context.Context, mahasiswa *models.
 	return r.db.WithContext(ctx).Save(mahasiswa).Error
 }
 
+// UpdateMahasiswaAdvisor sets the academic advisor of a mahasiswa
+func (r *UserRepository) UpdateMahasiswaAdvisor(ctx context.Context, mahasiswaID string, advisorID *string) error {
+	return r.db.WithContext(ctx).Model(&models.Mahasiswa{}).
+		Where("id = ?", mahasiswaID).
+		Update("academic_advisor_id", advisorID).Error
+}
+
 // UpdateDosen updates dosen data
 func (r *UserRepository) UpdateDosen(ctx context.Context, dosen *models.Dosen) error {
 	return r.db.WithContext(ctx).Save(dosen).Error
@@ -109,6 +109,40 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID string, req
 	return s.repo.GetUserByID(ctx, userID)
 }
 
+// SetAcademicAdvisorRequest represents set academic advisor request
+type SetAcademicAdvisorRequest struct {
+	AdvisorID *string `json:"advisor_id"` // Dosen user ID, nil to remove the advisor
+}
+
+// SetAcademicAdvisor assigns a dosen as the academic advisor of a mahasiswa, staff only
+func (s *UserService) SetAcademicAdvisor(ctx context.Context, role string, nim string, req SetAcademicAdvisorRequest) (*models.Mahasiswa, error) {
+	if role != string(models.RoleStaff) {
+		return nil, apperrors.NewForbiddenError("only staff can assign academic advisors")
+	}
+
+	mahasiswa, err := s.repo.GetMahasiswaByNIM(ctx, nim)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("mahasiswa", nim)
+	}
+
+	if req.AdvisorID != nil {
+		advisor, err := s.repo.GetUserByID(ctx, *req.AdvisorID)
+		if err != nil {
+			return nil, apperrors.NewNotFoundError("user", *req.AdvisorID)
+		}
+		if advisor.Role != models.RoleDosen {
+			return nil, apperrors.NewValidationError("academic advisor must be a dosen")
+		}
+	}
+
+	if err := s.repo.UpdateMahasiswaAdvisor(ctx, mahasiswa.ID, req.AdvisorID); err != nil {
+		return nil, apperrors.NewInternalError("failed to update mahasiswa", err)
+	}
+	mahasiswa.AcademicAdvisorID = req.AdvisorID
+
+	return mahasiswa, nil
+}
+
 // GetUserByID gets user by ID
 func (s *UserService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
 	return s.repo.GetUserByID(ctx, id)
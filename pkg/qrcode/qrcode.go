@@ -1,8 +1,12 @@
 package qrcode
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/skip2/go-qrcode"
@@ -14,6 +18,7 @@ type QRData struct {
 	ScheduleID string    `json:"schedule_id,omitempty"` // For attendance QR only
 	ExpiresAt  time.Time `json:"expires_at,omitempty"`  // For attendance QR only
 	Type       string    `json:"type"`                  // "kelas", "kampus", "gate"
+	Signature  string    `json:"sig,omitempty"`         // HMAC of the attendance fields, see Sign
 
 	// Gate access specific fields (for UNSRI gate integration)
 	// Only these fields are included in gate QR code
@@ -50,3 +55,34 @@ func ParseQRData(data string) (*QRData, error) {
 
 	return &qrData, nil
 }
+
+// signingPayload returns the attendance fields covered by the signature
+func signingPayload(data QRData) string {
+	return strings.Join([]string{
+		data.SessionID,
+		data.ScheduleID,
+		data.ExpiresAt.UTC().Format(time.RFC3339Nano),
+		data.Type,
+	}, "|")
+}
+
+// Sign sets the signature of attendance QR data so scans, including ones synced later
+// from offline devices, can be checked against the QR the server issued
+func Sign(data *QRData, key []byte) {
+	data.Signature = HMAC(key, signingPayload(*data))
+}
+
+// Verify checks the signature of attendance QR data
+func Verify(data *QRData, key []byte) bool {
+	if data.Signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(data.Signature), []byte(HMAC(key, signingPayload(*data))))
+}
+
+// HMAC returns the base64url encoded HMAC-SHA256 of message
+func HMAC(key []byte, message string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}